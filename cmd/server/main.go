@@ -10,9 +10,22 @@ import (
 	"syscall"
 	"time"
 
+	"gin-weather/internal/archive"
 	"gin-weather/internal/config"
+	"gin-weather/internal/consensus"
 	"gin-weather/internal/controller"
+	"gin-weather/internal/digest"
+	"gin-weather/internal/grid"
+	"gin-weather/internal/historical"
+	"gin-weather/internal/indices"
+	"gin-weather/internal/jobs"
+	"gin-weather/internal/live"
+	"gin-weather/internal/notify"
+	"gin-weather/internal/routing"
 	"gin-weather/internal/service"
+	"gin-weather/internal/shadow"
+	"gin-weather/internal/tiles"
+	"gin-weather/internal/webhook"
 )
 
 func main() {
@@ -23,16 +36,136 @@ func main() {
 	}
 
 	// 创建天气服务实例
-	var weatherService service.WeatherService
-	switch cfg.Weather.Provider {
-	case "openweathermap":
-		weatherService = service.NewOpenWeatherMapService(&cfg.Weather)
-	default:
-		log.Fatalf("不支持的天气服务提供商: %s", cfg.Weather.Provider)
+	weatherService, err := service.New(&cfg.Weather)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// 附近城市和经纬度范围查询总是由主服务提供
+	areaSearcher, _ := weatherService.(service.AreaSearcher)
+
+	// 天气服务支持图层瓦片时启用瓦片代理，瓦片总是由主服务提供
+	var tileProxy *tiles.Proxy
+	if tileProvider, ok := weatherService.(service.TileProvider); ok {
+		tileProxy, err = tiles.New(&cfg.Tiles, tileProvider)
+		if err != nil {
+			log.Fatalf("初始化瓦片代理失败: %v", err)
+		}
+	}
+
+	// 配置了多数据提供商融合时，融合服务可以在路由规则和 provider 参数中以 consensus 引用；
+	// 没有配置路由规则时所有请求都使用融合结果
+	var extraServices map[string]service.WeatherService
+	if cfg.Consensus.File != "" {
+		consensusService, err := consensus.Load(cfg.Consensus.File, &cfg.Weather, weatherService)
+		if err != nil {
+			log.Fatalf("加载融合配置失败: %v", err)
+		}
+		if cfg.Routing.RulesFile != "" {
+			extraServices = map[string]service.WeatherService{consensus.Name: consensusService}
+		} else {
+			weatherService = consensusService
+		}
+	}
+
+	// 配置了路由规则时，按请求的位置在多个数据提供商之间选择
+	if cfg.Routing.RulesFile != "" {
+		weatherService, err = routing.Load(cfg.Routing.RulesFile, &cfg.Weather, weatherService, extraServices)
+		if err != nil {
+			log.Fatalf("加载路由规则失败: %v", err)
+		}
+	}
+
+	// 网格采样一次请求大量的点，使用路由后的服务，不参与影子模式对比，也不记入观测存档
+	gridService := grid.New(&cfg.Grid, weatherService)
+
+	// 启用影子模式时，抽样的请求会在后台同时发给候选服务，用户只收到主服务的结果
+	var weatherShadow *shadow.Shadow
+	if cfg.Shadow.Provider != "" {
+		candidateConfig := cfg.Weather
+		candidateConfig.Provider = cfg.Shadow.Provider
+		candidateConfig.APIKey = cfg.Shadow.APIKey
+		candidateConfig.BaseURL = cfg.Shadow.BaseURL
+		candidate, err := service.New(&candidateConfig)
+		if err != nil {
+			log.Fatalf("初始化影子模式失败: %v", err)
+		}
+		weatherShadow, err = shadow.New(&cfg.Shadow, cfg.Weather.Provider, candidate)
+		if err != nil {
+			log.Fatalf("初始化影子模式失败: %v", err)
+		}
+		weatherService = weatherShadow.Wrap(weatherService)
+	}
+
+	// 打开观测存档，之后所有组件获取的天气都会被记录
+	var weatherArchive *archive.Archive
+	if cfg.Archive.Path != "" {
+		weatherArchive, err = archive.New(&cfg.Archive)
+		if err != nil {
+			log.Fatalf("初始化观测存档失败: %v", err)
+		}
+		weatherService = weatherArchive.Wrap(weatherService)
+		weatherArchive.Start()
+	}
+
+	// 加载生活指数规则
+	indicesEngine, err := indices.NewEngine(cfg.Indices.RulesFile)
+	if err != nil {
+		log.Fatalf("加载生活指数规则失败: %v", err)
+	}
+
+	// 创建并启动批量任务管理器
+	jobManager, err := jobs.NewManager(&cfg.Jobs, weatherService)
+	if err != nil {
+		log.Fatalf("初始化批量任务失败: %v", err)
+	}
+	jobManager.Start()
+
+	// 创建实时推送的共享刷新调度器
+	liveScheduler := live.NewScheduler(weatherService, time.Duration(cfg.Live.RefreshInterval)*time.Second)
+
+	// 加载通知渠道
+	notifier, err := notify.Load(cfg.Notify.ChannelsFile, time.Duration(cfg.Notify.Timeout)*time.Second)
+	if err != nil {
+		log.Fatalf("加载通知渠道失败: %v", err)
+	}
+
+	// 创建并启动阈值告警检查
+	webhookManager, err := webhook.NewManager(&cfg.Webhooks, weatherService, notifier)
+	if err != nil {
+		log.Fatalf("初始化 Webhook 失败: %v", err)
+	}
+	webhookManager.Start()
+
+	// 创建并启动定时天气简报
+	digestManager, err := digest.NewManager(&cfg.Digests, weatherService, notifier)
+	if err != nil {
+		log.Fatalf("初始化天气简报失败: %v", err)
+	}
+	digestManager.Start()
+
+	// 创建历史天气查询服务
+	historicalService, err := historical.New(&cfg.Historical, weatherService)
+	if err != nil {
+		log.Fatalf("初始化历史天气查询失败: %v", err)
 	}
 
 	// 设置路由
-	router := controller.SetupRouter(cfg, weatherService)
+	router := controller.SetupRouter(cfg, &controller.Dependencies{
+		WeatherService: weatherService,
+		IndicesEngine:  indicesEngine,
+		JobManager:     jobManager,
+		LiveScheduler:  liveScheduler,
+		WebhookManager: webhookManager,
+		Notifier:       notifier,
+		DigestManager:  digestManager,
+		Archive:        weatherArchive,
+		Historical:     historicalService,
+		Shadow:         weatherShadow,
+		Tiles:          tileProxy,
+		AreaSearcher:   areaSearcher,
+		Grid:           gridService,
+	})
 
 	// 创建 HTTP 服务器
 	server := &http.Server{
@@ -40,6 +173,9 @@ func main() {
 		Handler: router,
 	}
 
+	// 开始关闭时先断开实时推送连接，否则长连接会阻塞优雅关闭
+	server.RegisterOnShutdown(liveScheduler.Close)
+
 	// 启动服务器的 goroutine
 	go func() {
 		log.Printf("服务器启动在 %s:%d", cfg.Server.Host, cfg.Server.Port)
@@ -67,5 +203,34 @@ func main() {
 		log.Fatalf("服务器强制关闭: %v", err)
 	}
 
+	// 停止批量任务，未完成的任务会在下次启动时继续
+	if err := jobManager.Stop(ctx); err != nil {
+		log.Printf("批量任务未能在超时前停止: %v", err)
+	}
+
+	// 停止告警检查，等待进行中的投递结束
+	if err := webhookManager.Stop(ctx); err != nil {
+		log.Printf("Webhook 投递未能在超时前停止: %v", err)
+	}
+
+	// 停止天气简报，等待进行中的发送结束
+	if err := digestManager.Stop(ctx); err != nil {
+		log.Printf("天气简报发送未能在超时前停止: %v", err)
+	}
+
+	// 等待进行中的影子请求结束
+	if weatherShadow != nil {
+		if err := weatherShadow.Stop(ctx); err != nil {
+			log.Printf("影子请求未能在超时前结束: %v", err)
+		}
+	}
+
+	// 最后停止观测存档，写入其他组件停止前获取的观测
+	if weatherArchive != nil {
+		if err := weatherArchive.Stop(ctx); err != nil {
+			log.Printf("观测存档未能在超时前停止: %v", err)
+		}
+	}
+
 	log.Println("服务器已关闭")
 }
@@ -51,7 +51,7 @@ export interface Current {
   pressure: number
   humidity: number
   visibility: number
-  uv_index: number
+  uv_index?: number
   weather: Weather[]
   wind: Wind
   clouds: Clouds
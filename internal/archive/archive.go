@@ -0,0 +1,381 @@
+// Package archive 把获取到的每一次天气观测写入 SQLite，并按配置定时轮询固定位置，
+// 用于在不调用上游历史接口的情况下查询过去的天气；获取到的预报和定时轮询的位置的预报也会保存快照，用于之后与实况比较评估预报准确度。
+//
+// 观测在写入前统一换算为摄氏度和 m/s，查询时再换算为请求的单位系统；
+// 写入通过队列异步进行，不会拖慢天气查询
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/derived"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// ErrTooManyPoints 查询结果的数据点过多
+var ErrTooManyPoints = errors.New("查询结果的数据点过多，请缩小时间范围或增大降采样间隔")
+
+const (
+	// queueSize 等待写入的观测队列长度，队列满时丢弃新的观测
+	queueSize = 1024
+	// batchSize 单个事务最多写入的观测数
+	batchSize = 100
+	// pollConcurrency 定时轮询时并发查询天气的位置数上限
+	pollConcurrency = 4
+	// pruneInterval 清理过期记录的间隔
+	pruneInterval = time.Hour
+	// forecastInterval 定时轮询保存预报快照的最小间隔，与上游预报的更新周期一致，避免重复保存相同的预报
+	forecastInterval = 3 * time.Hour
+)
+
+// Archive 观测存档
+type Archive struct {
+	config    *config.ArchiveConfig
+	store     *Store
+	locations []model.WeatherRequest
+	queue     chan observation
+	// forecastQueue 等待写入的预报快照，每项是一份预报的全部时段
+	forecastQueue chan []forecastRow
+
+	// weatherService 带记录功能的天气服务，由 Wrap 设置，定时轮询通过它查询天气
+	weatherService service.WeatherService
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// flush 在轮询停止后关闭，通知写入协程写完剩余观测；written 在写入协程退出时关闭，未启动时为 nil
+	flush   chan struct{}
+	written chan struct{}
+	// lastForecast 上一次轮询预报的时间，只在轮询协程中读写
+	lastForecast time.Time
+}
+
+// New 打开存档数据库并解析需要定时轮询的位置
+func New(cfg *config.ArchiveConfig) (*Archive, error) {
+	locations, err := ParseLocations(cfg.Locations)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := OpenStore(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Archive{
+		config:        cfg,
+		store:         store,
+		locations:     locations,
+		queue:         make(chan observation, queueSize),
+		forecastQueue: make(chan []forecastRow, queueSize/16),
+		ctx:           ctx,
+		cancel:        cancel,
+		flush:         make(chan struct{}),
+	}, nil
+}
+
+// Wrap 返回会记录每次查询结果的天气服务，应在其他组件使用天气服务之前调用
+func (a *Archive) Wrap(weatherService service.WeatherService) service.WeatherService {
+	a.weatherService = &recordingService{inner: weatherService, archive: a}
+	return a.weatherService
+}
+
+// Start 启动写入、定时轮询和过期清理
+func (a *Archive) Start() {
+	a.written = make(chan struct{})
+	a.wg.Add(1)
+	go a.writeLoop()
+	go a.pollLoop()
+}
+
+// Stop 停止轮询，写入队列中剩余的观测后关闭数据库
+func (a *Archive) Stop(ctx context.Context) error {
+	a.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(a.flush)
+		if a.written != nil {
+			<-a.written
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return a.store.Close()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// History 查询历史观测，结果换算为 units 单位系统
+func (a *Archive) History(q *Query, units string) (*model.HistoryResponse, error) {
+	if q.Limit <= 0 || q.Limit > a.config.MaxPoints {
+		q.Limit = a.config.MaxPoints
+	}
+	if q.Interval > 0 && int(q.To.Sub(q.From)/q.Interval) > q.Limit {
+		return nil, ErrTooManyPoints
+	}
+
+	location, points, err := a.store.Query(q)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range points {
+		p := &points[i]
+		convertStat(&p.Temperature, func(v float64) float64 { return derived.FromCelsius(v, units) })
+		convertStat(&p.FeelsLike, func(v float64) float64 { return derived.FromCelsius(v, units) })
+		convertStat(&p.WindSpeed, func(v float64) float64 { return fromMetersPerSecond(v, units) })
+		convertStat(&p.Humidity, nil)
+		convertStat(&p.Pressure, nil)
+	}
+
+	interval := "raw"
+	if q.Interval > 0 {
+		interval = q.Interval.String()
+	}
+	return &model.HistoryResponse{
+		Location: *location,
+		From:     q.From.UTC(),
+		To:       q.To.UTC(),
+		Interval: interval,
+		Units:    units,
+		Points:   points,
+	}, nil
+}
+
+// record 把天气数据放入写入队列，query 为请求时使用的城市名称
+func (a *Archive) record(weather *model.WeatherResponse, units, query string) {
+	obs := toObservation(weather, units, query)
+	select {
+	case a.queue <- obs:
+	default:
+		log.Printf("观测存档队列已满，丢弃 %s 的观测", obs.Name)
+	}
+}
+
+// writeLoop 批量写入队列中的观测和预报快照，停止时写完剩余的数据再退出
+func (a *Archive) writeLoop() {
+	defer close(a.written)
+
+	for {
+		select {
+		case obs := <-a.queue:
+			a.writeBatch(obs)
+		case rows := <-a.forecastQueue:
+			a.writeForecast(rows)
+		case <-a.flush:
+			for {
+				select {
+				case obs := <-a.queue:
+					a.writeBatch(obs)
+				case rows := <-a.forecastQueue:
+					a.writeForecast(rows)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeForecast 写入一份预报快照
+func (a *Archive) writeForecast(rows []forecastRow) {
+	if err := a.store.insertForecasts(rows); err != nil {
+		log.Printf("%v", err)
+	}
+}
+
+// writeBatch 把 first 和队列中已有的观测合并写入
+func (a *Archive) writeBatch(first observation) {
+	batch := []observation{first}
+drain:
+	for len(batch) < batchSize {
+		select {
+		case obs := <-a.queue:
+			batch = append(batch, obs)
+		default:
+			break drain
+		}
+	}
+	if err := a.store.Insert(batch); err != nil {
+		log.Printf("写入观测存档失败: %v", err)
+	}
+}
+
+// pollLoop 定时轮询配置的位置并清理过期记录
+func (a *Archive) pollLoop() {
+	defer a.wg.Done()
+
+	var pollC <-chan time.Time
+	if len(a.locations) > 0 && a.weatherService != nil {
+		ticker := time.NewTicker(time.Duration(a.config.PollInterval) * time.Second)
+		defer ticker.Stop()
+		pollC = ticker.C
+		a.poll()
+	}
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+	a.prune()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-pollC:
+			a.poll()
+		case <-pruneTicker.C:
+			a.prune()
+		}
+	}
+}
+
+// poll 查询全部配置的位置，结果由 recordingService 写入存档；距上次保存预报快照超过 forecastInterval 时同时查询预报
+func (a *Archive) poll() {
+	for i, result := range service.FetchAll(a.weatherService, a.locations, pollConcurrency) {
+		if result.Err != nil {
+			log.Printf("观测存档轮询失败 (%s): %v", a.locations[i].Describe(), result.Err)
+		}
+	}
+
+	if time.Since(a.lastForecast) >= forecastInterval {
+		a.lastForecast = time.Now()
+		a.pollForecasts()
+	}
+}
+
+// pollForecasts 查询全部配置位置的预报，快照由 recordingService 写入存档；
+// 天气服务不支持预报时不做任何事
+func (a *Archive) pollForecasts() {
+	sem := make(chan struct{}, pollConcurrency)
+	var wg sync.WaitGroup
+	for i := range a.locations {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(req *model.WeatherRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := service.FetchForecast(a.weatherService, req)
+			if err != nil && !errors.Is(err, service.ErrForecastUnsupported) {
+				log.Printf("观测存档轮询预报失败 (%s): %v", req.Describe(), err)
+			}
+		}(&a.locations[i])
+	}
+	wg.Wait()
+}
+
+// prune 按保留天数删除过期记录，保留天数为 0 时不清理
+func (a *Archive) prune() {
+	if a.config.RetentionDays <= 0 {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -a.config.RetentionDays)
+	if _, err := a.store.Prune(cutoff); err != nil {
+		log.Printf("%v", err)
+	}
+}
+
+// ParseLocations 解析以分号分隔的位置列表，每项是城市名称或 "纬度,经度"，如 "Beijing;31.23,121.47"
+func ParseLocations(s string) ([]model.WeatherRequest, error) {
+	var locations []model.WeatherRequest
+	for _, item := range strings.Split(s, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		req := model.WeatherRequest{City: item, Units: "metric"}
+		if parts := strings.Split(item, ","); len(parts) == 2 {
+			lat, latErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+			lon, lonErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+			if latErr == nil && lonErr == nil {
+				req = model.WeatherRequest{Lat: lat, Lon: lon, Units: "metric"}
+			}
+		}
+		if err := req.Validate(); err != nil {
+			return nil, fmt.Errorf("存档位置 %q 无效: %w", item, err)
+		}
+		locations = append(locations, req)
+	}
+	return locations, nil
+}
+
+// toObservation 把天气数据换算为观测记录
+func toObservation(weather *model.WeatherResponse, units, query string) observation {
+	current := &weather.Current
+	obs := observation{
+		Lat:         weather.Location.Latitude,
+		Lon:         weather.Location.Longitude,
+		Name:        weather.Location.Name,
+		Country:     weather.Location.Country,
+		Timezone:    weather.Location.Timezone,
+		Provider:    weather.Provider,
+		ObservedAt:  current.UpdatedAt,
+		FetchedAt:   time.Unix(weather.Timestamp, 0),
+		Temperature: derived.ToCelsius(current.Temperature, units),
+		FeelsLike:   derived.ToCelsius(current.FeelsLike, units),
+		Humidity:    current.Humidity,
+		Pressure:    current.Pressure,
+		WindSpeed:   derived.ToMetersPerSecond(current.Wind.Speed, units),
+		WindGust:    derived.ToMetersPerSecond(current.Wind.Gust, units),
+		Clouds:      current.Clouds.All,
+		Visibility:  current.Visibility,
+	}
+	if current.UpdatedAt.IsZero() {
+		obs.ObservedAt = obs.FetchedAt
+	}
+	if current.Rain != nil {
+		obs.Rain1h = current.Rain.OneHour
+	}
+	if current.Snow != nil {
+		obs.Snow1h = current.Snow.OneHour
+	}
+	if len(current.Weather) > 0 {
+		obs.Condition = current.Weather[0].Main
+	}
+
+	for _, alias := range []string{weather.Location.Name, query} {
+		if alias = normalizeAlias(alias); alias != "" && (len(obs.Aliases) == 0 || obs.Aliases[0] != alias) {
+			obs.Aliases = append(obs.Aliases, alias)
+		}
+	}
+	return obs
+}
+
+// convertStat 四舍五入到一位小数，convert 不为 nil 时先做单位换算
+func convertStat(stat *model.HistoryStat, convert func(float64) float64) {
+	for _, v := range []*float64{&stat.Avg, &stat.Min, &stat.Max} {
+		if convert != nil {
+			*v = convert(*v)
+		}
+		*v = round1(*v)
+	}
+}
+
+// fromMetersPerSecond 把 m/s 换算为指定单位系统下的风速
+func fromMetersPerSecond(speed float64, units string) float64 {
+	if units == "imperial" {
+		return speed / 0.44704
+	}
+	return speed
+}
+
+// round1 保留一位小数
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,182 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+)
+
+// mockService 模拟天气服务，每次返回下一条预设观测
+type mockService struct {
+	mu    sync.Mutex
+	start time.Time
+	temps []float64
+	calls int
+}
+
+func (m *mockService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if city == "bad" {
+		return nil, errors.New("city not found")
+	}
+	i := m.calls % len(m.temps)
+	m.calls++
+	return &model.WeatherResponse{
+		Location: model.Location{Name: "北京市", Country: "CN", Latitude: 39.9075, Longitude: 116.3972, Timezone: 28800},
+		Current: model.Current{
+			Temperature: m.temps[i],
+			FeelsLike:   m.temps[i],
+			Humidity:    50 + i,
+			Pressure:    1010,
+			Wind:        model.Wind{Speed: 10},
+			UpdatedAt:   m.start.Add(time.Duration(i) * 10 * time.Minute),
+		},
+		Timestamp: m.start.Unix(),
+		Provider:  "mock",
+	}, nil
+}
+
+func (m *mockService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity("", units, lang)
+}
+
+func (m *mockService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity(city, units, lang)
+}
+
+func (m *mockService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCoordinates(lat, lon, units, lang)
+}
+
+func newTestArchive(t *testing.T, path string) *Archive {
+	t.Helper()
+	a, err := New(&config.ArchiveConfig{Path: path, PollInterval: 3600, MaxPoints: 100})
+	if err != nil {
+		t.Fatalf("创建存档失败: %v", err)
+	}
+	return a
+}
+
+func TestArchive_RecordAndHistory(t *testing.T) {
+	start := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	inner := &mockService{start: start, temps: []float64{50, 59, 68, 77, 86, 95}}
+
+	path := filepath.Join(t.TempDir(), "weather.db")
+	a := newTestArchive(t, path)
+	weatherService := a.Wrap(inner)
+	a.Start()
+
+	// 以华氏度获取 6 次观测，再重复获取第一条，重复的观测不会重复写入
+	for i := 0; i < 7; i++ {
+		if _, err := weatherService.GetWeatherByCity("Beijing", "imperial", "zh_cn"); err != nil {
+			t.Fatalf("获取天气失败: %v", err)
+		}
+	}
+	if _, err := weatherService.GetWeatherByCity("bad", "imperial", "zh_cn"); err == nil {
+		t.Fatal("期望返回错误")
+	}
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("停止存档失败: %v", err)
+	}
+
+	// 重新打开时迁移不会重复执行
+	a = newTestArchive(t, path)
+	defer a.Stop(context.Background())
+
+	// 按请求时的城市名称和返回的名称都能查到，原始数据按摄氏度返回
+	for _, city := range []string{"Beijing", "北京市"} {
+		history, err := a.History(&Query{City: city, From: start, To: start.Add(time.Hour)}, "metric")
+		if err != nil {
+			t.Fatalf("%s: 查询失败: %v", city, err)
+		}
+		if len(history.Points) != 6 || history.Interval != "raw" {
+			t.Fatalf("%s: 期望 6 条原始观测，实际为 %d", city, len(history.Points))
+		}
+		if p := history.Points[0]; p.Temperature.Avg != 10 || p.WindSpeed.Avg != 4.5 || !p.Time.Equal(start) {
+			t.Errorf("%s: 单位换算不正确: %+v", city, p)
+		}
+	}
+
+	// 按 30 分钟降采样，每段 3 条观测
+	history, err := a.History(&Query{Lat: 39.93, Lon: 116.4, From: start, To: start.Add(time.Hour), Interval: 30 * time.Minute}, "metric")
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(history.Points) != 2 || history.Location.Name != "北京市" {
+		t.Fatalf("期望 2 个数据点，实际为 %+v", history)
+	}
+	second := history.Points[1]
+	if second.Count != 3 || second.Temperature.Min != 25 || second.Temperature.Max != 35 || second.Temperature.Avg != 30 {
+		t.Errorf("第二个时间段的统计不正确: %+v", second)
+	}
+	if !second.Time.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("期望时间段从 00:30 开始，实际为 %v", second.Time)
+	}
+
+	// 按华氏度返回
+	history, _ = a.History(&Query{City: "beijing", From: start, To: start.Add(time.Hour), Interval: time.Hour}, "imperial")
+	if len(history.Points) != 1 || history.Points[0].Temperature.Max != 95 || history.Points[0].Temperature.Min != 50 {
+		t.Errorf("华氏度结果不正确: %+v", history.Points)
+	}
+
+	if _, err := a.History(&Query{City: "Shanghai", From: start, To: start.Add(time.Hour)}, "metric"); !errors.Is(err, ErrUnknownLocation) {
+		t.Errorf("期望返回 ErrUnknownLocation，实际为 %v", err)
+	}
+	if _, err := a.History(&Query{City: "Beijing", From: start, To: start.Add(1000 * time.Hour), Interval: time.Minute}, "metric"); !errors.Is(err, ErrTooManyPoints) {
+		t.Errorf("期望返回 ErrTooManyPoints，实际为 %v", err)
+	}
+	if _, err := a.History(&Query{City: "Beijing", From: start, To: start.Add(time.Hour), Limit: 5}, "metric"); !errors.Is(err, ErrTooManyPoints) {
+		t.Errorf("原始观测超过上限时期望返回 ErrTooManyPoints，实际为 %v", err)
+	}
+
+	// 清理 00:30 之前的记录
+	deleted, err := a.store.Prune(start.Add(30 * time.Minute))
+	if err != nil || deleted != 3 {
+		t.Errorf("期望清理 3 条记录，实际为 %d: %v", deleted, err)
+	}
+}
+
+func TestArchive_Poll(t *testing.T) {
+	inner := &mockService{start: time.Now().Add(-time.Minute), temps: []float64{20}}
+	a, err := New(&config.ArchiveConfig{Path: filepath.Join(t.TempDir(), "weather.db"), Locations: "Beijing", PollInterval: 3600, RetentionDays: 30, MaxPoints: 100})
+	if err != nil {
+		t.Fatalf("创建存档失败: %v", err)
+	}
+	a.Wrap(inner)
+	a.Start()
+	a.Stop(context.Background())
+
+	// Stop 会关闭数据库，重新打开后查询
+	a = newTestArchive(t, a.config.Path)
+	defer a.Stop(context.Background())
+
+	history, err := a.History(&Query{City: "Beijing", From: time.Now().Add(-time.Hour), To: time.Now()}, "metric")
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(history.Points) != 1 || history.Points[0].Temperature.Avg != 20 {
+		t.Errorf("期望启动时轮询一次，实际为 %+v", history.Points)
+	}
+}
+
+func TestParseLocations(t *testing.T) {
+	locations, err := ParseLocations(" Beijing ; 31.23,121.47;;New York, US ")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(locations) != 3 || locations[0].City != "Beijing" || locations[1].Lat != 31.23 || locations[2].City != "New York, US" {
+		t.Errorf("解析结果不正确: %+v", locations)
+	}
+
+	if _, err := ParseLocations("91,0"); err == nil {
+		t.Error("期望纬度超出范围时返回错误")
+	}
+}
@@ -0,0 +1,180 @@
+package archive
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gin-weather/internal/derived"
+	"gin-weather/internal/model"
+	"gin-weather/internal/skill"
+)
+
+const (
+	// snapshotInterval 同一位置、同一提供商的预报在该间隔内只保存一份
+	snapshotInterval = time.Hour
+	// matchWindow 预报有效时间前后该范围内最近的一次观测作为实况
+	matchWindow = 30 * time.Minute
+)
+
+// forecastRow 一个预报时段的快照，温度和风速统一换算为摄氏度和 m/s 保存
+type forecastRow struct {
+	Lat, Lon          float64
+	Provider          string
+	IssuedAt          time.Time
+	ValidAt           time.Time
+	Temperature       float64
+	WindSpeed         float64
+	PrecipProbability float64
+	Precipitation     float64
+}
+
+// recordForecast 把预报放入写入队列
+func (a *Archive) recordForecast(forecast *model.Forecast, units string) {
+	rows := toForecastRows(forecast, units)
+	if len(rows) == 0 {
+		return
+	}
+	select {
+	case a.forecastQueue <- rows:
+	default:
+		log.Printf("观测存档队列已满，丢弃 %s 的预报", forecast.Location.Name)
+	}
+}
+
+// ForecastSkill 比较 [from, to) 内到期的预报快照与实况观测，按提供商和提前量计算准确度；
+// q 不为 nil 时只统计该位置的预报
+func (a *Archive) ForecastSkill(q *Query, from, to time.Time, units string) (*model.ForecastSkillReport, error) {
+	var center *model.Location
+	if q != nil {
+		location, _, _, err := a.store.resolve(q)
+		if err != nil {
+			return nil, err
+		}
+		center = location
+	}
+
+	pairs, err := a.store.forecastPairs(center, from, to)
+	if err != nil {
+		return nil, err
+	}
+	scores := skill.Score(pairs)
+	skill.Convert(scores, units)
+
+	return &model.ForecastSkillReport{
+		From:   from.UTC(),
+		To:     to.UTC(),
+		Units:  units,
+		Scores: scores,
+	}, nil
+}
+
+// toForecastRows 把预报换算为快照，获取时间按 snapshotInterval 取整，用于去重
+func toForecastRows(forecast *model.Forecast, units string) []forecastRow {
+	issued := time.Unix(forecast.Timestamp, 0).Truncate(snapshotInterval)
+	rows := make([]forecastRow, 0, len(forecast.Items))
+	for _, item := range forecast.Items {
+		rows = append(rows, forecastRow{
+			Lat:               forecast.Location.Latitude,
+			Lon:               forecast.Location.Longitude,
+			Provider:          forecast.Provider,
+			IssuedAt:          issued,
+			ValidAt:           item.Time,
+			Temperature:       derived.ToCelsius(item.Temperature, units),
+			WindSpeed:         derived.ToMetersPerSecond(item.WindSpeed, units),
+			PrecipProbability: item.PrecipProbability,
+			Precipitation:     item.Rain + item.Snow,
+		})
+	}
+	return rows
+}
+
+// insertForecasts 在一个事务中写入预报快照，重复的快照会被忽略
+func (s *Store) insertForecasts(rows []forecastRow) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("写入预报快照失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO forecasts (
+		lat, lon, provider, issued_at, valid_at, temperature, wind_speed, precip_probability, precipitation
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("写入预报快照失败: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := range rows {
+		r := &rows[i]
+		if _, err := stmt.Exec(r.Lat, r.Lon, r.Provider, r.IssuedAt.Unix(), r.ValidAt.Unix(),
+			r.Temperature, r.WindSpeed, r.PrecipProbability, r.Precipitation); err != nil {
+			return fmt.Errorf("写入预报快照失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("写入预报快照失败: %w", err)
+	}
+	return nil
+}
+
+// forecastPairs 为 [from, to) 内到期的每条预报找到有效时间前后 matchWindow 内最近的观测；
+// center 不为 nil 时只查询该位置附近的预报
+func (s *Store) forecastPairs(center *model.Location, from, to time.Time) ([]skill.Pair, error) {
+	filter := "valid_at >= ? AND valid_at < ? AND valid_at <= issued_at + ?"
+	args := []interface{}{from.Unix(), to.Unix(), int64(skill.LeadBuckets[len(skill.LeadBuckets)-1] / time.Second)}
+	if center != nil {
+		filter += " AND lat BETWEEN ? AND ? AND lon BETWEEN ? AND ?"
+		args = append(args, center.Latitude-coordTolerance, center.Latitude+coordTolerance,
+			center.Longitude-coordTolerance, center.Longitude+coordTolerance)
+	}
+	window := int64(matchWindow / time.Second)
+	args = append(args, coordTolerance, coordTolerance, coordTolerance, coordTolerance, window, window)
+
+	// SQLite 中与 MIN() 聚合一起查询的其他列取自最小值所在的行，即时间最接近的观测
+	rows, err := s.db.Query(`SELECT f.provider, f.valid_at - f.issued_at,
+			f.temperature, f.wind_speed, f.precip_probability,
+			o.temperature, o.wind_speed, o.rain_1h + o.snow_1h, o.condition,
+			MIN(ABS(o.observed_at - f.valid_at))
+		FROM (SELECT * FROM forecasts WHERE `+filter+`) f
+		JOIN observations o
+			ON o.lat BETWEEN f.lat - ? AND f.lat + ?
+			AND o.lon BETWEEN f.lon - ? AND f.lon + ?
+			AND o.observed_at BETWEEN f.valid_at - ? AND f.valid_at + ?
+		GROUP BY f.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询预报快照失败: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []skill.Pair
+	for rows.Next() {
+		var p skill.Pair
+		var lead int64
+		var precipitation float64
+		var condition string
+		var distance int64
+		if err := rows.Scan(&p.Provider, &lead,
+			&p.ForecastTemperature, &p.ForecastWindSpeed, &p.ForecastPrecipProbability,
+			&p.ObservedTemperature, &p.ObservedWindSpeed, &precipitation, &condition, &distance,
+		); err != nil {
+			return nil, fmt.Errorf("读取预报快照失败: %w", err)
+		}
+		p.Lead = time.Duration(lead) * time.Second
+		p.ObservedPrecipitation = precipitation > 0 || precipConditions[condition]
+		pairs = append(pairs, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取预报快照失败: %w", err)
+	}
+	return pairs, nil
+}
+
+// precipConditions 表示正在降水的天气状况
+var precipConditions = map[string]bool{
+	"Rain":         true,
+	"Drizzle":      true,
+	"Snow":         true,
+	"Thunderstorm": true,
+}
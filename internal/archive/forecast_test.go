@@ -0,0 +1,120 @@
+package archive
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// forecastService 在 mockService 的基础上返回固定的预报
+type forecastService struct {
+	mockService
+	forecast *model.Forecast
+}
+
+func (f *forecastService) GetForecastByCity(city, units, lang string) (*model.Forecast, error) {
+	return f.forecast, nil
+}
+
+func (f *forecastService) GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error) {
+	return f.forecast, nil
+}
+
+func TestArchive_ForecastSkill(t *testing.T) {
+	issued := time.Date(2024, 3, 5, 0, 20, 0, 0, time.UTC)
+	inner := &forecastService{forecast: &model.Forecast{
+		Location: model.Location{Name: "北京市", Latitude: 39.91, Longitude: 116.39},
+		Items: []model.ForecastItem{
+			{Time: issued.Add(40 * time.Minute), Temperature: 53.6, WindSpeed: 22.37, PrecipProbability: 0.9}, // 12°C，10 m/s
+			{Time: issued.Add(3*time.Hour + 40*time.Minute), Temperature: 50},                                 // 没有对应的观测
+		},
+		Timestamp: issued.Unix(),
+		Provider:  "mock",
+	}}
+
+	path := filepath.Join(t.TempDir(), "weather.db")
+	cfg := &config.ArchiveConfig{Path: path, PollInterval: 3600, MaxPoints: 100, NormalsWindow: 15}
+	a, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建存档失败: %v", err)
+	}
+	weatherService := a.Wrap(inner)
+	a.Start()
+
+	// 同一小时内重复获取的预报只保存一份
+	for i := 0; i < 3; i++ {
+		if _, err := service.FetchForecast(weatherService, &model.WeatherRequest{City: "Beijing", Units: "imperial"}); err != nil {
+			t.Fatalf("获取预报失败: %v", err)
+		}
+	}
+	a.Stop(context.Background())
+
+	a, _ = New(cfg)
+	defer a.Stop(context.Background())
+
+	// 预报有效时间前后各有一次观测，取时间最接近的 01:05 的观测
+	a.store.Insert([]observation{
+		{Lat: 39.9075, Lon: 116.3972, Name: "北京市", Provider: "mock", ObservedAt: issued.Add(10 * time.Minute), Temperature: 0, Aliases: []string{"beijing"}},
+		{Lat: 39.9075, Lon: 116.3972, Name: "北京市", Provider: "mock", ObservedAt: issued.Add(45 * time.Minute), Temperature: 10, WindSpeed: 7, Condition: "Rain"},
+	})
+
+	report, err := a.ForecastSkill(&Query{City: "Beijing"}, issued.Add(-time.Hour), issued.Add(24*time.Hour), "metric")
+	if err != nil {
+		t.Fatalf("计算预报准确度失败: %v", err)
+	}
+	if len(report.Scores) != 1 {
+		t.Fatalf("期望 1 个分组，实际为 %+v", report.Scores)
+	}
+	score := report.Scores[0]
+	if score.Provider != "mock" || score.LeadTime != "0-12h" || score.Samples != 1 {
+		t.Errorf("分组不正确: %+v", score)
+	}
+	if score.Temperature.Bias != 2 || score.WindSpeed.Bias != 3 || score.Precipitation.Hits != 1 {
+		t.Errorf("误差不正确: %+v", score)
+	}
+
+	// 时间范围外没有数据
+	report, _ = a.ForecastSkill(nil, issued.Add(24*time.Hour), issued.Add(48*time.Hour), "metric")
+	if len(report.Scores) != 0 {
+		t.Errorf("期望没有数据，实际为 %+v", report.Scores)
+	}
+}
+
+func TestArchive_PollForecast(t *testing.T) {
+	issued := time.Now().Truncate(time.Hour)
+	inner := &forecastService{
+		mockService: mockService{start: time.Now().Add(-time.Minute), temps: []float64{20}},
+		forecast: &model.Forecast{
+			Location:  model.Location{Name: "北京市", Latitude: 39.91, Longitude: 116.39},
+			Items:     []model.ForecastItem{{Time: issued.Add(3 * time.Hour), Temperature: 20}},
+			Timestamp: issued.Unix(),
+			Provider:  "mock",
+		},
+	}
+
+	cfg := &config.ArchiveConfig{Path: filepath.Join(t.TempDir(), "weather.db"), Locations: "Beijing", PollInterval: 3600, MaxPoints: 100}
+	a, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建存档失败: %v", err)
+	}
+	a.Wrap(inner)
+	a.Start()
+	a.Stop(context.Background())
+
+	// 启动时的轮询同时保存了预报快照，不需要经过其他模块获取预报
+	a = newTestArchive(t, cfg.Path)
+	defer a.Stop(context.Background())
+
+	var count int
+	if err := a.store.db.QueryRow(`SELECT COUNT(*) FROM forecasts`).Scan(&count); err != nil {
+		t.Fatalf("查询预报快照失败: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("期望轮询保存 1 条预报快照，实际为 %d", count)
+	}
+}
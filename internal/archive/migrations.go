@@ -0,0 +1,95 @@
+package archive
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations 按顺序执行的数据库结构变更，已发布的条目不能修改，只能在末尾追加
+var migrations = []string{
+	// 1: 观测记录和位置别名
+	`CREATE TABLE observations (
+		id          INTEGER PRIMARY KEY,
+		lat         REAL    NOT NULL,
+		lon         REAL    NOT NULL,
+		name        TEXT    NOT NULL,
+		country     TEXT    NOT NULL,
+		timezone    INTEGER NOT NULL,
+		provider    TEXT    NOT NULL,
+		observed_at INTEGER NOT NULL,
+		fetched_at  INTEGER NOT NULL,
+		temperature REAL    NOT NULL,
+		feels_like  REAL    NOT NULL,
+		humidity    INTEGER NOT NULL,
+		pressure    INTEGER NOT NULL,
+		wind_speed  REAL    NOT NULL,
+		wind_gust   REAL    NOT NULL,
+		clouds      INTEGER NOT NULL,
+		visibility  INTEGER NOT NULL,
+		rain_1h     REAL    NOT NULL,
+		snow_1h     REAL    NOT NULL,
+		condition   TEXT    NOT NULL,
+		UNIQUE (lat, lon, provider, observed_at)
+	);
+	CREATE INDEX observations_location_time ON observations (lat, lon, observed_at);
+	CREATE INDEX observations_time ON observations (observed_at);
+	CREATE TABLE location_aliases (
+		alias TEXT NOT NULL,
+		lat   REAL NOT NULL,
+		lon   REAL NOT NULL,
+		PRIMARY KEY (alias, lat, lon)
+	);`,
+	// 2: 预报快照，每个位置、提供商每小时最多保存一份
+	`CREATE TABLE forecasts (
+		id                 INTEGER PRIMARY KEY,
+		lat                REAL    NOT NULL,
+		lon                REAL    NOT NULL,
+		provider           TEXT    NOT NULL,
+		issued_at          INTEGER NOT NULL,
+		valid_at           INTEGER NOT NULL,
+		temperature        REAL    NOT NULL,
+		wind_speed         REAL    NOT NULL,
+		precip_probability REAL    NOT NULL,
+		precipitation      REAL    NOT NULL,
+		UNIQUE (lat, lon, provider, issued_at, valid_at)
+	);
+	CREATE INDEX forecasts_valid ON forecasts (valid_at);`,
+}
+
+// migrate 执行尚未应用的迁移，每个迁移在单独的事务中执行
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("读取数据库版本失败: %w", err)
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("数据库版本 %d 高于程序支持的版本 %d", current, len(migrations))
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[version-1]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("执行迁移 %d 失败: %w", version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().Unix()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("记录迁移 %d 失败: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("提交迁移 %d 失败: %w", version, err)
+		}
+	}
+	return nil
+}
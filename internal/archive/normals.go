@@ -0,0 +1,175 @@
+package archive
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gin-weather/internal/derived"
+	"gin-weather/internal/model"
+)
+
+// normals 以摄氏度和 mm 计算的常年统计，没有数据的要素为 nil
+type normals struct {
+	years         int
+	temperature   *model.NormalStat
+	humidity      *model.NormalStat
+	precipitation *model.NormalStat
+}
+
+// Normals 计算 date 所在当地日期前后 NormalsWindow 天（各年）的常年统计，结果换算为 units 单位系统。
+// 先求每天的日均值再统计，观测频率不同的日期权重相同；date 当天的观测不参与计算
+func (a *Archive) Normals(q *Query, date time.Time, units string) (*model.ClimateNormals, error) {
+	location, filter, args, err := a.store.resolve(q)
+	if err != nil {
+		return nil, err
+	}
+	n, err := a.normals(filter, args, date)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ClimateNormals{
+		Location:      *location,
+		Date:          date.Format("2006-01-02"),
+		DayOfYear:     date.YearDay(),
+		WindowDays:    a.config.NormalsWindow,
+		Years:         n.years,
+		Units:         units,
+		Humidity:      n.humidity,
+		Precipitation: n.precipitation,
+	}
+	if n.temperature != nil {
+		result.Temperature = &model.NormalStat{
+			Mean:         round1(derived.FromCelsius(n.temperature.Mean, units)),
+			StdDev:       round1(temperatureDelta(n.temperature.StdDev, units)),
+			Days:         n.temperature.Days,
+			Observations: n.temperature.Observations,
+		}
+	}
+	for _, stat := range []*model.NormalStat{result.Humidity, result.Precipitation} {
+		if stat != nil {
+			stat.Mean, stat.StdDev = round1(stat.Mean), round1(stat.StdDev)
+		}
+	}
+	return result, nil
+}
+
+// Anomaly 比较天气数据与存档中同一时期的常年统计，weather 中的数值使用 units 单位系统。
+// 存档中没有该位置的记录时返回不含任何要素的结果
+func (a *Archive) Anomaly(weather *model.WeatherResponse, units string) (*model.Anomaly, error) {
+	result := &model.Anomaly{WindowDays: a.config.NormalsWindow}
+
+	q := &Query{Lat: weather.Location.Latitude, Lon: weather.Location.Longitude}
+	_, filter, args, err := a.store.resolve(q)
+	if errors.Is(err, ErrUnknownLocation) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	current := &weather.Current
+	observed := current.UpdatedAt
+	if observed.IsZero() {
+		observed = time.Now()
+	}
+	n, err := a.normals(filter, args, observed.In(time.FixedZone("", weather.Location.Timezone)))
+	if err != nil {
+		return nil, err
+	}
+	result.Years = n.years
+
+	celsius := derived.ToCelsius(current.Temperature, units)
+	if v := anomalyValue(celsius, n.temperature); v != nil {
+		// 温差用未舍入的摄氏度差值换算后再舍入，避免舍入误差被放大
+		v.Value = round1(current.Temperature)
+		v.Normal = round1(derived.FromCelsius(n.temperature.Mean, units))
+		v.Difference = round1(temperatureDelta(celsius-n.temperature.Mean, units))
+		result.Temperature = v
+	}
+	result.Humidity = anomalyValue(float64(current.Humidity), n.humidity)
+
+	precipitation := 0.0
+	if current.Rain != nil {
+		precipitation += current.Rain.OneHour
+	}
+	if current.Snow != nil {
+		precipitation += current.Snow.OneHour
+	}
+	result.Precipitation = anomalyValue(precipitation, n.precipitation)
+	return result, nil
+}
+
+// normals 统计 date 前后窗口内每天的日均值
+func (a *Archive) normals(filter string, args []interface{}, date time.Time) (*normals, error) {
+	days, err := a.store.dailyMeans(filter, args, date.YearDay(), a.config.NormalsWindow, date.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	years := make(map[string]bool)
+	for _, d := range days {
+		years[d.Day[:4]] = true
+	}
+	return &normals{
+		years:         len(years),
+		temperature:   normalStat(days, func(d *dailyMean) float64 { return d.Temperature }),
+		humidity:      normalStat(days, func(d *dailyMean) float64 { return d.Humidity }),
+		precipitation: normalStat(days, func(d *dailyMean) float64 { return d.Precipitation }),
+	}, nil
+}
+
+// normalStat 计算日均值的平均值和样本标准差，没有数据时返回 nil
+func normalStat(days []dailyMean, value func(*dailyMean) float64) *model.NormalStat {
+	if len(days) == 0 {
+		return nil
+	}
+
+	stat := &model.NormalStat{Days: len(days)}
+	sum := 0.0
+	for i := range days {
+		sum += value(&days[i])
+		stat.Observations += days[i].Count
+	}
+	stat.Mean = sum / float64(len(days))
+
+	if len(days) > 1 {
+		squares := 0.0
+		for i := range days {
+			d := value(&days[i]) - stat.Mean
+			squares += d * d
+		}
+		stat.StdDev = math.Sqrt(squares / float64(len(days)-1))
+	}
+	return stat
+}
+
+// anomalyValue 计算 value 相对常年统计的偏差，数值单位与 stat 相同；stat 为 nil 时返回 nil
+func anomalyValue(value float64, stat *model.NormalStat) *model.AnomalyValue {
+	if stat == nil {
+		return nil
+	}
+
+	v := &model.AnomalyValue{
+		Value:        round1(value),
+		Normal:       round1(stat.Mean),
+		Difference:   value - stat.Mean,
+		Days:         stat.Days,
+		Observations: stat.Observations,
+	}
+	if stat.StdDev > 0 {
+		z := math.Round(v.Difference/stat.StdDev*100) / 100
+		v.ZScore = &z
+	}
+	v.Difference = round1(v.Difference)
+	return v
+}
+
+// temperatureDelta 把摄氏度的温差换算为 units 单位系统下的温差
+func temperatureDelta(delta float64, units string) float64 {
+	if units == "imperial" {
+		return delta * 9 / 5
+	}
+	return delta
+}
@@ -0,0 +1,115 @@
+package archive
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+)
+
+func TestArchive_NormalsAndAnomaly(t *testing.T) {
+	a, err := New(&config.ArchiveConfig{Path: filepath.Join(t.TempDir(), "weather.db"), PollInterval: 3600, MaxPoints: 100, NormalsWindow: 5})
+	if err != nil {
+		t.Fatalf("创建存档失败: %v", err)
+	}
+	defer a.Stop(context.Background())
+
+	// 东八区：UTC 16:00 之后是当地的第二天
+	loc := time.FixedZone("", 8*3600)
+	obs := func(day time.Time, hour int, temp float64, humidity int, rain float64) observation {
+		return observation{
+			Lat: 39.9, Lon: 116.4, Name: "北京市", Country: "CN", Timezone: 8 * 3600, Provider: "mock",
+			ObservedAt: day.Add(time.Duration(hour) * time.Hour), Temperature: temp, Humidity: humidity, Rain1h: rain,
+			Aliases: []string{"beijing"},
+		}
+	}
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, loc) }
+
+	list := []observation{
+		// 2023 年 1 月 2 日：两次观测，日均 2°C
+		obs(day(2023, 1, 2), 2, 0, 40, 0),
+		obs(day(2023, 1, 2), 14, 4, 60, 0),
+		// 2023 年 12 月 31 日跨年仍在窗口内，日均 6°C
+		obs(day(2023, 12, 31), 12, 6, 50, 1),
+		// 2024 年 1 月 3 日，当地 0 点 30 分（UTC 前一天），日均 4°C
+		obs(day(2024, 1, 3), 0, 4, 50, 0).withMinutes(30),
+		// 目标日期当天不参与计算
+		obs(day(2024, 1, 5), 12, 30, 90, 5),
+		// 窗口外
+		obs(day(2024, 1, 20), 12, -20, 10, 0),
+	}
+	if err := a.store.Insert(list); err != nil {
+		t.Fatalf("写入观测失败: %v", err)
+	}
+
+	normals, err := a.Normals(&Query{City: "Beijing"}, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), "metric")
+	if err != nil {
+		t.Fatalf("计算常年统计失败: %v", err)
+	}
+	if normals.Years != 2 || normals.DayOfYear != 5 || normals.WindowDays != 5 {
+		t.Errorf("统计范围不正确: %+v", normals)
+	}
+	temp := normals.Temperature
+	if temp == nil || temp.Mean != 4 || temp.StdDev != 2 || temp.Days != 3 || temp.Observations != 4 {
+		t.Fatalf("温度统计不正确: %+v", temp)
+	}
+	if h := normals.Humidity; h == nil || h.Mean != 50 || h.StdDev != 0 {
+		t.Errorf("湿度统计不正确: %+v", h)
+	}
+
+	// 华氏度：平均值换算温度，标准差只按比例换算
+	imperial, _ := a.Normals(&Query{Lat: 39.9, Lon: 116.4}, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), "imperial")
+	if imperial.Temperature.Mean != 39.2 || imperial.Temperature.StdDev != 3.6 {
+		t.Errorf("华氏度统计不正确: %+v", imperial.Temperature)
+	}
+
+	weather := &model.WeatherResponse{
+		Location: model.Location{Latitude: 39.9075, Longitude: 116.3972, Timezone: 8 * 3600},
+		Current: model.Current{
+			Temperature: 50, // 10°C
+			Humidity:    50,
+			UpdatedAt:   day(2024, 1, 5).Add(10 * time.Hour),
+		},
+	}
+	anomaly, err := a.Anomaly(weather, "imperial")
+	if err != nil {
+		t.Fatalf("计算气候异常失败: %v", err)
+	}
+	if v := anomaly.Temperature; v == nil || v.Value != 50 || v.Normal != 39.2 || v.Difference != 10.8 || v.ZScore == nil || *v.ZScore != 3 || v.Days != 3 {
+		t.Errorf("温度异常不正确: %+v", v)
+	}
+	if v := anomaly.Humidity; v == nil || v.Difference != 0 || v.ZScore != nil {
+		t.Errorf("标准差为 0 时不应返回 z_score: %+v", v)
+	}
+	if v := anomaly.Precipitation; v == nil || v.Normal != 0.3 {
+		t.Errorf("降水异常不正确: %+v", v)
+	}
+
+	// 华氏度的差值由未舍入的摄氏度差值换算：50.84°F 比常年高 11.64°F，先舍入摄氏度会得到 11.7
+	weather.Current.Temperature = 50.84
+	anomaly, _ = a.Anomaly(weather, "imperial")
+	if v := anomaly.Temperature; v == nil || v.Value != 50.8 || v.Difference != 11.6 {
+		t.Errorf("华氏度温度异常不正确: %+v", v)
+	}
+	weather.Current.Temperature = 10.4666
+	anomaly, _ = a.Anomaly(weather, "metric")
+	if v := anomaly.Temperature; v == nil || v.Value != 10.5 || v.Difference != 6.5 {
+		t.Errorf("摄氏度温度异常不正确: %+v", v)
+	}
+
+	// 没有记录的位置返回空的异常数据块
+	weather.Location.Latitude = 0
+	anomaly, err = a.Anomaly(weather, "metric")
+	if err != nil || anomaly.Temperature != nil || anomaly.WindowDays != 5 {
+		t.Errorf("期望返回空的异常数据块，实际为 %+v, %v", anomaly, err)
+	}
+}
+
+// withMinutes 把观测时间推后若干分钟
+func (o observation) withMinutes(minutes int) observation {
+	o.ObservedAt = o.ObservedAt.Add(time.Duration(minutes) * time.Minute)
+	return o
+}
@@ -0,0 +1,69 @@
+package archive
+
+import (
+	"time"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// recordingService 包装天气服务，把每次成功获取的天气写入存档
+type recordingService struct {
+	inner   service.WeatherService
+	archive *Archive
+}
+
+// GetWeatherByCity 根据城市名称获取天气信息并记录，请求时的城市名称也会作为位置别名保存
+func (s *recordingService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	weather, err := s.inner.GetWeatherByCity(city, units, lang)
+	if err == nil {
+		s.archive.record(weather, units, city)
+	}
+	return weather, err
+}
+
+// GetWeatherByCoordinates 根据坐标获取天气信息并记录
+func (s *recordingService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	weather, err := s.inner.GetWeatherByCoordinates(lat, lon, units, lang)
+	if err == nil {
+		s.archive.record(weather, units, "")
+	}
+	return weather, err
+}
+
+// GetForecastByCity 根据城市名称获取天气预报并保存快照，用于之后评估预报准确度
+func (s *recordingService) GetForecastByCity(city, units, lang string) (*model.Forecast, error) {
+	forecast, err := service.FetchForecast(s.inner, &model.WeatherRequest{City: city, Units: units, Lang: lang})
+	if err == nil {
+		s.archive.recordForecast(forecast, units)
+	}
+	return forecast, err
+}
+
+// GetForecastByCoordinates 根据坐标获取天气预报并保存快照
+func (s *recordingService) GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error) {
+	forecast, err := service.FetchForecast(s.inner, &model.WeatherRequest{Lat: lat, Lon: lon, Units: units, Lang: lang})
+	if err == nil {
+		s.archive.recordForecast(forecast, units)
+	}
+	return forecast, err
+}
+
+// GetHistoricalByCity 转发到被包装的服务，历史天气不写入存档
+func (s *recordingService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.inner.GetHistoricalByCity(city, date, units, lang)
+}
+
+// GetHistoricalByCoordinates 转发到被包装的服务，历史天气不写入存档
+func (s *recordingService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.inner.GetHistoricalByCoordinates(lat, lon, date, units, lang)
+}
+
+// WithProvider 把数据提供商的选择转发给被包装的服务，结果同样会被记录
+func (s *recordingService) WithProvider(name string) (service.WeatherService, error) {
+	inner, err := service.SelectProvider(s.inner, name)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingService{inner: inner, archive: s.archive}, nil
+}
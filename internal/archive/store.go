@@ -0,0 +1,304 @@
+package archive
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gin-weather/internal/model"
+
+	// 纯 Go 实现的 SQLite 驱动，不依赖 cgo
+	_ "modernc.org/sqlite"
+)
+
+// ErrUnknownLocation 没有该位置的观测记录
+var ErrUnknownLocation = errors.New("没有该位置的历史观测记录")
+
+// coordTolerance 按坐标查询时允许的偏差（度），约 5 公里
+const coordTolerance = 0.05
+
+// observation 一条观测记录，温度和风速统一换算为摄氏度和 m/s 保存
+type observation struct {
+	Lat, Lon    float64
+	Name        string
+	Country     string
+	Timezone    int
+	Provider    string
+	ObservedAt  time.Time
+	FetchedAt   time.Time
+	Temperature float64
+	FeelsLike   float64
+	Humidity    int
+	Pressure    int
+	WindSpeed   float64
+	WindGust    float64
+	Clouds      int
+	Visibility  int
+	Rain1h      float64
+	Snow1h      float64
+	Condition   string
+	// Aliases 可以用来查询该位置的名称，如请求时使用的城市名和各语言下返回的名称
+	Aliases []string
+}
+
+// Query 历史观测查询条件
+type Query struct {
+	City     string        // 城市名称，与坐标二选一
+	Lat, Lon float64       // 坐标
+	From, To time.Time     // 时间范围 [From, To)
+	Interval time.Duration // 降采样间隔，0 表示返回原始观测
+	Limit    int           // 最多返回的数据点数
+}
+
+// Store SQLite 观测存储
+type Store struct {
+	db *sql.DB
+}
+
+// OpenStore 打开数据库并执行迁移
+func OpenStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("创建观测数据库目录失败: %w", err)
+	}
+
+	// WAL 模式下读写互不阻塞；写入冲突时等待而不是立即失败
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("打开观测数据库失败: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close 关闭数据库
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Insert 在一个事务中写入多条观测，同一位置同一观测时间的重复记录会被忽略
+func (s *Store) Insert(list []observation) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("写入观测记录失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	insertObservation, err := tx.Prepare(`INSERT OR IGNORE INTO observations (
+		lat, lon, name, country, timezone, provider, observed_at, fetched_at,
+		temperature, feels_like, humidity, pressure, wind_speed, wind_gust,
+		clouds, visibility, rain_1h, snow_1h, condition
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("写入观测记录失败: %w", err)
+	}
+	defer insertObservation.Close()
+
+	insertAlias, err := tx.Prepare(`INSERT OR IGNORE INTO location_aliases (alias, lat, lon) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("写入观测记录失败: %w", err)
+	}
+	defer insertAlias.Close()
+
+	for i := range list {
+		o := &list[i]
+		if _, err := insertObservation.Exec(
+			o.Lat, o.Lon, o.Name, o.Country, o.Timezone, o.Provider, o.ObservedAt.Unix(), o.FetchedAt.Unix(),
+			o.Temperature, o.FeelsLike, o.Humidity, o.Pressure, o.WindSpeed, o.WindGust,
+			o.Clouds, o.Visibility, o.Rain1h, o.Snow1h, o.Condition,
+		); err != nil {
+			return fmt.Errorf("写入观测记录失败: %w", err)
+		}
+		for _, alias := range o.Aliases {
+			if _, err := insertAlias.Exec(alias, o.Lat, o.Lon); err != nil {
+				return fmt.Errorf("写入位置别名失败: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("写入观测记录失败: %w", err)
+	}
+	return nil
+}
+
+// Prune 删除观测时间早于 before 的记录和有效时间早于 before 的预报快照，返回删除的观测条数
+func (s *Store) Prune(before time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM observations WHERE observed_at < ?`, before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("清理观测记录失败: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM forecasts WHERE valid_at < ?`, before.Unix()); err != nil {
+		return 0, fmt.Errorf("清理预报快照失败: %w", err)
+	}
+	// 不再有观测记录的别名一并删除
+	if _, err := s.db.Exec(`DELETE FROM location_aliases WHERE NOT EXISTS (
+		SELECT 1 FROM observations o WHERE o.lat = location_aliases.lat AND o.lon = location_aliases.lon
+	)`); err != nil {
+		return 0, fmt.Errorf("清理位置别名失败: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Query 按时间段降采样查询观测记录，返回的数值为摄氏度和 m/s；数据点超过 q.Limit 时返回错误
+func (s *Store) Query(q *Query) (*model.Location, []model.HistoryPoint, error) {
+	location, filter, args, err := s.resolve(q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 原始数据按秒分组，同一时刻的多条记录（如相邻坐标）会被合并
+	step := int64(q.Interval / time.Second)
+	if step <= 0 {
+		step = 1
+	}
+	// 观测时间精确到秒，结束时间向上取整，使 [From, To) 包含 To 所在秒之前的观测
+	from, to := q.From.Unix(), q.To.Unix()
+	if q.To.Nanosecond() > 0 {
+		to++
+	}
+	queryArgs := append([]interface{}{from, step}, args...)
+	queryArgs = append(queryArgs, from, to, q.Limit+1)
+
+	rows, err := s.db.Query(`SELECT (observed_at - ?) / ? AS bucket, COUNT(*),
+		AVG(temperature), MIN(temperature), MAX(temperature),
+		AVG(feels_like), MIN(feels_like), MAX(feels_like),
+		AVG(humidity), MIN(humidity), MAX(humidity),
+		AVG(pressure), MIN(pressure), MAX(pressure),
+		AVG(wind_speed), MIN(wind_speed), MAX(wind_speed)
+		FROM observations
+		WHERE `+filter+` AND observed_at >= ? AND observed_at < ?
+		GROUP BY bucket ORDER BY bucket LIMIT ?`, queryArgs...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询观测记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	points := []model.HistoryPoint{}
+	for rows.Next() {
+		var bucket int64
+		var p model.HistoryPoint
+		if err := rows.Scan(&bucket, &p.Count,
+			&p.Temperature.Avg, &p.Temperature.Min, &p.Temperature.Max,
+			&p.FeelsLike.Avg, &p.FeelsLike.Min, &p.FeelsLike.Max,
+			&p.Humidity.Avg, &p.Humidity.Min, &p.Humidity.Max,
+			&p.Pressure.Avg, &p.Pressure.Min, &p.Pressure.Max,
+			&p.WindSpeed.Avg, &p.WindSpeed.Min, &p.WindSpeed.Max,
+		); err != nil {
+			return nil, nil, fmt.Errorf("读取观测记录失败: %w", err)
+		}
+		p.Time = time.Unix(from+bucket*step, 0).UTC()
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("读取观测记录失败: %w", err)
+	}
+	if len(points) > q.Limit {
+		return nil, nil, ErrTooManyPoints
+	}
+	return location, points, nil
+}
+
+// resolve 查找查询条件对应的位置，返回最近一次观测中的位置信息和用于其他查询的过滤条件
+func (s *Store) resolve(q *Query) (*model.Location, string, []interface{}, error) {
+	filter, args, err := s.locationFilter(q)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var location model.Location
+	err = s.db.QueryRow(`SELECT name, country, lat, lon, timezone FROM observations WHERE `+filter+
+		` ORDER BY observed_at DESC LIMIT 1`, args...).
+		Scan(&location.Name, &location.Country, &location.Latitude, &location.Longitude, &location.Timezone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", nil, ErrUnknownLocation
+	}
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("查询观测记录失败: %w", err)
+	}
+	return &location, filter, args, nil
+}
+
+// locationFilter 构造位置过滤条件：按城市查询时通过别名找到对应的坐标，按坐标查询时允许少量偏差
+func (s *Store) locationFilter(q *Query) (string, []interface{}, error) {
+	if q.City == "" {
+		return "lat BETWEEN ? AND ? AND lon BETWEEN ? AND ?", []interface{}{
+			q.Lat - coordTolerance, q.Lat + coordTolerance, q.Lon - coordTolerance, q.Lon + coordTolerance,
+		}, nil
+	}
+
+	rows, err := s.db.Query(`SELECT lat, lon FROM location_aliases WHERE alias = ?`, normalizeAlias(q.City))
+	if err != nil {
+		return "", nil, fmt.Errorf("查询位置别名失败: %w", err)
+	}
+	defer rows.Close()
+
+	var conditions []string
+	var args []interface{}
+	for rows.Next() {
+		var lat, lon float64
+		if err := rows.Scan(&lat, &lon); err != nil {
+			return "", nil, fmt.Errorf("查询位置别名失败: %w", err)
+		}
+		conditions = append(conditions, "(lat = ? AND lon = ?)")
+		args = append(args, lat, lon)
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, fmt.Errorf("查询位置别名失败: %w", err)
+	}
+	if len(conditions) == 0 {
+		return "", nil, ErrUnknownLocation
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args, nil
+}
+
+// normalizeAlias 别名统一为去掉首尾空白的小写形式
+func normalizeAlias(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// dailyMean 某个当地日期内全部观测的平均值
+type dailyMean struct {
+	Day           string // 当地日期（YYYY-MM-DD）
+	Count         int
+	Temperature   float64
+	Humidity      float64
+	Precipitation float64 // 1 小时降水量（雨和雪之和）
+}
+
+// dailyMeans 返回位置在各年中与 dayOfYear 相差不超过 window 天的每个当地日期的平均值，跳过 exclude 当天。
+// 跨年的距离按 365 天计算，闰年 2 月之后的日期会有一天的偏差
+func (s *Store) dailyMeans(filter string, args []interface{}, dayOfYear, window int, exclude string) ([]dailyMean, error) {
+	queryArgs := append(append([]interface{}{}, args...), exclude, dayOfYear, dayOfYear, window)
+	rows, err := s.db.Query(`SELECT day, COUNT(*), AVG(temperature), AVG(humidity), AVG(precipitation) FROM (
+			SELECT date(observed_at + timezone, 'unixepoch') AS day,
+				CAST(strftime('%j', observed_at + timezone, 'unixepoch') AS INTEGER) AS doy,
+				temperature, humidity, rain_1h + snow_1h AS precipitation
+			FROM observations WHERE `+filter+`
+		)
+		WHERE day != ? AND MIN(ABS(doy - ?), 365 - ABS(doy - ?)) <= ?
+		GROUP BY day ORDER BY day`, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("查询观测记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var days []dailyMean
+	for rows.Next() {
+		var d dailyMean
+		if err := rows.Scan(&d.Day, &d.Count, &d.Temperature, &d.Humidity, &d.Precipitation); err != nil {
+			return nil, fmt.Errorf("读取观测记录失败: %w", err)
+		}
+		days = append(days, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取观测记录失败: %w", err)
+	}
+	return days, nil
+}
@@ -0,0 +1,163 @@
+package calendar
+
+import (
+	"math"
+	"time"
+)
+
+// 天文计算：太阳视黄经与朔（新月）时刻
+// 算法参考 Jean Meeus《Astronomical Algorithms》第 25、49 章，精度足以确定节气和朔所在的日期
+
+const (
+	// j2000 J2000.0 历元的儒略日
+	j2000 = 2451545.0
+	// unixEpochJD Unix 纪元（1970-01-01T00:00:00Z）的儒略日
+	unixEpochJD = 2440587.5
+	// tropicalYear 回归年长度（天）
+	tropicalYear = 365.2422
+	// synodicMonth 朔望月长度（天）
+	synodicMonth = 29.530588861
+)
+
+// rad 角度转弧度
+func rad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// normalizeDegrees 将角度归一化到 [0, 360)
+func normalizeDegrees(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// julianDay 计算公历日期 0 时（UT）的儒略日
+func julianDay(year, month, day int) float64 {
+	if month <= 2 {
+		year--
+		month += 12
+	}
+	a := year / 100
+	b := 2 - a + a/4
+	return math.Floor(365.25*float64(year+4716)) + math.Floor(30.6001*float64(month+1)) + float64(day) + float64(b) - 1524.5
+}
+
+// deltaT 估算力学时与世界时之差（秒），采用 Espenak & Meeus 多项式
+func deltaT(year float64) float64 {
+	switch {
+	case year < 1986:
+		t := year - 1975
+		return 45.45 + 1.067*t - t*t/260 - t*t*t/718
+	case year < 2005:
+		t := year - 2000
+		return 63.86 + 0.3345*t - 0.060374*t*t + 0.0017275*t*t*t + 0.000651814*t*t*t*t + 0.00002373599*t*t*t*t*t
+	case year < 2050:
+		t := year - 2000
+		return 62.92 + 0.32217*t + 0.005589*t*t
+	default:
+		u := (year - 1820) / 100
+		return -20 + 32*u*u - 0.5628*(2150-year)
+	}
+}
+
+// jdeToTime 将力学时儒略日转换为 UTC 时间
+func jdeToTime(jde float64) time.Time {
+	year := 2000 + (jde-j2000)/365.25
+	jd := jde - deltaT(year)/86400
+	seconds := (jd - unixEpochJD) * 86400
+	sec := math.Floor(seconds)
+	return time.Unix(int64(sec), int64((seconds-sec)*1e9)).UTC()
+}
+
+// solarLongitude 计算太阳视黄经（度）
+func solarLongitude(jde float64) float64 {
+	t := (jde - j2000) / 36525
+	l0 := 280.46646 + 36000.76983*t + 0.0003032*t*t
+	m := rad(357.52911 + 35999.05029*t - 0.0001537*t*t)
+	c := (1.914602-0.004817*t-0.000014*t*t)*math.Sin(m) +
+		(0.019993-0.000101*t)*math.Sin(2*m) +
+		0.000289*math.Sin(3*m)
+	omega := rad(125.04 - 1934.136*t)
+	return normalizeDegrees(l0 + c - 0.00569 - 0.00478*math.Sin(omega))
+}
+
+// solarTermJDE 求太阳视黄经到达 longitude 的时刻，approx 为初始估计值
+func solarTermJDE(longitude, approx float64) float64 {
+	jde := approx
+	for i := 0; i < 50; i++ {
+		diff := normalizeDegrees(longitude-solarLongitude(jde)+180) - 180
+		jde += diff * tropicalYear / 360
+		if math.Abs(diff) < 1e-7 {
+			break
+		}
+	}
+	return jde
+}
+
+// newMoonJDE 计算第 k 次朔的时刻（k = 0 对应 2000 年 1 月 6 日的朔）
+func newMoonJDE(k float64) float64 {
+	t := k / 1236.85
+	t2, t3, t4 := t*t, t*t*t, t*t*t*t
+
+	jde := 2451550.09766 + synodicMonth*k + 0.00015437*t2 - 0.000000150*t3 + 0.00000000073*t4
+	e := 1 - 0.002516*t - 0.0000074*t2
+	m := rad(2.5534 + 29.10535670*k - 0.0000014*t2 - 0.00000011*t3)
+	mp := rad(201.5643 + 385.81693528*k + 0.0107582*t2 + 0.00001238*t3 - 0.000000058*t4)
+	f := rad(160.7108 + 390.67050284*k - 0.0016118*t2 - 0.00000227*t3 + 0.000000011*t4)
+	omega := rad(124.7746 - 1.56375588*k + 0.0020672*t2 + 0.00000215*t3)
+
+	jde += -0.40720*math.Sin(mp) +
+		0.17241*e*math.Sin(m) +
+		0.01608*math.Sin(2*mp) +
+		0.01039*math.Sin(2*f) +
+		0.00739*e*math.Sin(mp-m) -
+		0.00514*e*math.Sin(mp+m) +
+		0.00208*e*e*math.Sin(2*m) -
+		0.00111*math.Sin(mp-2*f) -
+		0.00057*math.Sin(mp+2*f) +
+		0.00056*e*math.Sin(2*mp+m) -
+		0.00042*math.Sin(3*mp) +
+		0.00042*e*math.Sin(m+2*f) +
+		0.00038*e*math.Sin(m-2*f) -
+		0.00024*e*math.Sin(2*mp-m) -
+		0.00017*math.Sin(omega) -
+		0.00007*math.Sin(mp+2*m) +
+		0.00004*math.Sin(2*mp-2*f) +
+		0.00004*math.Sin(3*m) +
+		0.00003*math.Sin(mp+m-2*f) +
+		0.00003*math.Sin(2*mp+2*f) -
+		0.00003*math.Sin(mp+m+2*f) +
+		0.00003*math.Sin(mp-m+2*f) -
+		0.00002*math.Sin(mp-m-2*f) -
+		0.00002*math.Sin(3*mp+m) +
+		0.00002*math.Sin(4*mp)
+
+	// 行星摄动修正
+	planetary := [][3]float64{
+		{299.77, 0.107408, 0.000325},
+		{251.88, 0.016321, 0.000165},
+		{251.83, 26.651886, 0.000164},
+		{349.42, 36.412478, 0.000126},
+		{84.66, 18.206239, 0.000110},
+		{141.74, 53.303771, 0.000062},
+		{207.14, 2.453732, 0.000060},
+		{154.84, 7.306860, 0.000056},
+		{34.52, 27.261239, 0.000047},
+		{207.19, 0.121824, 0.000042},
+		{291.34, 1.844379, 0.000040},
+		{161.72, 24.198154, 0.000037},
+		{239.56, 25.513099, 0.000035},
+		{331.55, 3.592518, 0.000023},
+	}
+	for i, p := range planetary {
+		arg := p[0] + p[1]*k
+		if i == 0 {
+			arg -= 0.009173 * t2
+		}
+		jde += p[2] * math.Sin(rad(arg))
+	}
+
+	return jde
+}
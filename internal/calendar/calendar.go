@@ -0,0 +1,187 @@
+// Package calendar 提供农历（含闰月）、干支纪年与二十四节气的计算
+//
+// 农历按照现行国家标准《农历的编算和颁行》（GB/T 33661-2017）的规则编排：
+// 以北京时间（UTC+8）确定朔日与中气所在日期，含冬至的月份为十一月，
+// 两个冬至之间若有十三个月，则第一个不含中气的月份为闰月。
+// 节气时刻采用低精度太阳历表，误差在十分钟以内，足以确定节气所在日期。
+package calendar
+
+import (
+	"math"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// chinaZone 农历编算所用的北京时间
+var chinaZone = time.FixedZone("CST", 8*3600)
+
+var (
+	heavenlyStems   = []string{"甲", "乙", "丙", "丁", "戊", "己", "庚", "辛", "壬", "癸"}
+	earthlyBranches = []string{"子", "丑", "寅", "卯", "辰", "巳", "午", "未", "申", "酉", "戌", "亥"}
+	zodiacAnimals   = []string{"鼠", "牛", "虎", "兔", "龙", "蛇", "马", "羊", "猴", "鸡", "狗", "猪"}
+	monthNames      = []string{"正月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "冬月", "腊月"}
+	dayNames        = []string{
+		"初一", "初二", "初三", "初四", "初五", "初六", "初七", "初八", "初九", "初十",
+		"十一", "十二", "十三", "十四", "十五", "十六", "十七", "十八", "十九", "二十",
+		"廿一", "廿二", "廿三", "廿四", "廿五", "廿六", "廿七", "廿八", "廿九", "三十",
+	}
+	// termNames 按太阳视黄经 0°、15°、30° … 排列的节气名称
+	termNames = []string{
+		"春分", "清明", "谷雨", "立夏", "小满", "芒种",
+		"夏至", "小暑", "大暑", "立秋", "处暑", "白露",
+		"秋分", "寒露", "霜降", "立冬", "小雪", "大雪",
+		"冬至", "小寒", "大寒", "立春", "雨水", "惊蛰",
+	}
+)
+
+// Lookup 计算指定时刻所在日期（按 t 的时区）的农历与节气信息
+func Lookup(t time.Time) *model.CalendarInfo {
+	loc := t.Location()
+	year, month, day := t.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+
+	lunar := LunarDateOf(year, month, day)
+	ganzhi, zodiac := GanzhiYear(lunar.Year)
+
+	info := &model.CalendarInfo{
+		Date:       date.Format("2006-01-02"),
+		Timezone:   loc.String(),
+		Lunar:      lunar,
+		GanzhiYear: ganzhi,
+		Zodiac:     zodiac,
+	}
+
+	// 合并前后三年的节气，找出当前与下一个节气
+	var terms []model.SolarTerm
+	for y := year - 1; y <= year+1; y++ {
+		terms = append(terms, SolarTerms(y, loc)...)
+	}
+	today := info.Date
+	for i, term := range terms {
+		if term.Date > today {
+			info.NextTerm = term
+			if i > 0 {
+				info.CurrentTerm = terms[i-1]
+			}
+			break
+		}
+	}
+	info.IsTermDay = info.CurrentTerm.Date == today
+
+	return info
+}
+
+// LunarDateOf 将公历日期转换为农历日期
+func LunarDateOf(year int, month time.Month, day int) model.LunarDate {
+	jdn := dayNumber(year, int(month), day)
+
+	// 找到包含该日期的岁：从含冬至的冬月初一到下一个含冬至的冬月初一，
+	// 冬月初一早于冬至，因此要与冬月初一而不是冬至比较
+	solsticeYear := year
+	if jdn < newMoonDay(newMoonOnOrBefore(winterSolsticeDay(year))) {
+		solsticeYear = year - 1
+	}
+	k1 := newMoonOnOrBefore(winterSolsticeDay(solsticeYear))
+	k2 := newMoonOnOrBefore(winterSolsticeDay(solsticeYear + 1))
+	hasLeap := k2-k1 == 13
+
+	// 从冬月开始逐月推进到包含该日期的月，岁中第一个没有中气的月为闰月
+	k, number, isLeap, leapFound := k1, 11, false, false
+	for newMoonDay(k+1) <= jdn {
+		k++
+		isLeap = hasLeap && !leapFound && !hasMajorTerm(newMoonDay(k), newMoonDay(k+1))
+		if isLeap {
+			leapFound = true
+		} else {
+			number = number%12 + 1
+		}
+	}
+
+	lunarYear := solsticeYear
+	if number < 11 {
+		lunarYear++
+	}
+	monthName := monthNames[number-1]
+	if isLeap {
+		monthName = "闰" + monthName
+	}
+	d := jdn - newMoonDay(k) + 1
+	return model.LunarDate{
+		Year:      lunarYear,
+		Month:     number,
+		Day:       d,
+		IsLeap:    isLeap,
+		MonthName: monthName,
+		DayName:   dayNames[d-1],
+	}
+}
+
+// GanzhiYear 返回农历年的干支纪年与生肖
+func GanzhiYear(lunarYear int) (ganzhi, zodiac string) {
+	offset := ((lunarYear-4)%60 + 60) % 60
+	return heavenlyStems[offset%10] + earthlyBranches[offset%12], zodiacAnimals[offset%12]
+}
+
+// SolarTerms 返回公历某年按时间排序的二十四节气（从小寒到冬至），时刻与日期按 loc 表示
+func SolarTerms(year int, loc *time.Location) []model.SolarTerm {
+	terms := make([]model.SolarTerm, 0, 24)
+	base := julianDay(year, 1, 6)
+	for i := 0; i < 24; i++ {
+		longitude := (285 + 15*i) % 360
+		jde := solarTermJDE(float64(longitude), base+float64(i)*tropicalYear/24)
+		t := jdeToTime(jde).Round(time.Second).In(loc)
+		terms = append(terms, model.SolarTerm{
+			Name:      termNames[longitude/15],
+			Longitude: longitude,
+			Time:      t,
+			Date:      t.Format("2006-01-02"),
+		})
+	}
+	return terms
+}
+
+// dayNumber 返回公历日期的儒略日数（整数）
+func dayNumber(year, month, day int) int {
+	return int(julianDay(year, month, day) + 0.5)
+}
+
+// chinaDayNumber 返回时刻在北京时间下所在日期的儒略日数
+func chinaDayNumber(t time.Time) int {
+	y, m, d := t.In(chinaZone).Date()
+	return dayNumber(y, int(m), d)
+}
+
+// winterSolsticeDay 返回某年冬至在北京时间下的日期
+func winterSolsticeDay(year int) int {
+	return chinaDayNumber(jdeToTime(solarTermJDE(270, julianDay(year, 12, 21))))
+}
+
+// newMoonDay 返回第 k 次朔在北京时间下的日期
+func newMoonDay(k int) int {
+	return chinaDayNumber(jdeToTime(newMoonJDE(float64(k))))
+}
+
+// newMoonOnOrBefore 返回不晚于 jdn 当天的最近一次朔的序号
+func newMoonOnOrBefore(jdn int) int {
+	k := int(math.Floor((float64(jdn) - 2451550.09766) / synodicMonth))
+	for newMoonDay(k) > jdn {
+		k--
+	}
+	for newMoonDay(k+1) <= jdn {
+		k++
+	}
+	return k
+}
+
+// hasMajorTerm 判断 [start, end) 日期区间内是否含有中气（太阳视黄经为 30° 的整数倍）
+func hasMajorTerm(start, end int) bool {
+	return int(longitudeAtChinaMidnight(start)/30) != int(longitudeAtChinaMidnight(end)/30)
+}
+
+// longitudeAtChinaMidnight 计算北京时间某日 0 时的太阳视黄经
+func longitudeAtChinaMidnight(jdn int) float64 {
+	jd := float64(jdn) - 0.5 - 8.0/24
+	year := 2000 + (jd-j2000)/365.25
+	return solarLongitude(jd + deltaT(year)/86400)
+}
@@ -0,0 +1,134 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLunarDateOf(t *testing.T) {
+	// 已知日期对照表（来源：紫金山天文台发布的历书）
+	tests := []struct {
+		date   string
+		year   int
+		month  int
+		day    int
+		isLeap bool
+		name   string
+	}{
+		{"2000-02-05", 2000, 1, 1, false, "正月初一"},
+		{"2017-07-23", 2017, 6, 1, true, "闰六月初一"},
+		{"2020-01-25", 2020, 1, 1, false, "正月初一"},
+		{"2020-05-23", 2020, 4, 1, true, "闰四月初一"},
+		{"2021-02-12", 2021, 1, 1, false, "正月初一"},
+		{"2022-02-01", 2022, 1, 1, false, "正月初一"},
+		{"2023-01-21", 2022, 12, 30, false, "腊月三十"},
+		{"2023-01-22", 2023, 1, 1, false, "正月初一"},
+		{"2023-03-22", 2023, 2, 1, true, "闰二月初一"},
+		{"2024-02-10", 2024, 1, 1, false, "正月初一"},
+		{"2024-09-17", 2024, 8, 15, false, "八月十五"},
+		{"2025-01-29", 2025, 1, 1, false, "正月初一"},
+		{"2025-07-25", 2025, 6, 1, true, "闰六月初一"},
+		{"2025-10-06", 2025, 8, 15, false, "八月十五"},
+		{"2026-02-17", 2026, 1, 1, false, "正月初一"},
+		// 冬至之前、冬月初一之后的日期属于下一个岁
+		{"2023-12-15", 2023, 11, 3, false, "冬月初三"},
+		{"2024-12-05", 2024, 11, 5, false, "冬月初五"},
+		{"2024-12-20", 2024, 11, 20, false, "冬月二十"},
+		{"2024-12-31", 2024, 12, 1, false, "腊月初一"},
+		// 2033 年闰冬月：冬至前的冬月与冬至后的闰冬月
+		{"2033-12-20", 2033, 11, 29, false, "冬月廿九"},
+		{"2033-12-22", 2033, 11, 1, true, "闰冬月初一"},
+		{"2034-02-19", 2034, 1, 1, false, "正月初一"},
+	}
+
+	for _, tt := range tests {
+		d, _ := time.Parse("2006-01-02", tt.date)
+		got := LunarDateOf(d.Year(), d.Month(), d.Day())
+		if got.Year != tt.year || got.Month != tt.month || got.Day != tt.day || got.IsLeap != tt.isLeap {
+			t.Errorf("%s: 期望农历 %d-%d-%d (闰月=%v)，实际为 %d-%d-%d (闰月=%v)",
+				tt.date, tt.year, tt.month, tt.day, tt.isLeap, got.Year, got.Month, got.Day, got.IsLeap)
+		}
+		if name := got.MonthName + got.DayName; name != tt.name {
+			t.Errorf("%s: 期望名称 %s，实际为 %s", tt.date, tt.name, name)
+		}
+	}
+}
+
+func TestGanzhiYear(t *testing.T) {
+	tests := map[int][2]string{
+		1984: {"甲子", "鼠"},
+		2023: {"癸卯", "兔"},
+		2024: {"甲辰", "龙"},
+		2025: {"乙巳", "蛇"},
+		2026: {"丙午", "马"},
+	}
+
+	for year, want := range tests {
+		ganzhi, zodiac := GanzhiYear(year)
+		if ganzhi != want[0] || zodiac != want[1] {
+			t.Errorf("%d: 期望 %s%s，实际为 %s%s", year, want[0], want[1], ganzhi, zodiac)
+		}
+	}
+}
+
+func TestSolarTerms(t *testing.T) {
+	tests := []struct {
+		year int
+		name string
+		date string
+	}{
+		{2023, "冬至", "2023-12-22"},
+		{2024, "立春", "2024-02-04"},
+		{2024, "春分", "2024-03-20"},
+		{2024, "清明", "2024-04-04"},
+		{2024, "夏至", "2024-06-21"},
+		{2024, "冬至", "2024-12-21"},
+		{2025, "立春", "2025-02-03"},
+		{2025, "小寒", "2025-01-05"},
+	}
+
+	for _, tt := range tests {
+		found := false
+		for _, term := range SolarTerms(tt.year, chinaZone) {
+			if term.Name != tt.name {
+				continue
+			}
+			found = true
+			if term.Date != tt.date {
+				t.Errorf("%d %s: 期望日期 %s，实际为 %s", tt.year, tt.name, tt.date, term.Date)
+			}
+		}
+		if !found {
+			t.Errorf("%d: 未找到节气 %s", tt.year, tt.name)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("无法加载时区: %v", err)
+	}
+
+	info := Lookup(time.Date(2024, 2, 4, 20, 0, 0, 0, loc))
+	if info.GanzhiYear != "癸卯" {
+		t.Errorf("期望干支为 癸卯（春节前），实际为 %s", info.GanzhiYear)
+	}
+	if !info.IsTermDay || info.CurrentTerm.Name != "立春" {
+		t.Errorf("期望当天为立春，实际为 %s (节气日=%v)", info.CurrentTerm.Name, info.IsTermDay)
+	}
+	if info.NextTerm.Name != "雨水" {
+		t.Errorf("期望下一个节气为雨水，实际为 %s", info.NextTerm.Name)
+	}
+}
+
+func TestAstronomy(t *testing.T) {
+	// 2024-01-25 17:54 UTC 为满月
+	info := Astronomy(1706133600, 1706171400, time.Date(2024, 1, 25, 18, 0, 0, 0, time.UTC))
+	if info.MoonPhase != "full_moon" || info.MoonIllumination < 0.99 {
+		t.Errorf("期望满月，实际为 %s（照亮比例 %.2f）", info.MoonPhase, info.MoonIllumination)
+	}
+	if info.DayLength != 37800 || info.SolarNoon != 1706152500 {
+		t.Errorf("期望昼长 37800 秒、正午 1706152500，实际为 %d、%d", info.DayLength, info.SolarNoon)
+	}
+}
@@ -0,0 +1,62 @@
+package calendar
+
+import (
+	"math"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// moonPhases 八个月相的标识与名称，按月龄顺序排列
+var moonPhases = [][2]string{
+	{"new_moon", "新月"},
+	{"waxing_crescent", "娥眉月"},
+	{"first_quarter", "上弦月"},
+	{"waxing_gibbous", "盈凸月"},
+	{"full_moon", "满月"},
+	{"waning_gibbous", "亏凸月"},
+	{"last_quarter", "下弦月"},
+	{"waning_crescent", "残月"},
+}
+
+// Astronomy 根据日出日落时间戳计算昼长，并计算 now 时刻的月相
+func Astronomy(sunrise, sunset int64, now time.Time) *model.Astronomy {
+	info := &model.Astronomy{}
+	if sunrise > 0 && sunset > sunrise {
+		info.DayLength = sunset - sunrise
+		info.SolarNoon = sunrise + info.DayLength/2
+		info.IsDaytime = now.Unix() >= sunrise && now.Unix() < sunset
+	}
+
+	age, fraction := moonAge(now)
+	info.MoonAge = math.Round(age*10) / 10
+	info.MoonIllumination = math.Round((1-math.Cos(2*math.Pi*fraction))/2*100) / 100
+	phase := moonPhases[int(math.Floor(fraction*8+0.5))%8]
+	info.MoonPhase, info.MoonPhaseName = phase[0], phase[1]
+
+	return info
+}
+
+// moonAge 返回距上一次朔的天数以及在当前朔望月中的进度（0-1）
+func moonAge(t time.Time) (age, fraction float64) {
+	jd := float64(t.Unix())/86400 + unixEpochJD
+	k := math.Floor((jd - 2451550.09766) / synodicMonth)
+
+	prev, next := newMoonUT(k), newMoonUT(k+1)
+	for prev > jd {
+		k--
+		prev, next = newMoonUT(k), prev
+	}
+	for next <= jd {
+		k++
+		prev, next = next, newMoonUT(k+1)
+	}
+
+	age = jd - prev
+	return age, age / (next - prev)
+}
+
+// newMoonUT 返回第 k 次朔的儒略日（世界时）
+func newMoonUT(k float64) float64 {
+	return float64(jdeToTime(newMoonJDE(k)).UnixNano())/86400e9 + unixEpochJD
+}
@@ -10,6 +10,21 @@ import (
 type Config struct {
 	Server ServerConfig `json:"server"`
 	Weather WeatherConfig `json:"weather"`
+	Indices  IndicesConfig  `json:"indices"`
+	Batch    BatchConfig    `json:"batch"`
+	Jobs     JobsConfig     `json:"jobs"`
+	Live     LiveConfig     `json:"live"`
+	Webhooks WebhooksConfig `json:"webhooks"`
+	Notify   NotifyConfig   `json:"notify"`
+	Digests  DigestsConfig  `json:"digests"`
+	Archive  ArchiveConfig  `json:"archive"`
+	Historical HistoricalConfig `json:"historical"`
+	Shadow     ShadowConfig     `json:"shadow"`
+	Routing    RoutingConfig    `json:"routing"`
+	Consensus  ConsensusConfig  `json:"consensus"`
+	Tiles      TilesConfig      `json:"tiles"`
+	Area       AreaConfig       `json:"area"`
+	Grid       GridConfig       `json:"grid"`
 }
 
 // ServerConfig 服务器配置
@@ -25,6 +40,152 @@ type WeatherConfig struct {
 	BaseURL  string `json:"base_url"`
 	Timeout  int    `json:"timeout"` // 请求超时时间（秒）
 	Provider string `json:"provider"` // 天气服务提供商
+	OneCallURL string `json:"onecall_url"` // One Call 3.0 接口地址，用于查询历史天气
+	GeoURL     string `json:"geo_url"`     // 地理编码接口地址，用于把城市名称解析为坐标
+	TileURL    string `json:"tile_url"`    // 天气图层瓦片接口地址
+	RatePerMinute int `json:"rate_per_minute"` // 每分钟最多向天气 API 发起的请求数，0 表示不限制
+}
+
+// IndicesConfig 生活指数配置
+type IndicesConfig struct {
+	RulesFile string `json:"rules_file"` // 规则文件路径，为空时使用内置规则
+}
+
+// BatchConfig 批量查询配置
+type BatchConfig struct {
+	MaxItems    int `json:"max_items"`   // 单次批量查询的最大位置数
+	Concurrency int `json:"concurrency"` // 向天气服务并发请求的上限
+}
+
+// JobsConfig 异步批量任务配置
+type JobsConfig struct {
+	Dir           string `json:"dir"`             // 任务数据目录
+	Workers       int    `json:"workers"`         // 同时处理的任务数
+	RatePerMinute int    `json:"rate_per_minute"` // 每分钟最多向天气 API 发起的请求数
+	MaxItems      int    `json:"max_items"`       // 单个任务的最大位置数
+	MaxUploadMB   int    `json:"max_upload_mb"`   // 上传文件大小上限（MB）
+}
+
+// LiveConfig 实时推送配置
+type LiveConfig struct {
+	RefreshInterval   int `json:"refresh_interval"`   // 每个位置的后台刷新间隔（秒）
+	HeartbeatInterval int `json:"heartbeat_interval"` // 心跳间隔（秒）
+	MaxSubscriptions  int `json:"max_subscriptions"`  // 每个 WebSocket 连接最多订阅的位置数
+}
+
+// WebhooksConfig 阈值告警 Webhook 配置
+type WebhooksConfig struct {
+	Dir             string `json:"dir"`              // 规则数据目录
+	EvalInterval    int    `json:"eval_interval"`    // 规则检查间隔（秒）
+	Timeout         int    `json:"timeout"`          // 单次投递的超时时间（秒）
+	MaxRetries      int    `json:"max_retries"`      // 投递失败后的最大重试次数
+	RetryBackoff    int    `json:"retry_backoff"`    // 首次重试前的等待时间（秒），之后每次翻倍
+	DefaultCooldown int    `json:"default_cooldown"` // 规则未指定时的触发冷却时间（秒）
+	HistorySize     int    `json:"history_size"`     // 每条规则保留的投递记录数
+}
+
+// NotifyConfig 通知渠道配置
+type NotifyConfig struct {
+	ChannelsFile string `json:"channels_file"` // 渠道配置文件路径，为空时不启用任何渠道
+	Timeout      int    `json:"timeout"`       // 单次发送的超时时间（秒）
+}
+
+// DigestsConfig 定时天气简报配置
+type DigestsConfig struct {
+	Dir           string `json:"dir"`            // 订阅数据目录，多个副本共享同一目录时不会重复发送
+	CheckInterval int    `json:"check_interval"` // 检查到期订阅的间隔（秒）
+	Timeout       int    `json:"timeout"`        // 推送到 Webhook 地址的超时时间（秒）
+	MaxRetries    int    `json:"max_retries"`    // 推送到 Webhook 地址失败后的最大重试次数
+	RetryBackoff  int    `json:"retry_backoff"`  // 首次重试前的等待时间（秒），之后每次翻倍
+	MaxLocations  int    `json:"max_locations"`  // 每个订阅最多包含的位置数
+}
+
+// ArchiveConfig 观测存档配置
+type ArchiveConfig struct {
+	Path          string `json:"path"`           // SQLite 数据库文件路径，为空时不启用存档
+	Locations     string `json:"locations"`      // 定时轮询的位置，以分号分隔，每项是城市名称或 "纬度,经度"
+	PollInterval  int    `json:"poll_interval"`  // 定时轮询间隔（秒）
+	RetentionDays int    `json:"retention_days"` // 观测记录保留天数，0 表示永久保留
+	MaxPoints     int    `json:"max_points"`     // 单次历史查询最多返回的数据点数
+	NormalsWindow int    `json:"normals_window"` // 计算常年统计时包含目标日期前后的天数
+}
+
+// HistoricalConfig 历史天气查询配置
+type HistoricalConfig struct {
+	CacheDir    string `json:"cache_dir"`   // 历史天气缓存目录，为空时只缓存在内存中
+	MaxDays     int    `json:"max_days"`    // 单次查询最多包含的天数
+	Concurrency int    `json:"concurrency"` // 按天拆分查询时向天气服务并发请求的上限
+}
+
+// ShadowConfig 影子模式配置：在部分真实请求上后台调用候选天气服务，与主服务的结果比较
+type ShadowConfig struct {
+	Provider      string `json:"provider"`       // 候选天气服务提供商，为空时不启用影子模式
+	APIKey        string `json:"api_key"`        // 候选服务的 API Key，默认与主服务相同
+	BaseURL       string `json:"base_url"`       // 候选服务的接口地址，默认与主服务相同
+	SamplePercent int    `json:"sample_percent"` // 抽样比例（%），0-100
+	MaxInFlight   int    `json:"max_in_flight"`  // 同时进行的候选请求上限，超过时跳过抽样
+	Tolerances    string `json:"tolerances"`     // 覆盖默认的比较容差，如 "temperature=0.5,humidity=10"
+}
+
+// RoutingConfig 按地区选择数据提供商的配置
+type RoutingConfig struct {
+	RulesFile string `json:"rules_file"` // 路由规则文件路径，为空时所有请求都使用 WEATHER_PROVIDER
+}
+
+// ConsensusConfig 多数据提供商融合配置
+type ConsensusConfig struct {
+	File string `json:"file"` // 融合配置文件路径，为空时不启用融合
+}
+
+// TilesConfig 天气图层瓦片代理配置
+type TilesConfig struct {
+	CacheDir   string `json:"cache_dir"`    // 瓦片缓存目录，为空时不缓存
+	TTL        int    `json:"ttl"`          // 缓存的瓦片的有效期（秒）
+	MaxCacheMB int    `json:"max_cache_mb"` // 缓存目录的大小上限（MB），超过时淘汰最久未使用的瓦片
+	MaxZoom    int    `json:"max_zoom"`     // 允许请求的最大缩放级别
+}
+
+// AreaConfig 附近城市和经纬度范围查询配置
+type AreaConfig struct {
+	MaxResults     int `json:"max_results"`      // 单次查询最多返回的城市数
+	MaxRadiusKm    int `json:"max_radius_km"`    // 附近城市查询的最大半径（公里）
+	MaxBBoxDegrees int `json:"max_bbox_degrees"` // 经纬度范围查询的最大经度、纬度跨度（度）
+}
+
+// GridConfig 网格天气采样配置
+type GridConfig struct {
+	MaxPoints   int `json:"max_points"`  // 单次采样最多的网格点数
+	Concurrency int `json:"concurrency"` // 向天气服务请求的最大并发数，所有采样请求共享
+	TTL         int `json:"ttl"`         // 网格点天气的缓存时间（秒）
+}
+
+// ProviderConfig 配置文件中引用的一个数据提供商，未设置的字段使用 WEATHER_* 的配置
+type ProviderConfig struct {
+	Name       string `json:"name"`                  // 名称
+	Provider   string `json:"provider"`              // 天气服务类型，如 openweathermap
+	APIKey     string `json:"api_key,omitempty"`     // API Key
+	BaseURL    string `json:"base_url,omitempty"`    // 接口地址
+	OneCallURL string `json:"onecall_url,omitempty"` // One Call 3.0 接口地址
+	GeoURL     string `json:"geo_url,omitempty"`     // 地理编码接口地址
+}
+
+// WeatherConfig 返回以 base 为基础、应用了 p 中已设置字段的天气服务配置
+func (p *ProviderConfig) WeatherConfig(base *WeatherConfig) *WeatherConfig {
+	cfg := *base
+	cfg.Provider = p.Provider
+	if p.APIKey != "" {
+		cfg.APIKey = p.APIKey
+	}
+	if p.BaseURL != "" {
+		cfg.BaseURL = p.BaseURL
+	}
+	if p.OneCallURL != "" {
+		cfg.OneCallURL = p.OneCallURL
+	}
+	if p.GeoURL != "" {
+		cfg.GeoURL = p.GeoURL
+	}
+	return &cfg
 }
 
 // Load 从环境变量加载配置
@@ -40,6 +201,93 @@ func Load() (*Config, error) {
 			BaseURL:  getEnv("WEATHER_BASE_URL", "https://api.openweathermap.org/data/2.5"),
 			Timeout:  getEnvAsInt("WEATHER_TIMEOUT", 10),
 			Provider: getEnv("WEATHER_PROVIDER", "openweathermap"),
+			OneCallURL: getEnv("WEATHER_ONECALL_URL", "https://api.openweathermap.org/data/3.0/onecall"),
+			GeoURL:     getEnv("WEATHER_GEO_URL", "https://api.openweathermap.org/geo/1.0"),
+			TileURL:    getEnv("WEATHER_TILE_URL", "https://tile.openweathermap.org/map"),
+			RatePerMinute: getEnvAsInt("WEATHER_RATE_PER_MINUTE", 0),
+		},
+		Indices: IndicesConfig{
+			RulesFile: getEnv("INDICES_RULES_FILE", ""),
+		},
+		Batch: BatchConfig{
+			MaxItems:    getEnvAsInt("BATCH_MAX_ITEMS", 50),
+			Concurrency: getEnvAsInt("BATCH_CONCURRENCY", 5),
+		},
+		Jobs: JobsConfig{
+			Dir:           getEnv("JOBS_DIR", "data/jobs"),
+			Workers:       getEnvAsInt("JOBS_WORKERS", 2),
+			RatePerMinute: getEnvAsInt("JOBS_RATE_PER_MINUTE", 50),
+			MaxItems:      getEnvAsInt("JOBS_MAX_ITEMS", 100000),
+			MaxUploadMB:   getEnvAsInt("JOBS_MAX_UPLOAD_MB", 32),
+		},
+		Live: LiveConfig{
+			RefreshInterval:   getEnvAsInt("LIVE_REFRESH_INTERVAL", 300),
+			HeartbeatInterval: getEnvAsInt("LIVE_HEARTBEAT_INTERVAL", 15),
+			MaxSubscriptions:  getEnvAsInt("LIVE_MAX_SUBSCRIPTIONS", 50),
+		},
+		Webhooks: WebhooksConfig{
+			Dir:             getEnv("WEBHOOKS_DIR", "data/webhooks"),
+			EvalInterval:    getEnvAsInt("WEBHOOKS_EVAL_INTERVAL", 60),
+			Timeout:         getEnvAsInt("WEBHOOKS_TIMEOUT", 10),
+			MaxRetries:      getEnvAsInt("WEBHOOKS_MAX_RETRIES", 3),
+			RetryBackoff:    getEnvAsInt("WEBHOOKS_RETRY_BACKOFF", 10),
+			DefaultCooldown: getEnvAsInt("WEBHOOKS_DEFAULT_COOLDOWN", 3600),
+			HistorySize:     getEnvAsInt("WEBHOOKS_HISTORY_SIZE", 100),
+		},
+		Notify: NotifyConfig{
+			ChannelsFile: getEnv("NOTIFY_CHANNELS_FILE", ""),
+			Timeout:      getEnvAsInt("NOTIFY_TIMEOUT", 10),
+		},
+		Digests: DigestsConfig{
+			Dir:           getEnv("DIGESTS_DIR", "data/digests"),
+			CheckInterval: getEnvAsInt("DIGESTS_CHECK_INTERVAL", 20),
+			Timeout:       getEnvAsInt("DIGESTS_TIMEOUT", 10),
+			MaxRetries:    getEnvAsInt("DIGESTS_MAX_RETRIES", 3),
+			RetryBackoff:  getEnvAsInt("DIGESTS_RETRY_BACKOFF", 30),
+			MaxLocations:  getEnvAsInt("DIGESTS_MAX_LOCATIONS", 20),
+		},
+		Archive: ArchiveConfig{
+			Path:          getEnv("ARCHIVE_DB", "data/archive/weather.db"),
+			Locations:     getEnv("ARCHIVE_LOCATIONS", ""),
+			PollInterval:  getEnvAsInt("ARCHIVE_POLL_INTERVAL", 900),
+			RetentionDays: getEnvAsInt("ARCHIVE_RETENTION_DAYS", 365),
+			MaxPoints:     getEnvAsInt("ARCHIVE_MAX_POINTS", 2000),
+			NormalsWindow: getEnvAsInt("ARCHIVE_NORMALS_WINDOW", 15),
+		},
+		Historical: HistoricalConfig{
+			CacheDir:    getEnv("HISTORICAL_CACHE_DIR", "data/historical"),
+			MaxDays:     getEnvAsInt("HISTORICAL_MAX_DAYS", 31),
+			Concurrency: getEnvAsInt("HISTORICAL_CONCURRENCY", 4),
+		},
+		Shadow: ShadowConfig{
+			Provider:      getEnv("SHADOW_PROVIDER", ""),
+			APIKey:        getEnv("SHADOW_API_KEY", getEnv("WEATHER_API_KEY", "")),
+			BaseURL:       getEnv("SHADOW_BASE_URL", getEnv("WEATHER_BASE_URL", "https://api.openweathermap.org/data/2.5")),
+			SamplePercent: getEnvAsInt("SHADOW_SAMPLE_PERCENT", 10),
+			MaxInFlight:   getEnvAsInt("SHADOW_MAX_IN_FLIGHT", 4),
+			Tolerances:    getEnv("SHADOW_TOLERANCES", ""),
+		},
+		Routing: RoutingConfig{
+			RulesFile: getEnv("ROUTING_RULES_FILE", ""),
+		},
+		Consensus: ConsensusConfig{
+			File: getEnv("CONSENSUS_FILE", ""),
+		},
+		Tiles: TilesConfig{
+			CacheDir:   getEnv("TILES_CACHE_DIR", "data/tiles"),
+			TTL:        getEnvAsInt("TILES_CACHE_TTL", 1800),
+			MaxCacheMB: getEnvAsInt("TILES_CACHE_MAX_MB", 256),
+			MaxZoom:    getEnvAsInt("TILES_MAX_ZOOM", 12),
+		},
+		Area: AreaConfig{
+			MaxResults:     getEnvAsInt("AREA_MAX_RESULTS", 50),
+			MaxRadiusKm:    getEnvAsInt("AREA_MAX_RADIUS_KM", 200),
+			MaxBBoxDegrees: getEnvAsInt("AREA_MAX_BBOX_DEGREES", 10),
+		},
+		Grid: GridConfig{
+			MaxPoints:   getEnvAsInt("GRID_MAX_POINTS", 400),
+			Concurrency: getEnvAsInt("GRID_CONCURRENCY", 8),
+			TTL:         getEnvAsInt("GRID_CACHE_TTL", 600),
 		},
 	}
 
@@ -65,6 +313,86 @@ func (c *Config) validate() error {
 		return fmt.Errorf("天气 API 超时时间必须大于 0")
 	}
 
+	if c.Weather.RatePerMinute < 0 {
+		return fmt.Errorf("天气 API 的请求速率不能为负数")
+	}
+
+	if c.Batch.MaxItems <= 0 || c.Batch.Concurrency <= 0 {
+		return fmt.Errorf("批量查询的最大位置数和并发数必须大于 0")
+	}
+
+	if c.Jobs.Workers <= 0 || c.Jobs.RatePerMinute <= 0 || c.Jobs.MaxItems <= 0 || c.Jobs.MaxUploadMB <= 0 {
+		return fmt.Errorf("批量任务的 worker 数、请求速率、最大位置数和上传大小必须大于 0")
+	}
+
+	if c.Live.RefreshInterval <= 0 || c.Live.HeartbeatInterval <= 0 {
+		return fmt.Errorf("实时推送的刷新间隔和心跳间隔必须大于 0")
+	}
+
+	if c.Live.MaxSubscriptions <= 0 {
+		return fmt.Errorf("每个连接的最大订阅数必须大于 0")
+	}
+
+	if c.Webhooks.EvalInterval <= 0 || c.Webhooks.Timeout <= 0 || c.Webhooks.HistorySize <= 0 {
+		return fmt.Errorf("Webhook 的检查间隔、投递超时和投递记录数必须大于 0")
+	}
+
+	if c.Webhooks.MaxRetries < 0 || c.Webhooks.RetryBackoff < 0 || c.Webhooks.DefaultCooldown < 0 {
+		return fmt.Errorf("Webhook 的重试次数、重试间隔和冷却时间不能为负数")
+	}
+
+	if c.Notify.Timeout <= 0 {
+		return fmt.Errorf("通知发送超时时间必须大于 0")
+	}
+
+	if c.Digests.CheckInterval <= 0 || c.Digests.Timeout <= 0 || c.Digests.MaxLocations <= 0 {
+		return fmt.Errorf("简报的检查间隔、推送超时和位置数上限必须大于 0")
+	}
+
+	if c.Digests.MaxRetries < 0 || c.Digests.RetryBackoff < 0 {
+		return fmt.Errorf("简报的重试次数和重试间隔不能为负数")
+	}
+
+	if c.Archive.PollInterval <= 0 || c.Archive.MaxPoints <= 0 {
+		return fmt.Errorf("存档的轮询间隔和数据点上限必须大于 0")
+	}
+
+	if c.Archive.RetentionDays < 0 {
+		return fmt.Errorf("存档保留天数不能为负数")
+	}
+
+	if c.Archive.NormalsWindow <= 0 || c.Archive.NormalsWindow > 182 {
+		return fmt.Errorf("常年统计窗口必须在 1-182 天之间")
+	}
+
+	if c.Historical.MaxDays <= 0 || c.Historical.Concurrency <= 0 {
+		return fmt.Errorf("历史天气查询的最大天数和并发数必须大于 0")
+	}
+
+	if c.Shadow.SamplePercent < 0 || c.Shadow.SamplePercent > 100 {
+		return fmt.Errorf("影子模式的抽样比例必须在 0-100 之间")
+	}
+
+	if c.Shadow.MaxInFlight <= 0 {
+		return fmt.Errorf("影子模式的并发请求上限必须大于 0")
+	}
+
+	if c.Tiles.TTL <= 0 || c.Tiles.MaxCacheMB <= 0 {
+		return fmt.Errorf("瓦片缓存的有效期和大小上限必须大于 0")
+	}
+
+	if c.Tiles.MaxZoom < 0 || c.Tiles.MaxZoom > 20 {
+		return fmt.Errorf("瓦片的最大缩放级别必须在 0-20 之间")
+	}
+
+	if c.Area.MaxResults <= 0 || c.Area.MaxRadiusKm <= 0 || c.Area.MaxBBoxDegrees <= 0 {
+		return fmt.Errorf("区域查询的最大城市数、最大半径和最大跨度必须大于 0")
+	}
+
+	if c.Grid.MaxPoints <= 0 || c.Grid.Concurrency <= 0 || c.Grid.TTL <= 0 {
+		return fmt.Errorf("网格采样的最大点数、并发数和缓存时间必须大于 0")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// envPattern 配置文件中引用环境变量的 ${NAME} 语法，用于避免把密钥写进文件
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// LoadFile 读取 JSON 配置文件并解析到 v，文件中的 ${NAME} 先替换为对应环境变量的值，未设置的变量替换为空字符串。
+// what 是配置的名称，如“路由配置”，用于错误信息
+func LoadFile(path, what string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取%s失败: %w", what, err)
+	}
+	data = envPattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		return []byte(os.Getenv(string(match[2 : len(match)-1])))
+	})
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("解析%s失败: %w", what, err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	t.Setenv("TEST_CONFIG_SECRET", "s3cret")
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"key": "${TEST_CONFIG_SECRET}", "missing": "${TEST_CONFIG_UNSET}", "literal": "$HOME"}`), 0o644)
+
+	var v map[string]string
+	if err := LoadFile(path, "测试配置", &v); err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+	if v["key"] != "s3cret" || v["missing"] != "" || v["literal"] != "$HOME" {
+		t.Errorf("环境变量替换不正确: %v", v)
+	}
+
+	if err := LoadFile(filepath.Join(t.TempDir(), "none.json"), "测试配置", &v); err == nil || !strings.HasPrefix(err.Error(), "读取测试配置失败") {
+		t.Errorf("期望返回读取失败的错误，实际为 %v", err)
+	}
+	os.WriteFile(path, []byte(`{`), 0o644)
+	if err := LoadFile(path, "测试配置", &v); err == nil || !strings.HasPrefix(err.Error(), "解析测试配置失败") {
+		t.Errorf("期望返回解析失败的错误，实际为 %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+package consensus
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// sample 一个参与融合的结果及其权重
+type sample struct {
+	weight  float64
+	weather *model.WeatherResponse
+}
+
+// 字段的量纲，比较离散程度时据此把请求单位换算为公制单位
+const (
+	kindPlain = iota
+	kindTemperature
+	kindSpeed
+)
+
+// numericField 参与融合的数值字段
+type numericField struct {
+	name string
+	kind int
+	// agree 公制单位下离散程度不超过该值时可信度为 high，不超过两倍时为 medium
+	agree float64
+	// get 返回字段的值，提供商没有该数据时返回 NaN，不参与融合；set 收到 NaN 表示所有结果都没有该数据
+	get func(c *model.Current) float64
+	set func(c *model.Current, v float64)
+}
+
+// numericFields 参与融合的数值字段，风向单独按角度处理
+var numericFields = []numericField{
+	{"temperature", kindTemperature, 1,
+		func(c *model.Current) float64 { return c.Temperature },
+		func(c *model.Current, v float64) { c.Temperature = round2(v) }},
+	{"feels_like", kindTemperature, 1.5,
+		func(c *model.Current) float64 { return c.FeelsLike },
+		func(c *model.Current, v float64) { c.FeelsLike = round2(v) }},
+	{"temp_min", kindTemperature, 1.5,
+		func(c *model.Current) float64 { return c.TempMin },
+		func(c *model.Current, v float64) { c.TempMin = round2(v) }},
+	{"temp_max", kindTemperature, 1.5,
+		func(c *model.Current) float64 { return c.TempMax },
+		func(c *model.Current, v float64) { c.TempMax = round2(v) }},
+	{"pressure", kindPlain, 2,
+		func(c *model.Current) float64 { return float64(c.Pressure) },
+		func(c *model.Current, v float64) { c.Pressure = int(math.Round(v)) }},
+	{"humidity", kindPlain, 5,
+		func(c *model.Current) float64 { return float64(c.Humidity) },
+		func(c *model.Current, v float64) { c.Humidity = int(math.Round(v)) }},
+	{"visibility", kindPlain, 1000,
+		func(c *model.Current) float64 { return float64(c.Visibility) },
+		func(c *model.Current, v float64) { c.Visibility = int(math.Round(v)) }},
+	{"uv_index", kindPlain, 1,
+		func(c *model.Current) float64 {
+			if c.UVIndex == nil {
+				return math.NaN()
+			}
+			return *c.UVIndex
+		},
+		func(c *model.Current, v float64) {
+			c.UVIndex = nil
+			if !math.IsNaN(v) {
+				v = round2(v)
+				c.UVIndex = &v
+			}
+		}},
+	{"wind_speed", kindSpeed, 1,
+		func(c *model.Current) float64 { return c.Wind.Speed },
+		func(c *model.Current, v float64) { c.Wind.Speed = round2(v) }},
+	{"wind_gust", kindSpeed, 2,
+		func(c *model.Current) float64 { return c.Wind.Gust },
+		func(c *model.Current, v float64) { c.Wind.Gust = round2(v) }},
+	{"clouds", kindPlain, 20,
+		func(c *model.Current) float64 { return float64(c.Clouds.All) },
+		func(c *model.Current, v float64) { c.Clouds.All = int(math.Round(v)) }},
+	// 没有降水数据的提供商按 0 参与融合
+	{"rain_1h", kindPlain, 0.5,
+		func(c *model.Current) float64 {
+			if c.Rain == nil {
+				return 0
+			}
+			return c.Rain.OneHour
+		},
+		func(c *model.Current, v float64) {
+			c.Rain = nil
+			if v > 0 {
+				c.Rain = &model.Rain{OneHour: round2(v)}
+			}
+		}},
+	{"snow_1h", kindPlain, 0.5,
+		func(c *model.Current) float64 {
+			if c.Snow == nil {
+				return 0
+			}
+			return c.Snow.OneHour
+		},
+		func(c *model.Current, v float64) {
+			c.Snow = nil
+			if v > 0 {
+				c.Snow = &model.Snow{OneHour: round2(v)}
+			}
+		}},
+}
+
+const (
+	// windDirectionField 风向字段的名称
+	windDirectionField = "wind_direction"
+	// windDirectionAgree 风向的角度差不超过该值时可信度为 high
+	windDirectionAgree = 45
+)
+
+// combine 融合各结果：位置、日出日落等不融合的字段取自权重最高的结果，数据更新时间取最新的一个
+func combine(samples []sample, method, units string, info *model.ConsensusInfo) *model.WeatherResponse {
+	base := samples[0]
+	for _, s := range samples[1:] {
+		if s.weight > base.weight {
+			base = s
+		}
+	}
+	current := base.weather.Current
+
+	values := make([]float64, len(samples))
+	weights := make([]float64, len(samples))
+	for i, s := range samples {
+		weights[i] = s.weight
+		if s.weather.Current.UpdatedAt.After(current.UpdatedAt) {
+			current.UpdatedAt = s.weather.Current.UpdatedAt
+		}
+	}
+
+	info.Fields = make(map[string]model.FieldSpread, len(numericFields)+1)
+	for _, f := range numericFields {
+		// 只融合提供了该字段的结果
+		var present, presentWeights []float64
+		for i, s := range samples {
+			if v := f.get(&s.weather.Current); !math.IsNaN(v) {
+				present = append(present, v)
+				presentWeights = append(presentWeights, weights[i])
+			}
+		}
+		if len(present) == 0 {
+			f.set(&current, math.NaN())
+			continue
+		}
+		if method == MethodMedian {
+			f.set(&current, weightedMedian(present, presentWeights))
+		} else {
+			f.set(&current, weightedMean(present, presentWeights))
+		}
+		info.Fields[f.name] = linearSpread(present, presentWeights, f.agree/metricFactor(f.kind, units))
+	}
+
+	for i, s := range samples {
+		values[i] = float64(s.weather.Current.Wind.Direction)
+	}
+	direction, spread := circularBlend(values, weights)
+	current.Wind.Direction = direction
+	info.Fields[windDirectionField] = spread
+
+	current.Weather, info.Condition = vote(samples)
+
+	return &model.WeatherResponse{
+		Location:  base.weather.Location,
+		Current:   current,
+		Timestamp: time.Now().Unix(),
+		Provider:  Name,
+		Consensus: info,
+	}
+}
+
+// metricFactor 返回把 units 单位系统下的差值换算为公制单位时乘的系数
+func metricFactor(kind int, units string) float64 {
+	switch {
+	case kind == kindTemperature && units == "imperial":
+		return 5.0 / 9
+	case kind == kindSpeed && units == "imperial":
+		return 0.44704
+	default:
+		return 1
+	}
+}
+
+// weightedMedian 返回加权中位数，累计权重恰好为一半时取相邻两个值的平均值
+func weightedMedian(values, weights []float64) float64 {
+	order := make([]int, len(values))
+	var total float64
+	for i := range order {
+		order[i] = i
+		total += weights[i]
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	var cumulative float64
+	for k, i := range order {
+		cumulative += weights[i]
+		if math.Abs(cumulative-total/2) < 1e-9 && k+1 < len(order) {
+			return (values[i] + values[order[k+1]]) / 2
+		}
+		if cumulative > total/2 {
+			return values[i]
+		}
+	}
+	return values[order[len(order)-1]]
+}
+
+// weightedMean 返回加权平均值
+func weightedMean(values, weights []float64) float64 {
+	var sum, total float64
+	for i, v := range values {
+		sum += v * weights[i]
+		total += weights[i]
+	}
+	return sum / total
+}
+
+// linearSpread 计算数值字段的离散程度，agree 为请求单位下可信度为 high 的离散程度上限
+func linearSpread(values, weights []float64, agree float64) model.FieldSpread {
+	minValue, maxValue := values[0], values[0]
+	for _, v := range values[1:] {
+		minValue = math.Min(minValue, v)
+		maxValue = math.Max(maxValue, v)
+	}
+
+	mean := weightedMean(values, weights)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = v - mean
+	}
+	return newSpread(maxValue-minValue, deviations, weights, agree)
+}
+
+// circularBlend 按加权向量平均融合风向，返回融合后的风向和离散程度
+func circularBlend(values, weights []float64) (int, model.FieldSpread) {
+	var x, y float64
+	for i, v := range values {
+		x += weights[i] * math.Cos(v*math.Pi/180)
+		y += weights[i] * math.Sin(v*math.Pi/180)
+	}
+	mean := math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+
+	var maxDiff float64
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = angleDiff(v, mean)
+		for _, w := range values[i+1:] {
+			maxDiff = math.Max(maxDiff, math.Abs(angleDiff(v, w)))
+		}
+	}
+	return int(math.Round(mean)) % 360, newSpread(maxDiff, deviations, weights, windDirectionAgree)
+}
+
+// angleDiff 返回 a 减 b 的角度差，范围为 -180 到 180
+func angleDiff(a, b float64) float64 {
+	return math.Mod(math.Mod(a-b, 360)+540, 360) - 180
+}
+
+// newSpread 根据极差和各值与平均值的偏差计算离散程度和可信度
+func newSpread(spread float64, deviations, weights []float64, agree float64) model.FieldSpread {
+	var sum, total float64
+	for i, d := range deviations {
+		sum += weights[i] * d * d
+		total += weights[i]
+	}
+
+	confidence := "low"
+	switch {
+	case len(deviations) < 2:
+	case spread <= agree:
+		confidence = "high"
+	case spread <= 2*agree:
+		confidence = "medium"
+	}
+
+	return model.FieldSpread{
+		Spread:     round2(spread),
+		StdDev:     round2(math.Sqrt(sum / total)),
+		Sources:    len(deviations),
+		Confidence: confidence,
+	}
+}
+
+// vote 按权重对天气状况大类投票，平票时取先出现的；返回得票最多的大类中权重最高的结果的天气状况
+func vote(samples []sample) ([]model.Weather, model.ConditionVote) {
+	var order []string
+	votes := make(map[string]float64)
+	best := make(map[string]sample)
+	var total float64
+	sources := 0
+	for _, s := range samples {
+		if len(s.weather.Current.Weather) == 0 {
+			continue
+		}
+		main := s.weather.Current.Weather[0].Main
+		if _, ok := votes[main]; !ok {
+			order = append(order, main)
+		}
+		votes[main] += s.weight
+		if b, ok := best[main]; !ok || s.weight > b.weight {
+			best[main] = s
+		}
+		total += s.weight
+		sources++
+	}
+	if sources == 0 {
+		return nil, model.ConditionVote{Confidence: "low"}
+	}
+
+	winner := order[0]
+	for _, main := range order[1:] {
+		if votes[main] > votes[winner] {
+			winner = main
+		}
+	}
+
+	agreement := votes[winner] / total
+	confidence := "low"
+	switch {
+	case sources < 2:
+	case agreement >= 0.75:
+		confidence = "high"
+	case agreement >= 0.5:
+		confidence = "medium"
+	}
+
+	weather := append([]model.Weather(nil), best[winner].weather.Current.Weather...)
+	return weather, model.ConditionVote{
+		Main:       winner,
+		Agreement:  round2(agreement),
+		Sources:    sources,
+		Confidence: confidence,
+	}
+}
+
+// round2 保留两位小数
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
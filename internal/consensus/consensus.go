@@ -0,0 +1,273 @@
+// Package consensus 把多个数据提供商的当前天气融合为一个结果。
+//
+// 各数据提供商并行查询，超时或失败的不参与融合；数值字段取加权中位数或加权平均值，
+// 天气状况按权重投票，每个字段同时给出离散程度和可信度
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// Name 融合服务在路由规则和 provider 参数中使用的名称，也是融合结果的 provider 字段
+const Name = "consensus"
+
+const (
+	// MethodMedian 数值字段取加权中位数，不容易被单个偏差较大的提供商影响
+	MethodMedian = "median"
+	// MethodWeightedMean 数值字段取加权平均值
+	MethodWeightedMean = "weighted_mean"
+
+	// defaultTimeout 未配置时每个数据提供商的超时时间
+	defaultTimeout = 3 * time.Second
+)
+
+// MemberConfig 参与融合的一个数据提供商；name 与 WEATHER_PROVIDER 相同且未设置 provider 时表示主服务
+type MemberConfig struct {
+	config.ProviderConfig
+	Weight    float64 `json:"weight,omitempty"`     // 权重，默认 1
+	TimeoutMs int     `json:"timeout_ms,omitempty"` // 超时时间（毫秒），默认使用文件中的 timeout_ms
+}
+
+// File 融合配置文件格式
+type File struct {
+	Members   []MemberConfig `json:"members"`              // 参与融合的数据提供商
+	Method    string         `json:"method,omitempty"`     // median（默认）或 weighted_mean
+	TimeoutMs int            `json:"timeout_ms,omitempty"` // 每个数据提供商的默认超时时间（毫秒），默认 3000
+}
+
+// member 一个数据提供商及其权重和超时时间
+type member struct {
+	name    string
+	weight  float64
+	timeout time.Duration
+	service service.WeatherService
+}
+
+// Service 融合多个数据提供商结果的天气服务
+type Service struct {
+	method  string
+	members []member
+}
+
+// Load 从配置文件加载融合配置，primary 是按 base 创建的天气服务
+func Load(path string, base *config.WeatherConfig, primary service.WeatherService) (*Service, error) {
+	var file File
+	if err := config.LoadFile(path, "融合配置", &file); err != nil {
+		return nil, err
+	}
+	return New(&file, base, primary)
+}
+
+// New 根据融合配置创建融合天气服务
+func New(file *File, base *config.WeatherConfig, primary service.WeatherService) (*Service, error) {
+	method := file.Method
+	if method == "" {
+		method = MethodMedian
+	}
+	if method != MethodMedian && method != MethodWeightedMean {
+		return nil, fmt.Errorf("融合方式必须是 %s 或 %s", MethodMedian, MethodWeightedMean)
+	}
+
+	timeout := defaultTimeout
+	if file.TimeoutMs < 0 {
+		return nil, fmt.Errorf("超时时间不能为负数")
+	}
+	if file.TimeoutMs > 0 {
+		timeout = time.Duration(file.TimeoutMs) * time.Millisecond
+	}
+
+	if len(file.Members) == 0 {
+		return nil, fmt.Errorf("至少需要一个参与融合的数据提供商")
+	}
+
+	s := &Service{method: method}
+	names := make(map[string]bool, len(file.Members))
+	for _, mc := range file.Members {
+		if mc.Name == "" {
+			return nil, fmt.Errorf("数据提供商缺少 name")
+		}
+		if names[mc.Name] {
+			return nil, fmt.Errorf("数据提供商 %s 重复定义", mc.Name)
+		}
+		names[mc.Name] = true
+		if mc.Weight < 0 || mc.TimeoutMs < 0 {
+			return nil, fmt.Errorf("数据提供商 %s 的权重和超时时间不能为负数", mc.Name)
+		}
+
+		m := member{name: mc.Name, weight: mc.Weight, timeout: timeout}
+		if m.weight == 0 {
+			m.weight = 1
+		}
+		if mc.TimeoutMs > 0 {
+			m.timeout = time.Duration(mc.TimeoutMs) * time.Millisecond
+		}
+
+		if mc.Provider == "" && mc.Name == base.Provider {
+			m.service = primary
+		} else {
+			backend, err := service.New(mc.WeatherConfig(base))
+			if err != nil {
+				return nil, fmt.Errorf("数据提供商 %s 配置无效: %w", mc.Name, err)
+			}
+			m.service = backend
+		}
+
+		s.members = append(s.members, m)
+	}
+	return s, nil
+}
+
+// GetWeatherByCity 并行查询各数据提供商并融合结果
+func (s *Service) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	return s.blend(units, func(ws service.WeatherService) (*model.WeatherResponse, error) {
+		return ws.GetWeatherByCity(city, units, lang)
+	})
+}
+
+// GetWeatherByCoordinates 并行查询各数据提供商并融合结果
+func (s *Service) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return s.blend(units, func(ws service.WeatherService) (*model.WeatherResponse, error) {
+		return ws.GetWeatherByCoordinates(lat, lon, units, lang)
+	})
+}
+
+// GetForecastByCity 预报不参与融合，按配置顺序使用第一个成功的数据提供商
+func (s *Service) GetForecastByCity(city, units, lang string) (*model.Forecast, error) {
+	return s.firstForecast(&model.WeatherRequest{City: city, Units: units, Lang: lang})
+}
+
+// GetForecastByCoordinates 预报不参与融合，按配置顺序使用第一个成功的数据提供商
+func (s *Service) GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error) {
+	return s.firstForecast(&model.WeatherRequest{Lat: lat, Lon: lon, Units: units, Lang: lang})
+}
+
+// GetHistoricalByCity 历史天气不参与融合，按配置顺序使用第一个成功的数据提供商
+func (s *Service) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.first(func(ws service.WeatherService) (*model.WeatherResponse, error) {
+		return ws.GetHistoricalByCity(city, date, units, lang)
+	})
+}
+
+// GetHistoricalByCoordinates 历史天气不参与融合，按配置顺序使用第一个成功的数据提供商
+func (s *Service) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.first(func(ws service.WeatherService) (*model.WeatherResponse, error) {
+		return ws.GetHistoricalByCoordinates(lat, lon, date, units, lang)
+	})
+}
+
+// first 按配置顺序调用 fetch，返回第一个成功的结果
+func (s *Service) first(fetch func(ws service.WeatherService) (*model.WeatherResponse, error)) (*model.WeatherResponse, error) {
+	var errs []string
+	for _, m := range s.members {
+		weather, err := fetch(m.service)
+		if err == nil {
+			return weather, nil
+		}
+		errs = append(errs, m.name+": "+err.Error())
+	}
+	return nil, fmt.Errorf("所有数据提供商都失败: %s", strings.Join(errs, "; "))
+}
+
+// firstForecast 按配置顺序获取预报，返回第一个成功的结果
+func (s *Service) firstForecast(req *model.WeatherRequest) (*model.Forecast, error) {
+	var errs []string
+	for _, m := range s.members {
+		forecast, err := service.FetchForecast(m.service, req)
+		if err == nil {
+			return forecast, nil
+		}
+		errs = append(errs, m.name+": "+err.Error())
+	}
+	return nil, fmt.Errorf("所有数据提供商都失败: %s", strings.Join(errs, "; "))
+}
+
+// result 一个数据提供商的查询结果
+type result struct {
+	index   int
+	weather *model.WeatherResponse
+	err     error
+	latency time.Duration
+}
+
+// errTimeout 数据提供商未在超时时间内返回
+var errTimeout = errors.New("超时")
+
+// gather 并行调用 fetch，每个数据提供商最多等待各自的超时时间，超时后不再等待它返回。
+// 返回各数据提供商的结果，顺序与配置一致，超时的 err 为 errTimeout
+func (s *Service) gather(fetch func(ws service.WeatherService) (*model.WeatherResponse, error)) []result {
+	start := time.Now()
+	ch := make(chan result, len(s.members))
+	for i := range s.members {
+		go func(i int) {
+			m := &s.members[i]
+			done := make(chan result, 1)
+			go func() {
+				weather, err := fetch(m.service)
+				done <- result{index: i, weather: weather, err: err, latency: time.Since(start)}
+			}()
+
+			timer := time.NewTimer(m.timeout)
+			defer timer.Stop()
+			select {
+			case r := <-done:
+				ch <- r
+			case <-timer.C:
+				ch <- result{index: i, err: errTimeout, latency: m.timeout}
+			}
+		}(i)
+	}
+
+	results := make([]result, len(s.members))
+	for range s.members {
+		r := <-ch
+		results[r.index] = r
+	}
+	return results
+}
+
+// blend 并行查询各数据提供商并融合成功的结果，全部失败时返回错误
+func (s *Service) blend(units string, fetch func(ws service.WeatherService) (*model.WeatherResponse, error)) (*model.WeatherResponse, error) {
+	results := s.gather(fetch)
+
+	info := &model.ConsensusInfo{
+		Method:  s.method,
+		Members: make([]model.ConsensusMember, len(results)),
+	}
+	var samples []sample
+	var errs []string
+	for i, r := range results {
+		m := &s.members[i]
+		status := model.ConsensusMember{
+			Name:      m.name,
+			Weight:    m.weight,
+			Status:    "ok",
+			LatencyMs: float64(r.latency.Microseconds()) / 1000,
+		}
+		switch {
+		case errors.Is(r.err, errTimeout):
+			status.Status = "timeout"
+			status.Error = fmt.Sprintf("%d 毫秒内没有返回", m.timeout.Milliseconds())
+		case r.err != nil:
+			status.Status = "error"
+			status.Error = r.err.Error()
+		default:
+			samples = append(samples, sample{weight: m.weight, weather: r.weather})
+		}
+		if r.err != nil {
+			errs = append(errs, m.name+": "+status.Error)
+		}
+		info.Members[i] = status
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("所有数据提供商都失败: %s", strings.Join(errs, "; "))
+	}
+	return combine(samples, s.method, units, info), nil
+}
@@ -0,0 +1,214 @@
+package consensus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+)
+
+// mockService 模拟天气服务，返回固定的温度、风向和天气状况，delay 模拟上游耗时
+type mockService struct {
+	temperature float64
+	direction   int
+	condition   string
+	rain        float64
+	uv          float64 // 为 0 时模拟不提供紫外线指数的数据提供商
+	delay       time.Duration
+	err         error
+}
+
+func (m *mockService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	time.Sleep(m.delay)
+	if m.err != nil {
+		return nil, m.err
+	}
+	current := model.Current{
+		Temperature: m.temperature,
+		Humidity:    50,
+		Wind:        model.Wind{Speed: 3, Direction: m.direction},
+		Weather:     []model.Weather{{ID: 800, Main: m.condition, Description: strings.ToLower(m.condition)}},
+		UpdatedAt:   time.Unix(int64(m.temperature), 0),
+	}
+	if m.rain > 0 {
+		current.Rain = &model.Rain{OneHour: m.rain}
+	}
+	if m.uv > 0 {
+		uv := m.uv
+		current.UVIndex = &uv
+	}
+	return &model.WeatherResponse{Location: model.Location{Name: city}, Current: current, Provider: "mock"}, nil
+}
+
+func (m *mockService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity("coords", units, lang)
+}
+
+func (m *mockService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.GetWeatherByCity(city, units, lang)
+}
+
+func (m *mockService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetHistoricalByCity("coords", date, units, lang)
+}
+
+// newTestService 用模拟服务直接构造融合服务
+func newTestService(method string, members ...member) *Service {
+	return &Service{method: method, members: members}
+}
+
+func TestService_BlendsAndDropsFailures(t *testing.T) {
+	s := newTestService(MethodMedian,
+		member{name: "a", weight: 2, timeout: time.Second, service: &mockService{temperature: 20, direction: 350, condition: "Rain", rain: 1}},
+		member{name: "b", weight: 1, timeout: time.Second, service: &mockService{temperature: 21, direction: 10, condition: "Clouds"}},
+		member{name: "c", weight: 1, timeout: time.Second, service: &mockService{temperature: 30, direction: 0, condition: "Rain"}},
+		member{name: "slow", weight: 5, timeout: 20 * time.Millisecond, service: &mockService{temperature: 50, delay: 200 * time.Millisecond}},
+		member{name: "broken", weight: 5, timeout: time.Second, service: &mockService{err: errors.New("upstream error")}},
+	)
+
+	start := time.Now()
+	weather, err := s.GetWeatherByCity("Beijing", "metric", "zh_cn")
+	if err != nil {
+		t.Fatalf("融合失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("不应等待超时的数据提供商返回，实际耗时 %v", elapsed)
+	}
+
+	// 加权中位数：20（权重 2）、21、30，累计权重恰好一半时取 20 和 21 的平均值
+	if weather.Current.Temperature != 20.5 || weather.Provider != Name {
+		t.Errorf("期望温度为 20.5，实际为 %v（%s）", weather.Current.Temperature, weather.Provider)
+	}
+	// 风向按加权向量平均，350°（权重 2）、10°、0° 的结果偏向 350°；按算术平均会得到 177.5°
+	if weather.Current.Wind.Direction != 357 {
+		t.Errorf("期望风向为 357°，实际为 %d", weather.Current.Wind.Direction)
+	}
+	if weather.Current.UpdatedAt.Unix() != 30 || weather.Location.Name != "Beijing" {
+		t.Errorf("数据更新时间或位置不正确: %v %+v", weather.Current.UpdatedAt, weather.Location)
+	}
+
+	info := weather.Consensus
+	statuses := map[string]string{}
+	for _, m := range info.Members {
+		statuses[m.Name] = m.Status
+	}
+	if statuses["a"] != "ok" || statuses["slow"] != "timeout" || statuses["broken"] != "error" {
+		t.Errorf("数据提供商状态不正确: %+v", info.Members)
+	}
+
+	if f := info.Fields["temperature"]; f.Spread != 10 || f.Sources != 3 || f.Confidence != "low" {
+		t.Errorf("温度离散程度不正确: %+v", f)
+	}
+	if f := info.Fields["humidity"]; f.Spread != 0 || f.Confidence != "high" {
+		t.Errorf("湿度离散程度不正确: %+v", f)
+	}
+	if f := info.Fields["wind_direction"]; f.Spread != 20 || f.Confidence != "high" {
+		t.Errorf("风向离散程度不正确: %+v", f)
+	}
+	// 所有数据提供商都没有紫外线指数时保持缺失，而不是融合出 0
+	if _, ok := info.Fields["uv_index"]; ok || weather.Current.UVIndex != nil {
+		t.Errorf("期望没有紫外线指数，实际为 %v", weather.Current.UVIndex)
+	}
+
+	if info.Condition.Main != "Rain" || info.Condition.Agreement != 0.75 || info.Condition.Confidence != "high" {
+		t.Errorf("天气状况投票结果不正确: %+v", info.Condition)
+	}
+	if len(weather.Current.Weather) != 1 || weather.Current.Weather[0].Main != "Rain" {
+		t.Errorf("期望天气状况为 Rain，实际为 %+v", weather.Current.Weather)
+	}
+}
+
+func TestService_WeightedMean(t *testing.T) {
+	s := newTestService(MethodWeightedMean,
+		member{name: "a", weight: 3, timeout: time.Second, service: &mockService{temperature: 20, condition: "Clear", rain: 2, uv: 4}},
+		member{name: "b", weight: 1, timeout: time.Second, service: &mockService{temperature: 24, condition: "Clear"}},
+	)
+
+	weather, err := s.GetWeatherByCity("Beijing", "imperial", "en")
+	if err != nil {
+		t.Fatalf("融合失败: %v", err)
+	}
+	if weather.Current.Temperature != 21 {
+		t.Errorf("期望加权平均温度为 21，实际为 %v", weather.Current.Temperature)
+	}
+	if weather.Current.Rain == nil || weather.Current.Rain.OneHour != 1.5 {
+		t.Errorf("期望降水为 1.5 mm，实际为 %+v", weather.Current.Rain)
+	}
+	// 只有提供了紫外线指数的结果参与融合
+	if uv := weather.Current.UVIndex; uv == nil || *uv != 4 || weather.Consensus.Fields["uv_index"].Sources != 1 {
+		t.Errorf("期望紫外线指数为 4 且只有 1 个来源，实际为 %v %+v", uv, weather.Consensus.Fields["uv_index"])
+	}
+	// 华氏度下 4°F 约为 2.2°C，超过 1°C 但不超过 2°C 的两倍阈值
+	if f := weather.Consensus.Fields["temperature"]; f.Confidence != "low" || f.StdDev != 1.73 {
+		t.Errorf("温度离散程度不正确: %+v", f)
+	}
+}
+
+func TestService_AllFail(t *testing.T) {
+	s := newTestService(MethodMedian,
+		member{name: "a", weight: 1, timeout: time.Second, service: &mockService{err: errors.New("upstream error")}},
+		member{name: "b", weight: 1, timeout: 10 * time.Millisecond, service: &mockService{delay: 100 * time.Millisecond}},
+	)
+
+	_, err := s.GetWeatherByCity("Beijing", "metric", "zh_cn")
+	if err == nil || !strings.Contains(err.Error(), "a: upstream error") || !strings.Contains(err.Error(), "b: 10 毫秒内没有返回") {
+		t.Errorf("期望返回包含各数据提供商原因的错误，实际为 %v", err)
+	}
+
+	// 历史天气不参与融合，使用第一个成功的数据提供商
+	weather, err := s.GetHistoricalByCity("Beijing", time.Now(), "metric", "zh_cn")
+	if err != nil || weather.Provider != "mock" {
+		t.Errorf("期望历史天气来自第二个数据提供商，实际为 %+v, %v", weather, err)
+	}
+}
+
+func TestWeightedMedian(t *testing.T) {
+	tests := []struct {
+		values, weights []float64
+		want            float64
+	}{
+		{[]float64{3, 1, 2}, []float64{1, 1, 1}, 2},
+		{[]float64{1, 2}, []float64{1, 1}, 1.5},
+		{[]float64{1, 2, 10}, []float64{1, 1, 5}, 10},
+		{[]float64{5}, []float64{1}, 5},
+	}
+	for _, tt := range tests {
+		if got := weightedMedian(tt.values, tt.weights); got != tt.want {
+			t.Errorf("weightedMedian(%v, %v) = %v，期望 %v", tt.values, tt.weights, got, tt.want)
+		}
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	base := &config.WeatherConfig{Provider: "openweathermap"}
+	primary := config.ProviderConfig{Name: "openweathermap"}
+	tests := []struct {
+		name string
+		file File
+	}{
+		{"没有数据提供商", File{}},
+		{"未知的融合方式", File{Method: "mode", Members: []MemberConfig{{ProviderConfig: primary}}}},
+		{"未知的服务类型", File{Members: []MemberConfig{{ProviderConfig: config.ProviderConfig{Name: "x", Provider: "unknown"}}}}},
+		{"重复的数据提供商", File{Members: []MemberConfig{{ProviderConfig: primary}, {ProviderConfig: primary}}}},
+		{"负数权重", File{Members: []MemberConfig{{ProviderConfig: primary, Weight: -1}}}},
+	}
+	for _, tt := range tests {
+		if _, err := New(&tt.file, base, &mockService{}); err == nil {
+			t.Errorf("%s: 期望返回错误", tt.name)
+		}
+	}
+
+	s, err := New(&File{TimeoutMs: 500, Members: []MemberConfig{{ProviderConfig: primary}}}, base, &mockService{})
+	if err != nil {
+		t.Fatalf("创建融合服务失败: %v", err)
+	}
+	if s.method != MethodMedian || s.members[0].weight != 1 || s.members[0].timeout != 500*time.Millisecond {
+		t.Errorf("默认值不正确: %+v", s)
+	}
+}
@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/geojson"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultAreaLimit 未指定 limit 时附近城市查询返回的城市数
+	defaultAreaLimit = 20
+	// defaultRadiusKm 未指定 radius 时附近城市查询的半径（公里）
+	defaultRadiusKm = 50
+	// defaultBBoxZoom 未指定 zoom 时经纬度范围查询使用的地图缩放级别
+	defaultBBoxZoom = 10
+	// earthRadiusKm 地球平均半径（公里）
+	earthRadiusKm = 6371.0
+)
+
+// AreaController 附近城市和经纬度范围天气查询控制器
+type AreaController struct {
+	searcher service.AreaSearcher
+	config   *config.AreaConfig
+}
+
+// NewAreaController 创建区域查询控制器实例，searcher 为 nil 表示天气服务不支持区域查询
+func NewAreaController(searcher service.AreaSearcher, cfg *config.AreaConfig) *AreaController {
+	return &AreaController{
+		searcher: searcher,
+		config:   cfg,
+	}
+}
+
+// GetNearby 查询附近城市的天气
+// @Summary 查询附近城市的天气
+// @Description 返回距离指定坐标最近、且在半径范围内的城市的天气，按距离由近到远排列
+// @Tags weather
+// @Produce json,application/geo+json
+// @Param lat query number true "纬度"
+// @Param lon query number true "经度"
+// @Param radius query number false "半径（公里）" default(50)
+// @Param limit query int false "最多返回的城市数" default(20)
+// @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
+// @Param lang query string false "语言" default(zh_cn)
+// @Param format query string false "响应格式，geojson 返回 FeatureCollection" Enums(json, geojson)
+// @Success 200 {object} model.APIResponse{data=model.AreaResponse}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 503 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/weather/nearby [get]
+func (ac *AreaController) GetNearby(c *gin.Context) {
+	if !ac.enabled(c) {
+		return
+	}
+
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lon, errLon := strconv.ParseFloat(c.Query("lon"), 64)
+	if errLat != nil || errLon != nil || math.IsNaN(lat) || math.IsNaN(lon) {
+		respondWithError(c, http.StatusBadRequest, "参数错误", "必须提供数字格式的 lat 和 lon")
+		return
+	}
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		respondWithError(c, http.StatusBadRequest, "参数错误", "纬度必须在 -90 到 90 之间，经度必须在 -180 到 180 之间")
+		return
+	}
+
+	radius := float64(defaultRadiusKm)
+	if value := c.Query("radius"); value != "" {
+		var err error
+		radius, err = strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(radius) || radius <= 0 || radius > float64(ac.config.MaxRadiusKm) {
+			respondWithError(c, http.StatusBadRequest, "参数错误", fmt.Sprintf("radius 必须在 0 到 %d 公里之间", ac.config.MaxRadiusKm))
+			return
+		}
+	}
+
+	limit, ok := ac.parseLimit(c, min(defaultAreaLimit, ac.config.MaxResults))
+	if !ok {
+		return
+	}
+	units, lang, ok := parseUnitsAndLang(c)
+	if !ok {
+		return
+	}
+
+	items, err := ac.searcher.FindNearby(lat, lon, limit, units, lang)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
+		return
+	}
+
+	// 上游按距离排序但不支持半径，超出半径的城市在末尾，直接截掉
+	within := items[:0]
+	for _, item := range items {
+		if distanceKm(lat, lon, item.Location.Latitude, item.Location.Longitude) <= radius {
+			within = append(within, item)
+		}
+	}
+	// 上游返回了 limit 个城市且都在半径内时，半径内可能还有更多城市
+	truncated := len(items) >= limit && len(within) == len(items)
+	if len(within) > limit {
+		within = within[:limit]
+	}
+
+	ac.respond(c, within, truncated)
+}
+
+// GetBBox 查询经纬度范围内城市的天气
+// @Summary 查询经纬度范围内城市的天气
+// @Description 返回经纬度范围内城市的天气，用于在地图视野内展示天气
+// @Tags weather
+// @Produce json,application/geo+json
+// @Param bbox query string true "经纬度范围：最小经度,最小纬度,最大经度,最大纬度"
+// @Param zoom query int false "地图缩放级别，越大包含的小城市越多" default(10)
+// @Param limit query int false "最多返回的城市数，默认为服务端上限"
+// @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
+// @Param lang query string false "语言" default(zh_cn)
+// @Param format query string false "响应格式，geojson 返回 FeatureCollection" Enums(json, geojson)
+// @Success 200 {object} model.APIResponse{data=model.AreaResponse}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 503 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/weather/bbox [get]
+func (ac *AreaController) GetBBox(c *gin.Context) {
+	if !ac.enabled(c) {
+		return
+	}
+
+	bbox, err := parseBBox(c.Query("bbox"), float64(ac.config.MaxBBoxDegrees))
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	zoom := defaultBBoxZoom
+	if value := c.Query("zoom"); value != "" {
+		zoom, err = strconv.Atoi(value)
+		if err != nil || zoom < 1 || zoom > 20 {
+			respondWithError(c, http.StatusBadRequest, "参数错误", "zoom 必须是 1 到 20 之间的整数")
+			return
+		}
+	}
+
+	limit, ok := ac.parseLimit(c, ac.config.MaxResults)
+	if !ok {
+		return
+	}
+	units, lang, ok := parseUnitsAndLang(c)
+	if !ok {
+		return
+	}
+
+	items, err := ac.searcher.FindInBBox(bbox, zoom, units, lang)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
+		return
+	}
+
+	truncated := len(items) > limit
+	if truncated {
+		items = items[:limit]
+	}
+	ac.respond(c, items, truncated)
+}
+
+// enabled 检查天气服务是否支持区域查询，不支持时写入 503 响应
+func (ac *AreaController) enabled(c *gin.Context) bool {
+	if ac.searcher == nil {
+		respondWithError(c, http.StatusServiceUnavailable, "区域查询未启用", "当前天气服务不支持附近城市和经纬度范围查询")
+		return false
+	}
+	return true
+}
+
+// parseLimit 解析 limit 参数，必须在 1 到配置的上限之间
+func (ac *AreaController) parseLimit(c *gin.Context, defaultLimit int) (int, bool) {
+	value := c.Query("limit")
+	if value == "" {
+		return defaultLimit, true
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > ac.config.MaxResults {
+		respondWithError(c, http.StatusBadRequest, "参数错误", fmt.Sprintf("limit 必须是 1 到 %d 之间的整数", ac.config.MaxResults))
+		return 0, false
+	}
+	return limit, true
+}
+
+// respond 按请求的格式返回城市列表，请求 GeoJSON 时返回 FeatureCollection
+func (ac *AreaController) respond(c *gin.Context, items []model.WeatherResponse, truncated bool) {
+	if wantsGeoJSON(c) {
+		respondWithGeoJSON(c, geojson.Collection(items))
+		return
+	}
+	respondWithSuccess(c, &model.AreaResponse{
+		Count:     len(items),
+		Truncated: truncated,
+		Items:     items,
+	})
+}
+
+// parseUnitsAndLang 解析 units 和 lang 参数并填充默认值，失败时直接写入错误响应
+func parseUnitsAndLang(c *gin.Context) (string, string, bool) {
+	units := c.DefaultQuery("units", "metric")
+	if !model.ValidUnits(units) {
+		respondWithError(c, http.StatusBadRequest, "参数错误", "单位系统必须是 metric、imperial 或 standard")
+		return "", "", false
+	}
+	return units, c.DefaultQuery("lang", "zh_cn"), true
+}
+
+// parseBBox 解析 "最小经度,最小纬度,最大经度,最大纬度" 格式的经纬度范围，经度和纬度的跨度都不能超过 maxSpan 度
+func parseBBox(value string, maxSpan float64) ([4]float64, error) {
+	var bbox [4]float64
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return bbox, fmt.Errorf("bbox 必须是 最小经度,最小纬度,最大经度,最大纬度")
+	}
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(v) {
+			return bbox, fmt.Errorf("bbox 必须由 4 个数字组成")
+		}
+		bbox[i] = v
+	}
+
+	if bbox[0] < -180 || bbox[2] > 180 || bbox[1] < -90 || bbox[3] > 90 {
+		return bbox, fmt.Errorf("bbox 的经度必须在 -180 到 180 之间，纬度必须在 -90 到 90 之间")
+	}
+	if bbox[0] >= bbox[2] || bbox[1] >= bbox[3] {
+		return bbox, fmt.Errorf("bbox 的最小值必须小于最大值")
+	}
+	if bbox[2]-bbox[0] > maxSpan || bbox[3]-bbox[1] > maxSpan {
+		return bbox, fmt.Errorf("bbox 的经度和纬度跨度都不能超过 %g 度", maxSpan)
+	}
+	return bbox, nil
+}
+
+// distanceKm 用半正矢公式计算两点之间的球面距离（公里）
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockAreaSearcher 返回固定的城市列表，并记录收到的参数
+type mockAreaSearcher struct {
+	items []model.WeatherResponse
+	limit int
+	zoom  int
+}
+
+func (m *mockAreaSearcher) FindNearby(lat, lon float64, limit int, units, lang string) ([]model.WeatherResponse, error) {
+	m.limit = limit
+	if len(m.items) > limit {
+		return m.items[:limit], nil
+	}
+	return m.items, nil
+}
+
+func (m *mockAreaSearcher) FindInBBox(bbox [4]float64, zoom int, units, lang string) ([]model.WeatherResponse, error) {
+	m.zoom = zoom
+	return m.items, nil
+}
+
+// areaCity 创建位于 (lat, lon) 的城市天气
+func areaCity(name string, lat, lon float64) model.WeatherResponse {
+	return model.WeatherResponse{
+		Location: model.Location{Name: name, Latitude: lat, Longitude: lon},
+		Current:  model.Current{Temperature: 20, Weather: []model.Weather{{Main: "Clear"}}},
+		Provider: "mock",
+	}
+}
+
+func newAreaRouter(searcher service.AreaSearcher) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	controller := NewAreaController(searcher, &config.AreaConfig{MaxResults: 3, MaxRadiusKm: 100, MaxBBoxDegrees: 5})
+	router := gin.New()
+	router.GET("/nearby", controller.GetNearby)
+	router.GET("/bbox", controller.GetBBox)
+	return router
+}
+
+func TestAreaController_GetNearby(t *testing.T) {
+	// 北京以东约 0.1°、0.5°、1° 经度的三个城市，分别约 8.5、43、85 公里
+	searcher := &mockAreaSearcher{items: []model.WeatherResponse{
+		areaCity("a", 39.9, 116.5), areaCity("b", 39.9, 116.9), areaCity("c", 39.9, 117.4),
+	}}
+	router := newAreaRouter(searcher)
+
+	req, _ := http.NewRequest("GET", "/nearby?lat=39.9&lon=116.4&radius=50", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data model.AreaResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if searcher.limit != 3 || response.Data.Count != 2 || response.Data.Truncated || response.Data.Items[1].Location.Name != "b" {
+		t.Errorf("期望返回半径内的 2 个城市，实际为 %+v（limit %d）", response.Data, searcher.limit)
+	}
+
+	// 上游返回的城市数达到 limit 且都在半径内，可能还有更多
+	req, _ = http.NewRequest("GET", "/nearby?lat=39.9&lon=116.4&radius=100&limit=2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.Count != 2 || !response.Data.Truncated {
+		t.Errorf("期望结果被截断，实际为 %+v", response.Data)
+	}
+
+	for _, query := range []string{"lat=39.9", "lat=91&lon=0", "lat=0&lon=0&radius=101", "lat=0&lon=0&limit=4", "lat=0&lon=0&units=kelvin", "lat=NaN&lon=0", "lat=0&lon=NaN", "lat=0&lon=0&radius=NaN"} {
+		req, _ = http.NewRequest("GET", "/nearby?"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: 期望状态码 400，实际为 %d", query, w.Code)
+		}
+	}
+}
+
+func TestAreaController_GetBBox(t *testing.T) {
+	searcher := &mockAreaSearcher{items: []model.WeatherResponse{
+		areaCity("a", 39.9, 116.5), areaCity("b", 39.5, 116.9), areaCity("c", 39.1, 117.2), areaCity("d", 40, 117),
+	}}
+	router := newAreaRouter(searcher)
+
+	req, _ := http.NewRequest("GET", "/bbox?bbox=116,39,118,41&format=geojson", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != model.GeoJSONMediaType {
+		t.Fatalf("期望返回 GeoJSON，实际为 %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	var collection model.FeatureCollection
+	json.Unmarshal(w.Body.Bytes(), &collection)
+	if searcher.zoom != defaultBBoxZoom || collection.Type != "FeatureCollection" || len(collection.Features) != 3 {
+		t.Fatalf("期望返回 3 个要素，实际为 %+v", collection)
+	}
+	feature := collection.Features[1]
+	if feature.Geometry.Type != "Point" || feature.Geometry.Coordinates[0] != 116.9 || feature.Geometry.Coordinates[1] != 39.5 ||
+		feature.Properties.Name != "b" || feature.Properties.Condition != "Clear" {
+		t.Errorf("要素内容不正确: %+v", feature)
+	}
+
+	for _, query := range []string{"", "bbox=116,39,118", "bbox=118,39,116,41", "bbox=110,39,118,41", "bbox=116,39,118,41&zoom=0"} {
+		req, _ = http.NewRequest("GET", "/bbox?"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: 期望状态码 400，实际为 %d", query, w.Code)
+		}
+	}
+
+	router = newAreaRouter(nil)
+	req, _ = http.NewRequest("GET", "/bbox?bbox=116,39,118,41", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("期望未启用时返回 503，实际为 %d", w.Code)
+	}
+}
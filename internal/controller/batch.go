@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/geojson"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchController 批量天气查询控制器
+type BatchController struct {
+	weatherService service.WeatherService
+	config         *config.BatchConfig
+}
+
+// NewBatchController 创建批量查询控制器实例
+func NewBatchController(weatherService service.WeatherService, cfg *config.BatchConfig) *BatchController {
+	return &BatchController{
+		weatherService: weatherService,
+		config:         cfg,
+	}
+}
+
+// GetWeatherBatch 批量获取天气信息
+// @Summary 批量获取天气信息
+// @Description 一次查询多个城市或坐标的天气，服务端以有限并发向天气服务获取数据，结果按请求顺序返回，单个位置失败不影响其他位置
+// @Tags weather
+// @Accept json
+// @Produce json,application/geo+json
+// @Param request body model.BatchRequest true "位置列表"
+// @Param format query string false "响应格式，geojson 返回查询成功的位置组成的 FeatureCollection" Enums(json, geojson)
+// @Success 200 {object} model.APIResponse{data=model.BatchResponse}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/weather/batch [post]
+func (bc *BatchController) GetWeatherBatch(c *gin.Context) {
+	var req model.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+
+	if len(req.Locations) > bc.config.MaxItems {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", fmt.Sprintf("单次最多查询 %d 个位置", bc.config.MaxItems))
+		return
+	}
+
+	results := make([]model.BatchItemResult, len(req.Locations))
+
+	// 先校验每个位置，只把合法的位置交给天气服务
+	var valid []model.WeatherRequest
+	var validIndex []int
+	for i := range req.Locations {
+		item := req.Locations[i]
+		results[i].Index = i
+
+		if err := item.Validate(); err != nil {
+			results[i].Error = &model.ErrorResponse{
+				Error:   "参数验证失败",
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			}
+			continue
+		}
+
+		if item.Units == "" {
+			item.Units = defaultString(req.Units, "metric")
+		}
+		if item.Lang == "" {
+			item.Lang = defaultString(req.Lang, "zh_cn")
+		}
+		valid = append(valid, item)
+		validIndex = append(validIndex, i)
+	}
+
+	for j, result := range service.FetchAll(bc.weatherService, valid, bc.config.Concurrency) {
+		i := validIndex[j]
+		if result.Err != nil {
+			results[i].Error = &model.ErrorResponse{
+				Error:   "获取天气信息失败",
+				Code:    http.StatusInternalServerError,
+				Message: result.Err.Error(),
+			}
+			continue
+		}
+		results[i].Success = true
+		results[i].Data = result.Weather
+	}
+
+	// GeoJSON 要素必须有几何，查询失败的位置无法表示，只返回成功的位置
+	if wantsGeoJSON(c) {
+		var items []model.WeatherResponse
+		for _, result := range results {
+			if result.Success {
+				items = append(items, *result.Data)
+			}
+		}
+		respondWithGeoJSON(c, geojson.Collection(items))
+		return
+	}
+
+	resp := model.BatchResponse{Results: results}
+	for _, result := range results {
+		if result.Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	respondWithSuccess(c, resp)
+}
+
+// defaultString 返回 value，为空时返回 fallback
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FailingCityService 对指定城市返回错误的模拟天气服务
+type FailingCityService struct {
+	MockWeatherService
+	failCity string
+}
+
+func (m *FailingCityService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	if city == m.failCity {
+		return nil, errors.New("city not found")
+	}
+	return m.MockWeatherService.GetWeatherByCity(city, units, lang)
+}
+
+func TestBatchController_GetWeatherBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := NewBatchController(&FailingCityService{failCity: "Nowhere"}, &config.BatchConfig{MaxItems: 5, Concurrency: 2})
+	router := gin.New()
+	router.POST("/weather/batch", controller.GetWeatherBatch)
+
+	body := `{"locations":[{"city":"Beijing"},{"city":"Nowhere"},{"lat":31.23,"lon":121.47},{"lat":91,"lon":0},{"city":"Shanghai","units":"kelvin"}]}`
+	req, _ := http.NewRequest("POST", "/weather/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d", w.Code)
+	}
+
+	var response struct {
+		Data model.BatchResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+
+	expected := []struct {
+		success bool
+		code    int
+		name    string
+	}{
+		{true, 0, "Beijing"},
+		{false, http.StatusInternalServerError, ""},
+		{true, 0, "Test City"},
+		{false, http.StatusBadRequest, ""},
+		{false, http.StatusBadRequest, ""},
+	}
+	if len(response.Data.Results) != len(expected) {
+		t.Fatalf("期望 %d 个结果，实际为 %d", len(expected), len(response.Data.Results))
+	}
+	for i, want := range expected {
+		got := response.Data.Results[i]
+		if got.Index != i || got.Success != want.success {
+			t.Errorf("结果 %d: 期望 success=%v，实际为 index=%d success=%v", i, want.success, got.Index, got.Success)
+			continue
+		}
+		if want.success && got.Data.Location.Name != want.name {
+			t.Errorf("结果 %d: 期望城市 %s，实际为 %s", i, want.name, got.Data.Location.Name)
+		}
+		if !want.success && got.Error.Code != want.code {
+			t.Errorf("结果 %d: 期望错误码 %d，实际为 %d", i, want.code, got.Error.Code)
+		}
+	}
+	if response.Data.Succeeded != 2 || response.Data.Failed != 3 {
+		t.Errorf("期望成功 2 个、失败 3 个，实际为 %d、%d", response.Data.Succeeded, response.Data.Failed)
+	}
+}
+
+func TestBatchController_GetWeatherBatchGeoJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := NewBatchController(&FailingCityService{failCity: "Nowhere"}, &config.BatchConfig{MaxItems: 5, Concurrency: 2})
+	router := gin.New()
+	router.POST("/weather/batch", controller.GetWeatherBatch)
+
+	body := `{"locations":[{"city":"Beijing"},{"city":"Nowhere"},{"city":"Shanghai"}]}`
+	req, _ := http.NewRequest("POST", "/weather/batch?format=geojson", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != model.GeoJSONMediaType {
+		t.Fatalf("期望返回 GeoJSON，实际为 %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	var collection model.FeatureCollection
+	if err := json.Unmarshal(w.Body.Bytes(), &collection); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 2 ||
+		collection.Features[0].Properties.Name != "Beijing" || collection.Features[1].Properties.Name != "Shanghai" {
+		t.Errorf("期望只返回查询成功的 2 个位置，实际为 %+v", collection)
+	}
+}
+
+func TestBatchController_GetWeatherBatchTooMany(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := NewBatchController(&MockWeatherService{}, &config.BatchConfig{MaxItems: 1, Concurrency: 1})
+	router := gin.New()
+	router.POST("/weather/batch", controller.GetWeatherBatch)
+
+	body := `{"locations":[{"city":"Beijing"},{"city":"Shanghai"}]}`
+	req, _ := http.NewRequest("POST", "/weather/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("期望状态码 400，实际为 %d", w.Code)
+	}
+}
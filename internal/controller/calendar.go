@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"gin-weather/internal/calendar"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCalendarTimezone 农历查询的默认时区
+const defaultCalendarTimezone = "Asia/Shanghai"
+
+// CalendarController 农历与节气控制器
+type CalendarController struct{}
+
+// NewCalendarController 创建农历控制器实例
+func NewCalendarController() *CalendarController {
+	return &CalendarController{}
+}
+
+// GetCalendar 获取农历与节气信息
+// @Summary 获取农历与节气信息
+// @Description 计算指定日期的农历日期（含闰月）、干支纪年以及当前和下一个节气
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param date query string false "公历日期（YYYY-MM-DD），默认为当天"
+// @Param tz query string false "IANA 时区名称" default(Asia/Shanghai)
+// @Success 200 {object} model.APIResponse{data=model.CalendarInfo}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/calendar [get]
+func (cc *CalendarController) GetCalendar(c *gin.Context) {
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", defaultCalendarTimezone))
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数错误", "时区名称不正确")
+		return
+	}
+
+	date := time.Now().In(loc)
+	if dateStr := c.Query("date"); dateStr != "" {
+		date, err = time.ParseInLocation("2006-01-02", dateStr, loc)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, "参数错误", "日期格式必须为 YYYY-MM-DD")
+			return
+		}
+		if date.Year() < 1900 || date.Year() > 2100 {
+			respondWithError(c, http.StatusBadRequest, "参数错误", "日期必须在 1900 到 2100 年之间")
+			return
+		}
+	}
+
+	respondWithSuccess(c, calendar.Lookup(date))
+}
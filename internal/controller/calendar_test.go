@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCalendarController_GetCalendar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := NewCalendarController()
+	router := gin.New()
+	router.GET("/calendar", controller.GetCalendar)
+
+	req, _ := http.NewRequest("GET", "/calendar?date=2024-02-10&tz=Asia/Shanghai", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d", w.Code)
+	}
+
+	var response struct {
+		Success bool               `json:"success"`
+		Data    model.CalendarInfo `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+
+	if response.Data.GanzhiYear != "甲辰" || response.Data.Lunar.MonthName+response.Data.Lunar.DayName != "正月初一" {
+		t.Errorf("期望 甲辰年正月初一，实际为 %s年%s%s", response.Data.GanzhiYear, response.Data.Lunar.MonthName, response.Data.Lunar.DayName)
+	}
+}
+
+func TestCalendarController_GetCalendarInvalidDate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := NewCalendarController()
+	router := gin.New()
+	router.GET("/calendar", controller.GetCalendar)
+
+	for _, query := range []string{"date=2024/02/10", "tz=Mars/Olympus"} {
+		req, _ := http.NewRequest("GET", "/calendar?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: 期望状态码 400，实际为 %d", query, w.Code)
+		}
+	}
+}
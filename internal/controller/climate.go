@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gin-weather/internal/archive"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClimateController 气候统计控制器
+type ClimateController struct {
+	archive *archive.Archive
+}
+
+// NewClimateController 创建气候统计控制器实例，archive 为 nil 表示未启用存档
+func NewClimateController(archive *archive.Archive) *ClimateController {
+	return &ClimateController{
+		archive: archive,
+	}
+}
+
+// GetNormals 查询常年气候统计
+// @Summary 查询常年气候统计
+// @Description 根据观测存档计算某个日期前后（各年同一时期）的温度、湿度和降水的平均值与标准差，每项都给出参与计算的天数和观测次数
+// @Tags climate
+// @Produce json
+// @Param city query string false "城市名称（与坐标二选一）"
+// @Param lat query number false "纬度"
+// @Param lon query number false "经度"
+// @Param date query string false "目标日期（当地，YYYY-MM-DD），默认为今天（UTC）"
+// @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
+// @Success 200 {object} model.APIResponse{data=model.ClimateNormals}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 503 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/climate/normals [get]
+func (cc *ClimateController) GetNormals(c *gin.Context) {
+	if cc.archive == nil {
+		respondWithError(c, http.StatusServiceUnavailable, "存档未启用", "服务未配置观测存档数据库")
+		return
+	}
+
+	q, units, err := parseLocationQuery(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+
+	date := time.Now().UTC()
+	if raw := c.Query("date"); raw != "" {
+		if date, err = time.Parse("2006-01-02", raw); err != nil {
+			respondWithError(c, http.StatusBadRequest, "参数验证失败", "日期格式必须是 YYYY-MM-DD")
+			return
+		}
+	}
+
+	normals, err := cc.archive.Normals(q, date, units)
+	switch {
+	case errors.Is(err, archive.ErrUnknownLocation):
+		respondWithError(c, http.StatusNotFound, "没有历史记录", err.Error())
+	case err != nil:
+		respondWithError(c, http.StatusInternalServerError, "计算常年统计失败", err.Error())
+	default:
+		respondWithSuccess(c, normals)
+	}
+}
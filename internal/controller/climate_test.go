@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"gin-weather/internal/archive"
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClimateController_GetNormals(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.ArchiveConfig{Path: filepath.Join(t.TempDir(), "weather.db"), PollInterval: 3600, MaxPoints: 100, NormalsWindow: 15}
+	a, err := archive.New(cfg)
+	if err != nil {
+		t.Fatalf("创建存档失败: %v", err)
+	}
+	weatherService := a.Wrap(&MockWeatherService{})
+	a.Start()
+	weatherService.GetWeatherByCity("Beijing", "metric", "zh_cn")
+	a.Stop(context.Background())
+	if a, err = archive.New(cfg); err != nil {
+		t.Fatalf("重新打开存档失败: %v", err)
+	}
+	defer a.Stop(context.Background())
+
+	router := gin.New()
+	router.GET("/climate/normals", NewClimateController(a).GetNormals)
+	weather := NewWeatherController(weatherService, a)
+	router.GET("/weather", weather.GetWeather)
+	do := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 只有今天的观测，今天的常年统计没有数据，但位置存在
+	w := do("/climate/normals?city=Beijing")
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var normals struct {
+		Data model.ClimateNormals `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &normals)
+	if normals.Data.Location.Name != "Beijing" || normals.Data.Temperature != nil || normals.Data.WindowDays != 15 {
+		t.Errorf("返回结果不正确: %+v", normals.Data)
+	}
+
+	if w := do("/climate/normals?city=Shanghai"); w.Code != http.StatusNotFound {
+		t.Errorf("期望未知位置返回 404，实际为 %d", w.Code)
+	}
+	if w := do("/climate/normals?city=Beijing&date=01-05"); w.Code != http.StatusBadRequest {
+		t.Errorf("期望日期格式错误返回 400，实际为 %d", w.Code)
+	}
+
+	w = do("/weather?city=Beijing&include=anomaly")
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data model.WeatherResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.Anomaly == nil || response.Data.Anomaly.WindowDays != 15 {
+		t.Errorf("期望返回 anomaly 数据块，实际为 %+v", response.Data.Anomaly)
+	}
+}
+
+func TestClimateController_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/climate/normals", NewClimateController(nil).GetNormals)
+	router.GET("/weather", NewWeatherController(&MockWeatherService{}, nil).GetWeather)
+
+	for path, code := range map[string]int{
+		"/climate/normals?city=Beijing":         http.StatusServiceUnavailable,
+		"/weather?city=Beijing&include=anomaly": http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != code {
+			t.Errorf("%s: 期望状态码 %d，实际为 %d", path, code, w.Code)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-weather/internal/digest"
+	"gin-weather/internal/model"
+	"gin-weather/internal/resource"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DigestController 定时天气简报控制器
+type DigestController struct {
+	manager *digest.Manager
+}
+
+// NewDigestController 创建简报控制器实例
+func NewDigestController(manager *digest.Manager) *DigestController {
+	return &DigestController{
+		manager: manager,
+	}
+}
+
+// CreateDigest 创建简报订阅
+// @Summary 创建简报订阅
+// @Description 按 cron 表达式在指定时区内定时汇总多个位置的当前天气、今天的最高最低温度和降水风险，发送到通知渠道或 url；secret 只在创建时返回
+// @Tags digests
+// @Accept json
+// @Produce json
+// @Param subscription body model.DigestSubscription true "简报订阅"
+// @Success 201 {object} model.APIResponse{data=model.DigestSubscription}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/digests [post]
+func (dc *DigestController) CreateDigest(c *gin.Context) {
+	var sub model.DigestSubscription
+	if err := c.ShouldBindJSON(&sub); err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+
+	created, err := dc.manager.Create(&sub)
+	if err != nil {
+		var validationErr *resource.ValidationError
+		if errors.As(err, &validationErr) {
+			respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+			return
+		}
+		respondWithError(c, http.StatusInternalServerError, "创建简报订阅失败", err.Error())
+		return
+	}
+
+	c.Header("Location", "/api/v1/digests/"+created.ID)
+	respondWithStatus(c, http.StatusCreated, created)
+}
+
+// ListDigests 列出全部简报订阅
+// @Summary 列出简报订阅
+// @Description 按创建时间返回全部订阅及其下一次发送时间，不包含 secret
+// @Tags digests
+// @Produce json
+// @Success 200 {object} model.APIResponse{data=[]model.DigestSubscription}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/digests [get]
+func (dc *DigestController) ListDigests(c *gin.Context) {
+	list, err := dc.manager.List()
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "读取简报订阅失败", err.Error())
+		return
+	}
+	respondWithSuccess(c, list)
+}
+
+// GetDigest 查询单个简报订阅
+// @Summary 查询简报订阅
+// @Tags digests
+// @Produce json
+// @Param id path string true "订阅 ID"
+// @Success 200 {object} model.APIResponse{data=model.DigestSubscription}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/digests/{id} [get]
+func (dc *DigestController) GetDigest(c *gin.Context) {
+	sub, err := dc.manager.Get(c.Param("id"))
+	if err != nil {
+		dc.respondWithLookupError(c, err)
+		return
+	}
+	respondWithSuccess(c, sub)
+}
+
+// DeleteDigest 删除简报订阅
+// @Summary 删除简报订阅
+// @Tags digests
+// @Param id path string true "订阅 ID"
+// @Success 204
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/digests/{id} [delete]
+func (dc *DigestController) DeleteDigest(c *gin.Context) {
+	if err := dc.manager.Delete(c.Param("id")); err != nil {
+		dc.respondWithLookupError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PreviewDigest 预览简报内容
+// @Summary 预览简报
+// @Description 立即查询订阅中各位置的天气并返回简报内容，不会发送
+// @Tags digests
+// @Produce json
+// @Param id path string true "订阅 ID"
+// @Success 200 {object} model.APIResponse{data=model.Digest}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/digests/{id}/preview [get]
+func (dc *DigestController) PreviewDigest(c *gin.Context) {
+	preview, err := dc.manager.Preview(c.Param("id"))
+	if err != nil {
+		dc.respondWithLookupError(c, err)
+		return
+	}
+	respondWithSuccess(c, preview)
+}
+
+// respondWithLookupError 订阅不存在时返回 404，其他错误返回 500
+func (dc *DigestController) respondWithLookupError(c *gin.Context, err error) {
+	if errors.Is(err, digest.ErrNotFound) {
+		respondWithError(c, http.StatusNotFound, "简报订阅不存在", err.Error())
+		return
+	}
+	respondWithError(c, http.StatusInternalServerError, "读取简报订阅失败", err.Error())
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/digest"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDigestController_CRUD(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager, err := digest.NewManager(&config.DigestsConfig{Dir: t.TempDir(), CheckInterval: 60, Timeout: 5, MaxLocations: 5}, &MockWeatherService{}, nil)
+	if err != nil {
+		t.Fatalf("创建简报管理器失败: %v", err)
+	}
+	controller := NewDigestController(manager)
+
+	router := gin.New()
+	router.POST("/digests", controller.CreateDigest)
+	router.GET("/digests", controller.ListDigests)
+	router.GET("/digests/:id", controller.GetDigest)
+	router.DELETE("/digests/:id", controller.DeleteDigest)
+	router.GET("/digests/:id/preview", controller.PreviewDigest)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/digests", `{"schedule":"0 7 * *","timezone":"Asia/Shanghai","locations":[{"city":"Beijing"}],"url":"https://example.com/hook"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("期望无效的 cron 表达式返回 400，实际为 %d", w.Code)
+	}
+
+	w = do("POST", "/digests", `{"schedule":"0 7 * * 1-5","timezone":"Asia/Shanghai","locations":[{"city":"Beijing"},{"lat":31.23,"lon":121.47}],"url":"https://example.com/hook"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("期望状态码 201，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Data model.DigestSubscription `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created.Data.ID == "" || created.Data.Secret == "" || created.Data.NextRunAt == nil {
+		t.Fatalf("期望返回订阅 ID、密钥和下一次发送时间，实际为 %+v", created.Data)
+	}
+	if w.Header().Get("Location") != "/api/v1/digests/"+created.Data.ID {
+		t.Errorf("Location 头不正确: %s", w.Header().Get("Location"))
+	}
+
+	w = do("GET", "/digests", "")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), created.Data.Secret) {
+		t.Errorf("期望列表返回 200 且不包含密钥，实际为 %d", w.Code)
+	}
+
+	w = do("GET", "/digests/"+created.Data.ID+"/preview", "")
+	var preview struct {
+		Data model.Digest `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &preview)
+	if w.Code != http.StatusOK || len(preview.Data.Items) != 2 || preview.Data.Items[0].Name != "Beijing" {
+		t.Errorf("预览结果不正确: %d %s", w.Code, w.Body.String())
+	}
+	// 模拟服务不支持预报，降水风险未知
+	if preview.Data.Items[0].Forecast || preview.Data.Items[0].PrecipRisk != model.PrecipRiskUnknown {
+		t.Errorf("期望没有预报时降水风险为 unknown，实际为 %+v", preview.Data.Items[0])
+	}
+
+	if w = do("DELETE", "/digests/"+created.Data.ID, ""); w.Code != http.StatusNoContent {
+		t.Errorf("期望删除返回 204，实际为 %d", w.Code)
+	}
+	if w = do("GET", "/digests/"+created.Data.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("期望删除后返回 404，实际为 %d", w.Code)
+	}
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gin-weather/internal/geojson"
+	"gin-weather/internal/grid"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultGridStep 未指定 step 时的网格间距（度）
+	defaultGridStep = 0.5
+	// defaultGridField 未指定 field 时作为数值的字段
+	defaultGridField = "temperature"
+)
+
+// GridController 网格天气采样控制器
+type GridController struct {
+	grid *grid.Service
+}
+
+// NewGridController 创建网格采样控制器实例
+func NewGridController(grid *grid.Service) *GridController {
+	return &GridController{
+		grid: grid,
+	}
+}
+
+// GetGrid 按规则网格采样经纬度范围内的天气
+// @Summary 按规则网格采样天气
+// @Description 在经纬度范围内按 step 度的间距生成网格点并查询每个点的天气，用于绘制区域热力图。网格点对齐到经纬度为 step 整数倍的位置
+// @Tags weather
+// @Produce json,application/geo+json
+// @Param bbox query string true "经纬度范围：最小经度,最小纬度,最大经度,最大纬度"
+// @Param step query number false "网格间距（度），不小于 0.01" default(0.5)
+// @Param field query string false "作为数值的字段" Enums(temperature, feels_like, humidity, pressure, visibility, uv_index, wind_speed, clouds, rain_1h, snow_1h) default(temperature)
+// @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
+// @Param lang query string false "语言" default(zh_cn)
+// @Param format query string false "响应格式，geojson 返回每个网格点一个要素的 FeatureCollection" Enums(json, geojson)
+// @Success 200 {object} model.APIResponse{data=model.GridResponse}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/weather/grid [get]
+func (gc *GridController) GetGrid(c *gin.Context) {
+	// 网格的规模由点数上限限制，不限制经纬度跨度
+	bbox, err := parseBBox(c.Query("bbox"), 360)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	step := defaultGridStep
+	if value := c.Query("step"); value != "" {
+		step, err = strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(step) || math.IsInf(step, 0) || step < grid.MinStep {
+			respondWithError(c, http.StatusBadRequest, "参数错误", fmt.Sprintf("step 必须是不小于 %g 的有限数字", grid.MinStep))
+			return
+		}
+	}
+
+	field := c.DefaultQuery("field", defaultGridField)
+	value, ok := grid.Fields[field]
+	if !ok {
+		respondWithError(c, http.StatusBadRequest, "参数错误", "field 必须是 "+strings.Join(grid.FieldNames(), "、")+" 之一")
+		return
+	}
+
+	units, lang, ok := parseUnitsAndLang(c)
+	if !ok {
+		return
+	}
+
+	sample, err := gc.grid.Sample(bbox, step, units, lang)
+	switch {
+	case errors.Is(err, grid.ErrInvalidGrid):
+		respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	case err != nil:
+		respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
+		return
+	}
+
+	if wantsGeoJSON(c) {
+		respondWithGeoJSON(c, gridFeatures(sample))
+		return
+	}
+
+	resp := &model.GridResponse{
+		BBox:   bbox,
+		Step:   step,
+		Field:  field,
+		Units:  units,
+		Lats:   sample.Lats,
+		Lons:   sample.Lons,
+		Values: make([][]*float64, len(sample.Lats)),
+		Points: [][3]float64{},
+		Min:    math.Inf(1),
+		Max:    math.Inf(-1),
+		Failed: sample.Failed,
+	}
+	for i, lat := range sample.Lats {
+		resp.Values[i] = make([]*float64, len(sample.Lons))
+		for j, lon := range sample.Lons {
+			weather := sample.Weather[i][j]
+			if weather == nil {
+				continue
+			}
+			v := value(&weather.Current)
+			if math.IsNaN(v) {
+				continue
+			}
+			resp.Values[i][j] = &v
+			resp.Points = append(resp.Points, [3]float64{lat, lon, v})
+			resp.Min = math.Min(resp.Min, v)
+			resp.Max = math.Max(resp.Max, v)
+		}
+	}
+	// 所有网格点都没有该数据（如实况不含紫外线指数）时没有色阶范围
+	if len(resp.Points) == 0 {
+		resp.Min, resp.Max = 0, 0
+	}
+	respondWithSuccess(c, resp)
+}
+
+// gridFeatures 把网格采样结果转换为要素集合，每个获取成功的网格点一个要素，几何为网格点而不是天气数据中的位置
+func gridFeatures(sample *grid.Sample) model.FeatureCollection {
+	var items []model.WeatherResponse
+	for i, lat := range sample.Lats {
+		for j, lon := range sample.Lons {
+			if sample.Weather[i][j] == nil {
+				continue
+			}
+			// 缓存的结果在请求间共享，复制后再修改坐标
+			item := *sample.Weather[i][j]
+			item.Location.Latitude, item.Location.Longitude = lat, lon
+			items = append(items, item)
+		}
+	}
+	return geojson.Collection(items)
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/grid"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGridController_GetGrid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := grid.New(&config.GridConfig{MaxPoints: 20, Concurrency: 2, TTL: 60}, &MockWeatherService{})
+	router := gin.New()
+	router.GET("/weather/grid", NewGridController(service).GetGrid)
+	do := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/weather/grid?bbox=116,39,117,40&field=humidity")
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data model.GridResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	data := response.Data
+	if len(data.Lats) != 3 || len(data.Lons) != 3 || len(data.Values) != 3 || len(data.Points) != 9 || data.Field != "humidity" {
+		t.Fatalf("期望默认间距 0.5 生成 3x3 的网格，实际为 %+v", data)
+	}
+	if v := data.Values[2][1]; v == nil || *v != 60 || data.Points[7] != [3]float64{40, 116.5, 60} || data.Min != 60 || data.Max != 60 {
+		t.Errorf("网格数值不正确: %+v", data)
+	}
+
+	// 模拟服务的实况没有紫外线指数，网格点的数值为 null
+	w = do("/weather/grid?bbox=116,39,117,40&step=1&field=uv_index")
+	response.Data = model.GridResponse{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if w.Code != http.StatusOK || len(response.Data.Points) != 0 || response.Data.Values[0][0] != nil {
+		t.Errorf("期望没有紫外线数据时数值为 null，实际为 %d: %s", w.Code, w.Body.String())
+	}
+
+	w = do("/weather/grid?bbox=116,39,117,40&step=1&format=geojson")
+	var collection model.FeatureCollection
+	json.Unmarshal(w.Body.Bytes(), &collection)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != model.GeoJSONMediaType || len(collection.Features) != 4 {
+		t.Fatalf("期望返回 4 个要素的 GeoJSON，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	// 要素的坐标为网格点，而不是天气数据中的位置
+	if got := collection.Features[3].Geometry.Coordinates; got[0] != 117 || got[1] != 40 {
+		t.Errorf("期望最后一个要素位于 (117, 40)，实际为 %v", got)
+	}
+
+	for _, query := range []string{
+		"",
+		"bbox=116,39,117",
+		"bbox=116,39,117,40&step=0",
+		"bbox=116,39,117,40&step=abc",
+		"bbox=116,39,117,40&step=Inf",
+		"bbox=116,39,117,40&step=NaN",
+		"bbox=116,39,117,40&step=0.1",
+		"bbox=116,39,117,40&field=color",
+		"bbox=116,39,117,40&units=kelvin",
+	} {
+		if w := do("/weather/grid?" + query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: 期望状态码 400，实际为 %d", query, w.Code)
+		}
+	}
+}
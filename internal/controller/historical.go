@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gin-weather/internal/geojson"
+	"gin-weather/internal/historical"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HistoricalController 历史天气控制器
+type HistoricalController struct {
+	historical *historical.Service
+}
+
+// NewHistoricalController 创建历史天气控制器实例
+func NewHistoricalController(historical *historical.Service) *HistoricalController {
+	return &HistoricalController{
+		historical: historical,
+	}
+}
+
+// GetHistorical 查询过去某一天或一段日期的天气
+// @Summary 查询历史天气
+// @Description 查询过去某一天（date）或一段日期（from 到 to）每天当地正午前后的天气，结果会被永久缓存
+// @Tags weather
+// @Produce json,application/geo+json
+// @Param city query string false "城市名称（与坐标二选一）"
+// @Param lat query number false "纬度（需要与经度一起使用）"
+// @Param lon query number false "经度（需要与纬度一起使用）"
+// @Param date query string false "日期（YYYY-MM-DD），与 from 二选一"
+// @Param from query string false "起始日期（YYYY-MM-DD，含）"
+// @Param to query string false "结束日期（YYYY-MM-DD，含），默认与 from 相同"
+// @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
+// @Param lang query string false "语言" default(zh_cn)
+// @Param format query string false "响应格式，geojson 返回每天一个要素的 FeatureCollection" Enums(json, geojson)
+// @Success 200 {object} model.APIResponse{data=model.HistoricalResponse}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/weather/historical [get]
+func (hc *HistoricalController) GetHistorical(c *gin.Context) {
+	req, ok := bindWeatherRequest(c)
+	if !ok {
+		return
+	}
+
+	from, to, err := parseDateRange(c.Query("date"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+
+	response, err := hc.historical.Lookup(req, from, to)
+	switch {
+	case errors.Is(err, historical.ErrInvalidRange):
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+	case err != nil:
+		respondWithError(c, http.StatusInternalServerError, "获取历史天气失败", err.Error())
+	case wantsGeoJSON(c):
+		respondWithGeoJSON(c, geojson.Historical(response))
+	default:
+		respondWithSuccess(c, response)
+	}
+}
+
+// parseDateRange 解析 date 或 from/to 日期参数，to 为空时与 from 相同
+func parseDateRange(date, from, to string) (time.Time, time.Time, error) {
+	if date != "" {
+		if from != "" || to != "" {
+			return time.Time{}, time.Time{}, errors.New("date 不能与 from、to 同时使用")
+		}
+		from, to = date, date
+	}
+	if from == "" {
+		return time.Time{}, time.Time{}, errors.New("必须提供 date 或 from 参数")
+	}
+	if to == "" {
+		to = from
+	}
+
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("日期格式必须是 YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("日期格式必须是 YYYY-MM-DD")
+	}
+	return start, end, nil
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/historical"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHistoricalController_GetHistorical(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service, err := historical.New(&config.HistoricalConfig{MaxDays: 7, Concurrency: 2}, &MockWeatherService{})
+	if err != nil {
+		t.Fatalf("创建历史天气服务失败: %v", err)
+	}
+	router := gin.New()
+	router.GET("/weather/historical", NewHistoricalController(service).GetHistorical)
+	do := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/weather/historical?city=Beijing&date=2024-03-01")
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data model.HistoricalResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Data.Days) != 1 || response.Data.Days[0].Date != "2024-03-01" || response.Data.Location.Name != "Beijing" || response.Data.Units != "metric" {
+		t.Fatalf("返回结果不正确: %+v", response.Data)
+	}
+
+	w = do("/weather/historical?lat=39.9&lon=116.4&from=2024-03-01&to=2024-03-03")
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if w.Code != http.StatusOK || len(response.Data.Days) != 3 {
+		t.Errorf("期望返回 3 天的数据，实际为 %d: %s", w.Code, w.Body.String())
+	}
+
+	w = do("/weather/historical?city=Beijing&from=2024-03-01&to=2024-03-02&format=geojson")
+	var collection model.FeatureCollection
+	json.Unmarshal(w.Body.Bytes(), &collection)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != model.GeoJSONMediaType || len(collection.Features) != 2 {
+		t.Fatalf("期望返回 2 个要素的 GeoJSON，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	if got := collection.Features[1].Properties.UpdatedAt; !got.Equal(time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("期望第二个要素为 3 月 2 日的观测，实际为 %v", got)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"缺少位置", "/weather/historical?date=2024-03-01"},
+		{"缺少日期", "/weather/historical?city=Beijing"},
+		{"日期格式错误", "/weather/historical?city=Beijing&date=2024/03/01"},
+		{"同时使用 date 和 from", "/weather/historical?city=Beijing&date=2024-03-01&from=2024-03-01"},
+		{"超过最大天数", "/weather/historical?city=Beijing&from=2024-03-01&to=2024-03-31"},
+		{"未来日期", "/weather/historical?city=Beijing&date=2999-01-01"},
+	}
+	for _, tt := range tests {
+		if w := do(tt.path); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: 期望状态码 400，实际为 %d", tt.name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-weather/internal/archive"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultHistoryRange 未指定 from 时默认查询的时间范围
+const defaultHistoryRange = 24 * time.Hour
+
+// HistoryController 历史观测控制器
+type HistoryController struct {
+	archive *archive.Archive
+}
+
+// NewHistoryController 创建历史观测控制器实例，archive 为 nil 表示未启用存档
+func NewHistoryController(archive *archive.Archive) *HistoryController {
+	return &HistoryController{
+		archive: archive,
+	}
+}
+
+// GetHistory 查询历史观测
+// @Summary 查询历史观测
+// @Description 从本地存档查询服务获取过的天气观测，可以按 interval 在服务端降采样，每个时间段返回各字段的平均、最小和最大值
+// @Tags history
+// @Produce json
+// @Param city query string false "城市名称（与坐标二选一）"
+// @Param lat query number false "纬度"
+// @Param lon query number false "经度"
+// @Param from query string false "起始时间（含），RFC3339、YYYY-MM-DD（UTC）或 Unix 秒，默认为 to 之前 24 小时"
+// @Param to query string false "结束时间（不含），格式同 from，默认为当前时间"
+// @Param interval query string false "降采样间隔，如 15m、1h、1d，不填或为 raw 时返回原始观测"
+// @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
+// @Success 200 {object} model.APIResponse{data=model.HistoryResponse}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 503 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/history [get]
+func (hc *HistoryController) GetHistory(c *gin.Context) {
+	if hc.archive == nil {
+		respondWithError(c, http.StatusServiceUnavailable, "存档未启用", "服务未配置观测存档数据库")
+		return
+	}
+
+	q, units, err := parseHistoryQuery(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+
+	history, err := hc.archive.History(q, units)
+	switch {
+	case errors.Is(err, archive.ErrUnknownLocation):
+		respondWithError(c, http.StatusNotFound, "没有历史记录", err.Error())
+	case errors.Is(err, archive.ErrTooManyPoints):
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+	case err != nil:
+		respondWithError(c, http.StatusInternalServerError, "查询历史观测失败", err.Error())
+	default:
+		respondWithSuccess(c, history)
+	}
+}
+
+// parseHistoryQuery 解析历史查询参数
+func parseHistoryQuery(c *gin.Context) (*archive.Query, string, error) {
+	q, units, err := parseLocationQuery(c)
+	if err != nil {
+		return nil, "", err
+	}
+
+	q.To = time.Now()
+	if raw := c.Query("to"); raw != "" {
+		if q.To, err = parseTimeParam(raw); err != nil {
+			return nil, "", fmt.Errorf("to 参数无效: %w", err)
+		}
+	}
+	q.From = q.To.Add(-defaultHistoryRange)
+	if raw := c.Query("from"); raw != "" {
+		if q.From, err = parseTimeParam(raw); err != nil {
+			return nil, "", fmt.Errorf("from 参数无效: %w", err)
+		}
+	}
+	if !q.From.Before(q.To) {
+		return nil, "", errors.New("from 必须早于 to")
+	}
+
+	if q.Interval, err = parseInterval(c.Query("interval")); err != nil {
+		return nil, "", err
+	}
+	return q, units, nil
+}
+
+// parseLocationQuery 解析存档查询的城市或坐标以及单位系统参数
+func parseLocationQuery(c *gin.Context) (*archive.Query, string, error) {
+	q := &archive.Query{City: strings.TrimSpace(c.Query("city"))}
+	if q.City == "" {
+		lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+		lon, lonErr := strconv.ParseFloat(c.Query("lon"), 64)
+		if latErr != nil || lonErr != nil {
+			return nil, "", errors.New("必须提供城市名称或坐标信息")
+		}
+		req := model.WeatherRequest{Lat: lat, Lon: lon}
+		if err := req.Validate(); err != nil {
+			return nil, "", err
+		}
+		q.Lat, q.Lon = lat, lon
+	}
+
+	units := c.DefaultQuery("units", "metric")
+	if !model.ValidUnits(units) {
+		return nil, "", errors.New("单位系统必须是 metric、imperial 或 standard")
+	}
+	return q, units, nil
+}
+
+// parseTimeParam 解析 RFC3339 时间、YYYY-MM-DD 日期（UTC）或 Unix 秒
+func parseTimeParam(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, nil
+	}
+	if sec, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Time{}, errors.New("时间格式必须是 RFC3339、YYYY-MM-DD 或 Unix 秒")
+}
+
+// parseInterval 解析降采样间隔，支持 Go 时长格式以及以 d 结尾的天数；空值或 raw 表示不降采样
+func parseInterval(raw string) (time.Duration, error) {
+	if raw == "" || raw == "raw" {
+		return 0, nil
+	}
+
+	var interval time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("interval 参数无效: %s", raw)
+		}
+		interval = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if interval, err = time.ParseDuration(raw); err != nil {
+			return 0, fmt.Errorf("interval 参数无效: %s", raw)
+		}
+	}
+
+	if interval < time.Minute || interval%time.Second != 0 {
+		return 0, errors.New("interval 不能小于 1 分钟，且必须是整秒")
+	}
+	return interval, nil
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"gin-weather/internal/archive"
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHistoryController_GetHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	path := filepath.Join(t.TempDir(), "weather.db")
+	cfg := &config.ArchiveConfig{Path: path, PollInterval: 3600, MaxPoints: 100}
+	a, err := archive.New(cfg)
+	if err != nil {
+		t.Fatalf("创建存档失败: %v", err)
+	}
+	weatherService := a.Wrap(&MockWeatherService{})
+	a.Start()
+	if _, err := weatherService.GetWeatherByCity("Beijing", "metric", "zh_cn"); err != nil {
+		t.Fatalf("获取天气失败: %v", err)
+	}
+	// 停止时写完队列中的观测，再重新打开用于查询
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("停止存档失败: %v", err)
+	}
+	if a, err = archive.New(cfg); err != nil {
+		t.Fatalf("重新打开存档失败: %v", err)
+	}
+	defer a.Stop(context.Background())
+
+	router := gin.New()
+	router.GET("/history", NewHistoryController(a).GetHistory)
+	do := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/history?city=beijing&units=imperial")
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data model.HistoryResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Data.Points) != 1 || response.Data.Interval != "raw" || response.Data.Location.Name != "Beijing" {
+		t.Fatalf("返回结果不正确: %+v", response.Data)
+	}
+	if temp := response.Data.Points[0].Temperature.Avg; temp != 77.9 {
+		t.Errorf("期望温度换算为 77.9°F，实际为 %v", temp)
+	}
+
+	w = do("/history?lat=39.9&lon=116.4&interval=1h")
+	if w.Code != http.StatusOK {
+		t.Errorf("期望按坐标查询返回 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"缺少位置", "/history", http.StatusBadRequest},
+		{"无效的单位", "/history?city=Beijing&units=kelvin", http.StatusBadRequest},
+		{"无效的时间", "/history?city=Beijing&from=yesterday", http.StatusBadRequest},
+		{"起止时间颠倒", "/history?city=Beijing&from=2024-03-05&to=2024-03-04", http.StatusBadRequest},
+		{"间隔过小", "/history?city=Beijing&interval=30s", http.StatusBadRequest},
+		{"数据点过多", "/history?city=Beijing&from=2024-01-01&to=2024-03-01&interval=1m", http.StatusBadRequest},
+		{"未知位置", "/history?city=Shanghai", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := do(tt.path); w.Code != tt.code {
+			t.Errorf("%s: 期望状态码 %d，实际为 %d", tt.name, tt.code, w.Code)
+		}
+	}
+}
+
+func TestHistoryController_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/history", NewHistoryController(nil).GetHistory)
+	req, _ := http.NewRequest("GET", "/history?city=Beijing", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("期望未启用存档时返回 503，实际为 %d", w.Code)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		{"", "0s", true},
+		{"raw", "0s", true},
+		{"15m", "15m0s", true},
+		{"1d", "24h0m0s", true},
+		{"59s", "", false},
+		{"1.5m", "1m30s", true},
+		{"xd", "", false},
+	}
+	for _, tt := range tests {
+		got, err := parseInterval(tt.raw)
+		if (err == nil) != tt.ok || (tt.ok && got.String() != tt.want) {
+			t.Errorf("parseInterval(%q) = %v, %v", tt.raw, got, err)
+		}
+	}
+}
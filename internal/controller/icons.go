@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"gin-weather/internal/icons"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// versionedIconCacheControl 带有当前版本 v 参数的地址内容不会变化，允许客户端和 CDN 长期缓存
+	versionedIconCacheControl = "public, max-age=31536000, immutable"
+	// iconCacheControl 不带版本的地址在新版本中内容可能变化，每次使用前通过 ETag 验证
+	iconCacheControl = "public, no-cache"
+)
+
+// IconController 天气图标控制器
+type IconController struct{}
+
+// NewIconController 创建天气图标控制器实例
+func NewIconController() *IconController {
+	return &IconController{}
+}
+
+// GetIcon 获取天气图标
+// @Summary 获取天气图标
+// @Description 返回内嵌的 SVG 天气图标。代码可以是 OpenWeatherMap 图标代码（如 10n），也可以是中立的状况标识加 -day 或 -night（如 rain_light-night）
+// @Tags icons
+// @Produce image/svg+xml
+// @Param code path string true "图标代码，后面加 .svg"
+// @Param v query string false "图标版本，与当前版本一致时允许长期缓存，icon_url 中已包含"
+// @Success 200 {file} file
+// @Success 304 "图标未变化"
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/icons/{code}.svg [get]
+func (ic *IconController) GetIcon(c *gin.Context) {
+	file := c.Param("file")
+	code, ok := strings.CutSuffix(file, ".svg")
+	if !ok {
+		respondWithError(c, http.StatusNotFound, "图标不存在", "图标地址必须以 .svg 结尾")
+		return
+	}
+	icon, ok := icons.Lookup(code)
+	if !ok {
+		respondWithError(c, http.StatusNotFound, "图标不存在", "未知的图标代码: "+code)
+		return
+	}
+
+	if c.Query("v") == icon.Version {
+		c.Header("Cache-Control", versionedIconCacheControl)
+	} else {
+		c.Header("Cache-Control", iconCacheControl)
+	}
+	c.Header("ETag", icon.ETag)
+	if etagMatches(c.GetHeader("If-None-Match"), icon.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "image/svg+xml", icon.Data)
+}
+
+// etagMatches 判断 If-None-Match 请求头是否包含 etag，支持多个值、弱校验前缀和 *
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-weather/internal/icons"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIconController_GetIcon(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/icons/:file", NewIconController().GetIcon)
+
+	req, _ := http.NewRequest("GET", "/icons/10n.svg", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d", w.Code)
+	}
+	// 不带版本的地址在新版本中内容可能变化，不能标记为 immutable
+	if w.Header().Get("Content-Type") != "image/svg+xml" || w.Header().Get("Cache-Control") != iconCacheControl {
+		t.Errorf("响应头不正确: %v", w.Header())
+	}
+	if !strings.HasPrefix(w.Body.String(), "<svg") {
+		t.Errorf("期望返回 SVG，实际为 %s", w.Body.String())
+	}
+
+	// 同一个图标用中立的状况标识请求，内容和实体标签相同
+	etag := w.Header().Get("ETag")
+	req, _ = http.NewRequest("GET", "/icons/rain_showers-night.svg", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != etag {
+		t.Errorf("期望与 10n 使用同一个图标，实际状态码 %d，ETag %s", w.Code, w.Header().Get("ETag"))
+	}
+
+	req, _ = http.NewRequest("GET", "/icons/10n.svg", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("期望状态码 304，实际为 %d", w.Code)
+	}
+
+	// icon_url 带有当前版本，可以长期缓存；过期的版本按不带版本处理
+	url := icons.URL(model.NewCondition(model.ConditionRainShowers, true))
+	req, _ = http.NewRequest("GET", strings.TrimPrefix(url, "/api/v1"), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != versionedIconCacheControl || w.Header().Get("ETag") != etag {
+		t.Errorf("%s: 期望允许长期缓存，实际为 %d %v", url, w.Code, w.Header())
+	}
+	req, _ = http.NewRequest("GET", "/icons/10n.svg?v=0000", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Header().Get("Cache-Control") != iconCacheControl {
+		t.Errorf("期望过期的版本不能长期缓存，实际为 %s", w.Header().Get("Cache-Control"))
+	}
+
+	for _, path := range []string{"/icons/10n.png", "/icons/sunny.svg"} {
+		req, _ = http.NewRequest("GET", path, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: 期望状态码 404，实际为 %d", path, w.Code)
+		}
+	}
+}
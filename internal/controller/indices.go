@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"gin-weather/internal/indices"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IndicesController 生活指数控制器
+type IndicesController struct {
+	weatherService service.WeatherService
+	engine         *indices.Engine
+}
+
+// NewIndicesController 创建生活指数控制器实例
+func NewIndicesController(weatherService service.WeatherService, engine *indices.Engine) *IndicesController {
+	return &IndicesController{
+		weatherService: weatherService,
+		engine:         engine,
+	}
+}
+
+// GetIndices 获取生活指数
+// @Summary 获取生活指数
+// @Description 根据城市名称或坐标的当前天气和未来 12 小时的预报降水计算穿衣、紫外线、洗车、运动、感冒等生活指数，缺少紫外线数据时不返回紫外线指数
+// @Tags indices
+// @Accept json
+// @Produce json
+// @Param city query string false "城市名称（与坐标二选一）"
+// @Param lat query number false "纬度（需要与经度一起使用）"
+// @Param lon query number false "经度（需要与纬度一起使用）"
+// @Param lang query string false "语言" default(zh_cn)
+// @Success 200 {object} model.APIResponse{data=model.IndicesResponse}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/indices [get]
+func (ic *IndicesController) GetIndices(c *gin.Context) {
+	req, ok := bindWeatherRequest(c)
+	if !ok {
+		return
+	}
+
+	// 规则表的阈值均为公制单位
+	req.Units = "metric"
+
+	weatherResp, err := service.Fetch(ic.weatherService, req)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
+		return
+	}
+
+	// 预报只用于补充未来的降水，获取失败时退回到只使用当前天气
+	forecast, err := service.FetchForecast(ic.weatherService, req)
+	if err != nil && !errors.Is(err, service.ErrForecastUnsupported) {
+		log.Printf("生活指数获取天气预报失败 (%s): %v", req.Describe(), err)
+	}
+
+	in := &indices.Input{Current: &weatherResp.Current, Forecast: forecast, Now: time.Now()}
+	respondWithSuccess(c, model.IndicesResponse{
+		Location: weatherResp.Location,
+		Indices:  ic.engine.Evaluate(in, req.Lang),
+		Forecast: forecast != nil,
+		Provider: weatherResp.Provider,
+	})
+}
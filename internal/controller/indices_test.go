@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin-weather/internal/indices"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rainyForecastService 在 MockWeatherService 的基础上提供接下来几个小时很可能降水的预报
+type rainyForecastService struct {
+	MockWeatherService
+}
+
+func (s *rainyForecastService) GetForecastByCity(city, units, lang string) (*model.Forecast, error) {
+	now := time.Now()
+	return &model.Forecast{
+		Location: model.Location{Name: city},
+		Items: []model.ForecastItem{
+			{Time: now.Add(-time.Hour), PrecipProbability: 0.1},
+			{Time: now.Add(2 * time.Hour), PrecipProbability: 0.8, Rain: 3.2},
+		},
+		Timestamp: now.Unix(),
+		Provider:  "openweathermap",
+	}, nil
+}
+
+func (s *rainyForecastService) GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error) {
+	return s.GetForecastByCity("Test City", units, lang)
+}
+
+func TestIndicesController_GetIndices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine, err := indices.NewEngine("")
+	if err != nil {
+		t.Fatalf("加载内置规则失败: %v", err)
+	}
+
+	get := func(weatherService service.WeatherService, path string) (*httptest.ResponseRecorder, map[string]model.LifeIndex, bool) {
+		router := gin.New()
+		router.GET("/indices", NewIndicesController(weatherService, engine).GetIndices)
+
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Data model.IndicesResponse `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		levels := make(map[string]model.LifeIndex)
+		for _, index := range resp.Data.Indices {
+			levels[index.Key] = index
+		}
+		return w, levels, resp.Data.Forecast
+	}
+
+	if w, _, _ := get(&MockWeatherService{}, "/indices"); w.Code != http.StatusBadRequest {
+		t.Errorf("期望缺少位置时返回 400，实际为 %d", w.Code)
+	}
+
+	// 不支持预报的服务只使用当前天气；实况没有紫外线数据，不返回紫外线指数
+	w, levels, forecast := get(&MockWeatherService{}, "/indices?city=Beijing")
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	if forecast {
+		t.Error("期望不支持预报时 forecast 为 false")
+	}
+	if _, ok := levels["uv"]; ok {
+		t.Errorf("期望缺少紫外线数据时不返回紫外线指数，实际为 %+v", levels["uv"])
+	}
+	if levels["car_wash"].Label != "适宜" || levels["clothing"].Name != "穿衣指数" {
+		t.Errorf("生活指数不正确: %s", w.Body.String())
+	}
+
+	// 预报显示接下来很可能降水，洗车指数随之下调
+	w, levels, forecast = get(&rainyForecastService{}, "/indices?city=Beijing&lang=en")
+	if w.Code != http.StatusOK || !forecast {
+		t.Fatalf("期望使用预报并返回 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	if levels["car_wash"].Label != "Poor" {
+		t.Errorf("期望预报有降水时洗车指数为 Poor，实际为 %+v", levels["car_wash"])
+	}
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/jobs"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobController 异步批量任务控制器
+type JobController struct {
+	manager *jobs.Manager
+	config  *config.JobsConfig
+}
+
+// NewJobController 创建批量任务控制器实例
+func NewJobController(manager *jobs.Manager, cfg *config.JobsConfig) *JobController {
+	return &JobController{
+		manager: manager,
+		config:  cfg,
+	}
+}
+
+// CreateJob 创建批量任务
+// @Summary 创建批量任务
+// @Description 上传 CSV 或 NDJSON 格式的位置列表，由后台 worker 在上游配额内逐个查询天气
+// @Tags jobs
+// @Accept text/csv,application/x-ndjson,multipart/form-data
+// @Produce json
+// @Param file formData file false "位置列表文件（multipart 上传时使用）"
+// @Param format query string false "上传格式，默认根据 Content-Type 或文件扩展名判断" Enums(csv, ndjson)
+// @Param units query string false "默认单位系统" Enums(metric, imperial, standard) default(metric)
+// @Param lang query string false "默认语言" default(zh_cn)
+// @Success 202 {object} model.APIResponse{data=model.Job}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/jobs [post]
+func (jc *JobController) CreateJob(c *gin.Context) {
+	units := c.DefaultQuery("units", "metric")
+	lang := c.DefaultQuery("lang", "zh_cn")
+	if !model.ValidUnits(units) {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", "单位系统必须是 metric、imperial 或 standard")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(jc.config.MaxUploadMB)<<20)
+
+	body, format, err := uploadedFile(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+	defer body.Close()
+
+	items, err := jobs.ParseItems(body, format, jc.config.MaxItems)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "文件解析失败", err.Error())
+		return
+	}
+
+	job, err := jc.manager.Submit(items, units, lang)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "创建任务失败", err.Error())
+		return
+	}
+
+	c.Header("Location", "/api/v1/jobs/"+job.ID)
+	respondWithStatus(c, http.StatusAccepted, job)
+}
+
+// GetJob 查询批量任务进度
+// @Summary 查询批量任务进度
+// @Description 返回任务状态以及已处理、成功和失败的数量
+// @Tags jobs
+// @Produce json
+// @Param id path string true "任务 ID"
+// @Success 200 {object} model.APIResponse{data=model.Job}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/jobs/{id} [get]
+func (jc *JobController) GetJob(c *gin.Context) {
+	job, err := jc.manager.Get(c.Param("id"))
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "任务不存在", err.Error())
+		return
+	}
+	respondWithSuccess(c, job)
+}
+
+// GetJobResult 获取批量任务结果
+// @Summary 获取批量任务结果
+// @Description 以 NDJSON 或 CSV 流式返回任务结果，任务未完成时只包含已处理的部分，任务状态通过 X-Job-Status 响应头返回
+// @Tags jobs
+// @Produce application/x-ndjson,text/csv
+// @Param id path string true "任务 ID"
+// @Param format query string false "结果格式" Enums(ndjson, csv) default(ndjson)
+// @Success 200 {string} string "任务结果"
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/jobs/{id}/result [get]
+func (jc *JobController) GetJobResult(c *gin.Context) {
+	id := c.Param("id")
+	job, err := jc.manager.Get(id)
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "任务不存在", err.Error())
+		return
+	}
+
+	format := c.DefaultQuery("format", jobs.FormatNDJSON)
+	switch format {
+	case jobs.FormatNDJSON:
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	case jobs.FormatCSV:
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="`+id+`.csv"`)
+	default:
+		respondWithError(c, http.StatusBadRequest, "参数错误", "结果格式必须是 ndjson 或 csv")
+		return
+	}
+
+	c.Header("X-Job-Status", job.Status)
+	c.Status(http.StatusOK)
+	if err := jc.manager.WriteResults(id, format, c.Writer); err != nil && !errors.Is(err, jobs.ErrNotFound) {
+		// 响应头已经发出，只能中断连接
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+// uploadedFile 从 multipart 表单或请求体中取出上传内容，并判断其格式
+func uploadedFile(c *gin.Context) (io.ReadCloser, string, error) {
+	format := c.Query("format")
+	contentType := c.ContentType()
+
+	if contentType == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("缺少上传文件 file")
+		}
+		if format == "" {
+			format = formatFromName(header.Filename)
+		}
+		f, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		return checkFormat(f, format)
+	}
+
+	if format == "" {
+		switch contentType {
+		case "text/csv":
+			format = jobs.FormatCSV
+		case "application/x-ndjson", "application/ndjson", "application/jsonl":
+			format = jobs.FormatNDJSON
+		}
+	}
+	return checkFormat(c.Request.Body, format)
+}
+
+// checkFormat 校验上传格式
+func checkFormat(body io.ReadCloser, format string) (io.ReadCloser, string, error) {
+	if format != jobs.FormatCSV && format != jobs.FormatNDJSON {
+		body.Close()
+		return nil, "", errors.New("无法确定上传格式，请通过 format 参数指定 csv 或 ndjson")
+	}
+	return body, format, nil
+}
+
+// formatFromName 根据文件扩展名判断格式
+func formatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return jobs.FormatCSV
+	case ".ndjson", ".jsonl":
+		return jobs.FormatNDJSON
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/jobs"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestJobController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.JobsConfig{Dir: t.TempDir(), Workers: 1, RatePerMinute: 60000, MaxItems: 10, MaxUploadMB: 1}
+	manager, err := jobs.NewManager(cfg, &MockWeatherService{})
+	if err != nil {
+		t.Fatalf("创建任务管理器失败: %v", err)
+	}
+	defer manager.Stop(context.Background())
+	controller := NewJobController(manager, cfg)
+
+	router := gin.New()
+	router.POST("/jobs", controller.CreateJob)
+	router.GET("/jobs/:id", controller.GetJob)
+	router.GET("/jobs/:id/result", controller.GetJobResult)
+	do := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for name, w := range map[string]*httptest.ResponseRecorder{
+		"无法判断格式": do("POST", "/jobs", "text/plain", "Beijing"),
+		"单位无效":   do("POST", "/jobs?units=kelvin", "text/csv", "city\nBeijing\n"),
+		"没有位置":   do("POST", "/jobs", "text/csv", "city\n"),
+	} {
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: 期望状态码 400，实际为 %d", name, w.Code)
+		}
+	}
+
+	// worker 尚未启动，任务停留在排队中
+	w := do("POST", "/jobs", "application/x-ndjson", `{"ref":"S1","city":"Beijing"}`+"\n"+`{"ref":"S2","lat":31.23,"lon":121.47}`+"\n")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("期望状态码 202，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Data model.Job `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	id := created.Data.ID
+	if id == "" || created.Data.Status != model.JobStatusQueued || created.Data.Total != 2 {
+		t.Fatalf("创建的任务不正确: %+v", created.Data)
+	}
+	if w.Header().Get("Location") != "/api/v1/jobs/"+id {
+		t.Errorf("Location 头不正确: %s", w.Header().Get("Location"))
+	}
+
+	// 未完成的任务可以查询结果，只包含已处理的部分，状态通过响应头返回
+	w = do("GET", "/jobs/"+id+"/result", "", "")
+	if w.Code != http.StatusOK || w.Header().Get("X-Job-Status") != model.JobStatusQueued || w.Body.Len() != 0 {
+		t.Errorf("期望未完成的任务返回空结果和 queued 状态，实际为 %d %q: %s", w.Code, w.Header().Get("X-Job-Status"), w.Body.String())
+	}
+	if w = do("GET", "/jobs/"+id+"/result?format=xml", "", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("期望无效的结果格式返回 400，实际为 %d", w.Code)
+	}
+	if w = do("GET", "/jobs/missing", "", ""); w.Code != http.StatusNotFound {
+		t.Errorf("期望不存在的任务返回 404，实际为 %d", w.Code)
+	}
+	if w = do("GET", "/jobs/missing/result", "", ""); w.Code != http.StatusNotFound {
+		t.Errorf("期望不存在的任务结果返回 404，实际为 %d", w.Code)
+	}
+
+	manager.Start()
+	var job model.Job
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != model.JobStatusCompleted && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		var resp struct {
+			Data model.Job `json:"data"`
+		}
+		json.Unmarshal(do("GET", "/jobs/"+id, "", "").Body.Bytes(), &resp)
+		job = resp.Data
+	}
+	if job.Status != model.JobStatusCompleted || job.Succeeded != 2 || job.Progress != 100 {
+		t.Fatalf("期望任务完成，实际为 %+v", job)
+	}
+
+	w = do("GET", "/jobs/"+id+"/result", "", "")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if w.Header().Get("X-Job-Status") != model.JobStatusCompleted || len(lines) != 2 || !strings.Contains(lines[0], `"ref":"S1"`) {
+		t.Errorf("NDJSON 结果不正确: %s", w.Body.String())
+	}
+
+	w = do("GET", "/jobs/"+id+"/result?format=csv", "", "")
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") || !strings.HasPrefix(w.Body.String(), "index,ref,success") {
+		t.Errorf("CSV 结果不正确: %s %s", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/notify"
+	"gin-weather/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotifyController 通知渠道控制器
+type NotifyController struct {
+	weatherService service.WeatherService
+	notifier       *notify.Notifier
+}
+
+// NewNotifyController 创建通知渠道控制器实例
+func NewNotifyController(weatherService service.WeatherService, notifier *notify.Notifier) *NotifyController {
+	return &NotifyController{
+		weatherService: weatherService,
+		notifier:       notifier,
+	}
+}
+
+// ListChannels 列出已配置的通知渠道
+// @Summary 列出通知渠道
+// @Description 返回渠道配置文件中定义的全部渠道，不包含地址和密钥
+// @Tags notify
+// @Produce json
+// @Success 200 {object} model.APIResponse{data=[]model.NotifyChannel}
+// @Router /api/v1/notify/channels [get]
+func (nc *NotifyController) ListChannels(c *gin.Context) {
+	respondWithSuccess(c, nc.notifier.Channels())
+}
+
+// TestNotify 向指定渠道发送一条测试通知
+// @Summary 发送测试通知
+// @Description 查询指定位置的当前天气，使用渠道模板渲染后发送，用于检查渠道配置
+// @Tags notify
+// @Accept json
+// @Produce json
+// @Param request body model.NotifyTestRequest true "渠道和位置"
+// @Success 200 {object} model.APIResponse{data=model.NotifyTestResponse}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 429 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 502 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/notify/test [post]
+func (nc *NotifyController) TestNotify(c *gin.Context) {
+	var req model.NotifyTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+	if !nc.notifier.Has(req.Channel) {
+		respondWithError(c, http.StatusNotFound, "通知渠道不存在", "未配置渠道 "+req.Channel)
+		return
+	}
+	if req.Units == "" {
+		req.Units = "metric"
+	}
+	if req.Lang == "" {
+		req.Lang = "zh_cn"
+	}
+	if req.Title == "" {
+		req.Title = "天气通知测试"
+	}
+
+	weatherResp, err := service.Fetch(nc.weatherService, &req.WeatherRequest)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
+		return
+	}
+
+	msg, err := nc.notifier.Send(c.Request.Context(), req.Channel, &notify.Event{
+		Title:   req.Title,
+		Weather: weatherResp,
+		Units:   req.Units,
+		Lang:    req.Lang,
+	})
+	if err != nil {
+		if errors.Is(err, notify.ErrRateLimited) {
+			respondWithError(c, http.StatusTooManyRequests, "发送过于频繁", err.Error())
+			return
+		}
+		respondWithError(c, http.StatusBadGateway, "发送通知失败", err.Error())
+		return
+	}
+
+	respondWithSuccess(c, model.NotifyTestResponse{
+		Channel: req.Channel,
+		Title:   msg.Title,
+		Body:    msg.Body,
+	})
+}
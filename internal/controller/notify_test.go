@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNotifyController_TestNotify(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var received string
+	robot := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer robot.Close()
+
+	notifier, err := notify.New([]notify.ChannelConfig{
+		{Name: "ops", Type: notify.TypeWeCom, URL: robot.URL, RatePerMinute: 1},
+	}, time.Second)
+	if err != nil {
+		t.Fatalf("创建 Notifier 失败: %v", err)
+	}
+
+	controller := NewNotifyController(&MockWeatherService{}, notifier)
+	router := gin.New()
+	router.POST("/notify/test", controller.TestNotify)
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/notify/test", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(`{"channel":"missing","city":"Beijing"}`); w.Code != http.StatusNotFound {
+		t.Errorf("期望未知渠道返回 404，实际为 %d", w.Code)
+	}
+
+	w := send(`{"channel":"ops","city":"Beijing"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data model.NotifyTestResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.Title != "天气通知测试" || !strings.Contains(received, "Beijing") {
+		t.Errorf("期望发送 Beijing 的测试通知，实际为 %+v，收到 %s", response.Data, received)
+	}
+
+	// 渠道限流为每分钟 1 条
+	if w := send(`{"channel":"ops","city":"Beijing"}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("期望超出限流返回 429，实际为 %d", w.Code)
+	}
+}
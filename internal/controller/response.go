@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"net/http"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/projection"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// respondWithError 返回错误响应
+func respondWithError(c *gin.Context, statusCode int, error, message string) {
+	c.JSON(statusCode, model.APIResponse{
+		Success: false,
+		Error: &model.ErrorResponse{
+			Error:   error,
+			Code:    statusCode,
+			Message: message,
+		},
+	})
+}
+
+// respondWithSuccess 返回成功响应
+func respondWithSuccess(c *gin.Context, data interface{}) {
+	respondWithStatus(c, http.StatusOK, data)
+}
+
+// respondWithStatus 以指定状态码返回成功响应，并按 fields、exclude 查询参数对数据做字段投影
+func respondWithStatus(c *gin.Context, statusCode int, data interface{}) {
+	fields := projection.ParseList(c.QueryArray("fields"))
+	exclude := projection.ParseList(c.QueryArray("exclude"))
+	data, err := projection.Apply(data, fields, exclude)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+
+	c.JSON(statusCode, model.APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// respondWithGeoJSON 以 application/geo+json 返回 GeoJSON 对象，不包装在 APIResponse 中，便于 GIS 工具直接加载
+func respondWithGeoJSON(c *gin.Context, data interface{}) {
+	// render.JSON 只在未设置 Content-Type 时写入 application/json
+	c.Header("Content-Type", model.GeoJSONMediaType)
+	c.Render(http.StatusOK, render.JSON{Data: data})
+}
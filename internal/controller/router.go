@@ -4,15 +4,67 @@ import (
 	"fmt"
 	"time"
 
+	"gin-weather/internal/archive"
 	"gin-weather/internal/config"
+	"gin-weather/internal/digest"
+	"gin-weather/internal/grid"
+	"gin-weather/internal/historical"
+	"gin-weather/internal/indices"
+	"gin-weather/internal/jobs"
+	"gin-weather/internal/live"
+	"gin-weather/internal/notify"
 	"gin-weather/internal/service"
+	"gin-weather/internal/shadow"
+	"gin-weather/internal/tiles"
+	"gin-weather/internal/webhook"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
+// Dependencies 路由依赖的服务组件，由 main 创建并负责其生命周期
+type Dependencies struct {
+	WeatherService service.WeatherService
+	IndicesEngine  *indices.Engine
+	JobManager     *jobs.Manager
+	LiveScheduler  *live.Scheduler
+	WebhookManager *webhook.Manager
+	Notifier       *notify.Notifier
+	DigestManager  *digest.Manager
+	Archive        *archive.Archive
+	Historical     *historical.Service
+	Shadow         *shadow.Shadow
+	Tiles          *tiles.Proxy
+	AreaSearcher   service.AreaSearcher
+	Grid           *grid.Service
+}
+
+// controllers 各功能模块的控制器
+type controllers struct {
+	weather    *WeatherController
+	calendar   *CalendarController
+	indices    *IndicesController
+	batch      *BatchController
+	jobs       *JobController
+	stream     *StreamController
+	ws         *WSController
+	webhooks   *WebhookController
+	rules      *RulesController
+	notify     *NotifyController
+	digests    *DigestController
+	history    *HistoryController
+	historical *HistoricalController
+	climate    *ClimateController
+	skill      *SkillController
+	shadow     *ShadowController
+	icons      *IconController
+	tiles      *TileController
+	area       *AreaController
+	grid       *GridController
+}
+
 // SetupRouter 设置路由
-func SetupRouter(cfg *config.Config, weatherService service.WeatherService) *gin.Engine {
+func SetupRouter(cfg *config.Config, deps *Dependencies) *gin.Engine {
 	// 设置 Gin 模式
 	gin.SetMode(cfg.Server.Mode)
 
@@ -23,10 +75,31 @@ func SetupRouter(cfg *config.Config, weatherService service.WeatherService) *gin
 	setupMiddleware(router)
 
 	// 创建控制器实例
-	weatherController := NewWeatherController(weatherService)
+	ctrls := &controllers{
+		weather:    NewWeatherController(deps.WeatherService, deps.Archive),
+		calendar:   NewCalendarController(),
+		indices:    NewIndicesController(deps.WeatherService, deps.IndicesEngine),
+		batch:      NewBatchController(deps.WeatherService, &cfg.Batch),
+		jobs:       NewJobController(deps.JobManager, &cfg.Jobs),
+		stream:     NewStreamController(deps.LiveScheduler, &cfg.Live),
+		ws:         NewWSController(deps.LiveScheduler, &cfg.Live),
+		webhooks:   NewWebhookController(deps.WebhookManager),
+		rules:      NewRulesController(deps.WeatherService),
+		notify:     NewNotifyController(deps.WeatherService, deps.Notifier),
+		digests:    NewDigestController(deps.DigestManager),
+		history:    NewHistoryController(deps.Archive),
+		historical: NewHistoricalController(deps.Historical),
+		climate:    NewClimateController(deps.Archive),
+		skill:      NewSkillController(deps.Archive),
+		shadow:     NewShadowController(deps.Shadow),
+		icons:      NewIconController(),
+		tiles:      NewTileController(deps.Tiles),
+		area:       NewAreaController(deps.AreaSearcher, &cfg.Area),
+		grid:       NewGridController(deps.Grid),
+	}
 
 	// 设置路由组
-	setupRoutes(router, weatherController)
+	setupRoutes(router, ctrls)
 
 	return router
 }
@@ -56,24 +129,106 @@ func setupMiddleware(router *gin.Engine) {
 }
 
 // setupRoutes 设置路由
-func setupRoutes(router *gin.Engine, weatherController *WeatherController) {
+func setupRoutes(router *gin.Engine, ctrls *controllers) {
 	// API 版本 1
 	v1 := router.Group("/api/v1")
 	{
 		// 健康检查
-		v1.GET("/health", weatherController.HealthCheck)
+		v1.GET("/health", ctrls.weather.HealthCheck)
 
 		// 天气相关路由
 		weather := v1.Group("/weather")
 		{
 			// 通用天气查询接口（支持城市名称或坐标）
-			weather.GET("", weatherController.GetWeather)
+			weather.GET("", ctrls.weather.GetWeather)
 
 			// 根据城市名称查询天气
-			weather.GET("/city/:city", weatherController.GetWeatherByCity)
+			weather.GET("/city/:city", ctrls.weather.GetWeatherByCity)
 
 			// 根据坐标查询天气
-			weather.GET("/coordinates/:lat/:lon", weatherController.GetWeatherByCoordinates)
+			weather.GET("/coordinates/:lat/:lon", ctrls.weather.GetWeatherByCoordinates)
+
+			// 通过 SSE 订阅天气实时更新
+			weather.GET("/stream", ctrls.stream.StreamWeather)
+
+			// 批量查询多个位置的天气
+			weather.POST("/batch", ctrls.batch.GetWeatherBatch)
+
+			// 查询过去某一天或一段日期的天气
+			weather.GET("/historical", ctrls.historical.GetHistorical)
+
+			// 查询附近城市或经纬度范围内城市的天气
+			weather.GET("/nearby", ctrls.area.GetNearby)
+			weather.GET("/bbox", ctrls.area.GetBBox)
+
+			// 按规则网格采样经纬度范围内的天气，用于绘制热力图
+			weather.GET("/grid", ctrls.grid.GetGrid)
+		}
+
+		// 通过 WebSocket 订阅多个位置的天气更新
+		v1.GET("/ws", ctrls.ws.ServeWS)
+
+		// 农历与节气
+		v1.GET("/calendar", ctrls.calendar.GetCalendar)
+
+		// 生活指数
+		v1.GET("/indices", ctrls.indices.GetIndices)
+
+		// 异步批量任务
+		jobRoutes := v1.Group("/jobs")
+		{
+			jobRoutes.POST("", ctrls.jobs.CreateJob)
+			jobRoutes.GET("/:id", ctrls.jobs.GetJob)
+			jobRoutes.GET("/:id/result", ctrls.jobs.GetJobResult)
+		}
+
+		// 阈值告警 Webhook
+		webhookRoutes := v1.Group("/webhooks")
+		{
+			webhookRoutes.POST("", ctrls.webhooks.CreateWebhook)
+			webhookRoutes.GET("", ctrls.webhooks.ListWebhooks)
+			webhookRoutes.GET("/:id", ctrls.webhooks.GetWebhook)
+			webhookRoutes.DELETE("/:id", ctrls.webhooks.DeleteWebhook)
+			webhookRoutes.GET("/:id/deliveries", ctrls.webhooks.GetDeliveries)
+		}
+
+		// 条件表达式试算
+		v1.POST("/rules/evaluate", ctrls.rules.EvaluateRule)
+
+		// 通知渠道
+		notifyRoutes := v1.Group("/notify")
+		{
+			notifyRoutes.GET("/channels", ctrls.notify.ListChannels)
+			notifyRoutes.POST("/test", ctrls.notify.TestNotify)
+		}
+
+		// 历史观测
+		v1.GET("/history", ctrls.history.GetHistory)
+
+		// 常年气候统计
+		v1.GET("/climate/normals", ctrls.climate.GetNormals)
+
+		// 天气图标
+		v1.GET("/icons/:file", ctrls.icons.GetIcon)
+
+		// 天气图层瓦片
+		v1.GET("/tiles/:layer/:z/:x/:y", ctrls.tiles.GetTile)
+
+		// 管理接口
+		adminRoutes := v1.Group("/admin")
+		{
+			adminRoutes.GET("/forecast-skill", ctrls.skill.GetForecastSkill)
+			adminRoutes.GET("/shadow", ctrls.shadow.GetShadowReport)
+		}
+
+		// 定时天气简报
+		digestRoutes := v1.Group("/digests")
+		{
+			digestRoutes.POST("", ctrls.digests.CreateDigest)
+			digestRoutes.GET("", ctrls.digests.ListDigests)
+			digestRoutes.GET("/:id", ctrls.digests.GetDigest)
+			digestRoutes.DELETE("/:id", ctrls.digests.DeleteDigest)
+			digestRoutes.GET("/:id/preview", ctrls.digests.PreviewDigest)
 		}
 	}
 
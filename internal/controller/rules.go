@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/rules"
+	"gin-weather/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RulesController 条件表达式控制器
+type RulesController struct {
+	weatherService service.WeatherService
+}
+
+// NewRulesController 创建条件表达式控制器实例
+func NewRulesController(weatherService service.WeatherService) *RulesController {
+	return &RulesController{
+		weatherService: weatherService,
+	}
+}
+
+// EvaluateRule 使用指定位置的当前天气试算条件表达式
+// @Summary 试算条件表达式
+// @Description 编译表达式并使用指定位置的当前天气求值，返回结果以及表达式引用的字段值
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param request body model.RuleEvaluateRequest true "表达式和位置"
+// @Success 200 {object} model.APIResponse{data=model.RuleEvaluateResponse}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 422 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/rules/evaluate [post]
+func (rc *RulesController) EvaluateRule(c *gin.Context) {
+	var req model.RuleEvaluateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+	if req.Units == "" {
+		req.Units = "metric"
+	}
+	if req.Lang == "" {
+		req.Lang = "zh_cn"
+	}
+
+	// 先编译再查询天气，表达式有误时不消耗上游请求
+	program, err := rules.Compile(req.Expression)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "表达式编译失败", err.Error())
+		return
+	}
+
+	weatherResp, err := service.Fetch(rc.weatherService, &req.WeatherRequest)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
+		return
+	}
+
+	env := rules.NewEnv(weatherResp, req.Units)
+	result, err := program.Eval(env)
+	if err != nil {
+		respondWithError(c, http.StatusUnprocessableEntity, "表达式求值失败", err.Error())
+		return
+	}
+
+	respondWithSuccess(c, model.RuleEvaluateResponse{
+		Expression: program.String(),
+		Result:     result,
+		Values:     program.Values(env),
+		Location:   weatherResp.Location,
+		Timestamp:  weatherResp.Timestamp,
+	})
+}
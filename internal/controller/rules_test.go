@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRulesController_EvaluateRule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := NewRulesController(&MockWeatherService{})
+	router := gin.New()
+	router.POST("/rules/evaluate", controller.EvaluateRule)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		result bool
+	}{
+		{"满足条件", `{"expression":"current.temperature > 20 && any(current.weather, .description == \"晴天\")","city":"Beijing"}`, http.StatusOK, true},
+		{"不满足条件", `{"expression":"current.wind.speed > 8","lat":31.23,"lon":121.47}`, http.StatusOK, false},
+		{"类型错误", `{"expression":"current.temperature > \"20\"","city":"Beijing"}`, http.StatusBadRequest, false},
+		{"缺少位置", `{"expression":"true"}`, http.StatusBadRequest, false},
+		{"求值失败", `{"expression":"current.temperature / current.clouds.all > 1","city":"Beijing"}`, http.StatusUnprocessableEntity, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/rules/evaluate", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("期望状态码 %d，实际为 %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data model.RuleEvaluateResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("解析响应失败: %v", err)
+			}
+			if response.Data.Result != tt.result {
+				t.Errorf("期望结果为 %v，实际为 %v", tt.result, response.Data.Result)
+			}
+			if len(response.Data.Values) == 0 {
+				t.Error("期望返回表达式引用的字段值")
+			}
+		})
+	}
+}
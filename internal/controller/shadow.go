@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"net/http"
+
+	"gin-weather/internal/shadow"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShadowController 影子模式控制器
+type ShadowController struct {
+	shadow *shadow.Shadow
+}
+
+// NewShadowController 创建影子模式控制器实例，shadow 为 nil 表示未启用影子模式
+func NewShadowController(shadow *shadow.Shadow) *ShadowController {
+	return &ShadowController{
+		shadow: shadow,
+	}
+}
+
+// GetShadowReport 查询影子模式的比较统计
+// @Summary 查询影子模式的比较统计
+// @Description 返回候选天气服务与主服务在抽样请求上的逐字段差异、超出容差的次数和耗时对比
+// @Tags admin
+// @Produce json
+// @Success 200 {object} model.APIResponse{data=model.ShadowReport}
+// @Failure 503 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/admin/shadow [get]
+func (sc *ShadowController) GetShadowReport(c *gin.Context) {
+	if sc.shadow == nil {
+		respondWithError(c, http.StatusServiceUnavailable, "影子模式未启用", "服务未配置候选天气服务（SHADOW_PROVIDER）")
+		return
+	}
+	respondWithSuccess(c, sc.shadow.Report())
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/shadow"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestShadowController_GetShadowReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s, err := shadow.New(&config.ShadowConfig{Provider: "candidate", SamplePercent: 10, MaxInFlight: 1}, "openweathermap", &MockWeatherService{})
+	if err != nil {
+		t.Fatalf("创建影子模式失败: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/enabled", NewShadowController(s).GetShadowReport)
+	router.GET("/disabled", NewShadowController(nil).GetShadowReport)
+
+	req, _ := http.NewRequest("GET", "/enabled", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d", w.Code)
+	}
+	var response struct {
+		Data model.ShadowReport `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.Primary != "openweathermap" || response.Data.Candidate != "candidate" || len(response.Data.Fields) == 0 {
+		t.Errorf("返回结果不正确: %+v", response.Data)
+	}
+
+	req, _ = http.NewRequest("GET", "/disabled", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("期望未启用影子模式时返回 503，实际为 %d", w.Code)
+	}
+}
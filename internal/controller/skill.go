@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin-weather/internal/archive"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultSkillDays 默认统计最近多少天内到期的预报
+	defaultSkillDays = 30
+	// maxSkillDays 最多统计的天数
+	maxSkillDays = 365
+)
+
+// SkillController 预报准确度控制器
+type SkillController struct {
+	archive *archive.Archive
+}
+
+// NewSkillController 创建预报准确度控制器实例，archive 为 nil 表示未启用存档
+func NewSkillController(archive *archive.Archive) *SkillController {
+	return &SkillController{
+		archive: archive,
+	}
+}
+
+// GetForecastSkill 查询预报准确度
+// @Summary 查询预报准确度
+// @Description 把存档中的预报快照与有效时间的实况观测配对，按数据提供商和预报提前量统计温度、风速的平均绝对误差和偏差以及降水预报准确率
+// @Tags admin
+// @Produce json
+// @Param city query string false "城市名称，不填时统计所有位置"
+// @Param lat query number false "纬度"
+// @Param lon query number false "经度"
+// @Param days query int false "统计最近多少天内到期的预报" default(30)
+// @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
+// @Success 200 {object} model.APIResponse{data=model.ForecastSkillReport}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 503 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/admin/forecast-skill [get]
+func (sc *SkillController) GetForecastSkill(c *gin.Context) {
+	if sc.archive == nil {
+		respondWithError(c, http.StatusServiceUnavailable, "存档未启用", "服务未配置观测存档数据库")
+		return
+	}
+
+	var q *archive.Query
+	units := c.DefaultQuery("units", "metric")
+	if c.Query("city") != "" || c.Query("lat") != "" || c.Query("lon") != "" {
+		var err error
+		if q, units, err = parseLocationQuery(c); err != nil {
+			respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+			return
+		}
+	} else if !model.ValidUnits(units) {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", "单位系统必须是 metric、imperial 或 standard")
+		return
+	}
+
+	days := defaultSkillDays
+	if raw := c.Query("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxSkillDays {
+			respondWithError(c, http.StatusBadRequest, "参数验证失败", "days 必须是 1-365 之间的整数")
+			return
+		}
+		days = n
+	}
+
+	to := time.Now()
+	report, err := sc.archive.ForecastSkill(q, to.AddDate(0, 0, -days), to, units)
+	switch {
+	case errors.Is(err, archive.ErrUnknownLocation):
+		respondWithError(c, http.StatusNotFound, "没有历史记录", err.Error())
+	case err != nil:
+		respondWithError(c, http.StatusInternalServerError, "计算预报准确度失败", err.Error())
+	default:
+		respondWithSuccess(c, report)
+	}
+}
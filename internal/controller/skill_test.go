@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"gin-weather/internal/archive"
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSkillController_GetForecastSkill(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	a, err := archive.New(&config.ArchiveConfig{Path: filepath.Join(t.TempDir(), "weather.db"), PollInterval: 3600, MaxPoints: 100, NormalsWindow: 15})
+	if err != nil {
+		t.Fatalf("创建存档失败: %v", err)
+	}
+	defer a.Stop(context.Background())
+
+	router := gin.New()
+	router.GET("/enabled", NewSkillController(a).GetForecastSkill)
+	router.GET("/disabled", NewSkillController(nil).GetForecastSkill)
+	do := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/enabled?days=7&units=imperial")
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data model.ForecastSkillReport `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.Units != "imperial" || len(response.Data.Scores) != 0 || response.Data.To.Sub(response.Data.From).Hours() != 7*24 {
+		t.Errorf("返回结果不正确: %+v", response.Data)
+	}
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/enabled?days=0", http.StatusBadRequest},
+		{"/enabled?units=kelvin", http.StatusBadRequest},
+		{"/enabled?lat=91&lon=0", http.StatusBadRequest},
+		{"/enabled?city=Beijing", http.StatusNotFound},
+		{"/disabled", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		if w := do(tt.path); w.Code != tt.code {
+			t.Errorf("%s: 期望状态码 %d，实际为 %d", tt.path, tt.code, w.Code)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/geojson"
+	"gin-weather/internal/live"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamController 天气实时推送控制器
+type StreamController struct {
+	scheduler *live.Scheduler
+	config    *config.LiveConfig
+}
+
+// NewStreamController 创建实时推送控制器实例
+func NewStreamController(scheduler *live.Scheduler, cfg *config.LiveConfig) *StreamController {
+	return &StreamController{
+		scheduler: scheduler,
+		config:    cfg,
+	}
+}
+
+// StreamWeather 通过 Server-Sent Events 推送天气更新
+// @Summary 订阅天气实时更新
+// @Description 保持 SSE 连接，后台刷新的数据发生变化时推送 weather 事件，获取失败时推送 error 事件，并定期发送心跳注释；通过 format=geojson 或 Accept: application/geo+json 请求时 weather 事件的数据为 GeoJSON 点要素
+// @Tags weather
+// @Produce text/event-stream
+// @Param city query string false "城市名称（与坐标二选一）"
+// @Param lat query number false "纬度（需要与经度一起使用）"
+// @Param lon query number false "经度（需要与纬度一起使用）"
+// @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
+// @Param lang query string false "语言" default(zh_cn)
+// @Param format query string false "weather 事件的数据格式" Enums(json, geojson)
+// @Success 200 {string} string "事件流"
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 503 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/weather/stream [get]
+func (sc *StreamController) StreamWeather(c *gin.Context) {
+	req, ok := bindWeatherRequest(c)
+	if !ok {
+		return
+	}
+
+	// 建立连接后响应头已经是事件流，在此之前确定事件数据的格式
+	asGeoJSON := wantsGeoJSON(c)
+
+	sub, err := sc.scheduler.Subscribe(*req)
+	if err != nil {
+		respondWithError(c, http.StatusServiceUnavailable, "服务不可用", err.Error())
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 Nginx 缓冲
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(time.Duration(sc.config.HeartbeatInterval) * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			// 客户端断开连接
+			return
+		case update, ok := <-sub.Updates():
+			if !ok {
+				// 服务正在关闭
+				return
+			}
+			if update.Err != nil {
+				writeEvent(c, "error", gin.H{"error": "获取天气信息失败", "message": update.Err.Error()})
+			} else if asGeoJSON {
+				writeEvent(c, "weather", geojson.Feature(update.Weather))
+			} else {
+				writeEvent(c, "weather", update.Weather)
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeEvent 写出一条 SSE 事件
+func writeEvent(c *gin.Context, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, payload)
+	c.Writer.Flush()
+}
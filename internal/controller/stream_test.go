@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/live"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamController_StreamWeather(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	scheduler := live.NewScheduler(&MockWeatherService{}, time.Hour)
+	controller := NewStreamController(scheduler, &config.LiveConfig{HeartbeatInterval: 1})
+
+	router := gin.New()
+	router.GET("/weather/stream", controller.StreamWeather)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/weather/stream?city=Beijing")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("期望 Content-Type 为 text/event-stream，实际为 %s", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	event, _ := reader.ReadString('\n')
+	data, _ := reader.ReadString('\n')
+	if event != "event: weather\n" || !strings.Contains(data, `"name":"Beijing"`) {
+		t.Errorf("期望收到 weather 事件，实际为 %q %q", event, data)
+	}
+
+	// 请求 GeoJSON 时 weather 事件的数据为点要素，坐标顺序为 [经度, 纬度]
+	req, _ := http.NewRequest("GET", server.URL+"/weather/stream?city=Beijing", nil)
+	req.Header.Set("Accept", model.GeoJSONMediaType)
+	geoResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer geoResp.Body.Close()
+	geoReader := bufio.NewReader(geoResp.Body)
+	event, _ = geoReader.ReadString('\n')
+	data, _ = geoReader.ReadString('\n')
+	if event != "event: weather\n" || !strings.Contains(data, `"type":"Feature"`) || !strings.Contains(data, `"coordinates":[116.4074,39.9042]`) {
+		t.Errorf("期望收到 GeoJSON 点要素，实际为 %q %q", event, data)
+	}
+
+	// 关闭调度器后连接结束
+	scheduler.Close()
+	done := make(chan struct{})
+	go func() {
+		for {
+			if _, err := reader.ReadString('\n'); err != nil {
+				close(done)
+				return
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Error("期望调度器关闭后连接结束")
+	}
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-weather/internal/service"
+	"gin-weather/internal/tiles"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TileController 天气图层瓦片控制器
+type TileController struct {
+	proxy *tiles.Proxy
+}
+
+// NewTileController 创建瓦片控制器实例，proxy 为 nil 表示天气服务不支持图层瓦片
+func NewTileController(proxy *tiles.Proxy) *TileController {
+	return &TileController{
+		proxy: proxy,
+	}
+}
+
+// GetTile 获取天气图层瓦片
+// @Summary 获取天气图层瓦片
+// @Description 代理上游的天气图层瓦片，API Key 只在服务端使用。瓦片缓存在磁盘上，支持 If-None-Match 和 If-Modified-Since 条件请求
+// @Tags tiles
+// @Produce image/png
+// @Param layer path string true "图层：precipitation、clouds、temperature、wind、pressure"
+// @Param z path int true "缩放级别"
+// @Param x path int true "瓦片列号"
+// @Param y path int true "瓦片行号，后面加 .png"
+// @Success 200 {file} file
+// @Success 304 "瓦片未变化"
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 429 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 502 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 503 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/tiles/{layer}/{z}/{x}/{y}.png [get]
+func (tc *TileController) GetTile(c *gin.Context) {
+	if tc.proxy == nil {
+		respondWithError(c, http.StatusServiceUnavailable, "瓦片代理未启用", "当前天气服务不支持图层瓦片")
+		return
+	}
+
+	yStr, ok := strings.CutSuffix(c.Param("y"), ".png")
+	if !ok {
+		respondWithError(c, http.StatusNotFound, "瓦片不存在", "瓦片地址必须以 .png 结尾")
+		return
+	}
+	z, errZ := strconv.Atoi(c.Param("z"))
+	x, errX := strconv.Atoi(c.Param("x"))
+	y, errY := strconv.Atoi(yStr)
+	if errZ != nil || errX != nil || errY != nil {
+		respondWithError(c, http.StatusBadRequest, "参数错误", "z、x、y 必须是整数")
+		return
+	}
+
+	tile, err := tc.proxy.Get(c.Param("layer"), z, x, y)
+	switch {
+	case errors.Is(err, tiles.ErrInvalidTile):
+		respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	case errors.Is(err, service.ErrQuotaExceeded):
+		respondWithError(c, http.StatusTooManyRequests, "获取瓦片失败", err.Error())
+		return
+	case err != nil:
+		// 上游错误的细节只记录在服务端，避免把上游地址等信息返回给浏览器
+		log.Printf("获取瓦片 %s/%d/%d/%d 失败: %v", c.Param("layer"), z, x, y, err)
+		respondWithError(c, http.StatusBadGateway, "获取瓦片失败", "天气图层服务暂时不可用")
+		return
+	}
+
+	maxAge := int(time.Until(tile.Expires).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+	c.Header("ETag", tile.ETag)
+	http.ServeContent(c.Writer, c.Request, "tile.png", tile.ModTime, bytes.NewReader(tile.Data))
+}
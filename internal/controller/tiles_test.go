@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/service"
+	"gin-weather/internal/tiles"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockTileProvider 返回固定内容的瓦片，err 不为 nil 时返回错误
+type mockTileProvider struct {
+	err error
+}
+
+func (m *mockTileProvider) FetchTile(layer string, z, x, y int) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []byte("\x89PNG tile"), nil
+}
+
+func newTileRouter(t *testing.T, provider service.TileProvider) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	var proxy *tiles.Proxy
+	if provider != nil {
+		var err error
+		proxy, err = tiles.New(&config.TilesConfig{CacheDir: t.TempDir(), TTL: 600, MaxCacheMB: 1, MaxZoom: 5}, provider)
+		if err != nil {
+			t.Fatalf("创建瓦片代理失败: %v", err)
+		}
+	}
+	router := gin.New()
+	router.GET("/tiles/:layer/:z/:x/:y", NewTileController(proxy).GetTile)
+	return router
+}
+
+func TestTileController_GetTile(t *testing.T) {
+	router := newTileRouter(t, &mockTileProvider{})
+
+	req, _ := http.NewRequest("GET", "/tiles/clouds/2/1/3.png", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "\x89PNG tile" {
+		t.Fatalf("期望状态码 200 并返回瓦片，实际为 %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "image/png" || w.Header().Get("Cache-Control") == "" || w.Header().Get("Last-Modified") == "" {
+		t.Errorf("响应头不正确: %v", w.Header())
+	}
+
+	req, _ = http.NewRequest("GET", "/tiles/clouds/2/1/3.png", nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("期望状态码 304，实际为 %d", w.Code)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/tiles/humidity/2/1/3.png", http.StatusBadRequest},
+		{"/tiles/clouds/6/0/0.png", http.StatusBadRequest},
+		{"/tiles/clouds/2/4/0.png", http.StatusBadRequest},
+		{"/tiles/clouds/a/0/0.png", http.StatusBadRequest},
+		{"/tiles/clouds/2/1/3.jpg", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req, _ = http.NewRequest("GET", tt.path, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: 期望状态码 %d，实际为 %d", tt.path, tt.status, w.Code)
+		}
+	}
+}
+
+func TestTileController_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider service.TileProvider
+		status   int
+	}{
+		{"未启用", nil, http.StatusServiceUnavailable},
+		{"超过配额", &mockTileProvider{err: service.ErrQuotaExceeded}, http.StatusTooManyRequests},
+		{"上游失败", &mockTileProvider{err: http.ErrHandlerTimeout}, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		router := newTileRouter(t, tt.provider)
+		req, _ := http.NewRequest("GET", "/tiles/clouds/0/0/0.png", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: 期望状态码 %d，实际为 %d", tt.name, tt.status, w.Code)
+		}
+	}
+}
+
+func TestTileController_UpstreamErrorHidesAPIKey(t *testing.T) {
+	// 上游已关闭，请求失败且没有缓存的瓦片
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	provider := service.NewOpenWeatherMapService(&config.WeatherConfig{APIKey: "secret-key", Timeout: 1, TileURL: server.URL})
+
+	router := newTileRouter(t, provider)
+	req, _ := http.NewRequest("GET", "/tiles/clouds/0/0/0.png", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("期望状态码 502，实际为 %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "secret-key") || strings.Contains(w.Body.String(), "appid") {
+		t.Errorf("响应中不应包含 API Key: %s", w.Body.String())
+	}
+}
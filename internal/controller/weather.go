@@ -4,9 +4,16 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"gin-weather/internal/archive"
+	"gin-weather/internal/calendar"
+	"gin-weather/internal/derived"
+	"gin-weather/internal/geojson"
 	"gin-weather/internal/model"
 	"gin-weather/internal/service"
+	"gin-weather/internal/summary"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,12 +21,14 @@ import (
 // WeatherController 天气控制器
 type WeatherController struct {
 	weatherService service.WeatherService
+	archive        *archive.Archive
 }
 
-// NewWeatherController 创建天气控制器实例
-func NewWeatherController(weatherService service.WeatherService) *WeatherController {
+// NewWeatherController 创建天气控制器实例，archive 用于计算气候异常，为 nil 时不支持 include=anomaly
+func NewWeatherController(weatherService service.WeatherService, archive *archive.Archive) *WeatherController {
 	return &WeatherController{
 		weatherService: weatherService,
+		archive:        archive,
 	}
 }
 
@@ -28,55 +37,41 @@ func NewWeatherController(weatherService service.WeatherService) *WeatherControl
 // @Description 根据城市名称或坐标获取当前天气信息
 // @Tags weather
 // @Accept json
-// @Produce json
+// @Produce json,plain,application/geo+json
 // @Param city query string false "城市名称（与坐标二选一）"
 // @Param lat query number false "纬度（需要与经度一起使用）"
 // @Param lon query number false "经度（需要与纬度一起使用）"
 // @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
 // @Param lang query string false "语言" default(zh_cn)
+// @Param include query string false "附加数据块（逗号分隔）" Enums(calendar, summary, derived, astronomy, anomaly)
+// @Param fields query string false "只返回指定字段（逗号分隔的路径，如 current.temperature）"
+// @Param exclude query string false "移除指定字段（逗号分隔的路径）"
+// @Param format query string false "响应格式，text 返回纯文本摘要，geojson 返回 GeoJSON 点要素" Enums(json, text, geojson)
+// @Param provider query string false "跳过路由规则，使用指定的数据提供商（用于调试，需要启用按地区路由）"
 // @Success 200 {object} model.APIResponse{data=model.WeatherResponse}
 // @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
 // @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
 // @Router /api/v1/weather [get]
 func (wc *WeatherController) GetWeather(c *gin.Context) {
-	// 解析查询参数
-	var req model.WeatherRequest
-	if err := c.ShouldBindQuery(&req); err != nil {
-		wc.respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+	// 解析并验证查询参数
+	req, ok := bindWeatherRequest(c)
+	if !ok {
 		return
 	}
 
-	// 验证参数
-	if err := wc.validateRequest(&req); err != nil {
-		wc.respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+	weatherService, ok := wc.serviceFor(c)
+	if !ok {
 		return
 	}
 
-	// 设置默认值
-	if req.Units == "" {
-		req.Units = "metric"
-	}
-	if req.Lang == "" {
-		req.Lang = "zh_cn"
-	}
-
-	var weatherResp *model.WeatherResponse
-	var err error
-
-	// 根据请求类型调用相应的服务方法
-	if req.City != "" {
-		weatherResp, err = wc.weatherService.GetWeatherByCity(req.City, req.Units, req.Lang)
-	} else {
-		weatherResp, err = wc.weatherService.GetWeatherByCoordinates(req.Lat, req.Lon, req.Units, req.Lang)
-	}
-
+	weatherResp, err := service.Fetch(weatherService, req)
 	if err != nil {
-		wc.respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
+		respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
 		return
 	}
 
 	// 返回成功响应
-	wc.respondWithSuccess(c, weatherResp)
+	wc.respondWithWeather(c, weatherResp)
 }
 
 // GetWeatherByCity 根据城市名称获取天气信息
@@ -84,10 +79,15 @@ func (wc *WeatherController) GetWeather(c *gin.Context) {
 // @Description 根据城市名称获取当前天气信息
 // @Tags weather
 // @Accept json
-// @Produce json
+// @Produce json,plain,application/geo+json
 // @Param city path string true "城市名称"
 // @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
 // @Param lang query string false "语言" default(zh_cn)
+// @Param include query string false "附加数据块（逗号分隔）" Enums(calendar, summary, derived, astronomy, anomaly)
+// @Param fields query string false "只返回指定字段（逗号分隔的路径，如 current.temperature）"
+// @Param exclude query string false "移除指定字段（逗号分隔的路径）"
+// @Param format query string false "响应格式，text 返回纯文本摘要，geojson 返回 GeoJSON 点要素" Enums(json, text, geojson)
+// @Param provider query string false "跳过路由规则，使用指定的数据提供商（用于调试，需要启用按地区路由）"
 // @Success 200 {object} model.APIResponse{data=model.WeatherResponse}
 // @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
 // @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
@@ -95,20 +95,25 @@ func (wc *WeatherController) GetWeather(c *gin.Context) {
 func (wc *WeatherController) GetWeatherByCity(c *gin.Context) {
 	city := c.Param("city")
 	if city == "" {
-		wc.respondWithError(c, http.StatusBadRequest, "参数错误", "城市名称不能为空")
+		respondWithError(c, http.StatusBadRequest, "参数错误", "城市名称不能为空")
 		return
 	}
 
 	units := c.DefaultQuery("units", "metric")
 	lang := c.DefaultQuery("lang", "zh_cn")
 
-	weatherResp, err := wc.weatherService.GetWeatherByCity(city, units, lang)
+	weatherService, ok := wc.serviceFor(c)
+	if !ok {
+		return
+	}
+
+	weatherResp, err := weatherService.GetWeatherByCity(city, units, lang)
 	if err != nil {
-		wc.respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
+		respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
 		return
 	}
 
-	wc.respondWithSuccess(c, weatherResp)
+	wc.respondWithWeather(c, weatherResp)
 }
 
 // GetWeatherByCoordinates 根据坐标获取天气信息
@@ -116,11 +121,16 @@ func (wc *WeatherController) GetWeatherByCity(c *gin.Context) {
 // @Description 根据经纬度坐标获取当前天气信息
 // @Tags weather
 // @Accept json
-// @Produce json
+// @Produce json,plain,application/geo+json
 // @Param lat path number true "纬度"
 // @Param lon path number true "经度"
 // @Param units query string false "单位系统" Enums(metric, imperial, standard) default(metric)
 // @Param lang query string false "语言" default(zh_cn)
+// @Param include query string false "附加数据块（逗号分隔）" Enums(calendar, summary, derived, astronomy, anomaly)
+// @Param fields query string false "只返回指定字段（逗号分隔的路径，如 current.temperature）"
+// @Param exclude query string false "移除指定字段（逗号分隔的路径）"
+// @Param format query string false "响应格式，text 返回纯文本摘要，geojson 返回 GeoJSON 点要素" Enums(json, text, geojson)
+// @Param provider query string false "跳过路由规则，使用指定的数据提供商（用于调试，需要启用按地区路由）"
 // @Success 200 {object} model.APIResponse{data=model.WeatherResponse}
 // @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
 // @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
@@ -131,36 +141,41 @@ func (wc *WeatherController) GetWeatherByCoordinates(c *gin.Context) {
 
 	lat, err := strconv.ParseFloat(latStr, 64)
 	if err != nil {
-		wc.respondWithError(c, http.StatusBadRequest, "参数错误", "纬度格式不正确")
+		respondWithError(c, http.StatusBadRequest, "参数错误", "纬度格式不正确")
 		return
 	}
 
 	lon, err := strconv.ParseFloat(lonStr, 64)
 	if err != nil {
-		wc.respondWithError(c, http.StatusBadRequest, "参数错误", "经度格式不正确")
+		respondWithError(c, http.StatusBadRequest, "参数错误", "经度格式不正确")
 		return
 	}
 
 	// 验证坐标范围
 	if lat < -90 || lat > 90 {
-		wc.respondWithError(c, http.StatusBadRequest, "参数错误", "纬度必须在 -90 到 90 之间")
+		respondWithError(c, http.StatusBadRequest, "参数错误", "纬度必须在 -90 到 90 之间")
 		return
 	}
 	if lon < -180 || lon > 180 {
-		wc.respondWithError(c, http.StatusBadRequest, "参数错误", "经度必须在 -180 到 180 之间")
+		respondWithError(c, http.StatusBadRequest, "参数错误", "经度必须在 -180 到 180 之间")
 		return
 	}
 
 	units := c.DefaultQuery("units", "metric")
 	lang := c.DefaultQuery("lang", "zh_cn")
 
-	weatherResp, err := wc.weatherService.GetWeatherByCoordinates(lat, lon, units, lang)
+	weatherService, ok := wc.serviceFor(c)
+	if !ok {
+		return
+	}
+
+	weatherResp, err := weatherService.GetWeatherByCoordinates(lat, lon, units, lang)
 	if err != nil {
-		wc.respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
+		respondWithError(c, http.StatusInternalServerError, "获取天气信息失败", err.Error())
 		return
 	}
 
-	wc.respondWithSuccess(c, weatherResp)
+	wc.respondWithWeather(c, weatherResp)
 }
 
 // HealthCheck 健康检查接口
@@ -172,49 +187,161 @@ func (wc *WeatherController) GetWeatherByCoordinates(c *gin.Context) {
 // @Success 200 {object} model.APIResponse{data=map[string]string}
 // @Router /api/v1/health [get]
 func (wc *WeatherController) HealthCheck(c *gin.Context) {
-	wc.respondWithSuccess(c, gin.H{
+	respondWithSuccess(c, gin.H{
 		"status":  "ok",
 		"service": "gin-weather",
 		"version": "1.0.0",
 	})
 }
 
-// validateRequest 验证请求参数
-func (wc *WeatherController) validateRequest(req *model.WeatherRequest) error {
-	// 城市名称和坐标必须提供其中一个
-	if req.City == "" && (req.Lat == 0 && req.Lon == 0) {
-		return fmt.Errorf("必须提供城市名称或坐标信息")
+// serviceFor 返回处理本次请求的天气服务，provider 参数指定数据提供商时跳过路由规则
+func (wc *WeatherController) serviceFor(c *gin.Context) (service.WeatherService, bool) {
+	name := c.Query("provider")
+	if name == "" {
+		return wc.weatherService, true
 	}
 
-	// 如果提供了坐标，需要验证范围
-	if req.Lat != 0 || req.Lon != 0 {
-		if req.Lat < -90 || req.Lat > 90 {
-			return fmt.Errorf("纬度必须在 -90 到 90 之间")
-		}
-		if req.Lon < -180 || req.Lon > 180 {
-			return fmt.Errorf("经度必须在 -180 到 180 之间")
-		}
+	weatherService, err := service.SelectProvider(wc.weatherService, name)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return nil, false
+	}
+	return weatherService, true
+}
+
+// bindWeatherRequest 解析并验证城市或坐标查询参数，同时填充默认值；失败时直接写入错误响应
+func bindWeatherRequest(c *gin.Context) (*model.WeatherRequest, bool) {
+	var req model.WeatherRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return nil, false
+	}
+
+	if err := req.Validate(); err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return nil, false
+	}
+
+	// 设置默认值
+	if req.Units == "" {
+		req.Units = "metric"
+	}
+	if req.Lang == "" {
+		req.Lang = "zh_cn"
 	}
 
-	return nil
+	return &req, true
 }
 
-// respondWithError 返回错误响应
-func (wc *WeatherController) respondWithError(c *gin.Context, statusCode int, error, message string) {
-	c.JSON(statusCode, model.APIResponse{
-		Success: false,
-		Error: &model.ErrorResponse{
-			Error:   error,
-			Code:    statusCode,
-			Message: message,
-		},
-	})
+// respondWithWeather 按请求的格式返回天气数据
+// 通过 format=text 或 Accept: text/plain 请求时返回纯文本摘要，
+// 通过 format=geojson 或 Accept: application/geo+json 请求时返回 GeoJSON 点要素，否则返回 JSON
+func (wc *WeatherController) respondWithWeather(c *gin.Context, resp *model.WeatherResponse) {
+	switch responseFormat(c) {
+	case formatText:
+		c.String(http.StatusOK, summary.Generate(resp, c.DefaultQuery("units", "metric"), c.DefaultQuery("lang", "zh_cn"))+"\n")
+		return
+	case formatGeoJSON:
+		respondWithGeoJSON(c, geojson.Feature(resp))
+		return
+	}
+
+	enriched, err := wc.applyIncludes(c, resp)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+	respondWithSuccess(c, enriched)
 }
 
-// respondWithSuccess 返回成功响应
-func (wc *WeatherController) respondWithSuccess(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, model.APIResponse{
-		Success: true,
-		Data:    data,
-	})
+// 响应格式，对应 format 查询参数的取值
+const (
+	formatJSON    = "json"
+	formatText    = "text"
+	formatGeoJSON = "geojson"
+)
+
+// responseFormat 返回客户端请求的响应格式：优先使用 format 查询参数，未指定时按 Accept 头协商，默认为 JSON
+func responseFormat(c *gin.Context) string {
+	if format := c.Query("format"); format != "" {
+		return format
+	}
+	switch c.NegotiateFormat(gin.MIMEJSON, gin.MIMEPlain, model.GeoJSONMediaType) {
+	case gin.MIMEPlain:
+		return formatText
+	case model.GeoJSONMediaType:
+		return formatGeoJSON
+	default:
+		return formatJSON
+	}
+}
+
+// wantsGeoJSON 判断客户端是否请求 GeoJSON 格式
+func wantsGeoJSON(c *gin.Context) bool {
+	return responseFormat(c) == formatGeoJSON
+}
+
+// includeBlocks 可以通过 include 查询参数附加的数据块
+var includeBlocks = map[string]bool{
+	"calendar":  true,
+	"summary":   true,
+	"derived":   true,
+	"astronomy": true,
+	"anomaly":   true,
+}
+
+// applyIncludes 根据 include 查询参数为天气响应附加可选数据块
+func (wc *WeatherController) applyIncludes(c *gin.Context, resp *model.WeatherResponse) (*model.WeatherResponse, error) {
+	includes := parseIncludes(c)
+	if len(includes) == 0 {
+		return resp, nil
+	}
+	for name := range includes {
+		if !includeBlocks[name] {
+			return nil, fmt.Errorf("未知的 include 数据块: %s", name)
+		}
+	}
+
+	units := c.DefaultQuery("units", "metric")
+	now := time.Now()
+
+	// 复制一份，避免修改服务层返回的数据
+	enriched := *resp
+	if includes["calendar"] {
+		loc := time.FixedZone("", resp.Location.Timezone)
+		enriched.Calendar = calendar.Lookup(now.In(loc))
+	}
+	if includes["summary"] {
+		enriched.Summary = summary.Generate(resp, units, c.DefaultQuery("lang", "zh_cn"))
+	}
+	if includes["derived"] {
+		enriched.Derived = derived.Compute(&resp.Current, units)
+	}
+	if includes["astronomy"] {
+		enriched.Astronomy = calendar.Astronomy(resp.Current.Sunrise, resp.Current.Sunset, now)
+	}
+	if includes["anomaly"] {
+		if wc.archive == nil {
+			return nil, fmt.Errorf("服务未配置观测存档，不支持 include=anomaly")
+		}
+		anomaly, err := wc.archive.Anomaly(resp, units)
+		if err != nil {
+			return nil, err
+		}
+		enriched.Anomaly = anomaly
+	}
+	return &enriched, nil
+}
+
+// parseIncludes 解析逗号分隔的 include 查询参数
+func parseIncludes(c *gin.Context) map[string]bool {
+	includes := make(map[string]bool)
+	for _, value := range c.QueryArray("include") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				includes[name] = true
+			}
+		}
+	}
+	return includes
 }
@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"gin-weather/internal/config"
 	"gin-weather/internal/model"
+	"gin-weather/internal/routing"
 
 	"github.com/gin-gonic/gin"
 )
@@ -61,13 +63,26 @@ func (m *MockWeatherService) GetWeatherByCoordinates(lat, lon float64, units, la
 	return m.GetWeatherByCity("Test City", units, lang)
 }
 
+// GetHistoricalByCity 返回与当前天气相同的数据，观测时间为 date 当天正午（UTC）
+func (m *MockWeatherService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	weather, err := m.GetWeatherByCity(city, units, lang)
+	if err == nil {
+		weather.Current.UpdatedAt = date.Add(12 * time.Hour)
+	}
+	return weather, err
+}
+
+func (m *MockWeatherService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetHistoricalByCity("Test City", date, units, lang)
+}
+
 func TestWeatherController_GetWeatherByCity(t *testing.T) {
 	// 设置 Gin 为测试模式
 	gin.SetMode(gin.TestMode)
 
 	// 创建模拟服务和控制器
 	mockService := &MockWeatherService{}
-	controller := NewWeatherController(mockService)
+	controller := NewWeatherController(mockService, nil)
 
 	// 创建路由
 	router := gin.New()
@@ -103,7 +118,7 @@ func TestWeatherController_GetWeatherByCoordinates(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := &MockWeatherService{}
-	controller := NewWeatherController(mockService)
+	controller := NewWeatherController(mockService, nil)
 
 	router := gin.New()
 	router.GET("/weather/coordinates/:lat/:lon", controller.GetWeatherByCoordinates)
@@ -131,7 +146,7 @@ func TestWeatherController_GetWeatherByCoordinatesInvalidLat(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := &MockWeatherService{}
-	controller := NewWeatherController(mockService)
+	controller := NewWeatherController(mockService, nil)
 
 	router := gin.New()
 	router.GET("/weather/coordinates/:lat/:lon", controller.GetWeatherByCoordinates)
@@ -151,7 +166,7 @@ func TestWeatherController_GetWeatherByCoordinatesOutOfRange(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := &MockWeatherService{}
-	controller := NewWeatherController(mockService)
+	controller := NewWeatherController(mockService, nil)
 
 	router := gin.New()
 	router.GET("/weather/coordinates/:lat/:lon", controller.GetWeatherByCoordinates)
@@ -171,7 +186,7 @@ func TestWeatherController_HealthCheck(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := &MockWeatherService{}
-	controller := NewWeatherController(mockService)
+	controller := NewWeatherController(mockService, nil)
 
 	router := gin.New()
 	router.GET("/health", controller.HealthCheck)
@@ -194,3 +209,133 @@ func TestWeatherController_HealthCheck(t *testing.T) {
 		t.Error("期望健康检查成功")
 	}
 }
+
+func TestWeatherController_GetWeatherByCityPlainText(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockWeatherService{}
+	controller := NewWeatherController(mockService, nil)
+
+	router := gin.New()
+	router.GET("/weather/city/:city", controller.GetWeatherByCity)
+
+	req, _ := http.NewRequest("GET", "/weather/city/北京", nil)
+	req.Header.Set("Accept", "text/plain")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("期望状态码 200，实际为 %d", w.Code)
+	}
+
+	expected := "北京 今天晴天，当前 26°C，体感 27°C，南风 3 级\n"
+	if w.Body.String() != expected {
+		t.Errorf("期望响应为 %q，实际为 %q", expected, w.Body.String())
+	}
+}
+
+func TestWeatherController_GetWeatherByCityGeoJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/weather/city/:city", NewWeatherController(&MockWeatherService{}, nil).GetWeatherByCity)
+
+	for _, tt := range []struct {
+		name   string
+		path   string
+		accept string
+	}{
+		{"format 参数", "/weather/city/Beijing?format=geojson", ""},
+		{"Accept 头", "/weather/city/Beijing", model.GeoJSONMediaType},
+	} {
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != model.GeoJSONMediaType {
+			t.Fatalf("%s: 期望返回 GeoJSON，实际为 %d %s", tt.name, w.Code, w.Header().Get("Content-Type"))
+		}
+		var feature model.Feature
+		if err := json.Unmarshal(w.Body.Bytes(), &feature); err != nil {
+			t.Fatalf("%s: 解析响应失败: %v", tt.name, err)
+		}
+		if feature.Type != "Feature" || feature.Geometry.Coordinates[0] != 116.4074 || feature.Geometry.Coordinates[1] != 39.9042 ||
+			feature.Properties.Name != "Beijing" || feature.Properties.Temperature != 25.5 {
+			t.Errorf("%s: 要素内容不正确: %+v", tt.name, feature)
+		}
+	}
+}
+
+func TestWeatherController_GetWeatherByCityFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockWeatherService{}
+	controller := NewWeatherController(mockService, nil)
+
+	router := gin.New()
+	router.GET("/weather/city/:city", controller.GetWeatherByCity)
+
+	req, _ := http.NewRequest("GET", "/weather/city/Beijing?include=derived&fields=location.name,current.temperature,derived.beaufort", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d", w.Code)
+	}
+
+	expected := `{"success":true,"data":{"current":{"temperature":25.5},"derived":{"beaufort":3},"location":{"name":"Beijing"}}}`
+	if w.Body.String() != expected {
+		t.Errorf("期望响应为 %s，实际为 %s", expected, w.Body.String())
+	}
+
+	// 未知字段和未知数据块返回 400
+	for _, query := range []string{"fields=current.unknown", "include=forecast"} {
+		req, _ = http.NewRequest("GET", "/weather/city/Beijing?"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: 期望状态码 400，实际为 %d", query, w.Code)
+		}
+	}
+}
+
+func TestWeatherController_ProviderOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/plain/city/:city", NewWeatherController(&MockWeatherService{}, nil).GetWeatherByCity)
+	routed, err := routing.New(&routing.File{}, &config.WeatherConfig{Provider: "openweathermap"}, &MockWeatherService{}, nil)
+	if err != nil {
+		t.Fatalf("创建路由失败: %v", err)
+	}
+	router.GET("/routed/city/:city", NewWeatherController(routed, nil).GetWeatherByCity)
+
+	req, _ := http.NewRequest("GET", "/routed/city/Beijing?provider=openweathermap", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data model.WeatherResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.Routing == nil || response.Data.Routing.Provider != "openweathermap" || response.Data.Routing.Rule != routing.RuleOverride {
+		t.Errorf("期望响应中报告指定的数据提供商，实际为 %+v", response.Data.Routing)
+	}
+
+	// 未知的数据提供商，以及未启用路由时指定数据提供商，返回 400
+	for _, path := range []string{"/routed/city/Beijing?provider=unknown", "/plain/city/Beijing?provider=openweathermap"} {
+		req, _ = http.NewRequest("GET", path, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: 期望状态码 400，实际为 %d", path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/resource"
+	"gin-weather/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookController 阈值告警 Webhook 控制器
+type WebhookController struct {
+	manager *webhook.Manager
+}
+
+// NewWebhookController 创建 Webhook 控制器实例
+func NewWebhookController(manager *webhook.Manager) *WebhookController {
+	return &WebhookController{
+		manager: manager,
+	}
+}
+
+// CreateWebhook 注册阈值告警规则
+// @Summary 注册阈值告警规则
+// @Description 当指定位置的当前天气字段满足条件时，向 url 推送带 HMAC-SHA256 签名的通知；secret 只在创建时返回
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body model.Webhook true "告警规则"
+// @Success 201 {object} model.APIResponse{data=model.Webhook}
+// @Failure 400 {object} model.APIResponse{error=model.ErrorResponse}
+// @Failure 500 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/webhooks [post]
+func (hc *WebhookController) CreateWebhook(c *gin.Context) {
+	var hook model.Webhook
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+
+	created, err := hc.manager.Create(&hook)
+	if err != nil {
+		var validationErr *resource.ValidationError
+		if errors.As(err, &validationErr) {
+			respondWithError(c, http.StatusBadRequest, "参数验证失败", err.Error())
+			return
+		}
+		respondWithError(c, http.StatusInternalServerError, "创建 Webhook 失败", err.Error())
+		return
+	}
+
+	c.Header("Location", "/api/v1/webhooks/"+created.ID)
+	respondWithStatus(c, http.StatusCreated, created)
+}
+
+// ListWebhooks 列出全部告警规则
+// @Summary 列出告警规则
+// @Description 按创建时间返回全部告警规则，不包含 secret
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} model.APIResponse{data=[]model.Webhook}
+// @Router /api/v1/webhooks [get]
+func (hc *WebhookController) ListWebhooks(c *gin.Context) {
+	respondWithSuccess(c, hc.manager.List())
+}
+
+// GetWebhook 查询单条告警规则
+// @Summary 查询告警规则
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "规则 ID"
+// @Success 200 {object} model.APIResponse{data=model.Webhook}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/webhooks/{id} [get]
+func (hc *WebhookController) GetWebhook(c *gin.Context) {
+	hook, err := hc.manager.Get(c.Param("id"))
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "Webhook 不存在", err.Error())
+		return
+	}
+	respondWithSuccess(c, hook)
+}
+
+// DeleteWebhook 删除告警规则
+// @Summary 删除告警规则
+// @Tags webhooks
+// @Param id path string true "规则 ID"
+// @Success 204
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/webhooks/{id} [delete]
+func (hc *WebhookController) DeleteWebhook(c *gin.Context) {
+	if err := hc.manager.Delete(c.Param("id")); err != nil {
+		if errors.Is(err, webhook.ErrNotFound) {
+			respondWithError(c, http.StatusNotFound, "Webhook 不存在", err.Error())
+			return
+		}
+		respondWithError(c, http.StatusInternalServerError, "删除 Webhook 失败", err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries 查询告警规则的投递记录
+// @Summary 查询投递记录
+// @Description 返回最近的投递尝试，最新的在前；记录保存在内存中，服务重启后清空
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "规则 ID"
+// @Success 200 {object} model.APIResponse{data=[]model.WebhookDelivery}
+// @Failure 404 {object} model.APIResponse{error=model.ErrorResponse}
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (hc *WebhookController) GetDeliveries(c *gin.Context) {
+	deliveries, err := hc.manager.Deliveries(c.Param("id"))
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "Webhook 不存在", err.Error())
+		return
+	}
+	respondWithSuccess(c, deliveries)
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWebhookController_CRUD(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager, err := webhook.NewManager(&config.WebhooksConfig{Dir: t.TempDir(), EvalInterval: 60, Timeout: 5, DefaultCooldown: 3600, HistorySize: 10}, &MockWeatherService{}, nil)
+	if err != nil {
+		t.Fatalf("创建 Webhook 管理器失败: %v", err)
+	}
+	controller := NewWebhookController(manager)
+
+	router := gin.New()
+	router.POST("/webhooks", controller.CreateWebhook)
+	router.GET("/webhooks", controller.ListWebhooks)
+	router.DELETE("/webhooks/:id", controller.DeleteWebhook)
+	router.GET("/webhooks/:id/deliveries", controller.GetDeliveries)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 未知字段返回 400
+	w := do("POST", "/webhooks", `{"url":"https://example.com/hook","location":{"city":"Shanghai"},"field":"gust","operator":">","threshold":15}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("期望未知字段返回 400，实际为 %d", w.Code)
+	}
+
+	w = do("POST", "/webhooks", `{"url":"https://example.com/hook","location":{"city":"Shanghai"},"field":"wind.gust","operator":">","threshold":15}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("期望状态码 201，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Data model.Webhook `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created.Data.ID == "" || created.Data.Secret == "" {
+		t.Errorf("期望返回规则 ID 和密钥，实际为 %+v", created.Data)
+	}
+
+	w = do("GET", "/webhooks", "")
+	if strings.Contains(w.Body.String(), created.Data.Secret) {
+		t.Error("期望列表中不包含密钥")
+	}
+
+	if w = do("GET", "/webhooks/"+created.Data.ID+"/deliveries", ""); w.Code != http.StatusOK {
+		t.Errorf("期望状态码 200，实际为 %d", w.Code)
+	}
+	if w = do("DELETE", "/webhooks/"+created.Data.ID, ""); w.Code != http.StatusNoContent {
+		t.Errorf("期望状态码 204，实际为 %d", w.Code)
+	}
+	if w = do("DELETE", "/webhooks/"+created.Data.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("期望状态码 404，实际为 %d", w.Code)
+	}
+}
@@ -0,0 +1,275 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/geojson"
+	"gin-weather/internal/live"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsWriteWait 单次写消息的超时时间
+	wsWriteWait = 10 * time.Second
+	// wsMaxMessageSize 客户端消息的最大长度
+	wsMaxMessageSize = 4096
+	// wsSendBuffer 每个连接待发送消息的缓冲数量
+	wsSendBuffer = 64
+)
+
+// WSController WebSocket 订阅控制器
+type WSController struct {
+	scheduler *live.Scheduler
+	config    *config.LiveConfig
+	upgrader  websocket.Upgrader
+}
+
+// NewWSController 创建 WebSocket 控制器实例
+func NewWSController(scheduler *live.Scheduler, cfg *config.LiveConfig) *WSController {
+	return &WSController{
+		scheduler: scheduler,
+		config:    cfg,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+			// 与 CORS 配置保持一致，生产环境中应该限制具体域名
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// wsSession 单个 WebSocket 连接的会话状态
+type wsSession struct {
+	conn      *websocket.Conn
+	scheduler *live.Scheduler
+	maxSubs   int
+	// geoJSON 连接以 format=geojson 或 Accept: application/geo+json 建立时为 true，天气数据以 GeoJSON 点要素推送
+	geoJSON bool
+
+	send chan model.WSServerMessage
+	done chan struct{}
+
+	mu   sync.Mutex
+	subs map[string]*live.Subscription
+}
+
+// ServeWS 处理 WebSocket 连接
+// @Summary WebSocket 订阅多个位置的天气更新
+// @Description 建立 WebSocket 连接后发送 subscribe/unsubscribe 消息订阅城市或坐标，数据变化时服务端推送 weather 消息；以 format=geojson 建立连接时 weather 消息在 feature 字段中携带 GeoJSON 点要素，代替 data 字段
+// @Tags weather
+// @Param format query string false "weather 消息的数据格式" Enums(json, geojson)
+// @Router /api/v1/ws [get]
+func (wc *WSController) ServeWS(c *gin.Context) {
+	conn, err := wc.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 已经写出了错误响应
+		return
+	}
+
+	session := &wsSession{
+		conn:      conn,
+		scheduler: wc.scheduler,
+		maxSubs:   wc.config.MaxSubscriptions,
+		geoJSON:   wantsGeoJSON(c),
+		send:      make(chan model.WSServerMessage, wsSendBuffer),
+		done:      make(chan struct{}),
+		subs:      make(map[string]*live.Subscription),
+	}
+
+	pingInterval := time.Duration(wc.config.HeartbeatInterval) * time.Second
+	go session.writeLoop(pingInterval)
+	session.readLoop(pingInterval * 2)
+}
+
+// readLoop 读取客户端消息，连接断开或超时未收到 pong 时清理会话
+func (s *wsSession) readLoop(pongWait time.Duration) {
+	defer s.close()
+
+	s.conn.SetReadLimit(wsMaxMessageSize)
+	s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg model.WSClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.sendError("", http.StatusBadRequest, "消息格式不正确", err.Error())
+			continue
+		}
+
+		switch msg.Type {
+		case model.WSTypeSubscribe:
+			s.subscribe(&msg)
+		case model.WSTypeUnsubscribe:
+			s.unsubscribe(&msg)
+		default:
+			s.sendError("", http.StatusBadRequest, "消息格式不正确", fmt.Sprintf("未知的消息类型: %s", msg.Type))
+		}
+	}
+}
+
+// writeLoop 串行写出消息并定期发送 ping，调度器关闭时主动关闭连接
+func (s *wsSession) writeLoop(pingInterval time.Duration) {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		s.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.scheduler.Done():
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+			return
+		case msg := <-s.send:
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscribe 处理订阅消息
+func (s *wsSession) subscribe(msg *model.WSClientMessage) {
+	req := msg.WeatherRequest
+	if req.Units == "" {
+		req.Units = "metric"
+	}
+	if req.Lang == "" {
+		req.Lang = "zh_cn"
+	}
+	if err := req.Validate(); err != nil {
+		s.sendError("", http.StatusBadRequest, "参数验证失败", err.Error())
+		return
+	}
+
+	key := live.LocationKey(&req)
+
+	s.mu.Lock()
+	if _, ok := s.subs[key]; ok {
+		s.mu.Unlock()
+		s.enqueue(model.WSServerMessage{Type: model.WSTypeSubscribed, Key: key})
+		return
+	}
+	if len(s.subs) >= s.maxSubs {
+		s.mu.Unlock()
+		s.sendError(key, http.StatusTooManyRequests, "订阅数量超出限制", fmt.Sprintf("每个连接最多订阅 %d 个位置", s.maxSubs))
+		return
+	}
+
+	sub, err := s.scheduler.Subscribe(req)
+	if err != nil {
+		s.mu.Unlock()
+		s.sendError(key, http.StatusServiceUnavailable, "服务不可用", err.Error())
+		return
+	}
+	s.subs[key] = sub
+	s.mu.Unlock()
+
+	s.enqueue(model.WSServerMessage{Type: model.WSTypeSubscribed, Key: key})
+	go s.forward(sub)
+}
+
+// unsubscribe 处理取消订阅消息
+func (s *wsSession) unsubscribe(msg *model.WSClientMessage) {
+	key := msg.Key
+	if key == "" {
+		req := msg.WeatherRequest
+		if req.Units == "" {
+			req.Units = "metric"
+		}
+		if req.Lang == "" {
+			req.Lang = "zh_cn"
+		}
+		key = live.LocationKey(&req)
+	}
+
+	s.mu.Lock()
+	sub, ok := s.subs[key]
+	delete(s.subs, key)
+	s.mu.Unlock()
+
+	if !ok {
+		s.sendError(key, http.StatusNotFound, "订阅不存在", "未订阅该位置")
+		return
+	}
+	sub.Close()
+	s.enqueue(model.WSServerMessage{Type: model.WSTypeUnsubscribed, Key: key})
+}
+
+// forward 将订阅的更新转发给客户端
+func (s *wsSession) forward(sub *live.Subscription) {
+	for update := range sub.Updates() {
+		if update.Err != nil {
+			s.sendError(update.Key, http.StatusInternalServerError, "获取天气信息失败", update.Err.Error())
+			continue
+		}
+		msg := model.WSServerMessage{Type: model.WSTypeWeather, Key: update.Key}
+		if s.geoJSON {
+			feature := geojson.Feature(update.Weather)
+			msg.Feature = &feature
+		} else {
+			msg.Data = update.Weather
+		}
+		s.enqueue(msg)
+	}
+}
+
+// enqueue 将消息放入发送队列，连接关闭后直接丢弃
+func (s *wsSession) enqueue(msg model.WSServerMessage) {
+	select {
+	case s.send <- msg:
+	case <-s.done:
+	}
+}
+
+// sendError 发送错误消息
+func (s *wsSession) sendError(key string, code int, error, message string) {
+	s.enqueue(model.WSServerMessage{
+		Type: model.WSTypeError,
+		Key:  key,
+		Error: &model.ErrorResponse{
+			Error:   error,
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
+// close 取消全部订阅并关闭连接
+func (s *wsSession) close() {
+	close(s.done)
+
+	s.mu.Lock()
+	subs := s.subs
+	s.subs = make(map[string]*live.Subscription)
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.Close()
+	}
+	s.conn.Close()
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/live"
+	"gin-weather/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// dialWS 建立 WebSocket 连接，query 为连接地址的查询字符串
+func dialWS(t *testing.T, maxSubs int, query string) (*websocket.Conn, *live.Scheduler) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	scheduler := live.NewScheduler(&MockWeatherService{}, time.Hour)
+	controller := NewWSController(scheduler, &config.LiveConfig{HeartbeatInterval: 1, MaxSubscriptions: maxSubs})
+
+	router := gin.New()
+	router.GET("/ws", controller.ServeWS)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	t.Cleanup(scheduler.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws"+query, nil)
+	if err != nil {
+		t.Fatalf("建立 WebSocket 连接失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, scheduler
+}
+
+func readWS(t *testing.T, conn *websocket.Conn) model.WSServerMessage {
+	t.Helper()
+	var msg model.WSServerMessage
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("读取消息失败: %v", err)
+	}
+	return msg
+}
+
+func TestWSController_SubscribeAndUnsubscribe(t *testing.T) {
+	conn, _ := dialWS(t, 5, "")
+
+	conn.WriteJSON(map[string]string{"type": "subscribe", "city": "Beijing"})
+
+	// subscribed 与首次 weather 推送的先后顺序不固定
+	var subscribed, weather *model.WSServerMessage
+	for subscribed == nil || weather == nil {
+		msg := readWS(t, conn)
+		switch msg.Type {
+		case model.WSTypeSubscribed:
+			subscribed = &msg
+		case model.WSTypeWeather:
+			weather = &msg
+		default:
+			t.Fatalf("收到意外的消息类型: %s", msg.Type)
+		}
+	}
+	if weather.Data == nil || weather.Data.Location.Name != "Beijing" {
+		t.Errorf("期望收到 Beijing 的天气数据，实际为 %+v", weather.Data)
+	}
+	if subscribed.Key != weather.Key {
+		t.Errorf("期望推送的位置标识为 %s，实际为 %s", subscribed.Key, weather.Key)
+	}
+
+	conn.WriteJSON(map[string]string{"type": "unsubscribe", "key": subscribed.Key})
+	if msg := readWS(t, conn); msg.Type != model.WSTypeUnsubscribed || msg.Key != subscribed.Key {
+		t.Errorf("期望收到 unsubscribed 消息，实际为 %+v", msg)
+	}
+}
+
+func TestWSController_GeoJSON(t *testing.T) {
+	conn, _ := dialWS(t, 5, "?format=geojson")
+
+	conn.WriteJSON(map[string]string{"type": "subscribe", "city": "Beijing"})
+	for {
+		msg := readWS(t, conn)
+		if msg.Type != model.WSTypeWeather {
+			continue
+		}
+		if msg.Data != nil || msg.Feature == nil {
+			t.Fatalf("期望以 feature 字段推送 GeoJSON，实际为 %+v", msg)
+		}
+		if got := msg.Feature.Geometry.Coordinates; got[0] != 116.4074 || got[1] != 39.9042 || msg.Feature.Properties.Name != "Beijing" {
+			t.Errorf("GeoJSON 点要素不正确: %+v", msg.Feature)
+		}
+		return
+	}
+}
+
+func TestWSController_SubscriptionLimit(t *testing.T) {
+	conn, _ := dialWS(t, 1, "")
+
+	conn.WriteJSON(map[string]string{"type": "subscribe", "city": "Beijing"})
+	conn.WriteJSON(map[string]string{"type": "subscribe", "city": "Shanghai"})
+
+	for {
+		msg := readWS(t, conn)
+		if msg.Type != model.WSTypeError {
+			continue
+		}
+		if msg.Error == nil || msg.Error.Code != 429 {
+			t.Errorf("期望错误码 429，实际为 %+v", msg.Error)
+		}
+		return
+	}
+}
+
+func TestWSController_InvalidMessage(t *testing.T) {
+	conn, _ := dialWS(t, 5, "")
+
+	conn.WriteJSON(map[string]string{"type": "subscribe"})
+	if msg := readWS(t, conn); msg.Type != model.WSTypeError || msg.Error.Code != 400 {
+		t.Errorf("期望缺少位置时返回 400 错误，实际为 %+v", msg)
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte("not json"))
+	if msg := readWS(t, conn); msg.Type != model.WSTypeError || msg.Error.Code != 400 {
+		t.Errorf("期望消息格式错误时返回 400 错误，实际为 %+v", msg)
+	}
+}
+
+func TestWSController_ClosedOnShutdown(t *testing.T) {
+	conn, scheduler := dialWS(t, 5, "")
+
+	scheduler.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("期望调度器关闭后收到 going away 关闭帧，实际为 %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+// Package derived 根据基础气象要素计算露点、体感指数和风级等衍生指标
+package derived
+
+import (
+	"math"
+
+	"gin-weather/internal/model"
+)
+
+// beaufortLimits 蒲福风级 1-17 级的风速下限（m/s）
+var beaufortLimits = []float64{0.3, 1.6, 3.4, 5.5, 8.0, 10.8, 13.9, 17.2, 20.8, 24.5, 28.5, 32.7, 37.0, 41.5, 46.2, 51.0, 56.1}
+
+// Compute 计算衍生指标，结果的温度单位与 units 一致
+func Compute(current *model.Current, units string) *model.Derived {
+	tempC := ToCelsius(current.Temperature, units)
+	windMS := ToMetersPerSecond(current.Wind.Speed, units)
+	humidity := float64(current.Humidity)
+
+	return &model.Derived{
+		DewPoint:  round1(FromCelsius(DewPoint(tempC, humidity), units)),
+		HeatIndex: round1(FromCelsius(HeatIndex(tempC, humidity), units)),
+		WindChill: round1(FromCelsius(WindChill(tempC, windMS), units)),
+		Beaufort:  Beaufort(windMS),
+	}
+}
+
+// DewPoint 使用 Magnus 公式计算露点温度（°C）
+func DewPoint(tempC, humidity float64) float64 {
+	if humidity <= 0 {
+		return math.NaN()
+	}
+	const a, b = 17.62, 243.12
+	gamma := math.Log(humidity/100) + a*tempC/(b+tempC)
+	return b * gamma / (a - gamma)
+}
+
+// HeatIndex 使用美国国家气象局的回归公式计算炎热指数（°C），气温较低时返回气温本身
+func HeatIndex(tempC, humidity float64) float64 {
+	t := tempC*9/5 + 32
+	hi := 0.5 * (t + 61 + (t-68)*1.2 + humidity*0.094)
+	if (hi+t)/2 >= 80 {
+		hi = -42.379 + 2.04901523*t + 10.14333127*humidity -
+			0.22475541*t*humidity - 0.00683783*t*t -
+			0.05481717*humidity*humidity + 0.00122874*t*t*humidity +
+			0.00085282*t*humidity*humidity - 0.00000199*t*t*humidity*humidity
+		if humidity < 13 && t >= 80 && t <= 112 {
+			hi -= (13 - humidity) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+		} else if humidity > 85 && t >= 80 && t <= 87 {
+			hi += (humidity - 85) / 10 * (87 - t) / 5
+		}
+	}
+	if hi < t {
+		return tempC
+	}
+	return (hi - 32) * 5 / 9
+}
+
+// WindChill 计算风寒温度（°C），仅在气温不高于 10°C 且风速大于 4.8 km/h 时有效，否则返回气温本身
+func WindChill(tempC, windMS float64) float64 {
+	v := windMS * 3.6
+	if tempC > 10 || v <= 4.8 {
+		return tempC
+	}
+	p := math.Pow(v, 0.16)
+	return 13.12 + 0.6215*tempC - 11.37*p + 0.3965*tempC*p
+}
+
+// Beaufort 将风速（m/s）换算为蒲福风级（0-17）
+func Beaufort(speed float64) int {
+	level := 0
+	for level < len(beaufortLimits) && speed >= beaufortLimits[level] {
+		level++
+	}
+	return level
+}
+
+// ToCelsius 将指定单位系统下的温度换算为摄氏度
+func ToCelsius(value float64, units string) float64 {
+	switch units {
+	case "imperial":
+		return (value - 32) * 5 / 9
+	case "standard":
+		return value - 273.15
+	default:
+		return value
+	}
+}
+
+// FromCelsius 将摄氏度换算为指定单位系统下的温度
+func FromCelsius(value float64, units string) float64 {
+	switch units {
+	case "imperial":
+		return value*9/5 + 32
+	case "standard":
+		return value + 273.15
+	default:
+		return value
+	}
+}
+
+// ToMetersPerSecond 将指定单位系统下的风速换算为 m/s（imperial 单位系统下为英里/小时）
+func ToMetersPerSecond(speed float64, units string) float64 {
+	if units == "imperial" {
+		return speed * 0.44704
+	}
+	return speed
+}
+
+// round1 保留一位小数，值不是有限数字（如湿度为 0 时的露点）时返回 nil，表示无法计算
+func round1(value float64) *float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	rounded := math.Round(value*10) / 10
+	return &rounded
+}
@@ -0,0 +1,65 @@
+package derived
+
+import (
+	"math"
+	"testing"
+
+	"gin-weather/internal/model"
+)
+
+func TestDewPoint(t *testing.T) {
+	if got := DewPoint(25, 60); math.Abs(got-16.7) > 0.1 {
+		t.Errorf("期望露点约为 16.7°C，实际为 %.2f", got)
+	}
+}
+
+func TestHeatIndex(t *testing.T) {
+	// 32°C、70% 湿度时炎热指数约为 41°C
+	if got := HeatIndex(32, 70); math.Abs(got-40.7) > 0.5 {
+		t.Errorf("期望炎热指数约为 40.7°C，实际为 %.2f", got)
+	}
+	if got := HeatIndex(15, 50); got != 15 {
+		t.Errorf("期望气温较低时返回气温本身，实际为 %.2f", got)
+	}
+}
+
+func TestWindChill(t *testing.T) {
+	// -10°C、风速 30 km/h 时风寒温度约为 -19.5°C
+	if got := WindChill(-10, 30/3.6); math.Abs(got+19.5) > 0.2 {
+		t.Errorf("期望风寒温度约为 -19.5°C，实际为 %.2f", got)
+	}
+	if got := WindChill(20, 10); got != 20 {
+		t.Errorf("期望气温较高时返回气温本身，实际为 %.2f", got)
+	}
+}
+
+func TestBeaufort(t *testing.T) {
+	tests := map[float64]int{0: 0, 0.3: 1, 3.3: 2, 3.4: 3, 10.8: 6, 32.7: 12, 60: 17}
+	for speed, want := range tests {
+		if got := Beaufort(speed); got != want {
+			t.Errorf("风速 %.1f m/s: 期望 %d 级，实际为 %d 级", speed, want, got)
+		}
+	}
+}
+
+func TestComputeImperial(t *testing.T) {
+	current := &model.Current{Temperature: 77, Humidity: 60, Wind: model.Wind{Speed: 9.2}}
+	got := Compute(current, "imperial")
+	if got.DewPoint == nil || math.Abs(*got.DewPoint-62.1) > 0.2 {
+		t.Errorf("期望露点约为 62.1°F，实际为 %v", got.DewPoint)
+	}
+	if got.Beaufort != 3 {
+		t.Errorf("期望 3 级风，实际为 %d 级", got.Beaufort)
+	}
+}
+
+func TestComputeMissingDewPoint(t *testing.T) {
+	// 湿度为 0 时露点无法计算，应省略而不是报告为 0°C
+	got := Compute(&model.Current{Temperature: 20, Humidity: 0}, "metric")
+	if got.DewPoint != nil {
+		t.Errorf("期望露点为 nil，实际为 %v", *got.DewPoint)
+	}
+	if got.HeatIndex == nil || *got.HeatIndex != 20 {
+		t.Errorf("期望炎热指数为 20，实际为 %v", got.HeatIndex)
+	}
+}
@@ -0,0 +1,132 @@
+package digest
+
+import (
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// forecastStep 预报时段长度，与 OpenWeatherMap 5 天预报的 3 小时间隔一致
+const forecastStep = 3 * time.Hour
+
+// 降水概率对应的风险等级分界
+const (
+	moderateRiskPop = 0.2
+	highRiskPop     = 0.5
+)
+
+// build 查询订阅中全部位置的当前天气和预报，生成简报
+func (m *Manager) build(sub *model.DigestSubscription, scheduledAt time.Time) *model.Digest {
+	items := make([]model.DigestItem, len(sub.Locations))
+	sem := make(chan struct{}, fetchConcurrency)
+	var wg sync.WaitGroup
+
+	for i := range sub.Locations {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			items[i] = m.buildItem(&sub.Locations[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return &model.Digest{
+		SubscriptionID: sub.ID,
+		Title:          title(sub),
+		ScheduledAt:    scheduledAt,
+		Units:          sub.Units,
+		Lang:           sub.Lang,
+		Items:          items,
+	}
+}
+
+// buildItem 生成单个位置的简报内容，预报获取失败时退回到只使用当前天气
+func (m *Manager) buildItem(req *model.WeatherRequest) model.DigestItem {
+	item := model.DigestItem{Name: req.City}
+
+	weather, err := service.Fetch(m.weatherService, req)
+	if err != nil {
+		if item.Name == "" {
+			item.Name = req.Describe()
+		}
+		item.Error = err.Error()
+		return item
+	}
+	item.Name = weather.Location.Name
+	item.Weather = weather
+
+	forecast, err := service.FetchForecast(m.weatherService, req)
+	if err != nil && !errors.Is(err, service.ErrForecastUnsupported) {
+		log.Printf("简报获取天气预报失败 (%s): %v", req.Describe(), err)
+	}
+	summarize(&item, weather, forecast, time.Now())
+	return item
+}
+
+// summarize 计算当地今天剩余时段的最高、最低温度和降水风险。
+// 有预报时取当前温度与今天各预报时段的极值；没有预报时退回到当前观测中的最高、最低温度，
+// 降水风险只能根据是否正在降水判断
+func summarize(item *model.DigestItem, weather *model.WeatherResponse, forecast *model.Forecast, now time.Time) {
+	current := &weather.Current
+	raining := (current.Rain != nil && (current.Rain.OneHour > 0 || current.Rain.ThreeHour > 0)) ||
+		(current.Snow != nil && (current.Snow.OneHour > 0 || current.Snow.ThreeHour > 0))
+
+	if forecast == nil {
+		item.High = math.Max(current.TempMax, current.Temperature)
+		item.Low = math.Min(current.TempMin, current.Temperature)
+		item.PrecipRisk = model.PrecipRiskUnknown
+		if raining {
+			item.PrecipRisk = model.PrecipRiskHigh
+		}
+		return
+	}
+
+	item.Forecast = true
+	item.High = current.Temperature
+	item.Low = current.Temperature
+
+	loc := time.FixedZone("", weather.Location.Timezone)
+	local := now.In(loc)
+	dayEnd := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+
+	pop := 0.0
+	for _, f := range forecast.Items {
+		// 只统计与今天剩余时间有重叠的时段
+		if !f.Time.Before(dayEnd) || !f.Time.Add(forecastStep).After(now) {
+			continue
+		}
+		item.High = math.Max(item.High, f.TempMax)
+		item.Low = math.Min(item.Low, f.TempMin)
+		pop = math.Max(pop, f.PrecipProbability)
+	}
+
+	percent := math.Round(pop * 100)
+	item.PrecipProbability = &percent
+	switch {
+	case raining || pop >= highRiskPop:
+		item.PrecipRisk = model.PrecipRiskHigh
+	case pop >= moderateRiskPop:
+		item.PrecipRisk = model.PrecipRiskModerate
+	default:
+		item.PrecipRisk = model.PrecipRiskLow
+	}
+}
+
+// title 简报标题，未命名时使用默认标题
+func title(sub *model.DigestSubscription) string {
+	if sub.Name != "" {
+		return sub.Name
+	}
+	if strings.HasPrefix(sub.Lang, "en") {
+		return "Weather digest"
+	}
+	return "天气简报"
+}
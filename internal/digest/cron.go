@@ -0,0 +1,203 @@
+package digest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 解析后的五段式 cron 表达式：分 时 日 月 周
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny、dowAny 记录日和周字段是否以 * 开头，两者都受限时按标准 cron 语义取并集
+	domAny, dowAny bool
+}
+
+// cronField 单个字段的取值范围
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	dowNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+
+	cronFields = [5]cronField{
+		{name: "分钟", min: 0, max: 59},
+		{name: "小时", min: 0, max: 23},
+		{name: "日", min: 1, max: 31},
+		{name: "月", min: 1, max: 12, names: monthNames},
+		// 周日可以写成 0 或 7
+		{name: "星期", min: 0, max: 7, names: dowNames},
+	}
+
+	// descriptors 常用的简写
+	descriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// ParseSchedule 解析 cron 表达式，支持 *、列表（1,3）、范围（1-5）、步长（*/15、8-18/2）、
+// 月份和星期的英文缩写以及 @daily 等简写
+func ParseSchedule(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron 表达式必须包含 5 个字段（分 时 日 月 周），实际为 %d 个", len(parts))
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseField(part, &cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+
+	// 统一把周日表示为 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &Schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: strings.HasPrefix(parts[2], "*"),
+		dowAny: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseField 把一个字段解析为取值位图
+func parseField(field string, spec *cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s字段的步长 %q 无效", spec.name, item[i+1:])
+			}
+			rangePart, step = item[:i], n
+		}
+
+		lo, hi := spec.min, spec.max
+		if rangePart != "*" {
+			var err error
+			if i := strings.Index(rangePart, "-"); i >= 0 {
+				if lo, err = fieldValue(rangePart[:i], spec); err != nil {
+					return 0, err
+				}
+				if hi, err = fieldValue(rangePart[i+1:], spec); err != nil {
+					return 0, err
+				}
+				if lo > hi {
+					return 0, fmt.Errorf("%s字段的范围 %q 起点大于终点", spec.name, rangePart)
+				}
+			} else {
+				if lo, err = fieldValue(rangePart, spec); err != nil {
+					return 0, err
+				}
+				// 单个值加步长表示从该值到最大值，如 5/15
+				hi = lo
+				if step > 1 {
+					hi = spec.max
+				}
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// fieldValue 解析单个数值或名称
+func fieldValue(s string, spec *cronField) (int, error) {
+	if v, ok := spec.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("%s字段的取值 %q 无效，范围为 %d-%d", spec.name, s, spec.min, spec.max)
+	}
+	return v, nil
+}
+
+// maxSearchYears 查找下一次执行时间时最多向后搜索的年数，超过时认为表达式永远不会触发（如 2 月 30 日）
+const maxSearchYears = 5
+
+// Next 返回 t 之后（不含 t）在 loc 时区内第一个满足表达式的时间，找不到时返回零值。
+// 夏令时切换时被跳过的时刻不会执行，重复的时刻只执行一次
+func (s *Schedule) Next(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + maxSearchYears
+
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = advance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			next := t.Add(time.Minute)
+			// 夏令时结束时时钟回拨，跳过重复的时段
+			_, before := t.Zone()
+			if _, after := next.Zone(); after < before {
+				next = next.Add(time.Duration(before-after) * time.Second)
+			}
+			t = next
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// advance 跳到下一个候选时间。夏令时开始时目标时刻可能不存在，time.Date 会把它换算到更早的时间，
+// 此时改为按绝对时间前进到下一个整点
+func advance(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
+
+// dayMatches 判断日期是否满足日和星期字段
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
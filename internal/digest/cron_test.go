@@ -0,0 +1,73 @@
+package digest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		expr string
+		loc  *time.Location
+		from time.Time
+		want time.Time
+	}{
+		// 每天早上 7 点，按订阅时区计算
+		{"0 7 * * *", shanghai, time.Date(2024, 3, 1, 6, 59, 0, 0, shanghai), time.Date(2024, 3, 1, 7, 0, 0, 0, shanghai)},
+		{"0 7 * * *", shanghai, time.Date(2024, 3, 1, 7, 0, 0, 0, shanghai), time.Date(2024, 3, 2, 7, 0, 0, 0, shanghai)},
+		// 工作日：2024-03-01 是周五，下一次是周一
+		{"0 7 * * 1-5", shanghai, time.Date(2024, 3, 1, 8, 0, 0, 0, shanghai), time.Date(2024, 3, 4, 7, 0, 0, 0, shanghai)},
+		{"30 8 * * mon,fri", time.UTC, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 4, 8, 30, 0, 0, time.UTC)},
+		{"*/15 9-17/4 * * *", time.UTC, time.Date(2024, 3, 1, 9, 50, 0, 0, time.UTC), time.Date(2024, 3, 1, 13, 0, 0, 0, time.UTC)},
+		// 日和星期都受限时取并集
+		{"0 0 13 * 5", time.UTC, time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 9, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.UTC, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.UTC, time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// 夏令时开始当天 2:30 不存在，跳到下一天
+		{"30 2 * * *", newYork, time.Date(2024, 3, 10, 0, 0, 0, 0, newYork), time.Date(2024, 3, 11, 2, 30, 0, 0, newYork)},
+		// 从 UTC 时间换算到订阅时区
+		{"0 7 * * *", newYork, time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 7, 0, 0, 0, newYork)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: 解析失败: %v", tt.expr, err)
+		}
+		got := schedule.Next(tt.from, tt.loc)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s 在 %v 之后: 期望 %v，实际为 %v", tt.expr, tt.from, tt.want, got)
+		}
+	}
+}
+
+func TestScheduleNextDSTFallBack(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	schedule, _ := ParseSchedule("30 1 * * *")
+
+	// 夏令时结束当天 1:30 出现两次，只执行第一次
+	first := schedule.Next(time.Date(2024, 11, 3, 0, 0, 0, 0, newYork), newYork)
+	second := schedule.Next(first, newYork)
+	if first.Hour() != 1 || first.Minute() != 30 || second.Day() != 4 {
+		t.Errorf("期望 11 月 3 日 1:30 之后是 11 月 4 日，实际为 %v、%v", first, second)
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "0 24 * * *", "0 0 0 * *", "0 0 * 13 *", "5-1 * * * *", "*/0 * * * *", "0 7 * * funday"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("%q: 期望返回错误", expr)
+		}
+	}
+
+	schedule, err := ParseSchedule("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if next := schedule.Next(time.Now(), time.UTC); !next.IsZero() {
+		t.Errorf("期望 2 月 30 日永远不会触发，实际为 %v", next)
+	}
+}
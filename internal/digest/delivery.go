@@ -0,0 +1,107 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/webhook"
+)
+
+// send 生成简报并发送到订阅的全部目标，结果记录为订阅的最近一次发送
+func (m *Manager) send(sub model.DigestSubscription, scheduledAt time.Time, loc *time.Location) {
+	defer m.wg.Done()
+
+	digest := m.build(&sub, scheduledAt)
+	run := &model.DigestRun{ScheduledAt: scheduledAt, Success: true}
+
+	for _, name := range sub.Channels {
+		if _, err := m.notifier.SendDigest(m.ctx, name, digest, loc); err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if sub.URL != "" {
+		if err := m.post(&sub, digest); err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("url: %v", err))
+		}
+	}
+	run.Success = len(run.Errors) == 0
+	run.SentAt = time.Now()
+
+	if !run.Success {
+		log.Printf("简报订阅 %s 发送失败: %v", sub.ID, run.Errors)
+	}
+
+	err := m.store.Update(func(subs map[string]*model.DigestSubscription) error {
+		// 订阅已被删除时不再记录
+		if stored, ok := subs[sub.ID]; ok {
+			stored.LastRun = run
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("保存简报订阅 %s 的发送结果失败: %v", sub.ID, err)
+	}
+}
+
+// post 把简报推送到订阅的 Webhook 地址，签名方式与阈值告警相同，失败时按指数退避重试
+func (m *Manager) post(sub *model.DigestSubscription, digest *model.Digest) error {
+	body, err := json.Marshal(model.DigestPayload{Event: model.DigestEvent, Digest: digest})
+	if err != nil {
+		return err
+	}
+	// 同一次计划发送的投递 ID 固定，接收方可以据此去重
+	deliveryID := sub.ID + "-" + strconv.FormatInt(digest.ScheduledAt.Unix(), 10)
+
+	backoff := m.backoff
+	for attempt := 0; ; attempt++ {
+		err = m.postOnce(sub, deliveryID, body)
+		if err == nil || attempt >= m.config.MaxRetries {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-m.ctx.Done():
+			return err
+		}
+	}
+}
+
+// postOnce 发送一次推送请求
+func (m *Manager) postOnce(sub *model.DigestSubscription, deliveryID string, body []byte) error {
+	ctx, cancel := context.WithTimeout(m.ctx, time.Duration(m.config.Timeout)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gin-weather-webhook/1.0")
+	req.Header.Set(webhook.HeaderEvent, model.DigestEvent)
+	req.Header.Set(webhook.HeaderID, sub.ID)
+	req.Header.Set(webhook.HeaderDelivery, deliveryID)
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(sub.Secret, timestamp, body))
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("接收方返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
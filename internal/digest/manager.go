@@ -0,0 +1,341 @@
+// Package digest 实现定时天气简报：订阅按 cron 表达式在各自的时区内定时汇总多个位置的
+// 当前天气、当天最高最低温度和降水风险，通过通知渠道或带签名的 Webhook 推送。
+//
+// 订阅保存在本地目录中，多个副本共享同一目录时，每次计划发送先独占创建认领文件，
+// 只有认领成功的副本会发送，因此同一份简报不会重复发出
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	// 内嵌时区数据库，运行环境没有安装 tzdata 时也能解析订阅的时区
+	_ "time/tzdata"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/notify"
+	"gin-weather/internal/resource"
+	"gin-weather/internal/service"
+)
+
+// ErrNotFound 订阅不存在
+var ErrNotFound = errors.New("简报订阅不存在")
+
+const (
+	// fetchConcurrency 生成一份简报时并发查询天气的位置数上限
+	fetchConcurrency = 4
+	// claimRetention 认领记录的保留时间
+	claimRetention = 7 * 24 * time.Hour
+)
+
+// Manager 简报订阅管理与定时发送
+type Manager struct {
+	config         *config.DigestsConfig
+	store          *Store
+	weatherService service.WeatherService
+	notifier       *notify.Notifier
+	client         *http.Client
+	backoff        time.Duration
+	owner          string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager 创建管理器，notifier 为 nil 时订阅不能使用通知渠道
+func NewManager(cfg *config.DigestsConfig, weatherService service.WeatherService, notifier *notify.Notifier) (*Manager, error) {
+	store, err := NewStore(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	// 提前检查已有订阅文件能否解析
+	if _, err := store.Load(); err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		config:         cfg,
+		store:          store,
+		weatherService: weatherService,
+		notifier:       notifier,
+		client:         &http.Client{},
+		backoff:        time.Duration(cfg.RetryBackoff) * time.Second,
+		owner:          fmt.Sprintf("%s/%d", hostname, os.Getpid()),
+		ctx:            ctx,
+		cancel:         cancel,
+	}, nil
+}
+
+// Start 启动定时检查
+func (m *Manager) Start() {
+	m.wg.Add(1)
+	go m.run()
+}
+
+// Stop 停止定时检查并等待进行中的发送结束，等待中的重试会被放弃
+func (m *Manager) Stop(ctx context.Context) error {
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Create 校验并保存订阅，推送到 url 且未提供密钥时自动生成；返回值包含密钥
+func (m *Manager) Create(sub *model.DigestSubscription) (*model.DigestSubscription, error) {
+	if err := m.validate(sub); err != nil {
+		return nil, err
+	}
+
+	id, err := resource.NewID()
+	if err != nil {
+		return nil, err
+	}
+	if sub.URL != "" && sub.Secret == "" {
+		if sub.Secret, err = resource.NewSecret(); err != nil {
+			return nil, err
+		}
+	}
+	sub.ID = id
+	sub.CreatedAt = time.Now()
+	sub.NextRunAt = nil
+	sub.LastRun = nil
+
+	err = m.store.Update(func(subs map[string]*model.DigestSubscription) error {
+		subs[id] = sub
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := *sub
+	m.withNextRun(&created, time.Now())
+	return &created, nil
+}
+
+// List 按创建时间返回全部订阅，不包含密钥
+func (m *Manager) List() ([]model.DigestSubscription, error) {
+	subs, err := m.store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := make([]model.DigestSubscription, 0, len(subs))
+	for _, sub := range subs {
+		copied := redact(sub)
+		m.withNextRun(&copied, now)
+		list = append(list, copied)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list, nil
+}
+
+// Get 返回单个订阅，不包含密钥
+func (m *Manager) Get(id string) (*model.DigestSubscription, error) {
+	subs, err := m.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	sub, ok := subs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := redact(sub)
+	m.withNextRun(&copied, time.Now())
+	return &copied, nil
+}
+
+// Delete 删除订阅
+func (m *Manager) Delete(id string) error {
+	return m.store.Update(func(subs map[string]*model.DigestSubscription) error {
+		if _, ok := subs[id]; !ok {
+			return ErrNotFound
+		}
+		delete(subs, id)
+		return nil
+	})
+}
+
+// Preview 立即生成订阅的简报内容但不发送
+func (m *Manager) Preview(id string) (*model.Digest, error) {
+	subs, err := m.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	sub, ok := subs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return m.build(sub, time.Now()), nil
+}
+
+// run 按固定间隔检查到期的订阅
+func (m *Manager) run() {
+	defer m.wg.Done()
+
+	interval := time.Duration(m.config.CheckInterval) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 从一个检查间隔之前开始，重启前后到期的发送不会被漏掉，已发送的会被认领记录挡住
+	last := time.Now().Add(-interval)
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.check(last, now)
+			last = now
+		}
+	}
+}
+
+// check 发送计划时间落在 (from, to] 内的订阅，同一订阅错过多次时只发送最近的一次
+func (m *Manager) check(from, to time.Time) {
+	subs, err := m.store.Load()
+	if err != nil {
+		log.Printf("读取简报订阅失败: %v", err)
+		return
+	}
+
+	for _, sub := range subs {
+		schedule, loc, err := parseTiming(sub)
+		if err != nil {
+			log.Printf("简报订阅 %s 的计划无效: %v", sub.ID, err)
+			continue
+		}
+
+		var due time.Time
+		for next := schedule.Next(from, loc); !next.IsZero() && !next.After(to); next = schedule.Next(next, loc) {
+			due = next
+		}
+		if due.IsZero() {
+			continue
+		}
+
+		claimed, err := m.store.Claim(sub.ID, due, m.owner)
+		if err != nil {
+			log.Printf("简报订阅 %s: %v", sub.ID, err)
+		}
+		if !claimed {
+			continue
+		}
+
+		m.wg.Add(1)
+		go m.send(*sub, due, loc)
+	}
+
+	if err := m.store.PruneClaims(to.Add(-claimRetention)); err != nil {
+		log.Printf("清理简报认领记录失败: %v", err)
+	}
+}
+
+// withNextRun 填充下一次发送时间
+func (m *Manager) withNextRun(sub *model.DigestSubscription, now time.Time) {
+	schedule, loc, err := parseTiming(sub)
+	if err != nil {
+		return
+	}
+	if next := schedule.Next(now, loc); !next.IsZero() {
+		sub.NextRunAt = &next
+	}
+}
+
+// validate 校验订阅并填充默认值
+func (m *Manager) validate(sub *model.DigestSubscription) error {
+	if sub.URL == "" && len(sub.Channels) == 0 {
+		return resource.Invalidf("url 和 channels 至少需要提供一个")
+	}
+	if sub.URL != "" {
+		target, err := url.Parse(sub.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return resource.Invalidf("url 必须是 http 或 https 地址")
+		}
+	}
+	for _, name := range sub.Channels {
+		if m.notifier == nil || !m.notifier.Has(name) {
+			return resource.Invalidf("通知渠道 %s 不存在", name)
+		}
+	}
+
+	if sub.Timezone == "" {
+		sub.Timezone = "UTC"
+	}
+	schedule, loc, err := parseTiming(sub)
+	if err != nil {
+		return resource.Invalidf("%v", err)
+	}
+	if schedule.Next(time.Now(), loc).IsZero() {
+		return resource.Invalidf("cron 表达式 %q 永远不会触发", sub.Schedule)
+	}
+
+	if len(sub.Locations) == 0 {
+		return resource.Invalidf("locations 不能为空")
+	}
+	if len(sub.Locations) > m.config.MaxLocations {
+		return resource.Invalidf("locations 最多包含 %d 个位置", m.config.MaxLocations)
+	}
+	if sub.Units == "" {
+		sub.Units = "metric"
+	}
+	if sub.Lang == "" {
+		sub.Lang = "zh_cn"
+	}
+	if !model.ValidUnits(sub.Units) {
+		return resource.Invalidf("单位系统必须是 metric、imperial 或 standard")
+	}
+	// 简报中的温度统一使用订阅的单位和语言
+	for i := range sub.Locations {
+		sub.Locations[i].Units = sub.Units
+		sub.Locations[i].Lang = sub.Lang
+		if err := sub.Locations[i].Validate(); err != nil {
+			return resource.Invalidf("第 %d 个位置无效: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// parseTiming 解析订阅的 cron 表达式和时区
+func parseTiming(sub *model.DigestSubscription) (*Schedule, *time.Location, error) {
+	schedule, err := ParseSchedule(sub.Schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("无效的时区 %q", sub.Timezone)
+	}
+	return schedule, loc, nil
+}
+
+// redact 返回去掉密钥的副本
+func redact(sub *model.DigestSubscription) model.DigestSubscription {
+	copied := *sub
+	copied.Secret = ""
+	return copied
+}
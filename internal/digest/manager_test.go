@@ -0,0 +1,236 @@
+package digest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/resource"
+	"gin-weather/internal/webhook"
+)
+
+// mockService 模拟天气服务，城市名为 bad 时返回错误；forecast 为 nil 时不支持预报
+type mockService struct {
+	forecast []model.ForecastItem
+}
+
+func (m *mockService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	if city == "bad" {
+		return nil, errors.New("city not found")
+	}
+	return &model.WeatherResponse{
+		Location: model.Location{Name: city, Timezone: 8 * 3600},
+		Current: model.Current{
+			Temperature: 20,
+			TempMin:     19,
+			TempMax:     21,
+			Humidity:    60,
+			Weather:     []model.Weather{{Main: "Clouds", Description: "多云"}},
+		},
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+func (m *mockService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity(fmt.Sprintf("%.2f,%.2f", lat, lon), units, lang)
+}
+
+func (m *mockService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity(city, units, lang)
+}
+
+func (m *mockService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCoordinates(lat, lon, units, lang)
+}
+
+// forecastService 在 mockService 的基础上支持预报
+type forecastService struct {
+	mockService
+}
+
+func (m *forecastService) GetForecastByCity(city, units, lang string) (*model.Forecast, error) {
+	return &model.Forecast{Items: m.forecast}, nil
+}
+
+func (m *forecastService) GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error) {
+	return m.GetForecastByCity("", units, lang)
+}
+
+func newTestManager(t *testing.T, dir string) *Manager {
+	t.Helper()
+	m, err := NewManager(&config.DigestsConfig{Dir: dir, CheckInterval: 60, Timeout: 5, MaxRetries: 1, MaxLocations: 3}, &mockService{}, nil)
+	if err != nil {
+		t.Fatalf("创建简报管理器失败: %v", err)
+	}
+	return m
+}
+
+func TestManager_SendOnceAcrossReplicas(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	var bodies [][]byte
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		// 第一次请求失败，验证重试
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	replicaA := newTestManager(t, dir)
+	replicaB := newTestManager(t, dir)
+
+	created, err := replicaA.Create(&model.DigestSubscription{
+		Name:      "早间天气",
+		Schedule:  "0 7 * * *",
+		Timezone:  "Asia/Shanghai",
+		Locations: []model.WeatherRequest{{City: "Beijing"}, {City: "bad"}},
+		URL:       server.URL,
+	})
+	if err != nil {
+		t.Fatalf("创建订阅失败: %v", err)
+	}
+	if created.Secret == "" || created.NextRunAt == nil {
+		t.Fatalf("期望返回密钥和下一次发送时间，实际为 %+v", created)
+	}
+
+	// 另一个副本能看到新订阅
+	if list, _ := replicaB.List(); len(list) != 1 || list[0].Secret != "" {
+		t.Fatalf("期望另一个副本读到 1 个不含密钥的订阅，实际为 %+v", list)
+	}
+
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	scheduled := time.Date(2024, 3, 1, 7, 0, 0, 0, shanghai)
+	from, to := scheduled.Add(-30*time.Second), scheduled.Add(30*time.Second)
+	replicaA.check(from, to)
+	replicaB.check(from, to)
+	replicaB.check(from, to)
+
+	// 等待后台发送完成
+	replicaA.wg.Wait()
+	replicaB.wg.Wait()
+
+	if len(requests) != 1 {
+		t.Fatalf("期望只推送 1 次，实际为 %d", len(requests))
+	}
+	req := requests[0]
+	timestamp, _ := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	if req.Header.Get(webhook.HeaderSignature) != webhook.Sign(created.Secret, timestamp, bodies[0]) {
+		t.Error("签名不正确")
+	}
+	if req.Header.Get(webhook.HeaderEvent) != model.DigestEvent {
+		t.Errorf("期望事件类型 %s，实际为 %s", model.DigestEvent, req.Header.Get(webhook.HeaderEvent))
+	}
+
+	var payload model.DigestPayload
+	if err := json.Unmarshal(bodies[0], &payload); err != nil {
+		t.Fatalf("解析推送内容失败: %v", err)
+	}
+	if len(payload.Items) != 2 || payload.Items[0].Name != "Beijing" || payload.Items[1].Error == "" {
+		t.Errorf("简报内容不正确: %+v", payload.Items)
+	}
+	if !payload.ScheduledAt.Equal(scheduled) || payload.Title != "早间天气" {
+		t.Errorf("简报标题或计划时间不正确: %s %v", payload.Title, payload.ScheduledAt)
+	}
+
+	sub, err := replicaB.Get(created.ID)
+	if err != nil {
+		t.Fatalf("查询订阅失败: %v", err)
+	}
+	if sub.LastRun == nil || !sub.LastRun.Success || !sub.LastRun.ScheduledAt.Equal(scheduled) {
+		t.Errorf("期望记录成功的发送结果，实际为 %+v", sub.LastRun)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	loc := time.FixedZone("", 8*3600)
+	now := time.Date(2024, 3, 1, 7, 0, 0, 0, loc)
+	service := &forecastService{mockService{forecast: []model.ForecastItem{
+		{Time: now.Add(-2 * time.Hour), TempMin: 15, TempMax: 16, PrecipProbability: 0.1},
+		{Time: now.Add(4 * time.Hour), TempMin: 18, TempMax: 26, PrecipProbability: 0.35},
+		// 第二天的时段不计入
+		{Time: now.Add(20 * time.Hour), TempMin: 5, TempMax: 30, PrecipProbability: 0.9},
+	}}}
+	weather, _ := service.GetWeatherByCity("Beijing", "metric", "zh_cn")
+	forecast, _ := service.GetForecastByCity("Beijing", "metric", "zh_cn")
+
+	var item model.DigestItem
+	summarize(&item, weather, forecast, now)
+	if !item.Forecast || item.High != 26 || item.Low != 15 {
+		t.Errorf("期望最高 26、最低 15，实际为 %+v", item)
+	}
+	if item.PrecipProbability == nil || *item.PrecipProbability != 35 || item.PrecipRisk != model.PrecipRiskModerate {
+		t.Errorf("期望降水概率 35%%、风险 moderate，实际为 %v %s", item.PrecipProbability, item.PrecipRisk)
+	}
+
+	// 没有预报时退回当前观测，降水风险未知
+	item = model.DigestItem{}
+	summarize(&item, weather, nil, now)
+	if item.Forecast || item.High != 21 || item.Low != 19 || item.PrecipRisk != model.PrecipRiskUnknown || item.PrecipProbability != nil {
+		t.Errorf("没有预报时的结果不正确: %+v", item)
+	}
+
+	weather.Current.Rain = &model.Rain{OneHour: 0.5}
+	summarize(&item, weather, nil, now)
+	if item.PrecipRisk != model.PrecipRiskHigh {
+		t.Errorf("正在降雨时期望风险为 high，实际为 %s", item.PrecipRisk)
+	}
+}
+
+func TestManager_Validate(t *testing.T) {
+	m := newTestManager(t, t.TempDir())
+	location := []model.WeatherRequest{{City: "Beijing"}}
+
+	tests := []model.DigestSubscription{
+		{Schedule: "0 7 * * *", Locations: location},
+		{Schedule: "0 7 * * *", Locations: location, URL: "ftp://example.com"},
+		{Schedule: "0 7 * * *", Locations: location, Channels: []string{"ops"}},
+		{Schedule: "0 25 * * *", Locations: location, URL: "https://example.com"},
+		{Schedule: "0 0 30 2 *", Locations: location, URL: "https://example.com"},
+		{Schedule: "0 7 * * *", Timezone: "Mars/Olympus", Locations: location, URL: "https://example.com"},
+		{Schedule: "0 7 * * *", URL: "https://example.com"},
+		{Schedule: "0 7 * * *", Locations: []model.WeatherRequest{{City: "A"}, {City: "B"}, {City: "C"}, {City: "D"}}, URL: "https://example.com"},
+		{Schedule: "0 7 * * *", Locations: []model.WeatherRequest{{Lat: 91}}, URL: "https://example.com"},
+		{Schedule: "0 7 * * *", Locations: location, Units: "kelvin", URL: "https://example.com"},
+	}
+	for i, sub := range tests {
+		_, err := m.Create(&sub)
+		var validationErr *resource.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("用例 %d: 期望返回校验错误，实际为 %v", i, err)
+		}
+	}
+
+	created, err := m.Create(&model.DigestSubscription{Schedule: "@daily", Locations: location, URL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("创建订阅失败: %v", err)
+	}
+	if created.Timezone != "UTC" || created.Units != "metric" || created.Locations[0].Lang != "zh_cn" {
+		t.Errorf("默认值不正确: %+v", created)
+	}
+
+	if err := m.Delete(created.ID); err != nil {
+		t.Fatalf("删除订阅失败: %v", err)
+	}
+	if err := m.Delete(created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("期望返回 ErrNotFound，实际为 %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package digest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+const (
+	// subscriptionsFile 订阅文件名
+	subscriptionsFile = "subscriptions.json"
+	// lockFile 修改订阅文件时持有的锁文件
+	lockFile = "subscriptions.lock"
+	// claimsDir 记录已认领发送任务的目录
+	claimsDir = "claims"
+
+	// lockTimeout 等待锁的最长时间
+	lockTimeout = 5 * time.Second
+	// staleLock 锁文件超过该时间未释放时认为持有者已崩溃
+	staleLock = 30 * time.Second
+)
+
+// Store 将订阅以 JSON 文件形式保存在本地目录。多个副本可以共享同一目录：
+// 修改订阅时通过锁文件互斥，每次计划发送通过独占创建认领文件保证只有一个副本执行
+type Store struct {
+	dir string
+}
+
+// NewStore 创建存储并确保目录存在
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, claimsDir), 0o755); err != nil {
+		return nil, fmt.Errorf("创建简报目录失败: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Load 读取全部订阅，文件不存在时返回空列表
+func (s *Store) Load() (map[string]*model.DigestSubscription, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, subscriptionsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]*model.DigestSubscription), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取简报订阅失败: %w", err)
+	}
+
+	var list []*model.DigestSubscription
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("解析简报订阅失败: %w", err)
+	}
+	subs := make(map[string]*model.DigestSubscription, len(list))
+	for _, sub := range list {
+		subs[sub.ID] = sub
+	}
+	return subs, nil
+}
+
+// Update 在锁内读取全部订阅并交给 fn 修改，fn 返回 nil 时写回文件
+func (s *Store) Update(fn func(subs map[string]*model.DigestSubscription) error) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	subs, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(subs); err != nil {
+		return err
+	}
+	return s.save(subs)
+}
+
+// save 按创建时间顺序覆盖写入全部订阅
+func (s *Store) save(subs map[string]*model.DigestSubscription) error {
+	list := make([]*model.DigestSubscription, 0, len(subs))
+	for _, sub := range subs {
+		list = append(list, sub)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化简报订阅失败: %w", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中途崩溃留下损坏的文件，其他副本也不会读到写了一半的内容
+	path := filepath.Join(s.dir, subscriptionsFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("写入简报订阅失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("写入简报订阅失败: %w", err)
+	}
+	return nil
+}
+
+// lock 独占创建锁文件，返回释放函数
+func (s *Store) lock() (func(), error) {
+	path := filepath.Join(s.dir, lockFile)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("获取简报订阅锁失败: %w", err)
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("获取简报订阅锁超时")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// Claim 认领订阅在 scheduledAt 的一次发送，同一次发送只有第一个调用者返回 true
+func (s *Store) Claim(id string, scheduledAt time.Time, owner string) (bool, error) {
+	name := id + "-" + strconv.FormatInt(scheduledAt.Unix(), 10)
+	f, err := os.OpenFile(filepath.Join(s.dir, claimsDir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("认领简报发送失败: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(owner + "\n"); err != nil {
+		return true, fmt.Errorf("写入认领记录失败: %w", err)
+	}
+	return true, nil
+}
+
+// PruneClaims 删除计划时间早于 before 的认领记录
+func (s *Store) PruneClaims(before time.Time) error {
+	entries, err := os.ReadDir(filepath.Join(s.dir, claimsDir))
+	if err != nil {
+		return fmt.Errorf("读取认领记录失败: %w", err)
+	}
+	for _, entry := range entries {
+		i := strings.LastIndex(entry.Name(), "-")
+		if i < 0 {
+			continue
+		}
+		ts, err := strconv.ParseInt(entry.Name()[i+1:], 10, 64)
+		if err != nil || !time.Unix(ts, 0).Before(before) {
+			continue
+		}
+		os.Remove(filepath.Join(s.dir, claimsDir, entry.Name()))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Package geojson 把天气结果转换为 GeoJSON（RFC 7946）要素
+package geojson
+
+import "gin-weather/internal/model"
+
+// Feature 把一个位置的天气转换为点要素，几何取自位置的经纬度，属性为展平的当前天气
+func Feature(weather *model.WeatherResponse) model.Feature {
+	current := &weather.Current
+	props := model.WeatherProperties{
+		Name:          weather.Location.Name,
+		Country:       weather.Location.Country,
+		Provider:      weather.Provider,
+		Temperature:   current.Temperature,
+		FeelsLike:     current.FeelsLike,
+		TempMin:       current.TempMin,
+		TempMax:       current.TempMax,
+		Pressure:      current.Pressure,
+		Humidity:      current.Humidity,
+		Visibility:    current.Visibility,
+		UVIndex:       current.UVIndex,
+		WindSpeed:     current.Wind.Speed,
+		WindDirection: current.Wind.Direction,
+		WindGust:      current.Wind.Gust,
+		Clouds:        current.Clouds.All,
+		UpdatedAt:     current.UpdatedAt,
+	}
+	if current.Rain != nil {
+		props.Rain1h = current.Rain.OneHour
+	}
+	if current.Snow != nil {
+		props.Snow1h = current.Snow.OneHour
+	}
+	if len(current.Weather) > 0 {
+		w := &current.Weather[0]
+		props.Condition = w.Main
+		props.Description = w.Description
+		props.IconURL = w.IconURL
+		if w.Condition != nil {
+			code := w.Condition.Code
+			props.ConditionSlug = w.Condition.Slug
+			props.ConditionCode = &code
+		}
+	}
+
+	return model.Feature{
+		Type: "Feature",
+		Geometry: model.Geometry{
+			Type:        "Point",
+			Coordinates: []float64{weather.Location.Longitude, weather.Location.Latitude},
+		},
+		Properties: props,
+	}
+}
+
+// Collection 把多个位置的天气转换为要素集合
+func Collection(items []model.WeatherResponse) model.FeatureCollection {
+	features := make([]model.Feature, len(items))
+	for i := range items {
+		features[i] = Feature(&items[i])
+	}
+	return model.FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// Historical 把历史天气转换为要素集合，每个获取成功的日期一个要素，几何相同，updated_at 为当天的观测时间
+func Historical(resp *model.HistoricalResponse) model.FeatureCollection {
+	features := make([]model.Feature, 0, len(resp.Days))
+	for _, day := range resp.Days {
+		if day.Weather == nil {
+			continue
+		}
+		features = append(features, Feature(&model.WeatherResponse{
+			Location: resp.Location,
+			Current:  *day.Weather,
+			Provider: resp.Provider,
+		}))
+	}
+	return model.FeatureCollection{Type: "FeatureCollection", Features: features}
+}
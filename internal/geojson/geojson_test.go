@@ -0,0 +1,173 @@
+package geojson
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+func TestFeature(t *testing.T) {
+	updated := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	uv := 3.5
+
+	tests := []struct {
+		name    string
+		weather *model.WeatherResponse
+		check   func(t *testing.T, f model.Feature)
+	}{
+		{
+			name: "坐标顺序为经度在前",
+			weather: &model.WeatherResponse{
+				Location: model.Location{Name: "北京市", Country: "CN", Latitude: 39.9042, Longitude: 116.4074},
+				Current: model.Current{
+					Temperature: 12.5,
+					UVIndex:     &uv,
+					Wind:        model.Wind{Speed: 3, Direction: 180},
+					Clouds:      model.Clouds{All: 75},
+					Rain:        &model.Rain{OneHour: 1.2},
+					Weather: []model.Weather{
+						{Main: "Rain", Description: "小雨", Condition: &model.Condition{Code: 61, Slug: "rain_light"}},
+						{Main: "Mist"},
+					},
+					UpdatedAt: updated,
+				},
+				Provider: "openweathermap",
+			},
+			check: func(t *testing.T, f model.Feature) {
+				if f.Type != "Feature" || f.Geometry.Type != "Point" {
+					t.Errorf("要素类型不正确: %s %s", f.Type, f.Geometry.Type)
+				}
+				if got := f.Geometry.Coordinates; len(got) != 2 || got[0] != 116.4074 || got[1] != 39.9042 {
+					t.Errorf("期望坐标为 [116.4074, 39.9042]，实际为 %v", got)
+				}
+				p := f.Properties
+				if p.Name != "北京市" || p.Provider != "openweathermap" || p.Temperature != 12.5 || p.WindDirection != 180 || p.Clouds != 75 || p.Rain1h != 1.2 {
+					t.Errorf("属性不正确: %+v", p)
+				}
+				if p.UVIndex == nil || *p.UVIndex != 3.5 || !p.UpdatedAt.Equal(updated) {
+					t.Errorf("紫外线指数或更新时间不正确: %+v", p)
+				}
+				// 只取第一个天气状况
+				if p.Condition != "Rain" || p.Description != "小雨" || p.ConditionSlug != "rain_light" || p.ConditionCode == nil || *p.ConditionCode != 61 {
+					t.Errorf("天气状况不正确: %+v", p)
+				}
+			},
+		},
+		{
+			name: "没有中立天气状况",
+			weather: &model.WeatherResponse{
+				Location: model.Location{Latitude: -33.87, Longitude: 151.21},
+				Current:  model.Current{Weather: []model.Weather{{Main: "Clear"}}},
+			},
+			check: func(t *testing.T, f model.Feature) {
+				p := f.Properties
+				if p.Condition != "Clear" || p.ConditionSlug != "" || p.ConditionCode != nil {
+					t.Errorf("期望只有主要状况，实际为 %+v", p)
+				}
+				if p.UVIndex != nil || p.Rain1h != 0 || p.Snow1h != 0 {
+					t.Errorf("期望缺少的数据为空，实际为 %+v", p)
+				}
+				if got := f.Geometry.Coordinates; got[0] != 151.21 || got[1] != -33.87 {
+					t.Errorf("期望坐标为 [151.21, -33.87]，实际为 %v", got)
+				}
+			},
+		},
+		{
+			name:    "没有天气状况",
+			weather: &model.WeatherResponse{Location: model.Location{Name: "Nowhere"}},
+			check: func(t *testing.T, f model.Feature) {
+				data, _ := json.Marshal(f.Properties)
+				for _, key := range []string{`"condition"`, `"condition_code"`, `"uv_index"`} {
+					if strings.Contains(string(data), key) {
+						t.Errorf("期望省略 %s，实际为 %s", key, data)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, Feature(tt.weather))
+		})
+	}
+}
+
+func TestCollection(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []model.WeatherResponse
+		names []string
+	}{
+		{"空列表", nil, []string{}},
+		{"保持顺序", []model.WeatherResponse{
+			{Location: model.Location{Name: "北京市", Longitude: 116.4, Latitude: 39.9}},
+			{Location: model.Location{Name: "上海市", Longitude: 121.47, Latitude: 31.23}},
+		}, []string{"北京市", "上海市"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collection := Collection(tt.items)
+			if collection.Type != "FeatureCollection" || len(collection.Features) != len(tt.names) {
+				t.Fatalf("期望 %d 个要素，实际为 %+v", len(tt.names), collection)
+			}
+			for i, name := range tt.names {
+				f := collection.Features[i]
+				if f.Properties.Name != name || f.Geometry.Coordinates[0] != tt.items[i].Location.Longitude {
+					t.Errorf("第 %d 个要素不正确: %+v", i, f)
+				}
+			}
+			// 空集合也要编码为数组，而不是 null
+			if data, _ := json.Marshal(collection); !strings.Contains(string(data), `"features":[`) {
+				t.Errorf("features 应该是数组: %s", data)
+			}
+		})
+	}
+}
+
+func TestHistorical(t *testing.T) {
+	day := func(date string, temperature float64) model.HistoricalDay {
+		observed, _ := time.Parse("2006-01-02", date)
+		return model.HistoricalDay{Date: date, Weather: &model.Current{Temperature: temperature, UpdatedAt: observed.Add(4 * time.Hour)}}
+	}
+	location := model.Location{Name: "北京市", Latitude: 39.9042, Longitude: 116.4074}
+
+	tests := []struct {
+		name  string
+		days  []model.HistoricalDay
+		temps []float64
+	}{
+		{"全部成功", []model.HistoricalDay{day("2024-03-01", 5), day("2024-03-02", 7)}, []float64{5, 7}},
+		{"跳过失败的日期", []model.HistoricalDay{
+			day("2024-03-01", 5),
+			{Date: "2024-03-02", Error: "upstream error"},
+			day("2024-03-03", 9),
+		}, []float64{5, 9}},
+		{"全部失败", []model.HistoricalDay{{Date: "2024-03-01", Error: "upstream error"}}, []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collection := Historical(&model.HistoricalResponse{Location: location, Provider: "openweathermap", Days: tt.days})
+			if len(collection.Features) != len(tt.temps) {
+				t.Fatalf("期望 %d 个要素，实际为 %d", len(tt.temps), len(collection.Features))
+			}
+			for i, temp := range tt.temps {
+				f := collection.Features[i]
+				if f.Properties.Temperature != temp || f.Properties.Provider != "openweathermap" || f.Properties.Name != "北京市" {
+					t.Errorf("第 %d 个要素不正确: %+v", i, f.Properties)
+				}
+				if got := f.Geometry.Coordinates; got[0] != 116.4074 || got[1] != 39.9042 {
+					t.Errorf("期望坐标为 [116.4074, 39.9042]，实际为 %v", got)
+				}
+				if f.Properties.UpdatedAt.Hour() != 4 {
+					t.Errorf("期望 updated_at 为当天的观测时间，实际为 %v", f.Properties.UpdatedAt)
+				}
+			}
+		})
+	}
+}
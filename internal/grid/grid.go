@@ -0,0 +1,226 @@
+// Package grid 在经纬度范围内按规则网格采样天气，用于绘制区域热力图。
+//
+// 网格点对齐到经纬度为 step 整数倍的全局网格，平移或缩放地图时相同的网格点可以复用缓存；
+// 同一网格点的并发请求只向天气服务请求一次，所有采样请求共享同一个并发上限
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// ErrInvalidGrid 网格参数无效，如网格点过多或范围内没有网格点
+var ErrInvalidGrid = errors.New("网格参数无效")
+
+// MinStep 网格的最小间距（度），约 1 公里，更密的网格超出了数据提供商的分辨率
+const MinStep = 0.01
+
+// Fields 可以作为热力图数值的字段，名称与 GeoJSON 属性一致；数据提供商没有该数据时返回 NaN
+var Fields = map[string]func(*model.Current) float64{
+	"temperature": func(c *model.Current) float64 { return c.Temperature },
+	"feels_like":  func(c *model.Current) float64 { return c.FeelsLike },
+	"humidity":    func(c *model.Current) float64 { return float64(c.Humidity) },
+	"pressure":    func(c *model.Current) float64 { return float64(c.Pressure) },
+	"visibility":  func(c *model.Current) float64 { return float64(c.Visibility) },
+	"uv_index": func(c *model.Current) float64 {
+		if c.UVIndex == nil {
+			return math.NaN()
+		}
+		return *c.UVIndex
+	},
+	"wind_speed": func(c *model.Current) float64 { return c.Wind.Speed },
+	"clouds":     func(c *model.Current) float64 { return float64(c.Clouds.All) },
+	"rain_1h": func(c *model.Current) float64 {
+		if c.Rain == nil {
+			return 0
+		}
+		return c.Rain.OneHour
+	},
+	"snow_1h": func(c *model.Current) float64 {
+		if c.Snow == nil {
+			return 0
+		}
+		return c.Snow.OneHour
+	},
+}
+
+// FieldNames 返回按名称排序的全部字段名，用于错误提示
+func FieldNames() []string {
+	names := make([]string, 0, len(Fields))
+	for name := range Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sample 一次网格采样的结果
+type Sample struct {
+	Lats []float64 // 各行的纬度，由南向北
+	Lons []float64 // 各列的经度，由西向东
+	// Weather 按 [行][列] 排列的天气，获取失败的点为 nil。缓存的结果在多个请求间共享，调用方不能修改
+	Weather [][]*model.WeatherResponse
+	Failed  int // 获取失败的点数
+}
+
+// entry 一个网格点的缓存
+type entry struct {
+	weather *model.WeatherResponse
+	expires time.Time
+}
+
+// call 一次进行中的上游请求，同一个网格点的并发请求共享结果
+type call struct {
+	done    chan struct{}
+	weather *model.WeatherResponse
+	err     error
+}
+
+// Service 网格天气采样服务
+type Service struct {
+	weatherService service.WeatherService
+	maxPoints      int
+	ttl            time.Duration
+	sem            chan struct{}
+
+	mu       sync.Mutex
+	cache    map[string]*entry
+	inflight map[string]*call
+}
+
+// New 创建网格天气采样服务
+func New(cfg *config.GridConfig, weatherService service.WeatherService) *Service {
+	return &Service{
+		weatherService: weatherService,
+		maxPoints:      cfg.MaxPoints,
+		ttl:            time.Duration(cfg.TTL) * time.Second,
+		sem:            make(chan struct{}, cfg.Concurrency),
+		cache:          make(map[string]*entry),
+		inflight:       make(map[string]*call),
+	}
+}
+
+// Sample 获取 bbox（最小经度、最小纬度、最大经度、最大纬度）内间距为 step 度的全部网格点的天气。
+// 单个点失败只计入 Failed；所有点都失败时返回第一个错误
+func (s *Service) Sample(bbox [4]float64, step float64, units, lang string) (*Sample, error) {
+	if math.IsNaN(step) || math.IsInf(step, 0) || step < MinStep {
+		return nil, fmt.Errorf("%w: 网格间距必须是不小于 %g 度的有限数字", ErrInvalidGrid, MinStep)
+	}
+	latStart, latCount := span(bbox[1], bbox[3], step)
+	lonStart, lonCount := span(bbox[0], bbox[2], step)
+	if latCount <= 0 || lonCount <= 0 {
+		return nil, fmt.Errorf("%w: 范围内没有网格点，请减小网格间距", ErrInvalidGrid)
+	}
+	if latCount*lonCount > int64(s.maxPoints) {
+		return nil, fmt.Errorf("%w: 网格有 %d 个点，超过上限 %d，请增大网格间距或缩小范围", ErrInvalidGrid, latCount*lonCount, s.maxPoints)
+	}
+
+	s.sweep()
+
+	sample := &Sample{
+		Lats:    axis(latStart, latCount, step),
+		Lons:    axis(lonStart, lonCount, step),
+		Weather: make([][]*model.WeatherResponse, latCount),
+	}
+	errs := make([][]error, latCount)
+	var wg sync.WaitGroup
+	for i, lat := range sample.Lats {
+		sample.Weather[i] = make([]*model.WeatherResponse, lonCount)
+		errs[i] = make([]error, lonCount)
+		for j, lon := range sample.Lons {
+			wg.Add(1)
+			go func(i, j int, lat, lon float64) {
+				defer wg.Done()
+				sample.Weather[i][j], errs[i][j] = s.get(lat, lon, units, lang)
+			}(i, j, lat, lon)
+		}
+	}
+	wg.Wait()
+
+	var firstErr error
+	for i := range errs {
+		for _, err := range errs[i] {
+			if err != nil {
+				sample.Failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+	}
+	if int64(sample.Failed) == latCount*lonCount {
+		return nil, firstErr
+	}
+	return sample, nil
+}
+
+// get 返回一个网格点的天气，缓存未过期时不请求上游
+func (s *Service) get(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	key := fmt.Sprintf("%.6f,%.6f,%s,%s", lat, lon, units, lang)
+
+	s.mu.Lock()
+	if e, ok := s.cache[key]; ok && time.Now().Before(e.expires) {
+		s.mu.Unlock()
+		return e.weather, nil
+	}
+	if c, ok := s.inflight[key]; ok {
+		s.mu.Unlock()
+		<-c.done
+		return c.weather, c.err
+	}
+	c := &call{done: make(chan struct{})}
+	s.inflight[key] = c
+	s.mu.Unlock()
+
+	// 只有实际请求上游的一方占用并发名额，等待共享结果的请求不占用，避免互相等待
+	s.sem <- struct{}{}
+	c.weather, c.err = s.weatherService.GetWeatherByCoordinates(lat, lon, units, lang)
+	<-s.sem
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	if c.err == nil {
+		s.cache[key] = &entry{weather: c.weather, expires: time.Now().Add(s.ttl)}
+	}
+	s.mu.Unlock()
+	close(c.done)
+	return c.weather, c.err
+}
+
+// sweep 删除过期的缓存
+func (s *Service) sweep() {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, e := range s.cache {
+		if !now.Before(e.expires) {
+			delete(s.cache, key)
+		}
+	}
+}
+
+// span 返回 [lo, hi] 内第一个 step 整数倍的序号和网格点数
+func span(lo, hi, step float64) (int64, int64) {
+	// 容忍浮点误差，使恰好落在边界上的网格点包含在内
+	const epsilon = 1e-9
+	first := int64(math.Ceil(lo/step - epsilon))
+	last := int64(math.Floor(hi/step + epsilon))
+	return first, last - first + 1
+}
+
+// axis 返回从第 first 个 step 整数倍开始的 count 个坐标，保留 6 位小数以去掉浮点误差
+func axis(first, count int64, step float64) []float64 {
+	values := make([]float64, count)
+	for i := range values {
+		values[i] = math.Round(float64(first+int64(i))*step*1e6) / 1e6
+	}
+	return values
+}
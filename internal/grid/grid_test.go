@@ -0,0 +1,170 @@
+package grid
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+)
+
+// mockWeather 以纬度加经度作为温度返回天气，记录请求次数和最大并发数；纬度等于 failLat 时返回错误
+type mockWeather struct {
+	calls   atomic.Int32
+	active  atomic.Int32
+	peak    atomic.Int32
+	delay   time.Duration
+	failLat float64
+}
+
+func (m *mockWeather) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (m *mockWeather) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	m.calls.Add(1)
+	n := m.active.Add(1)
+	defer m.active.Add(-1)
+	for {
+		peak := m.peak.Load()
+		if n <= peak || m.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(m.delay)
+	if lat == m.failLat {
+		return nil, errors.New("upstream error")
+	}
+	return &model.WeatherResponse{
+		Location: model.Location{Latitude: lat, Longitude: lon},
+		Current:  model.Current{Temperature: lat + lon},
+	}, nil
+}
+
+func (m *mockWeather) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (m *mockWeather) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func newTestService(weather *mockWeather, maxPoints int) *Service {
+	return New(&config.GridConfig{MaxPoints: maxPoints, Concurrency: 2, TTL: 60}, weather)
+}
+
+func TestService_SampleAlignment(t *testing.T) {
+	weather := &mockWeather{failLat: -1}
+	s := newTestService(weather, 100)
+
+	// 网格点对齐到 0.5 的整数倍，恰好落在边界上的点包含在内
+	sample, err := s.Sample([4]float64{116.1, 39.5, 117.0, 40.3}, 0.5, "metric", "zh_cn")
+	if err != nil {
+		t.Fatalf("采样失败: %v", err)
+	}
+	wantLats, wantLons := []float64{39.5, 40}, []float64{116.5, 117}
+	if len(sample.Lats) != 2 || sample.Lats[0] != wantLats[0] || sample.Lats[1] != wantLats[1] ||
+		len(sample.Lons) != 2 || sample.Lons[0] != wantLons[0] || sample.Lons[1] != wantLons[1] {
+		t.Fatalf("期望纬度 %v、经度 %v，实际为 %v、%v", wantLats, wantLons, sample.Lats, sample.Lons)
+	}
+	if got := sample.Weather[1][0].Current.Temperature; got != 40+116.5 {
+		t.Errorf("期望 [1][0] 为 (40, 116.5) 的天气，实际温度为 %v", got)
+	}
+
+	// 0.1 的倍数在浮点运算下有误差，坐标应保留到 6 位小数
+	sample, err = s.Sample([4]float64{0.1, 0.1, 0.3, 0.2}, 0.1, "metric", "zh_cn")
+	if err != nil {
+		t.Fatalf("采样失败: %v", err)
+	}
+	if len(sample.Lons) != 3 || sample.Lons[2] != 0.3 || len(sample.Lats) != 2 {
+		t.Errorf("期望 2 行 3 列且最后一列为 0.3，实际为 %v、%v", sample.Lats, sample.Lons)
+	}
+}
+
+func TestService_SampleInvalid(t *testing.T) {
+	s := newTestService(&mockWeather{}, 10)
+	tests := []struct {
+		name string
+		bbox [4]float64
+		step float64
+	}{
+		{"间距过小", [4]float64{0, 0, 1, 1}, 0.001},
+		{"间距为无穷大", [4]float64{0, 0, 1, 1}, math.Inf(1)},
+		{"超过点数上限", [4]float64{0, 0, 1, 1}, 0.25},
+		{"范围内没有网格点", [4]float64{0.1, 0.1, 0.4, 0.4}, 0.5},
+	}
+	for _, tt := range tests {
+		if _, err := s.Sample(tt.bbox, tt.step, "metric", "zh_cn"); !errors.Is(err, ErrInvalidGrid) {
+			t.Errorf("%s: 期望返回 ErrInvalidGrid，实际为 %v", tt.name, err)
+		}
+	}
+}
+
+func TestService_SampleCacheAndConcurrency(t *testing.T) {
+	weather := &mockWeather{delay: 20 * time.Millisecond, failLat: -1}
+	s := newTestService(weather, 100)
+	bbox := [4]float64{0, 0, 2, 2}
+
+	// 两个并发请求的 9 个网格点完全相同，每个点只请求一次上游
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Sample(bbox, 1, "metric", "zh_cn"); err != nil {
+				t.Errorf("采样失败: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := weather.calls.Load(); calls != 9 {
+		t.Errorf("期望请求上游 9 次，实际为 %d", calls)
+	}
+	if peak := weather.peak.Load(); peak > 2 {
+		t.Errorf("期望最大并发数不超过 2，实际为 %d", peak)
+	}
+
+	// 平移后重叠的网格点使用缓存，只请求新增的一列
+	if _, err := s.Sample([4]float64{1, 0, 3, 2}, 1, "metric", "zh_cn"); err != nil {
+		t.Fatalf("采样失败: %v", err)
+	}
+	if calls := weather.calls.Load(); calls != 12 {
+		t.Errorf("期望平移后共请求上游 12 次，实际为 %d", calls)
+	}
+
+	// 单位不同时不共享缓存
+	if _, err := s.Sample(bbox, 1, "imperial", "zh_cn"); err != nil {
+		t.Fatalf("采样失败: %v", err)
+	}
+	if calls := weather.calls.Load(); calls != 21 {
+		t.Errorf("期望不同单位重新请求上游，共 21 次，实际为 %d", calls)
+	}
+}
+
+func TestService_SampleFailures(t *testing.T) {
+	weather := &mockWeather{failLat: 1}
+	s := newTestService(weather, 100)
+
+	sample, err := s.Sample([4]float64{0, 0, 1, 1}, 1, "metric", "zh_cn")
+	if err != nil {
+		t.Fatalf("部分失败时不应返回错误: %v", err)
+	}
+	if sample.Failed != 2 || sample.Weather[1][0] != nil || sample.Weather[0][1] == nil {
+		t.Errorf("期望纬度 1 的 2 个点失败，实际失败 %d 个", sample.Failed)
+	}
+
+	// 失败的结果不缓存
+	calls := weather.calls.Load()
+	s.Sample([4]float64{0, 0, 1, 1}, 1, "metric", "zh_cn")
+	if got := weather.calls.Load() - calls; got != 2 {
+		t.Errorf("期望只重新请求失败的 2 个点，实际请求 %d 次", got)
+	}
+
+	if _, err := s.Sample([4]float64{0, 1, 1, 1.5}, 1, "metric", "zh_cn"); err == nil || errors.Is(err, ErrInvalidGrid) {
+		t.Errorf("所有点都失败时期望返回上游错误，实际为 %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package historical
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gin-weather/internal/model"
+)
+
+// cache 历史天气缓存，过去的天气不会变化，条目不会过期；
+// 配置了目录时每个条目保存为一个文件，重启后仍然有效，否则只保存在内存中
+type cache struct {
+	dir string
+
+	mu  sync.RWMutex
+	mem map[string]*model.WeatherResponse
+}
+
+// newCache 创建缓存，dir 不为空时自动创建目录
+func newCache(dir string) (*cache, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("创建历史天气缓存目录失败: %w", err)
+		}
+	}
+	return &cache{dir: dir, mem: make(map[string]*model.WeatherResponse)}, nil
+}
+
+// get 读取缓存条目，文件损坏时视为未命中
+func (c *cache) get(key string) (*model.WeatherResponse, bool) {
+	if c.dir == "" {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		weather, ok := c.mem[key]
+		return weather, ok
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var weather model.WeatherResponse
+	if err := json.Unmarshal(data, &weather); err != nil {
+		return nil, false
+	}
+	return &weather, true
+}
+
+// put 写入缓存条目，文件先写入临时文件再重命名，避免并发读取到不完整的内容
+func (c *cache) put(key string, weather *model.WeatherResponse) error {
+	if c.dir == "" {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.mem[key] = weather
+		return nil
+	}
+
+	data, err := json.Marshal(weather)
+	if err != nil {
+		return fmt.Errorf("序列化历史天气失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("写入历史天气缓存失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入历史天气缓存失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入历史天气缓存失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("写入历史天气缓存失败: %w", err)
+	}
+	return nil
+}
+
+// path 返回缓存条目的文件路径，文件名取键的哈希，避免城市名称中的特殊字符
+func (c *cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+".json")
+}
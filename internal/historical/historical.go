@@ -0,0 +1,163 @@
+// Package historical 查询过去某一天或某一段日期的天气。
+//
+// 跨多天的查询按天拆分，以有限的并发度向天气服务请求；
+// 过去的天气不会变化，已经稳定的结果会被永久缓存，重复查询不再请求上游
+package historical
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// ErrInvalidRange 查询的日期范围无效
+var ErrInvalidRange = errors.New("日期范围无效")
+
+// dateLayout 日期格式
+const dateLayout = "2006-01-02"
+
+// settleDelay 观测时间距今超过该时长的结果才会缓存，避免缓存上游尚未补全的数据
+const settleDelay = 6 * time.Hour
+
+// earliestDate 上游历史数据的起始日期
+var earliestDate = time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Service 历史天气查询服务
+type Service struct {
+	config         *config.HistoricalConfig
+	weatherService service.WeatherService
+	cache          *cache
+}
+
+// New 创建历史天气查询服务
+func New(cfg *config.HistoricalConfig, weatherService service.WeatherService) (*Service, error) {
+	c, err := newCache(cfg.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		config:         cfg,
+		weatherService: weatherService,
+		cache:          c,
+	}, nil
+}
+
+// Lookup 查询 from 到 to（均按 UTC 日期，含两端）每一天的天气。
+// 单日失败只记录在对应的条目中；所有日期都失败时返回第一个错误
+func (s *Service) Lookup(req *model.WeatherRequest, from, to time.Time) (*model.HistoricalResponse, error) {
+	dates, err := s.dates(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]service.FetchResult, len(dates))
+	sem := make(chan struct{}, s.config.Concurrency)
+	var wg sync.WaitGroup
+	for i := range dates {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			weather, err := s.fetch(req, dates[i])
+			results[i] = service.FetchResult{Weather: weather, Err: err}
+		}(i)
+	}
+	wg.Wait()
+
+	response := &model.HistoricalResponse{
+		Units: req.Units,
+		Days:  make([]model.HistoricalDay, len(dates)),
+	}
+	var firstErr error
+	found := false
+	for i, result := range results {
+		day := &response.Days[i]
+		day.Date = dates[i].Format(dateLayout)
+		if result.Err != nil {
+			day.Error = result.Err.Error()
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+			continue
+		}
+		day.Weather = &result.Weather.Current
+		if !found {
+			response.Location = result.Weather.Location
+			response.Provider = result.Weather.Provider
+			found = true
+		}
+	}
+	if !found {
+		return nil, firstErr
+	}
+	return response, nil
+}
+
+// fetch 获取某一天的天气，优先读取缓存
+func (s *Service) fetch(req *model.WeatherRequest, date time.Time) (*model.WeatherResponse, error) {
+	key := cacheKey(req, date)
+	if weather, ok := s.cache.get(key); ok {
+		return weather, nil
+	}
+
+	weather, err := service.FetchHistorical(s.weatherService, req, date)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(weather.Current.UpdatedAt) > settleDelay {
+		if err := s.cache.put(key, weather); err != nil {
+			log.Printf("%v", err)
+		}
+	}
+	return weather, nil
+}
+
+// dates 校验日期范围并按天展开
+func (s *Service) dates(from, to time.Time) ([]time.Time, error) {
+	from, to = truncateDay(from), truncateDay(to)
+	today := truncateDay(time.Now())
+
+	switch {
+	case to.Before(from):
+		return nil, fmt.Errorf("%w: 结束日期不能早于起始日期", ErrInvalidRange)
+	case to.After(today):
+		return nil, fmt.Errorf("%w: 不能查询今天之后的日期", ErrInvalidRange)
+	case from.Before(earliestDate):
+		return nil, fmt.Errorf("%w: 最早只能查询 %s", ErrInvalidRange, earliestDate.Format(dateLayout))
+	}
+
+	days := int(to.Sub(from)/(24*time.Hour)) + 1
+	if days > s.config.MaxDays {
+		return nil, fmt.Errorf("%w: 单次最多查询 %d 天，请求了 %d 天", ErrInvalidRange, s.config.MaxDays, days)
+	}
+
+	dates := make([]time.Time, days)
+	for i := range dates {
+		dates[i] = from.AddDate(0, 0, i)
+	}
+	return dates, nil
+}
+
+// cacheKey 缓存键由位置、日期、单位和语言组成
+func cacheKey(req *model.WeatherRequest, date time.Time) string {
+	location := fmt.Sprintf("%.4f,%.4f", req.Lat, req.Lon)
+	if req.City != "" {
+		location = "city:" + strings.ToLower(strings.TrimSpace(req.City))
+	}
+	return strings.Join([]string{location, date.Format(dateLayout), req.Units, req.Lang}, "|")
+}
+
+// truncateDay 返回 t 所在的 UTC 日期零点
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
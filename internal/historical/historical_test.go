@@ -0,0 +1,163 @@
+package historical
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+)
+
+// mockService 模拟天气服务，历史天气的温度为日期中的日，并记录请求次数和最大并发数
+type mockService struct {
+	mu      sync.Mutex
+	calls   map[string]int
+	active  int32
+	maxSeen int32
+	failDay int
+}
+
+func (m *mockService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	n := atomic.AddInt32(&m.active, 1)
+	defer atomic.AddInt32(&m.active, -1)
+	for {
+		seen := atomic.LoadInt32(&m.maxSeen)
+		if n <= seen || atomic.CompareAndSwapInt32(&m.maxSeen, seen, n) {
+			break
+		}
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	m.mu.Lock()
+	m.calls[date.Format(dateLayout)]++
+	m.mu.Unlock()
+
+	if date.Day() == m.failDay {
+		return nil, errors.New("upstream error")
+	}
+	return &model.WeatherResponse{
+		Location: model.Location{Name: city},
+		Current:  model.Current{Temperature: float64(date.Day()), UpdatedAt: date.Add(12 * time.Hour)},
+		Provider: "mock",
+	}, nil
+}
+
+func (m *mockService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetHistoricalByCity("coords", date, units, lang)
+}
+
+func (m *mockService) totalCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	total := 0
+	for _, n := range m.calls {
+		total += n
+	}
+	return total
+}
+
+func TestLookup_SplitAndCache(t *testing.T) {
+	ws := &mockService{calls: make(map[string]int), failDay: 5}
+	cfg := &config.HistoricalConfig{CacheDir: t.TempDir(), MaxDays: 31, Concurrency: 3}
+	s, err := New(cfg, ws)
+	if err != nil {
+		t.Fatalf("创建服务失败: %v", err)
+	}
+
+	req := &model.WeatherRequest{City: "Beijing", Units: "metric", Lang: "zh_cn"}
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	response, err := s.Lookup(req, from, to)
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(response.Days) != 10 || response.Location.Name != "Beijing" || response.Provider != "mock" {
+		t.Fatalf("返回结果不正确: %+v", response)
+	}
+	for i, day := range response.Days {
+		if i == 4 {
+			if day.Error == "" || day.Weather != nil {
+				t.Errorf("期望 3 月 5 日返回错误: %+v", day)
+			}
+			continue
+		}
+		if day.Date != from.AddDate(0, 0, i).Format(dateLayout) || day.Weather == nil || day.Weather.Temperature != float64(i+1) {
+			t.Errorf("第 %d 天的结果不正确: %+v", i, day)
+		}
+	}
+	if ws.maxSeen > 3 {
+		t.Errorf("期望并发数不超过 3，实际为 %d", ws.maxSeen)
+	}
+
+	// 重新创建服务后，成功的日期从磁盘缓存读取，失败的日期重新请求
+	s, _ = New(cfg, ws)
+	if _, err := s.Lookup(req, from, to); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if total := ws.totalCalls(); total != 11 {
+		t.Errorf("期望共请求 11 次，实际为 %d", total)
+	}
+
+	// 单位不同时不共用缓存
+	imperial := *req
+	imperial.Units = "imperial"
+	s.Lookup(&imperial, from, from)
+	if n := ws.calls["2024-03-01"]; n != 2 {
+		t.Errorf("期望 3 月 1 日按英制重新请求，实际请求 %d 次", n)
+	}
+}
+
+func TestLookup_RecentNotCached(t *testing.T) {
+	ws := &mockService{calls: make(map[string]int)}
+	s, _ := New(&config.HistoricalConfig{MaxDays: 31, Concurrency: 2}, ws)
+
+	req := &model.WeatherRequest{Lat: 39.9, Lon: 116.4, Units: "metric", Lang: "zh_cn"}
+	today := truncateDay(time.Now())
+	for i := 0; i < 2; i++ {
+		if _, err := s.Lookup(req, today, today); err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+	}
+	if n := ws.calls[today.Format(dateLayout)]; n != 2 {
+		t.Errorf("期望今天的数据不被缓存，实际请求 %d 次", n)
+	}
+}
+
+func TestLookup_InvalidRange(t *testing.T) {
+	ws := &mockService{calls: make(map[string]int), failDay: 1}
+	s, _ := New(&config.HistoricalConfig{MaxDays: 7, Concurrency: 2}, ws)
+	req := &model.WeatherRequest{City: "Beijing"}
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{"结束早于起始", day, day.AddDate(0, 0, -1)},
+		{"超过最大天数", day, day.AddDate(0, 0, 7)},
+		{"未来日期", day, time.Now().AddDate(0, 0, 2)},
+		{"早于 1979 年", time.Date(1978, 12, 31, 0, 0, 0, 0, time.UTC), day},
+	}
+	for _, tt := range tests {
+		if _, err := s.Lookup(req, tt.from, tt.to); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("%s: 期望返回 ErrInvalidRange，实际为 %v", tt.name, err)
+		}
+	}
+
+	// 所有日期都失败时返回错误
+	if _, err := s.Lookup(req, day, day); err == nil || errors.Is(err, ErrInvalidRange) {
+		t.Errorf("期望返回上游错误，实际为 %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Package icons 内嵌天气图标，按 OpenWeatherMap 图标代码或中立的天气状况标识查找。
+//
+// 图标随程序一起发布，同一个地址的内容在程序的生命周期内不会变化
+package icons
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"path"
+	"strings"
+
+	"gin-weather/internal/model"
+)
+
+// PathPrefix 图标接口的路径前缀，图标地址为 PathPrefix + 代码 + ".svg"
+const PathPrefix = "/api/v1/icons/"
+
+//go:embed svg/*.svg
+var files embed.FS
+
+// conditionIcons 中立状况标识对应的图标，值中的 %s 为 day 或 night
+var conditionIcons = map[string]string{
+	model.ConditionClear:              "clear-%s",
+	model.ConditionMainlyClear:        "partly-cloudy-%s",
+	model.ConditionPartlyCloudy:       "partly-cloudy-%s",
+	model.ConditionOvercast:           "cloudy",
+	model.ConditionSmoke:              "smoke",
+	model.ConditionHaze:               "haze",
+	model.ConditionDust:               "dust",
+	model.ConditionSand:               "dust",
+	model.ConditionMist:               "fog",
+	model.ConditionSquall:             "wind",
+	model.ConditionTornado:            "tornado",
+	model.ConditionFog:                "fog",
+	model.ConditionDrizzleLight:       "drizzle",
+	model.ConditionDrizzle:            "drizzle",
+	model.ConditionDrizzleHeavy:       "drizzle",
+	model.ConditionFreezingDrizzle:    "freezing-rain",
+	model.ConditionRainLight:          "rain",
+	model.ConditionRain:               "rain",
+	model.ConditionRainHeavy:          "rain-heavy",
+	model.ConditionFreezingRain:       "freezing-rain",
+	model.ConditionSleetLight:         "sleet",
+	model.ConditionSleet:              "sleet",
+	model.ConditionSnowLight:          "snow",
+	model.ConditionSnow:               "snow",
+	model.ConditionSnowHeavy:          "snow",
+	model.ConditionRainShowersLight:   "showers-%s",
+	model.ConditionRainShowers:        "showers-%s",
+	model.ConditionRainShowersViolent: "rain-heavy",
+	model.ConditionSnowShowersLight:   "snow",
+	model.ConditionSnowShowers:        "snow",
+	model.ConditionThunderstorm:       "thunderstorm",
+	model.ConditionThunderstormHeavy:  "thunderstorm",
+	model.ConditionThunderstormHail:   "thunderstorm",
+	model.ConditionUnknown:            "unknown",
+}
+
+// owmIcons OpenWeatherMap 图标代码（去掉 d、n 后缀）对应的图标
+var owmIcons = map[string]string{
+	"01": "clear-%s",
+	"02": "partly-cloudy-%s",
+	"03": "cloudy",
+	"04": "cloudy",
+	"09": "rain",
+	"10": "showers-%s",
+	"11": "thunderstorm",
+	"13": "snow",
+	"50": "fog",
+}
+
+// Icon 一个图标文件
+type Icon struct {
+	Data []byte
+	// Version 根据内容计算的版本，图标内容变化时随之变化，用作图标地址的 v 参数
+	Version string
+	// ETag 根据内容计算的实体标签，已包含引号
+	ETag string
+}
+
+// icons 按文件名（不含扩展名）索引的图标
+var icons = loadIcons()
+
+// loadIcons 读取内嵌的全部图标并计算实体标签
+func loadIcons() map[string]*Icon {
+	entries, err := files.ReadDir("svg")
+	if err != nil {
+		panic(err)
+	}
+	loaded := make(map[string]*Icon, len(entries))
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("svg", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		sum := sha256.Sum256(data)
+		version := hex.EncodeToString(sum[:8])
+		loaded[strings.TrimSuffix(entry.Name(), ".svg")] = &Icon{
+			Data:    data,
+			Version: version,
+			ETag:    `"` + version + `"`,
+		}
+	}
+	return loaded
+}
+
+// Lookup 按代码查找图标。代码可以是 OpenWeatherMap 图标代码（如 10n），
+// 也可以是中立的状况标识，后面可以加 -day 或 -night（如 rain_light-night），不加时为白天
+func Lookup(code string) (*Icon, bool) {
+	name, ok := resolve(code)
+	if !ok {
+		return nil, false
+	}
+	icon, ok := icons[name]
+	return icon, ok
+}
+
+// resolve 把代码解析为图标文件名
+func resolve(code string) (string, bool) {
+	if len(code) == 3 && (code[2] == 'd' || code[2] == 'n') {
+		if pattern, ok := owmIcons[code[:2]]; ok {
+			variant := model.VariantDay
+			if code[2] == 'n' {
+				variant = model.VariantNight
+			}
+			return withVariant(pattern, variant), true
+		}
+	}
+
+	slug, variant := code, model.VariantDay
+	if i := strings.LastIndexByte(code, '-'); i >= 0 {
+		slug, variant = code[:i], code[i+1:]
+		if variant != model.VariantDay && variant != model.VariantNight {
+			return "", false
+		}
+	}
+	pattern, ok := conditionIcons[slug]
+	if !ok {
+		return "", false
+	}
+	return withVariant(pattern, variant), true
+}
+
+// withVariant 把图标名称中的 %s 替换为 day 或 night，没有昼夜之分的图标原样返回
+func withVariant(pattern, variant string) string {
+	return strings.Replace(pattern, "%s", variant, 1)
+}
+
+// URL 返回天气状况对应的图标地址，condition 为 nil 时返回空字符串。
+// 地址带有图标内容的版本 v，图标在新版本中变化时地址随之变化，客户端可以长期缓存
+func URL(condition *model.Condition) string {
+	if condition == nil {
+		return ""
+	}
+	code := condition.Slug + "-" + condition.Variant
+	icon, ok := Lookup(code)
+	if !ok {
+		return ""
+	}
+	return PathPrefix + code + ".svg?v=" + icon.Version
+}
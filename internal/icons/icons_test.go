@@ -0,0 +1,75 @@
+package icons
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"gin-weather/internal/model"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		code string
+		file string
+	}{
+		{"01d", "clear-day"},
+		{"01n", "clear-night"},
+		{"04n", "cloudy"},
+		{"10n", "showers-night"},
+		{"clear", "clear-day"},
+		{"clear-night", "clear-night"},
+		{"rain_light-night", "rain"},
+		{"rain_showers-night", "showers-night"},
+	}
+	for _, tt := range tests {
+		icon, ok := Lookup(tt.code)
+		if !ok || icon != icons[tt.file] {
+			t.Errorf("%s: 期望使用图标 %s", tt.code, tt.file)
+		}
+	}
+
+	for _, code := range []string{"", "05d", "01x", "clear-evening", "sunny", "rain_light-"} {
+		if _, ok := Lookup(code); ok {
+			t.Errorf("%q: 期望找不到图标", code)
+		}
+	}
+}
+
+func TestIcons_Complete(t *testing.T) {
+	patterns := make([]string, 0, len(conditionIcons)+len(owmIcons))
+	for slug, pattern := range conditionIcons {
+		if c := model.NewCondition(slug, false); c.Slug != slug {
+			t.Errorf("%s 不是已定义的状况标识", slug)
+		}
+		patterns = append(patterns, pattern)
+	}
+	for _, pattern := range owmIcons {
+		patterns = append(patterns, pattern)
+	}
+
+	for _, pattern := range patterns {
+		for _, variant := range []string{model.VariantDay, model.VariantNight} {
+			name := withVariant(pattern, variant)
+			icon, ok := icons[name]
+			if !ok {
+				t.Errorf("缺少图标文件 %s.svg", name)
+				continue
+			}
+			var doc struct {
+				XMLName xml.Name `xml:"svg"`
+			}
+			if err := xml.Unmarshal(icon.Data, &doc); err != nil {
+				t.Errorf("%s.svg 不是有效的 SVG: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	if got := URL(model.NewCondition(model.ConditionRainLight, true)); got != "/api/v1/icons/rain_light-night.svg?v="+icons["rain"].Version {
+		t.Errorf("图标地址不正确: %s", got)
+	}
+	if URL(nil) != "" {
+		t.Error("期望没有天气状况时图标地址为空")
+	}
+}
@@ -0,0 +1,309 @@
+// Package indices 根据当前天气和未来的预报降水计算生活指数（穿衣、紫外线、洗车、运动、感冒等）
+//
+// 指数的计算规则来自 JSON 规则表，可以通过 INDICES_RULES_FILE 指定外部文件在不重新编译的情况下调整。
+// 每个指数包含若干等级，按顺序匹配，第一个所有条件都满足的等级即为结果；
+// 最后一个等级不能带条件，作为兜底。缺少数据的指标不满足任何条件，
+// 指数声明的必需指标缺少数据时不返回该指数，避免用兜底等级冒充结果。
+package indices
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// defaultRules 内置的默认规则表
+//
+//go:embed rules.json
+var defaultRules []byte
+
+const (
+	// defaultLang 规则表缺少对应语言时使用的语言
+	defaultLang = "zh_cn"
+	// forecastWindow 预报指标统计的时长，从当前时间开始
+	forecastWindow = 12 * time.Hour
+	// forecastStep 预报时段长度，与 OpenWeatherMap 5 天预报的 3 小时间隔一致
+	forecastStep = 3 * time.Hour
+)
+
+// RuleSet 生活指数规则表
+type RuleSet struct {
+	Indices []IndexRule `json:"indices"`
+}
+
+// IndexRule 单个生活指数的规则
+type IndexRule struct {
+	Key      string            `json:"key"`                // 指数标识
+	Name     map[string]string `json:"name"`               // 各语言的指数名称
+	Requires []string          `json:"requires,omitempty"` // 必需的指标，任一指标缺少数据时不返回该指数
+	Levels   []LevelRule       `json:"levels"`             // 按顺序匹配的等级
+}
+
+// LevelRule 指数等级规则
+type LevelRule struct {
+	Level  int               `json:"level"`  // 等级
+	When   []Condition       `json:"when"`   // 匹配条件（全部满足），为空表示兜底
+	Label  map[string]string `json:"label"`  // 各语言的等级名称
+	Advice map[string]string `json:"advice"` // 各语言的建议
+}
+
+// Condition 指标范围条件，区间为 [Min, Max)
+type Condition struct {
+	Metric string   `json:"metric"`        // 指标名称
+	Min    *float64 `json:"min,omitempty"` // 下限（含）
+	Max    *float64 `json:"max,omitempty"` // 上限（不含）
+}
+
+// Input 计算生活指数的输入，数值均为公制单位
+type Input struct {
+	Current *model.Current
+	// Forecast 天气预报，天气服务不支持预报或获取失败时为 nil，此时预报指标缺少数据
+	Forecast *model.Forecast
+	// Now 当前时间，预报指标只统计之后 forecastWindow 内的时段
+	Now time.Time
+}
+
+// metricFunc 返回指标的值，第二个返回值为 false 表示缺少该指标的数据
+type metricFunc func(in *Input) (float64, bool)
+
+// current 把只依赖当前天气、总是有数据的指标包装为 metricFunc
+func current(f func(c *model.Current) float64) metricFunc {
+	return func(in *Input) (float64, bool) { return f(in.Current), true }
+}
+
+// metricFuncs 规则可以引用的指标，数值均为公制单位
+var metricFuncs = map[string]metricFunc{
+	"temperature":   current(func(c *model.Current) float64 { return c.Temperature }),
+	"feels_like":    current(func(c *model.Current) float64 { return c.FeelsLike }),
+	"temp_range":    current(func(c *model.Current) float64 { return c.TempMax - c.TempMin }),
+	"humidity":      current(func(c *model.Current) float64 { return float64(c.Humidity) }),
+	"pressure":      current(func(c *model.Current) float64 { return float64(c.Pressure) }),
+	"visibility":    current(func(c *model.Current) float64 { return float64(c.Visibility) }),
+	"wind_speed":    current(func(c *model.Current) float64 { return c.Wind.Speed }),
+	"wind_gust":     current(func(c *model.Current) float64 { return c.Wind.Gust }),
+	"cloud_cover":   current(func(c *model.Current) float64 { return float64(c.Clouds.All) }),
+	"precipitation": current(precipitation),
+	"precipitating": current(func(c *model.Current) float64 {
+		if isPrecipitating(c) {
+			return 1
+		}
+		return 0
+	}),
+	// 部分数据提供商的实况不包含紫外线指数
+	"uv_index": func(in *Input) (float64, bool) {
+		if in.Current.UVIndex == nil {
+			return 0, false
+		}
+		return *in.Current.UVIndex, true
+	},
+	// 未来各预报时段降水概率的最大值（%）
+	"forecast_precip_probability": func(in *Input) (float64, bool) {
+		var pop float64
+		ok := in.eachForecast(func(f *model.ForecastItem) {
+			pop = math.Max(pop, f.PrecipProbability*100)
+		})
+		return pop, ok
+	},
+	// 未来各预报时段的降水总量（mm）
+	"forecast_precipitation": func(in *Input) (float64, bool) {
+		var total float64
+		ok := in.eachForecast(func(f *model.ForecastItem) {
+			total += f.Rain + f.Snow
+		})
+		return total, ok
+	},
+}
+
+// eachForecast 对与 [Now, Now+forecastWindow) 有重叠的预报时段调用 f，没有这样的时段时返回 false
+func (in *Input) eachForecast(f func(item *model.ForecastItem)) bool {
+	if in.Forecast == nil {
+		return false
+	}
+	end := in.Now.Add(forecastWindow)
+	found := false
+	for i := range in.Forecast.Items {
+		item := &in.Forecast.Items[i]
+		if !item.Time.Before(end) || !item.Time.Add(forecastStep).After(in.Now) {
+			continue
+		}
+		f(item)
+		found = true
+	}
+	return found
+}
+
+// Engine 生活指数计算引擎
+type Engine struct {
+	rules *RuleSet
+}
+
+// NewEngine 创建生活指数计算引擎，path 为空时使用内置规则表
+func NewEngine(path string) (*Engine, error) {
+	data := defaultRules
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("读取生活指数规则文件失败: %w", err)
+		}
+	}
+
+	var rules RuleSet
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("解析生活指数规则失败: %w", err)
+	}
+	if err := rules.validate(); err != nil {
+		return nil, fmt.Errorf("生活指数规则无效: %w", err)
+	}
+
+	return &Engine{rules: &rules}, nil
+}
+
+// Evaluate 计算全部生活指数，必需指标缺少数据的指数不返回
+func (e *Engine) Evaluate(in *Input, lang string) []model.LifeIndex {
+	result := make([]model.LifeIndex, 0, len(e.rules.Indices))
+	for _, index := range e.rules.Indices {
+		if !index.available(in) {
+			continue
+		}
+		for _, level := range index.Levels {
+			if !level.matches(in) {
+				continue
+			}
+			result = append(result, model.LifeIndex{
+				Key:    index.Key,
+				Name:   localize(index.Name, lang),
+				Level:  level.Level,
+				Label:  localize(level.Label, lang),
+				Advice: localize(level.Advice, lang),
+			})
+			break
+		}
+	}
+	return result
+}
+
+// available 判断指数的必需指标是否都有数据
+func (r *IndexRule) available(in *Input) bool {
+	for _, metric := range r.Requires {
+		if _, ok := metricFuncs[metric](in); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matches 判断等级的全部条件是否满足，缺少数据的指标不满足条件
+func (l *LevelRule) matches(in *Input) bool {
+	for _, cond := range l.When {
+		value, ok := metricFuncs[cond.Metric](in)
+		if !ok {
+			return false
+		}
+		if cond.Min != nil && value < *cond.Min {
+			return false
+		}
+		if cond.Max != nil && value >= *cond.Max {
+			return false
+		}
+	}
+	return true
+}
+
+// validate 验证规则表，确保指标名称有效且每个指数都有兜底等级
+func (r *RuleSet) validate() error {
+	if len(r.Indices) == 0 {
+		return fmt.Errorf("至少需要定义一个指数")
+	}
+
+	seen := make(map[string]bool)
+	for _, index := range r.Indices {
+		if index.Key == "" {
+			return fmt.Errorf("指数 key 不能为空")
+		}
+		if seen[index.Key] {
+			return fmt.Errorf("指数 %s 重复定义", index.Key)
+		}
+		seen[index.Key] = true
+
+		for _, metric := range index.Requires {
+			if _, ok := metricFuncs[metric]; !ok {
+				return fmt.Errorf("指数 %s 引用了未知指标 %s", index.Key, metric)
+			}
+		}
+		if len(index.Levels) == 0 {
+			return fmt.Errorf("指数 %s 没有定义等级", index.Key)
+		}
+		for _, level := range index.Levels {
+			for _, cond := range level.When {
+				if _, ok := metricFuncs[cond.Metric]; !ok {
+					return fmt.Errorf("指数 %s 引用了未知指标 %s", index.Key, cond.Metric)
+				}
+				if cond.Min == nil && cond.Max == nil {
+					return fmt.Errorf("指数 %s 的条件 %s 缺少 min 或 max", index.Key, cond.Metric)
+				}
+				if cond.Min != nil && cond.Max != nil && *cond.Min >= *cond.Max {
+					return fmt.Errorf("指数 %s 的条件 %s 范围无效", index.Key, cond.Metric)
+				}
+			}
+		}
+		if last := index.Levels[len(index.Levels)-1]; len(last.When) != 0 {
+			return fmt.Errorf("指数 %s 的最后一个等级必须是无条件的兜底等级", index.Key)
+		}
+	}
+
+	return nil
+}
+
+// localize 按语言取文本，依次回退到语言前缀（如 en_us → en）和默认语言
+func localize(texts map[string]string, lang string) string {
+	lang = strings.ToLower(lang)
+	if text, ok := texts[lang]; ok {
+		return text
+	}
+	if i := strings.IndexAny(lang, "_-"); i > 0 {
+		if text, ok := texts[lang[:i]]; ok {
+			return text
+		}
+	}
+	return texts[defaultLang]
+}
+
+// precipitation 返回最近一小时的降水量（mm），缺少一小时数据时按三小时数据折算
+func precipitation(c *model.Current) float64 {
+	var total float64
+	if c.Rain != nil {
+		if c.Rain.OneHour > 0 {
+			total += c.Rain.OneHour
+		} else {
+			total += c.Rain.ThreeHour / 3
+		}
+	}
+	if c.Snow != nil {
+		if c.Snow.OneHour > 0 {
+			total += c.Snow.OneHour
+		} else {
+			total += c.Snow.ThreeHour / 3
+		}
+	}
+	return total
+}
+
+// isPrecipitating 判断当前是否有降水
+func isPrecipitating(c *model.Current) bool {
+	if precipitation(c) > 0 {
+		return true
+	}
+	for _, w := range c.Weather {
+		switch w.Main {
+		case "Rain", "Drizzle", "Thunderstorm", "Snow":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package indices
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+func TestEngine_Evaluate(t *testing.T) {
+	engine, err := NewEngine("")
+	if err != nil {
+		t.Fatalf("加载内置规则失败: %v", err)
+	}
+
+	current := &model.Current{
+		Temperature: 20,
+		FeelsLike:   20,
+		TempMin:     16,
+		TempMax:     23,
+		Humidity:    55,
+		Wind:        model.Wind{Speed: 3},
+		Weather:     []model.Weather{{ID: 800, Main: "Clear"}},
+	}
+
+	levels := make(map[string]model.LifeIndex)
+	for _, index := range engine.Evaluate(&Input{Current: current}, "zh_cn") {
+		levels[index.Key] = index
+	}
+
+	// 实况没有紫外线数据时不返回紫外线指数，而不是落到兜底等级
+	if index, ok := levels["uv"]; ok {
+		t.Errorf("缺少紫外线数据时期望不返回紫外线指数，实际为 %+v", index)
+	}
+
+	expected := map[string]string{
+		"clothing":  "舒适",
+		"car_wash":  "适宜",
+		"exercise":  "适宜",
+		"cold_risk": "少发",
+	}
+	for key, label := range expected {
+		if got := levels[key].Label; got != label {
+			t.Errorf("%s: 期望等级为 %s，实际为 %s", key, label, got)
+		}
+	}
+
+	uv := 1.5
+	current.UVIndex = &uv
+	for _, index := range engine.Evaluate(&Input{Current: current}, "zh_cn") {
+		if index.Key == "uv" && index.Label != "最弱" {
+			t.Errorf("uv: 期望等级为 最弱，实际为 %s", index.Label)
+		}
+	}
+
+	// 降水时不宜洗车和户外运动
+	current.Weather = []model.Weather{{ID: 500, Main: "Rain"}}
+	current.Rain = &model.Rain{OneHour: 1.2}
+	for _, index := range engine.Evaluate(&Input{Current: current}, "en") {
+		if (index.Key == "car_wash" || index.Key == "exercise") && index.Label != "Unsuitable" {
+			t.Errorf("%s: 期望降水时为 Unsuitable，实际为 %s", index.Key, index.Label)
+		}
+	}
+}
+
+func TestEngine_EvaluateForecast(t *testing.T) {
+	engine, err := NewEngine("")
+	if err != nil {
+		t.Fatalf("加载内置规则失败: %v", err)
+	}
+
+	now := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	current := &model.Current{Temperature: 20, FeelsLike: 20, Humidity: 55, Weather: []model.Weather{{ID: 800, Main: "Clear"}}}
+	tests := []struct {
+		name     string
+		forecast *model.Forecast
+		label    string
+	}{
+		{"没有预报", nil, "适宜"},
+		{"12 小时内可能降水", &model.Forecast{Items: []model.ForecastItem{
+			{Time: now.Add(-time.Hour), PrecipProbability: 0.1},
+			{Time: now.Add(9 * time.Hour), PrecipProbability: 0.8, Rain: 2},
+		}}, "较不宜"},
+		{"降水在 12 小时之后", &model.Forecast{Items: []model.ForecastItem{
+			{Time: now.Add(12 * time.Hour), PrecipProbability: 0.9},
+		}}, "适宜"},
+		{"已经结束的时段", &model.Forecast{Items: []model.ForecastItem{
+			{Time: now.Add(-3 * time.Hour), PrecipProbability: 0.9},
+		}}, "适宜"},
+	}
+	for _, tt := range tests {
+		for _, index := range engine.Evaluate(&Input{Current: current, Forecast: tt.forecast, Now: now}, "zh_cn") {
+			if index.Key == "car_wash" && index.Label != tt.label {
+				t.Errorf("%s: 期望洗车指数为 %s，实际为 %s", tt.name, tt.label, index.Label)
+			}
+		}
+	}
+
+	in := &Input{Current: current, Forecast: tests[1].forecast, Now: now}
+	if total, ok := metricFuncs["forecast_precipitation"](in); !ok || total != 2 {
+		t.Errorf("期望未来降水量为 2，实际为 %v (%v)", total, ok)
+	}
+}
+
+func TestNewEngineInvalidRules(t *testing.T) {
+	tests := map[string]string{
+		"未知指标":   `{"indices":[{"key":"a","levels":[{"level":1,"when":[{"metric":"foo","min":1}]},{"level":2}]}]}`,
+		"缺少兜底":   `{"indices":[{"key":"a","levels":[{"level":1,"when":[{"metric":"humidity","min":1}]}]}]}`,
+		"未知必需指标": `{"indices":[{"key":"a","requires":["foo"],"levels":[{"level":1}]}]}`,
+		"范围无效":   `{"indices":[{"key":"a","levels":[{"level":1,"when":[{"metric":"humidity","min":5,"max":1}]},{"level":2}]}]}`,
+	}
+
+	dir := t.TempDir()
+	for name, content := range tests {
+		path := filepath.Join(dir, "rules.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("写入规则文件失败: %v", err)
+		}
+		if _, err := NewEngine(path); err == nil {
+			t.Errorf("%s: 期望返回错误", name)
+		}
+	}
+}
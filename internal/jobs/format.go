@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// 上传和结果支持的格式
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ParseItems 按格式解析上传的位置列表，最多 maxItems 个
+func ParseItems(r io.Reader, format string, maxItems int) ([]model.JobItem, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r, maxItems)
+	case FormatNDJSON:
+		return parseNDJSON(r, maxItems)
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s", format)
+	}
+}
+
+// parseCSV 解析带表头的 CSV，可用列为 ref（或 id）、city、lat、lon、units、lang，其他列会被忽略
+func parseCSV(r io.Reader, maxItems int) ([]model.JobItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("文件为空")
+	} else if err != nil {
+		return nil, fmt.Errorf("读取 CSV 表头失败: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "id" {
+			name = "ref"
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["city"]; !ok {
+		if _, hasLat := columns["lat"]; !hasLat {
+			return nil, fmt.Errorf("CSV 表头必须包含 city 列或 lat、lon 列")
+		}
+	}
+
+	var items []model.JobItem
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("第 %d 行解析失败: %w", line, err)
+		}
+		if len(items) >= maxItems {
+			return nil, fmt.Errorf("单个任务最多包含 %d 个位置", maxItems)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		item := model.JobItem{Ref: field("ref")}
+		item.City = field("city")
+		item.Units = field("units")
+		item.Lang = field("lang")
+		if item.Lat, err = parseCoordinate(field("lat")); err != nil {
+			return nil, fmt.Errorf("第 %d 行纬度格式不正确", line)
+		}
+		if item.Lon, err = parseCoordinate(field("lon")); err != nil {
+			return nil, fmt.Errorf("第 %d 行经度格式不正确", line)
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("文件中没有位置数据")
+	}
+	return items, nil
+}
+
+// parseNDJSON 解析每行一个 JSON 对象的位置列表，空行会被忽略
+func parseNDJSON(r io.Reader, maxItems int) ([]model.JobItem, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var items []model.JobItem
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(items) >= maxItems {
+			return nil, fmt.Errorf("单个任务最多包含 %d 个位置", maxItems)
+		}
+
+		var item model.JobItem
+		if err := json.Unmarshal([]byte(text), &item); err != nil {
+			return nil, fmt.Errorf("第 %d 行解析失败: %w", line, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("文件中没有位置数据")
+	}
+	return items, nil
+}
+
+// parseCoordinate 解析坐标，空字符串视为未提供
+func parseCoordinate(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// csvHeader 结果 CSV 的列
+var csvHeader = []string{
+	"index", "ref", "success", "error", "name", "country", "latitude", "longitude",
+	"temperature", "feels_like", "humidity", "pressure", "wind_speed", "wind_direction",
+	"weather_main", "weather_description", "updated_at",
+}
+
+// convertResultsToCSV 将 NDJSON 结果逐行转换为 CSV
+func convertResultsToCSV(r io.Reader, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(r)
+	for {
+		var result model.JobResult
+		if err := decoder.Decode(&result); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("解析结果失败: %w", err)
+		}
+		if err := writer.Write(resultRecord(&result)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// resultRecord 将单个结果展开为 CSV 行
+func resultRecord(result *model.JobResult) []string {
+	record := make([]string, len(csvHeader))
+	record[0] = strconv.Itoa(result.Index)
+	record[1] = result.Ref
+	record[2] = strconv.FormatBool(result.Success)
+	if result.Error != nil {
+		record[3] = result.Error.Message
+	}
+
+	if data := result.Data; data != nil {
+		formatFloat := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+		record[4] = data.Location.Name
+		record[5] = data.Location.Country
+		record[6] = formatFloat(data.Location.Latitude)
+		record[7] = formatFloat(data.Location.Longitude)
+		record[8] = formatFloat(data.Current.Temperature)
+		record[9] = formatFloat(data.Current.FeelsLike)
+		record[10] = strconv.Itoa(data.Current.Humidity)
+		record[11] = strconv.Itoa(data.Current.Pressure)
+		record[12] = formatFloat(data.Current.Wind.Speed)
+		record[13] = strconv.Itoa(data.Current.Wind.Direction)
+		if len(data.Current.Weather) > 0 {
+			record[14] = data.Current.Weather[0].Main
+			record[15] = data.Current.Weather[0].Description
+		}
+		record[16] = data.Current.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return record
+}
@@ -0,0 +1,392 @@
+// Package jobs 实现异步批量天气任务：上传大量位置后由后台 worker 在上游配额内逐个查询，
+// 任务和结果保存在本地目录中，服务重启后会从中断的位置继续处理
+package jobs
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/quota"
+	"gin-weather/internal/service"
+)
+
+// ErrNotFound 任务不存在
+var ErrNotFound = errors.New("任务不存在")
+
+// saveInterval 处理过程中保存任务进度的最小间隔
+const saveInterval = 2 * time.Second
+
+// Manager 批量任务管理器
+type Manager struct {
+	config         *config.JobsConfig
+	store          *Store
+	weatherService service.WeatherService
+	limiter        *quota.Limiter
+
+	mu      sync.Mutex
+	jobs    map[string]*model.Job
+	pending []string
+	notify  chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager 创建任务管理器并加载磁盘上已有的任务
+func NewManager(cfg *config.JobsConfig, weatherService service.WeatherService) (*Manager, error) {
+	store, err := NewStore(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := store.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		config:         cfg,
+		store:          store,
+		weatherService: weatherService,
+		limiter:        quota.NewLimiter(cfg.RatePerMinute, 1),
+		jobs:           make(map[string]*model.Job, len(jobs)),
+		notify:         make(chan struct{}, 1),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+
+	// 未完成的任务重新排队，按创建时间先后处理
+	var unfinished []*model.Job
+	for _, job := range jobs {
+		m.jobs[job.ID] = job
+		if job.Status != model.JobStatusCompleted {
+			unfinished = append(unfinished, job)
+		}
+	}
+	sort.Slice(unfinished, func(i, j int) bool {
+		return unfinished[i].CreatedAt.Before(unfinished[j].CreatedAt)
+	})
+	for _, job := range unfinished {
+		m.pending = append(m.pending, job.ID)
+	}
+
+	return m, nil
+}
+
+// Start 启动 worker
+func (m *Manager) Start() {
+	for i := 0; i < m.config.Workers; i++ {
+		m.wg.Add(1)
+		go m.worker()
+	}
+	if len(m.pending) > 0 {
+		log.Printf("恢复 %d 个未完成的批量任务", len(m.pending))
+		m.wake()
+	}
+}
+
+// Stop 停止 worker 并等待正在处理的位置完成，未完成的任务会在下次启动时继续
+func (m *Manager) Stop(ctx context.Context) error {
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Submit 创建新任务并排队
+func (m *Manager) Submit(items []model.JobItem, units, lang string) (*model.Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.Job{
+		ID:        id,
+		Status:    model.JobStatusQueued,
+		Total:     len(items),
+		Units:     units,
+		Lang:      lang,
+		CreatedAt: time.Now(),
+	}
+	if err := m.store.Create(job, items); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.jobs[id] = job
+	m.pending = append(m.pending, id)
+	snapshot := *job
+	m.mu.Unlock()
+
+	m.wake()
+	return &snapshot, nil
+}
+
+// Get 返回任务的当前状态
+func (m *Manager) Get(id string) (*model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// WriteResults 将已完成的结果按格式写出，任务未完成时只包含已处理的部分
+func (m *Manager) WriteResults(id, format string, w io.Writer) error {
+	if _, err := m.Get(id); err != nil {
+		return err
+	}
+
+	f, err := m.store.OpenResults(id)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// 只读取已完整写入的行，避免读到 worker 正在追加的半行
+	r := &completeLineReader{r: bufio.NewReader(f)}
+	switch format {
+	case FormatCSV:
+		return convertResultsToCSV(r, w)
+	default:
+		_, err := io.Copy(w, r)
+		return err
+	}
+}
+
+// worker 从队列中取出任务并处理
+func (m *Manager) worker() {
+	defer m.wg.Done()
+
+	for {
+		id, ok := m.next()
+		if !ok {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-m.notify:
+				continue
+			}
+		}
+
+		if err := m.process(id); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("批量任务 %s 处理失败: %v", id, err)
+			m.fail(id, err)
+		}
+		if m.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// next 取出下一个排队的任务。队列中还有任务时继续唤醒下一个空闲的 worker，
+// 连续提交多个任务时只发出一次通知，由取到任务的 worker 依次传递
+func (m *Manager) next() (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.pending) == 0 || m.ctx.Err() != nil {
+		return "", false
+	}
+	id := m.pending[0]
+	m.pending = m.pending[1:]
+	if len(m.pending) > 0 {
+		m.wake()
+	}
+	return id, true
+}
+
+// fail 把任务标记为失败，避免读写任务文件出错后一直停留在处理中
+func (m *Manager) fail(id string, err error) {
+	m.mu.Lock()
+	job := m.jobs[id]
+	finished := time.Now()
+	job.Status = model.JobStatusFailed
+	job.Error = err.Error()
+	job.FinishedAt = &finished
+	m.mu.Unlock()
+
+	if err := m.save(job); err != nil {
+		log.Printf("保存批量任务 %s 的失败状态失败: %v", id, err)
+	}
+}
+
+// wake 唤醒空闲的 worker
+func (m *Manager) wake() {
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
+
+// process 处理单个任务，从结果文件中已有的位置之后继续
+func (m *Manager) process(id string) error {
+	items, err := m.store.Items(id)
+	if err != nil {
+		return err
+	}
+	succeeded, failed, err := m.store.RecoverResults(id)
+	if err != nil {
+		return err
+	}
+	out, err := m.store.AppendResults(id)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	m.mu.Lock()
+	job := m.jobs[id]
+	now := time.Now()
+	job.Status = model.JobStatusRunning
+	job.Error, job.FinishedAt = "", nil
+	if job.StartedAt == nil {
+		job.StartedAt = &now
+	}
+	job.Succeeded, job.Failed = succeeded, failed
+	updateProgress(job)
+	m.mu.Unlock()
+	if err := m.save(job); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	lastSave := time.Now()
+	for i := succeeded + failed; i < len(items); i++ {
+		result, err := m.fetch(i, &items[i], job)
+		if err != nil {
+			// 服务正在关闭，保存进度后退出
+			m.save(job)
+			return err
+		}
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("写入结果失败: %w", err)
+		}
+
+		m.mu.Lock()
+		if result.Success {
+			job.Succeeded++
+		} else {
+			job.Failed++
+		}
+		updateProgress(job)
+		m.mu.Unlock()
+
+		if time.Since(lastSave) >= saveInterval {
+			if err := m.save(job); err != nil {
+				return err
+			}
+			lastSave = time.Now()
+		}
+	}
+
+	m.mu.Lock()
+	finished := time.Now()
+	job.Status = model.JobStatusCompleted
+	job.FinishedAt = &finished
+	m.mu.Unlock()
+	return m.save(job)
+}
+
+// fetch 在配额内查询单个位置，仅在服务关闭时返回错误
+func (m *Manager) fetch(index int, item *model.JobItem, job *model.Job) (*model.JobResult, error) {
+	result := &model.JobResult{Index: index, Ref: item.Ref}
+
+	req := item.WeatherRequest
+	if req.Units == "" {
+		req.Units = job.Units
+	}
+	if req.Lang == "" {
+		req.Lang = job.Lang
+	}
+	if err := req.Validate(); err != nil {
+		result.Error = &model.ErrorResponse{Error: "参数验证失败", Code: http.StatusBadRequest, Message: err.Error()}
+		return result, nil
+	}
+
+	if err := m.limiter.Wait(m.ctx); err != nil {
+		return nil, err
+	}
+
+	weather, err := service.Fetch(m.weatherService, &req)
+	if err != nil {
+		result.Error = &model.ErrorResponse{Error: "获取天气信息失败", Code: http.StatusInternalServerError, Message: err.Error()}
+		return result, nil
+	}
+	result.Success = true
+	result.Data = weather
+	return result, nil
+}
+
+// save 保存任务进度
+func (m *Manager) save(job *model.Job) error {
+	m.mu.Lock()
+	snapshot := *job
+	m.mu.Unlock()
+	return m.store.Save(&snapshot)
+}
+
+// updateProgress 根据成功和失败数量更新进度
+func updateProgress(job *model.Job) {
+	job.Processed = job.Succeeded + job.Failed
+	if job.Total > 0 {
+		job.Progress = float64(job.Processed*10000/job.Total) / 100
+	}
+}
+
+// newJobID 生成随机任务 ID
+func newJobID() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成任务 ID 失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// completeLineReader 只返回以换行符结尾的完整行，末尾不完整的行会被丢弃
+type completeLineReader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func (c *completeLineReader) Read(p []byte) (int, error) {
+	if len(c.buf) == 0 {
+		line, err := c.r.ReadBytes('\n')
+		if err != nil {
+			return 0, io.EOF
+		}
+		c.buf = line
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,260 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+)
+
+// mockService 模拟天气服务，城市名为 bad 时返回错误
+type mockService struct{}
+
+func (m *mockService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	if city == "bad" {
+		return nil, fmt.Errorf("city not found")
+	}
+	return &model.WeatherResponse{
+		Location: model.Location{Name: city},
+		Current:  model.Current{Temperature: 20, Weather: []model.Weather{{Main: "Clear", Description: "晴"}}},
+	}, nil
+}
+
+func (m *mockService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity(fmt.Sprintf("%.2f,%.2f", lat, lon), units, lang)
+}
+
+func (m *mockService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity(city, units, lang)
+}
+
+func (m *mockService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCoordinates(lat, lon, units, lang)
+}
+
+func newTestManager(t *testing.T, dir string) *Manager {
+	t.Helper()
+	m, err := NewManager(&config.JobsConfig{Dir: dir, Workers: 2, RatePerMinute: 60000}, &mockService{})
+	if err != nil {
+		t.Fatalf("创建任务管理器失败: %v", err)
+	}
+	return m
+}
+
+func waitForCompletion(t *testing.T, m *Manager, id string) *model.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("查询任务失败: %v", err)
+		}
+		if job.Status == model.JobStatusCompleted {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("等待任务完成超时")
+	return nil
+}
+
+func TestManager_ProcessCSV(t *testing.T) {
+	m := newTestManager(t, t.TempDir())
+	m.Start()
+	defer m.Stop(context.Background())
+
+	input := "ref,city,lat,lon\nS1,Beijing,,\nS2,,31.23,121.47\nS3,bad,,\nS4,,91,0\n"
+	items, err := ParseItems(strings.NewReader(input), FormatCSV, 100)
+	if err != nil {
+		t.Fatalf("解析 CSV 失败: %v", err)
+	}
+
+	job, err := m.Submit(items, "metric", "zh_cn")
+	if err != nil {
+		t.Fatalf("提交任务失败: %v", err)
+	}
+
+	job = waitForCompletion(t, m, job.ID)
+	if job.Total != 4 || job.Succeeded != 2 || job.Failed != 2 || job.Progress != 100 {
+		t.Errorf("任务统计不正确: %+v", job)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteResults(job.ID, FormatNDJSON, &buf); err != nil {
+		t.Fatalf("读取结果失败: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("期望 4 行结果，实际为 %d", len(lines))
+	}
+	for i, line := range lines {
+		var result model.JobResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatalf("解析结果失败: %v", err)
+		}
+		if result.Index != i || result.Ref != fmt.Sprintf("S%d", i+1) {
+			t.Errorf("第 %d 行结果顺序不正确: index=%d ref=%s", i, result.Index, result.Ref)
+		}
+	}
+
+	buf.Reset()
+	if err := m.WriteResults(job.ID, FormatCSV, &buf); err != nil {
+		t.Fatalf("读取 CSV 结果失败: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "index,ref,success") || !strings.Contains(buf.String(), "0,S1,true,,Beijing") {
+		t.Errorf("CSV 结果不正确: %s", buf.String())
+	}
+}
+
+func TestManager_ResumeAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	// 第一次启动只提交任务，不启动 worker，模拟处理到一半时服务重启
+	m := newTestManager(t, dir)
+	items := []model.JobItem{
+		{Ref: "a", WeatherRequest: model.WeatherRequest{City: "A"}},
+		{Ref: "b", WeatherRequest: model.WeatherRequest{City: "B"}},
+		{Ref: "c", WeatherRequest: model.WeatherRequest{City: "C"}},
+	}
+	job, err := m.Submit(items, "metric", "zh_cn")
+	if err != nil {
+		t.Fatalf("提交任务失败: %v", err)
+	}
+
+	// 写入一个完整结果和一个不完整的行
+	partial := `{"index":0,"ref":"a","success":true,"data":{"location":{"name":"A"}}}` + "\n" + `{"index":1,"ref":"b","succ`
+	if err := os.WriteFile(filepath.Join(dir, job.ID, resultsFile), []byte(partial), 0o644); err != nil {
+		t.Fatalf("写入结果失败: %v", err)
+	}
+
+	restarted := newTestManager(t, dir)
+	restarted.Start()
+	defer restarted.Stop(context.Background())
+
+	job = waitForCompletion(t, restarted, job.ID)
+	if job.Succeeded != 3 {
+		t.Errorf("期望成功 3 个，实际为 %d", job.Succeeded)
+	}
+
+	var buf bytes.Buffer
+	if err := restarted.WriteResults(job.ID, FormatNDJSON, &buf); err != nil {
+		t.Fatalf("读取结果失败: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], `"ref":"b"`) || !strings.Contains(lines[2], `"ref":"c"`) {
+		t.Errorf("恢复后的结果不正确: %s", buf.String())
+	}
+}
+
+// blockingService 每次查询先报告到达，再等待测试放行，用于观察并发处理的 worker 数量
+type blockingService struct {
+	mockService
+	arrived chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	b.arrived <- struct{}{}
+	<-b.release
+	return b.mockService.GetWeatherByCity(city, units, lang)
+}
+
+func TestManager_BurstUsesAllWorkers(t *testing.T) {
+	svc := &blockingService{arrived: make(chan struct{}, 2), release: make(chan struct{})}
+	m, err := NewManager(&config.JobsConfig{Dir: t.TempDir(), Workers: 2, RatePerMinute: 60000}, svc)
+	if err != nil {
+		t.Fatalf("创建任务管理器失败: %v", err)
+	}
+	m.Start()
+	defer m.Stop(context.Background())
+	time.Sleep(20 * time.Millisecond) // 等待两个 worker 进入空闲等待
+
+	// 模拟两次提交之间 worker 还没来得及取走通知：两个任务入队，只发出一次唤醒
+	var ids []string
+	m.mu.Lock()
+	for _, city := range []string{"A", "B"} {
+		job := &model.Job{ID: "job-" + city, Status: model.JobStatusQueued, Total: 1, Units: "metric", Lang: "zh_cn", CreatedAt: time.Now()}
+		if err := m.store.Create(job, []model.JobItem{{WeatherRequest: model.WeatherRequest{City: city}}}); err != nil {
+			t.Fatalf("创建任务失败: %v", err)
+		}
+		m.jobs[job.ID] = job
+		m.pending = append(m.pending, job.ID)
+		ids = append(ids, job.ID)
+	}
+	m.mu.Unlock()
+	m.wake()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-svc.arrived:
+		case <-time.After(time.Second):
+			close(svc.release)
+			t.Fatal("期望两个 worker 同时处理，实际只有一个 worker 被唤醒")
+		}
+	}
+	close(svc.release)
+
+	for _, id := range ids {
+		waitForCompletion(t, m, id)
+	}
+}
+
+func TestManager_FailedJob(t *testing.T) {
+	dir := t.TempDir()
+	m := newTestManager(t, dir)
+	job, err := m.Submit([]model.JobItem{{WeatherRequest: model.WeatherRequest{City: "A"}}}, "metric", "zh_cn")
+	if err != nil {
+		t.Fatalf("提交任务失败: %v", err)
+	}
+
+	// 位置列表文件丢失，任务无法处理，应该标记为失败而不是停留在处理中
+	if err := os.Remove(filepath.Join(dir, job.ID, inputFile)); err != nil {
+		t.Fatalf("删除位置列表失败: %v", err)
+	}
+	m.Start()
+	defer m.Stop(context.Background())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, _ = m.Get(job.ID); job.Status == model.JobStatusFailed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != model.JobStatusFailed || job.Error == "" || job.FinishedAt == nil {
+		t.Fatalf("期望任务失败并记录原因，实际为 %+v", job)
+	}
+
+	// 失败状态写入磁盘，重启后重新排队
+	restarted := newTestManager(t, dir)
+	if got, _ := restarted.Get(job.ID); got.Status != model.JobStatusFailed || len(restarted.pending) != 1 {
+		t.Errorf("期望重启后读取到失败状态并重新排队，实际为 %+v（排队 %d 个）", got, len(restarted.pending))
+	}
+}
+
+func TestParseItemsInvalid(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+	}{
+		{FormatCSV, "name\nBeijing\n"},
+		{FormatCSV, "city,lat,lon\n,abc,1\n"},
+		{FormatNDJSON, "{\"city\":\"Beijing\"}\nnot json\n"},
+		{FormatNDJSON, "\n\n"},
+		{FormatCSV, "city\nA\nB\nC\n"},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseItems(strings.NewReader(tt.input), tt.format, 2); err == nil {
+			t.Errorf("%s %q: 期望返回错误", tt.format, tt.input)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gin-weather/internal/model"
+)
+
+// 任务目录中的文件
+const (
+	jobFile     = "job.json"       // 任务元数据
+	inputFile   = "input.ndjson"   // 上传的位置列表
+	resultsFile = "results.ndjson" // 按顺序追加的结果
+)
+
+// Store 基于本地目录的任务存储，每个任务一个子目录
+type Store struct {
+	dir string
+}
+
+// NewStore 创建任务存储，目录不存在时自动创建
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建任务目录失败: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Create 保存新任务及其位置列表
+func (s *Store) Create(job *model.Job, items []model.JobItem) error {
+	dir := s.jobDir(job.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建任务目录失败: %w", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i := range items {
+		if err := encoder.Encode(&items[i]); err != nil {
+			return fmt.Errorf("序列化位置失败: %w", err)
+		}
+	}
+	if err := writeFileAtomic(filepath.Join(dir, inputFile), buf.Bytes()); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, resultsFile), nil, 0o644); err != nil {
+		return fmt.Errorf("创建结果文件失败: %w", err)
+	}
+
+	return s.Save(job)
+}
+
+// Save 保存任务元数据
+func (s *Store) Save(job *model.Job) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化任务失败: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(s.jobDir(job.ID), jobFile), data)
+}
+
+// LoadAll 加载全部任务元数据
+func (s *Store) LoadAll() ([]*model.Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取任务目录失败: %w", err)
+	}
+
+	var jobs []*model.Job
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name(), jobFile))
+		if err != nil {
+			// 创建过程中中断的任务没有元数据，跳过
+			continue
+		}
+		var job model.Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("解析任务 %s 失败: %w", entry.Name(), err)
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// Items 读取任务的位置列表
+func (s *Store) Items(id string) ([]model.JobItem, error) {
+	f, err := os.Open(filepath.Join(s.jobDir(id), inputFile))
+	if err != nil {
+		return nil, fmt.Errorf("打开位置列表失败: %w", err)
+	}
+	defer f.Close()
+
+	var items []model.JobItem
+	decoder := json.NewDecoder(f)
+	for {
+		var item model.JobItem
+		if err := decoder.Decode(&item); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("解析位置列表失败: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// RecoverResults 统计已写入的结果，并截掉进程中断时可能残留的不完整行
+func (s *Store) RecoverResults(id string) (succeeded, failed int, err error) {
+	path := filepath.Join(s.jobDir(id), resultsFile)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return 0, 0, fmt.Errorf("打开结果文件失败: %w", err)
+	}
+	defer f.Close()
+
+	var valid int64
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, 0, fmt.Errorf("读取结果文件失败: %w", err)
+		}
+
+		var result model.JobResult
+		if json.Unmarshal(line, &result) != nil {
+			break
+		}
+		valid += int64(len(line))
+		if result.Success {
+			succeeded++
+		} else {
+			failed++
+		}
+	}
+
+	if err := f.Truncate(valid); err != nil {
+		return 0, 0, fmt.Errorf("修复结果文件失败: %w", err)
+	}
+	return succeeded, failed, nil
+}
+
+// AppendResults 以追加方式打开结果文件
+func (s *Store) AppendResults(id string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(s.jobDir(id), resultsFile), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开结果文件失败: %w", err)
+	}
+	return f, nil
+}
+
+// OpenResults 以只读方式打开结果文件
+func (s *Store) OpenResults(id string) (*os.File, error) {
+	f, err := os.Open(filepath.Join(s.jobDir(id), resultsFile))
+	if err != nil {
+		return nil, fmt.Errorf("打开结果文件失败: %w", err)
+	}
+	return f, nil
+}
+
+// jobDir 返回任务目录
+func (s *Store) jobDir(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+// writeFileAtomic 先写临时文件再重命名，避免进程中断时留下不完整的文件
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return nil
+}
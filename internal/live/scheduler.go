@@ -0,0 +1,242 @@
+// Package live 提供共享的天气刷新调度器：同一位置无论有多少订阅者都只轮询一次上游，
+// 数据发生变化时推送给全部订阅者，供 SSE 和 WebSocket 等实时接口使用
+package live
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// ErrClosed 调度器已关闭
+var ErrClosed = errors.New("实时推送服务已关闭")
+
+// Update 推送给订阅者的更新
+type Update struct {
+	Key     string                 // 位置标识
+	Weather *model.WeatherResponse // 最新天气数据，获取失败时为 nil
+	Err     error                  // 获取失败的原因
+}
+
+// Scheduler 共享刷新调度器
+type Scheduler struct {
+	weatherService service.WeatherService
+	interval       time.Duration
+
+	mu     sync.Mutex
+	topics map[string]*topic
+	closed bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// topic 单个位置的轮询状态
+type topic struct {
+	key         string
+	req         model.WeatherRequest
+	subs        map[*Subscription]struct{}
+	latest      *model.WeatherResponse
+	fingerprint []byte
+	cancel      context.CancelFunc
+}
+
+// Subscription 对单个位置的订阅
+type Subscription struct {
+	scheduler *Scheduler
+	key       string
+	ch        chan Update
+	once      sync.Once
+}
+
+// NewScheduler 创建刷新调度器，interval 为每个位置的轮询间隔
+func NewScheduler(weatherService service.WeatherService, interval time.Duration) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		weatherService: weatherService,
+		interval:       interval,
+		topics:         make(map[string]*topic),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// LocationKey 返回位置的标识，相同城市或坐标、单位和语言的请求共享同一个轮询
+func LocationKey(req *model.WeatherRequest) string {
+	if req.City != "" {
+		return fmt.Sprintf("city:%s|%s|%s", strings.ToLower(strings.TrimSpace(req.City)), req.Units, req.Lang)
+	}
+	return fmt.Sprintf("coord:%.4f,%.4f|%s|%s", req.Lat, req.Lon, req.Units, req.Lang)
+}
+
+// Subscribe 订阅位置的天气更新，已有数据时会立即推送一次
+func (s *Scheduler) Subscribe(req model.WeatherRequest) (*Subscription, error) {
+	key := LocationKey(&req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrClosed
+	}
+
+	sub := &Subscription{scheduler: s, key: key, ch: make(chan Update, 1)}
+
+	t, ok := s.topics[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(s.ctx)
+		t = &topic{key: key, req: req, subs: make(map[*Subscription]struct{}), cancel: cancel}
+		s.topics[key] = t
+		s.wg.Add(1)
+		go s.poll(ctx, t)
+	}
+	t.subs[sub] = struct{}{}
+
+	if t.latest != nil {
+		sub.deliver(Update{Key: key, Weather: t.latest})
+	}
+	return sub, nil
+}
+
+// Close 停止全部轮询并关闭所有订阅的通道
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.cancel()
+	for key, t := range s.topics {
+		for sub := range t.subs {
+			close(sub.ch)
+		}
+		delete(s.topics, key)
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Done 返回调度器关闭时会被关闭的通道
+func (s *Scheduler) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Topics 返回当前正在轮询的位置数量
+func (s *Scheduler) Topics() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.topics)
+}
+
+// Key 返回订阅的位置标识
+func (sub *Subscription) Key() string {
+	return sub.key
+}
+
+// Updates 返回接收更新的通道，调度器关闭时通道会被关闭
+func (sub *Subscription) Updates() <-chan Update {
+	return sub.ch
+}
+
+// Close 取消订阅，最后一个订阅者离开时停止该位置的轮询
+func (sub *Subscription) Close() {
+	sub.once.Do(func() {
+		s := sub.scheduler
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		t, ok := s.topics[sub.key]
+		if !ok {
+			// 调度器已关闭，通道已经被关闭
+			return
+		}
+		if _, ok := t.subs[sub]; !ok {
+			return
+		}
+		delete(t.subs, sub)
+		close(sub.ch)
+
+		if len(t.subs) == 0 {
+			t.cancel()
+			delete(s.topics, sub.key)
+		}
+	})
+}
+
+// deliver 推送更新，订阅者来不及消费时只保留最新的一条，调用方需持有调度器的锁
+func (sub *Subscription) deliver(update Update) {
+	select {
+	case sub.ch <- update:
+		return
+	default:
+	}
+	select {
+	case <-sub.ch:
+	default:
+	}
+	sub.ch <- update
+}
+
+// poll 定期获取位置的天气，数据变化或获取失败时通知订阅者
+func (s *Scheduler) poll(ctx context.Context, t *topic) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		weather, err := service.Fetch(s.weatherService, &t.req)
+		if ctx.Err() != nil {
+			return
+		}
+		s.publish(t, weather, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publish 将获取结果推送给订阅者，数据没有变化时不推送
+func (s *Scheduler) publish(t *topic, weather *model.WeatherResponse, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.topics[t.key] != t {
+		return
+	}
+
+	update := Update{Key: t.key, Weather: weather, Err: err}
+	if err == nil {
+		fp := fingerprint(weather)
+		if t.latest != nil && bytes.Equal(fp, t.fingerprint) {
+			return
+		}
+		t.latest, t.fingerprint = weather, fp
+	}
+
+	for sub := range t.subs {
+		sub.deliver(update)
+	}
+}
+
+// fingerprint 计算天气数据的指纹，忽略每次请求都会变化的响应时间戳
+func fingerprint(weather *model.WeatherResponse) []byte {
+	copied := *weather
+	copied.Timestamp = 0
+	data, _ := json.Marshal(&copied)
+	return data
+}
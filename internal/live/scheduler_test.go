@@ -0,0 +1,128 @@
+package live
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// sequenceService 按调用次数返回不同温度的模拟天气服务
+type sequenceService struct {
+	mu    sync.Mutex
+	calls int
+	temps []float64
+}
+
+func (s *sequenceService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	temp := s.temps[min(s.calls, len(s.temps)-1)]
+	s.calls++
+	return &model.WeatherResponse{
+		Location:  model.Location{Name: city},
+		Current:   model.Current{Temperature: temp},
+		Timestamp: time.Now().UnixNano(),
+	}, nil
+}
+
+func (s *sequenceService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return s.GetWeatherByCity("coords", units, lang)
+}
+
+func (s *sequenceService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.GetWeatherByCity(city, units, lang)
+}
+
+func (s *sequenceService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.GetWeatherByCoordinates(lat, lon, units, lang)
+}
+
+func (s *sequenceService) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func receive(t *testing.T, sub *Subscription) Update {
+	t.Helper()
+	select {
+	case update, ok := <-sub.Updates():
+		if !ok {
+			t.Fatal("订阅通道意外关闭")
+		}
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("等待更新超时")
+	}
+	return Update{}
+}
+
+func TestScheduler_SharedPollingAndChangeDetection(t *testing.T) {
+	svc := &sequenceService{temps: []float64{20, 20, 20, 21}}
+	scheduler := NewScheduler(svc, 20*time.Millisecond)
+	defer scheduler.Close()
+
+	req := model.WeatherRequest{City: "Beijing", Units: "metric", Lang: "zh_cn"}
+	first, err := scheduler.Subscribe(req)
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	if update := receive(t, first); update.Weather.Current.Temperature != 20 {
+		t.Errorf("期望首次推送 20°C，实际为 %.0f", update.Weather.Current.Temperature)
+	}
+
+	// 同一位置的第二个订阅者立即收到缓存数据，并且共享同一个轮询
+	req.City = " beijing "
+	second, err := scheduler.Subscribe(req)
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	if update := receive(t, second); update.Weather.Current.Temperature != 20 {
+		t.Errorf("期望立即收到缓存的 20°C，实际为 %.0f", update.Weather.Current.Temperature)
+	}
+	if scheduler.Topics() != 1 {
+		t.Errorf("期望只有 1 个轮询，实际为 %d", scheduler.Topics())
+	}
+
+	// 温度未变化的轮询不会推送，变为 21°C 后两个订阅者都会收到
+	for _, sub := range []*Subscription{first, second} {
+		if update := receive(t, sub); update.Weather.Current.Temperature != 21 {
+			t.Errorf("期望推送 21°C，实际为 %.0f", update.Weather.Current.Temperature)
+		}
+	}
+
+	first.Close()
+	second.Close()
+	if scheduler.Topics() != 0 {
+		t.Errorf("期望最后一个订阅者离开后停止轮询，实际仍有 %d 个", scheduler.Topics())
+	}
+
+	calls := svc.callCount()
+	time.Sleep(60 * time.Millisecond)
+	if svc.callCount() > calls+1 {
+		t.Errorf("期望停止轮询后不再请求天气服务，调用次数从 %d 增加到 %d", calls, svc.callCount())
+	}
+}
+
+func TestScheduler_Close(t *testing.T) {
+	scheduler := NewScheduler(&sequenceService{temps: []float64{20}}, time.Hour)
+
+	sub, err := scheduler.Subscribe(model.WeatherRequest{Lat: 31.23, Lon: 121.47})
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	receive(t, sub)
+
+	scheduler.Close()
+	if _, ok := <-sub.Updates(); ok {
+		t.Error("期望调度器关闭后订阅通道被关闭")
+	}
+	sub.Close()
+
+	if _, err := scheduler.Subscribe(model.WeatherRequest{City: "Beijing"}); err != ErrClosed {
+		t.Errorf("期望关闭后订阅返回 ErrClosed，实际为 %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+package model
+
+// AreaResponse 附近城市或经纬度范围查询的结果
+type AreaResponse struct {
+	Count     int               `json:"count"`     // 返回的城市数
+	Truncated bool              `json:"truncated"` // 是否还有更多城市因数量上限未返回
+	Items     []WeatherResponse `json:"items"`     // 城市天气，附近城市按距离由近到远排列
+}
@@ -0,0 +1,23 @@
+package model
+
+// BatchRequest 批量天气查询请求
+type BatchRequest struct {
+	Locations []WeatherRequest `json:"locations" binding:"required"`                             // 位置列表（城市名称或坐标）
+	Units     string           `json:"units" binding:"omitempty,oneof=metric imperial standard"` // 默认单位系统
+	Lang      string           `json:"lang"`                                                     // 默认语言
+}
+
+// BatchItemResult 批量查询中单个位置的结果
+type BatchItemResult struct {
+	Index   int              `json:"index"`           // 在请求列表中的下标
+	Success bool             `json:"success"`         // 该位置是否查询成功
+	Data    *WeatherResponse `json:"data,omitempty"`  // 天气数据
+	Error   *ErrorResponse   `json:"error,omitempty"` // 错误信息
+}
+
+// BatchResponse 批量天气查询响应
+type BatchResponse struct {
+	Results   []BatchItemResult `json:"results"`   // 按请求顺序排列的结果
+	Succeeded int               `json:"succeeded"` // 成功数量
+	Failed    int               `json:"failed"`    // 失败数量
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// CalendarInfo 农历与节气信息
+type CalendarInfo struct {
+	Date        string    `json:"date"`         // 公历日期（YYYY-MM-DD）
+	Timezone    string    `json:"timezone"`     // 计算所用时区
+	Lunar       LunarDate `json:"lunar"`        // 农历日期
+	GanzhiYear  string    `json:"ganzhi_year"`  // 干支纪年，如 甲辰
+	Zodiac      string    `json:"zodiac"`       // 生肖
+	IsTermDay   bool      `json:"is_term_day"`  // 当天是否为节气
+	CurrentTerm SolarTerm `json:"current_term"` // 当前所处节气（最近一个已开始的节气）
+	NextTerm    SolarTerm `json:"next_term"`    // 下一个节气
+}
+
+// LunarDate 农历日期
+type LunarDate struct {
+	Year      int    `json:"year"`       // 农历年（以正月初一为界）
+	Month     int    `json:"month"`      // 农历月（1-12）
+	Day       int    `json:"day"`        // 农历日（1-30）
+	IsLeap    bool   `json:"is_leap"`    // 是否为闰月
+	MonthName string `json:"month_name"` // 月份名称，如 闰二月、腊月
+	DayName   string `json:"day_name"`   // 日名称，如 初一、廿三
+}
+
+// SolarTerm 二十四节气
+type SolarTerm struct {
+	Name      string    `json:"name"`      // 节气名称
+	Longitude int       `json:"longitude"` // 太阳视黄经（度）
+	Time      time.Time `json:"time"`      // 交节时刻
+	Date      string    `json:"date"`      // 交节日期（YYYY-MM-DD，按请求时区）
+}
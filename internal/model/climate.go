@@ -0,0 +1,41 @@
+package model
+
+// NormalStat 某个要素的常年统计，由窗口内每天的日均值计算
+type NormalStat struct {
+	Mean         float64 `json:"mean"`         // 平均值
+	StdDev       float64 `json:"stddev"`       // 样本标准差，少于 2 天时为 0
+	Days         int     `json:"days"`         // 参与计算的天数
+	Observations int     `json:"observations"` // 参与计算的观测次数
+}
+
+// ClimateNormals 某个日期前后的常年气候统计
+type ClimateNormals struct {
+	Location      Location    `json:"location"`                // 位置信息
+	Date          string      `json:"date"`                    // 目标日期（当地，YYYY-MM-DD）
+	DayOfYear     int         `json:"day_of_year"`             // 目标日期是一年中的第几天
+	WindowDays    int         `json:"window_days"`             // 统计窗口半宽，包含各年前后这么多天
+	Years         int         `json:"years"`                   // 数据覆盖的年数
+	Units         string      `json:"units"`                   // 单位系统
+	Temperature   *NormalStat `json:"temperature,omitempty"`   // 温度，没有数据时为空
+	Humidity      *NormalStat `json:"humidity,omitempty"`      // 湿度（%）
+	Precipitation *NormalStat `json:"precipitation,omitempty"` // 平均每小时降水量（mm）
+}
+
+// AnomalyValue 当前值相对常年平均值的偏差
+type AnomalyValue struct {
+	Value        float64  `json:"value"`             // 当前值
+	Normal       float64  `json:"normal"`            // 常年平均值
+	Difference   float64  `json:"difference"`        // 当前值减去常年平均值
+	ZScore       *float64 `json:"z_score,omitempty"` // 偏差相当于几个标准差，标准差为 0 时为空
+	Days         int      `json:"days"`              // 常年平均值基于的天数
+	Observations int      `json:"observations"`      // 常年平均值基于的观测次数
+}
+
+// Anomaly 气候异常（通过 include=anomaly 获取），与存档中同一时期的常年统计比较
+type Anomaly struct {
+	WindowDays    int           `json:"window_days"`             // 统计窗口半宽（天）
+	Years         int           `json:"years"`                   // 数据覆盖的年数
+	Temperature   *AnomalyValue `json:"temperature,omitempty"`   // 温度，没有历史数据时为空
+	Humidity      *AnomalyValue `json:"humidity,omitempty"`      // 湿度（%）
+	Precipitation *AnomalyValue `json:"precipitation,omitempty"` // 1 小时降水量（mm）
+}
@@ -0,0 +1,105 @@
+package model
+
+// 与数据提供商无关的天气状况标识。标识一经发布不再修改，客户端可以据此映射图标和文案；
+// 代码取自 WMO 4677 现在天气代码表（ww），与 WMO 4680 自动站代码对应的状况使用相同的标识
+const (
+	ConditionClear              = "clear"                // 晴
+	ConditionMainlyClear        = "mainly_clear"         // 晴间少云
+	ConditionPartlyCloudy       = "partly_cloudy"        // 多云
+	ConditionOvercast           = "overcast"             // 阴
+	ConditionSmoke              = "smoke"                // 烟、火山灰
+	ConditionHaze               = "haze"                 // 霾
+	ConditionDust               = "dust"                 // 浮尘
+	ConditionSand               = "sand"                 // 扬沙、沙尘暴
+	ConditionMist               = "mist"                 // 轻雾
+	ConditionSquall             = "squall"               // 飑
+	ConditionTornado            = "tornado"              // 龙卷
+	ConditionFog                = "fog"                  // 雾
+	ConditionDrizzleLight       = "drizzle_light"        // 小毛毛雨
+	ConditionDrizzle            = "drizzle"              // 毛毛雨
+	ConditionDrizzleHeavy       = "drizzle_heavy"        // 大毛毛雨
+	ConditionFreezingDrizzle    = "freezing_drizzle"     // 冻毛毛雨
+	ConditionRainLight          = "rain_light"           // 小雨
+	ConditionRain               = "rain"                 // 中雨
+	ConditionRainHeavy          = "rain_heavy"           // 大雨
+	ConditionFreezingRain       = "freezing_rain"        // 冻雨
+	ConditionSleetLight         = "sleet_light"          // 小雨夹雪
+	ConditionSleet              = "sleet"                // 雨夹雪
+	ConditionSnowLight          = "snow_light"           // 小雪
+	ConditionSnow               = "snow"                 // 中雪
+	ConditionSnowHeavy          = "snow_heavy"           // 大雪
+	ConditionRainShowersLight   = "rain_showers_light"   // 小阵雨
+	ConditionRainShowers        = "rain_showers"         // 阵雨
+	ConditionRainShowersViolent = "rain_showers_violent" // 强阵雨
+	ConditionSnowShowersLight   = "snow_showers_light"   // 小阵雪
+	ConditionSnowShowers        = "snow_showers"         // 阵雪
+	ConditionThunderstorm       = "thunderstorm"         // 雷暴
+	ConditionThunderstormHeavy  = "thunderstorm_heavy"   // 强雷暴
+	ConditionThunderstormHail   = "thunderstorm_hail"    // 雷暴伴有冰雹
+	ConditionUnknown            = "unknown"              // 无法识别的状况
+)
+
+// conditionCodes 各状况标识对应的 WMO 4677 代码，无法识别的状况为 -1
+var conditionCodes = map[string]int{
+	ConditionClear:              0,
+	ConditionMainlyClear:        1,
+	ConditionPartlyCloudy:       2,
+	ConditionOvercast:           3,
+	ConditionSmoke:              4,
+	ConditionHaze:               5,
+	ConditionDust:               6,
+	ConditionSand:               7,
+	ConditionMist:               10,
+	ConditionSquall:             18,
+	ConditionTornado:            19,
+	ConditionFog:                45,
+	ConditionDrizzleLight:       51,
+	ConditionDrizzle:            53,
+	ConditionDrizzleHeavy:       55,
+	ConditionFreezingDrizzle:    56,
+	ConditionRainLight:          61,
+	ConditionRain:               63,
+	ConditionRainHeavy:          65,
+	ConditionFreezingRain:       66,
+	ConditionSleetLight:         68,
+	ConditionSleet:              69,
+	ConditionSnowLight:          71,
+	ConditionSnow:               73,
+	ConditionSnowHeavy:          75,
+	ConditionRainShowersLight:   80,
+	ConditionRainShowers:        81,
+	ConditionRainShowersViolent: 82,
+	ConditionSnowShowersLight:   85,
+	ConditionSnowShowers:        86,
+	ConditionThunderstorm:       95,
+	ConditionThunderstormHeavy:  97,
+	ConditionThunderstormHail:   96,
+	ConditionUnknown:            -1,
+}
+
+const (
+	// VariantDay 白天
+	VariantDay = "day"
+	// VariantNight 夜间
+	VariantNight = "night"
+)
+
+// Condition 与数据提供商无关的天气状况
+type Condition struct {
+	Code    int    `json:"code"`    // WMO 4677 现在天气代码，无法识别时为 -1
+	Slug    string `json:"slug"`    // 稳定的状况标识，如 rain_light
+	Variant string `json:"variant"` // day 或 night
+}
+
+// NewCondition 根据状况标识创建天气状况，未定义的标识按 unknown 处理
+func NewCondition(slug string, night bool) *Condition {
+	code, ok := conditionCodes[slug]
+	if !ok {
+		slug, code = ConditionUnknown, conditionCodes[ConditionUnknown]
+	}
+	variant := VariantDay
+	if night {
+		variant = VariantNight
+	}
+	return &Condition{Code: code, Slug: slug, Variant: variant}
+}
@@ -0,0 +1,34 @@
+package model
+
+// ConsensusInfo 多数据提供商融合的说明
+type ConsensusInfo struct {
+	Method    string                 `json:"method"`    // 数值字段的融合方式：median 或 weighted_mean
+	Members   []ConsensusMember      `json:"members"`   // 各数据提供商的结果
+	Fields    map[string]FieldSpread `json:"fields"`    // 各数值字段在参与融合的数据提供商之间的离散程度
+	Condition ConditionVote          `json:"condition"` // 天气状况的投票结果
+}
+
+// ConsensusMember 参与融合的一个数据提供商
+type ConsensusMember struct {
+	Name      string  `json:"name"`            // 名称
+	Weight    float64 `json:"weight"`          // 权重
+	Status    string  `json:"status"`          // ok、error 或 timeout，只有 ok 的结果参与融合
+	Error     string  `json:"error,omitempty"` // 失败原因
+	LatencyMs float64 `json:"latency_ms"`      // 耗时（毫秒），超时时为超时时间
+}
+
+// FieldSpread 单个字段的离散程度，与字段使用相同的单位
+type FieldSpread struct {
+	Spread     float64 `json:"spread"`     // 最大值与最小值之差，风向为最大的角度差
+	StdDev     float64 `json:"stddev"`     // 加权标准差
+	Sources    int     `json:"sources"`    // 参与融合的数据提供商数
+	Confidence string  `json:"confidence"` // high、medium 或 low，只有一个来源时为 low
+}
+
+// ConditionVote 天气状况的加权投票结果
+type ConditionVote struct {
+	Main       string  `json:"main"`       // 得票最多的天气状况大类
+	Agreement  float64 `json:"agreement"`  // 得票权重占总权重的比例（0-1）
+	Sources    int     `json:"sources"`    // 参与投票的数据提供商数
+	Confidence string  `json:"confidence"` // high、medium 或 low
+}
@@ -0,0 +1,20 @@
+package model
+
+// Derived 衍生气象指标（通过 include=derived 获取），温度单位与请求的单位系统一致
+type Derived struct {
+	DewPoint  *float64 `json:"dew_point,omitempty"`  // 露点温度，无法计算（如湿度为 0）时省略
+	HeatIndex *float64 `json:"heat_index,omitempty"` // 炎热指数，无法计算时省略
+	WindChill *float64 `json:"wind_chill,omitempty"` // 风寒温度，无法计算时省略
+	Beaufort  int      `json:"beaufort"`             // 蒲福风级
+}
+
+// Astronomy 天文信息（通过 include=astronomy 获取）
+type Astronomy struct {
+	DayLength        int64   `json:"day_length"`        // 昼长（秒）
+	SolarNoon        int64   `json:"solar_noon"`        // 正午时间戳
+	IsDaytime        bool    `json:"is_daytime"`        // 当前是否为白天
+	MoonAge          float64 `json:"moon_age"`          // 月龄（天）
+	MoonIllumination float64 `json:"moon_illumination"` // 月面照亮比例（0-1）
+	MoonPhase        string  `json:"moon_phase"`        // 月相标识，如 waxing_crescent
+	MoonPhaseName    string  `json:"moon_phase_name"`   // 月相名称，如 娥眉月
+}
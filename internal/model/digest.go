@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// DigestEvent 天气简报推送事件
+const DigestEvent = "weather.digest"
+
+// 降水风险等级
+const (
+	PrecipRiskLow      = "low"
+	PrecipRiskModerate = "moderate"
+	PrecipRiskHigh     = "high"
+	PrecipRiskUnknown  = "unknown"
+)
+
+// DigestSubscription 定时天气简报订阅：按 schedule 在 timezone 时区内定时汇总 locations 的天气，
+// 发送到 channels 中的通知渠道或 url
+type DigestSubscription struct {
+	ID        string           `json:"id"`                    // 订阅 ID
+	Name      string           `json:"name,omitempty"`        // 简报名称，用于消息标题
+	Schedule  string           `json:"schedule"`              // 五段式 cron 表达式，如 "0 7 * * 1-5"
+	Timezone  string           `json:"timezone"`              // IANA 时区名称，如 Asia/Shanghai，默认 UTC
+	Locations []WeatherRequest `json:"locations"`             // 简报包含的位置
+	Units     string           `json:"units"`                 // 单位系统
+	Lang      string           `json:"lang"`                  // 语言
+	Channels  []string         `json:"channels,omitempty"`    // 通知渠道名称
+	URL       string           `json:"url,omitempty"`         // 接收简报的 Webhook 地址，与 channels 至少提供一个
+	Secret    string           `json:"secret,omitempty"`      // HMAC-SHA256 签名密钥，仅在创建时返回
+	CreatedAt time.Time        `json:"created_at"`            // 创建时间
+	NextRunAt *time.Time       `json:"next_run_at,omitempty"` // 下一次发送时间，仅在查询时计算
+	LastRun   *DigestRun       `json:"last_run,omitempty"`    // 最近一次发送结果
+}
+
+// DigestRun 一次简报发送的结果
+type DigestRun struct {
+	ScheduledAt time.Time `json:"scheduled_at"`     // 计划发送时间
+	SentAt      time.Time `json:"sent_at"`          // 实际发送时间
+	Success     bool      `json:"success"`          // 是否所有目标都发送成功
+	Errors      []string  `json:"errors,omitempty"` // 失败的目标及原因
+}
+
+// Digest 简报内容
+type Digest struct {
+	SubscriptionID string       `json:"subscription_id"` // 订阅 ID
+	Title          string       `json:"title"`           // 标题
+	ScheduledAt    time.Time    `json:"scheduled_at"`    // 计划发送时间
+	Units          string       `json:"units"`           // 单位系统
+	Lang           string       `json:"lang"`            // 语言
+	Items          []DigestItem `json:"items"`           // 各位置的天气，顺序与订阅一致
+}
+
+// DigestItem 简报中单个位置的天气
+type DigestItem struct {
+	Name              string           `json:"name"`                         // 位置名称
+	Weather           *WeatherResponse `json:"weather,omitempty"`            // 当前天气
+	High              float64          `json:"high"`                         // 当地今天的最高温度
+	Low               float64          `json:"low"`                          // 当地今天的最低温度
+	PrecipProbability *float64         `json:"precip_probability,omitempty"` // 今天剩余时段的最大降水概率（%），没有预报时为空
+	PrecipRisk        string           `json:"precip_risk"`                  // 降水风险：low、moderate、high 或 unknown
+	Forecast          bool             `json:"forecast"`                     // 最高、最低温度和降水风险是否来自预报
+	Error             string           `json:"error,omitempty"`              // 获取天气失败的原因
+}
+
+// DigestPayload 推送到 Webhook 地址的简报内容
+type DigestPayload struct {
+	Event string `json:"event"` // 事件类型
+	*Digest
+}
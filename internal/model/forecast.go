@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Forecast 逐时段天气预报
+type Forecast struct {
+	Location  Location       `json:"location"`          // 位置信息
+	Items     []ForecastItem `json:"items"`             // 按时间排序的预报时段
+	Timestamp int64          `json:"timestamp"`         // 数据获取时间戳
+	Provider  string         `json:"provider"`          // 数据提供商
+	Routing   *RoutingInfo   `json:"routing,omitempty"` // 按地区路由选择的数据提供商（启用路由时返回）
+}
+
+// ForecastItem 单个预报时段
+type ForecastItem struct {
+	Time              time.Time `json:"time"`               // 时段开始时间
+	Temperature       float64   `json:"temperature"`        // 温度
+	TempMin           float64   `json:"temp_min"`           // 时段内最低温度
+	TempMax           float64   `json:"temp_max"`           // 时段内最高温度
+	Humidity          int       `json:"humidity"`           // 湿度（%）
+	WindSpeed         float64   `json:"wind_speed"`         // 风速
+	PrecipProbability float64   `json:"precip_probability"` // 降水概率（0-1）
+	Rain              float64   `json:"rain,omitempty"`     // 时段内降雨量（mm）
+	Snow              float64   `json:"snow,omitempty"`     // 时段内降雪量（mm）
+	Weather           []Weather `json:"weather"`            // 天气状况
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// GeoJSONMediaType GeoJSON 的媒体类型（RFC 7946）
+const GeoJSONMediaType = "application/geo+json"
+
+// Geometry GeoJSON 几何对象，目前只使用 Point，坐标为 [经度, 纬度]
+type Geometry struct {
+	Type        string    `json:"type"`        // 固定为 Point
+	Coordinates []float64 `json:"coordinates"` // [经度, 纬度]
+}
+
+// Feature GeoJSON 要素
+type Feature struct {
+	Type       string            `json:"type"`       // 固定为 Feature
+	Geometry   Geometry          `json:"geometry"`   // 位置
+	Properties WeatherProperties `json:"properties"` // 展平的当前天气
+}
+
+// FeatureCollection GeoJSON 要素集合
+type FeatureCollection struct {
+	Type     string    `json:"type"`     // 固定为 FeatureCollection
+	Features []Feature `json:"features"` // 要素列表
+}
+
+// WeatherProperties 展平为一层的当前天气，作为 GeoJSON 要素的属性，便于在 GIS 工具中按字段着色和筛选
+type WeatherProperties struct {
+	Name          string    `json:"name"`                     // 城市名称
+	Country       string    `json:"country"`                  // 国家代码
+	Provider      string    `json:"provider"`                 // 数据提供商
+	Temperature   float64   `json:"temperature"`              // 当前温度
+	FeelsLike     float64   `json:"feels_like"`               // 体感温度
+	TempMin       float64   `json:"temp_min"`                 // 最低温度
+	TempMax       float64   `json:"temp_max"`                 // 最高温度
+	Pressure      int       `json:"pressure"`                 // 大气压力（hPa）
+	Humidity      int       `json:"humidity"`                 // 湿度（%）
+	Visibility    int       `json:"visibility"`               // 能见度（米）
+	UVIndex       *float64  `json:"uv_index,omitempty"`       // 紫外线指数，数据提供商不提供时省略
+	WindSpeed     float64   `json:"wind_speed"`               // 风速
+	WindDirection int       `json:"wind_direction"`           // 风向（度）
+	WindGust      float64   `json:"wind_gust"`                // 阵风速度
+	Clouds        int       `json:"clouds"`                   // 云量（%）
+	Rain1h        float64   `json:"rain_1h"`                  // 过去 1 小时降雨量（mm）
+	Snow1h        float64   `json:"snow_1h"`                  // 过去 1 小时降雪量（mm）
+	Condition     string    `json:"condition,omitempty"`      // 天气主要状况
+	Description   string    `json:"description,omitempty"`    // 天气详细描述
+	ConditionSlug string    `json:"condition_slug,omitempty"` // 中立的天气状况标识
+	ConditionCode *int      `json:"condition_code,omitempty"` // WMO 4677 天气代码
+	IconURL       string    `json:"icon_url,omitempty"`       // 内嵌图标的地址
+	UpdatedAt     time.Time `json:"updated_at"`               // 数据更新时间
+}
@@ -0,0 +1,17 @@
+package model
+
+// GridResponse 网格天气采样结果。values 按行（纬度由南向北）、列（经度由西向东）排列，
+// 可以直接作为栅格绘制；points 可以直接交给 Leaflet.heat 等点热力图库
+type GridResponse struct {
+	BBox   [4]float64   `json:"bbox"`   // 请求的经纬度范围：最小经度、最小纬度、最大经度、最大纬度
+	Step   float64      `json:"step"`   // 网格间距（度）
+	Field  string       `json:"field"`  // 数值对应的字段，如 temperature
+	Units  string       `json:"units"`  // 单位系统
+	Lats   []float64    `json:"lats"`   // 各行的纬度
+	Lons   []float64    `json:"lons"`   // 各列的经度
+	Values [][]*float64 `json:"values"` // 按 [行][列] 排列的数值，获取失败或没有该数据的点为 null
+	Points [][3]float64 `json:"points"` // 获取成功且有数值的点：[纬度, 经度, 数值]
+	Min    float64      `json:"min"`    // 数值的最小值，用于确定色阶，没有数值时为 0
+	Max    float64      `json:"max"`    // 数值的最大值
+	Failed int          `json:"failed"` // 获取失败的点数
+}
@@ -0,0 +1,16 @@
+package model
+
+// HistoricalDay 某一天的历史天气
+type HistoricalDay struct {
+	Date    string   `json:"date"`              // 日期（YYYY-MM-DD）
+	Weather *Current `json:"weather,omitempty"` // 当天当地正午前后的观测，获取失败时为空
+	Error   string   `json:"error,omitempty"`   // 获取失败的原因
+}
+
+// HistoricalResponse 历史天气查询结果
+type HistoricalResponse struct {
+	Location Location        `json:"location"` // 位置信息
+	Units    string          `json:"units"`    // 单位系统
+	Provider string          `json:"provider"` // 数据提供商
+	Days     []HistoricalDay `json:"days"`     // 按日期排序的每日天气
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// HistoryStat 一个时间段内某个字段的统计值
+type HistoryStat struct {
+	Avg float64 `json:"avg"` // 平均值
+	Min float64 `json:"min"` // 最小值
+	Max float64 `json:"max"` // 最大值
+}
+
+// HistoryPoint 降采样后的一个时间段
+type HistoryPoint struct {
+	Time        time.Time   `json:"time"`        // 时间段开始时间
+	Count       int         `json:"count"`       // 时间段内的观测次数
+	Temperature HistoryStat `json:"temperature"` // 温度
+	FeelsLike   HistoryStat `json:"feels_like"`  // 体感温度
+	Humidity    HistoryStat `json:"humidity"`    // 湿度（%）
+	Pressure    HistoryStat `json:"pressure"`    // 气压（hPa）
+	WindSpeed   HistoryStat `json:"wind_speed"`  // 风速
+}
+
+// HistoryResponse 历史观测查询结果
+type HistoryResponse struct {
+	Location Location       `json:"location"` // 位置信息，取最近一次观测
+	From     time.Time      `json:"from"`     // 查询起始时间（含）
+	To       time.Time      `json:"to"`       // 查询结束时间（不含）
+	Interval string         `json:"interval"` // 降采样间隔，原始数据时为 raw
+	Units    string         `json:"units"`    // 单位系统
+	Points   []HistoryPoint `json:"points"`   // 按时间排序的数据点
+}
@@ -0,0 +1,18 @@
+package model
+
+// LifeIndex 生活指数
+type LifeIndex struct {
+	Key    string `json:"key"`    // 指数标识，如 clothing、car_wash
+	Name   string `json:"name"`   // 指数名称
+	Level  int    `json:"level"`  // 指数等级
+	Label  string `json:"label"`  // 等级名称
+	Advice string `json:"advice"` // 建议
+}
+
+// IndicesResponse 生活指数响应
+type IndicesResponse struct {
+	Location Location    `json:"location"` // 位置信息
+	Indices  []LifeIndex `json:"indices"`  // 生活指数列表，必需数据缺失的指数不返回
+	Forecast bool        `json:"forecast"` // 是否参考了天气预报
+	Provider string      `json:"provider"` // 数据提供商
+}
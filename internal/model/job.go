@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// 批量任务状态
+const (
+	JobStatusQueued    = "queued"    // 排队中
+	JobStatusRunning   = "running"   // 处理中
+	JobStatusCompleted = "completed" // 已完成
+	JobStatusFailed    = "failed"    // 读写任务文件失败，服务重启后重新排队
+)
+
+// Job 异步批量天气任务
+type Job struct {
+	ID         string     `json:"id"`                    // 任务 ID
+	Status     string     `json:"status"`                // 任务状态
+	Total      int        `json:"total"`                 // 位置总数
+	Processed  int        `json:"processed"`             // 已处理数量
+	Succeeded  int        `json:"succeeded"`             // 成功数量
+	Failed     int        `json:"failed"`                // 失败数量
+	Progress   float64    `json:"progress"`              // 进度百分比
+	Units      string     `json:"units"`                 // 默认单位系统
+	Lang       string     `json:"lang"`                  // 默认语言
+	CreatedAt  time.Time  `json:"created_at"`            // 创建时间
+	StartedAt  *time.Time `json:"started_at,omitempty"`  // 开始处理时间
+	FinishedAt *time.Time `json:"finished_at,omitempty"` // 完成或失败的时间
+	Error      string     `json:"error,omitempty"`       // 任务失败的原因
+}
+
+// JobItem 批量任务中的单个位置
+type JobItem struct {
+	Ref string `json:"ref,omitempty"` // 调用方自定义的标识（如门店编号），原样出现在结果中
+	WeatherRequest
+}
+
+// JobResult 批量任务中单个位置的结果
+type JobResult struct {
+	Index   int              `json:"index"`           // 在上传文件中的下标
+	Ref     string           `json:"ref,omitempty"`   // 调用方自定义的标识
+	Success bool             `json:"success"`         // 是否成功
+	Data    *WeatherResponse `json:"data,omitempty"`  // 天气数据
+	Error   *ErrorResponse   `json:"error,omitempty"` // 错误信息
+}
@@ -0,0 +1,22 @@
+package model
+
+// NotifyChannel 通知渠道概要
+type NotifyChannel struct {
+	Name          string `json:"name"`            // 渠道名称
+	Type          string `json:"type"`            // 渠道类型
+	RatePerMinute int    `json:"rate_per_minute"` // 每分钟最多发送的消息数
+}
+
+// NotifyTestRequest 测试通知请求，位置字段与通用天气查询相同
+type NotifyTestRequest struct {
+	Channel string `json:"channel" binding:"required"` // 渠道名称
+	Title   string `json:"title"`                      // 消息标题，默认为“天气通知测试”
+	WeatherRequest
+}
+
+// NotifyTestResponse 测试通知结果
+type NotifyTestResponse struct {
+	Channel string `json:"channel"` // 渠道名称
+	Title   string `json:"title"`   // 发送的标题
+	Body    string `json:"body"`    // 发送的正文
+}
@@ -0,0 +1,7 @@
+package model
+
+// RoutingInfo 按地区路由的选择结果
+type RoutingInfo struct {
+	Provider string `json:"provider"` // 路由配置中的数据提供商名称
+	Rule     string `json:"rule"`     // 匹配的规则名称，没有规则匹配时为 default，通过 provider 参数指定时为 override
+}
@@ -0,0 +1,16 @@
+package model
+
+// RuleEvaluateRequest 表达式试算请求，位置字段与通用天气查询相同
+type RuleEvaluateRequest struct {
+	Expression string `json:"expression" binding:"required"` // 条件表达式
+	WeatherRequest
+}
+
+// RuleEvaluateResponse 表达式试算结果
+type RuleEvaluateResponse struct {
+	Expression string                 `json:"expression"` // 条件表达式
+	Result     bool                   `json:"result"`     // 表达式结果
+	Values     map[string]interface{} `json:"values"`     // 表达式引用的字段的当前值
+	Location   Location               `json:"location"`   // 位置信息
+	Timestamp  int64                  `json:"timestamp"`  // 天气数据时间戳
+}
@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// ShadowReport 影子模式的比较统计
+type ShadowReport struct {
+	Primary          string             `json:"primary"`              // 主服务提供商
+	Candidate        string             `json:"candidate"`            // 候选服务提供商
+	SamplePercent    int                `json:"sample_percent"`       // 抽样比例（%）
+	Since            time.Time          `json:"since"`                // 统计开始时间
+	Sampled          int                `json:"sampled"`              // 被抽中并发起候选请求的次数
+	Skipped          int                `json:"skipped"`              // 被抽中但因候选请求过多而跳过的次数
+	CandidateErrors  int                `json:"candidate_errors"`     // 候选服务返回错误的次数
+	Compared         int                `json:"compared"`             // 两个服务都成功、完成比较的次数
+	Diverged         int                `json:"diverged"`             // 至少一个字段超出容差的次数
+	LastError        string             `json:"last_error,omitempty"` // 候选服务最近一次的错误
+	Fields           []ShadowFieldStat  `json:"fields"`               // 各字段的差异统计
+	PrimaryLatency   LatencyStat        `json:"primary_latency"`      // 被抽中请求的主服务耗时
+	CandidateLatency LatencyStat        `json:"candidate_latency"`    // 候选服务耗时
+	Recent           []ShadowDivergence `json:"recent"`               // 最近的超出容差的比较，最新的在前
+}
+
+// ShadowFieldStat 单个字段的差异统计，温度为摄氏度，风速为 m/s
+type ShadowFieldStat struct {
+	Field       string  `json:"field"`         // 字段名称
+	Tolerance   float64 `json:"tolerance"`     // 容差，差值的绝对值超过该值视为不一致
+	Compared    int     `json:"compared"`      // 比较次数
+	Diverged    int     `json:"diverged"`      // 超出容差的次数
+	MeanAbsDiff float64 `json:"mean_abs_diff"` // 差值绝对值的平均值
+	MaxAbsDiff  float64 `json:"max_abs_diff"`  // 差值绝对值的最大值
+}
+
+// LatencyStat 最近若干次请求的耗时统计（毫秒）
+type LatencyStat struct {
+	Samples int     `json:"samples"` // 参与统计的请求数
+	Mean    float64 `json:"mean_ms"`
+	P50     float64 `json:"p50_ms"`
+	P95     float64 `json:"p95_ms"`
+	Max     float64 `json:"max_ms"`
+}
+
+// ShadowDivergence 一次超出容差的比较
+type ShadowDivergence struct {
+	Time     time.Time   `json:"time"`     // 比较时间
+	Location string      `json:"location"` // 主服务返回的位置名称
+	Fields   []FieldDiff `json:"fields"`   // 超出容差的字段
+}
+
+// FieldDiff 单个字段在两个服务间的差异，天气状况字段的值为天气状况代码，不一致时差值为 1
+type FieldDiff struct {
+	Field      string  `json:"field"`
+	Primary    float64 `json:"primary"`
+	Candidate  float64 `json:"candidate"`
+	Difference float64 `json:"difference"` // 候选值减主服务值
+}
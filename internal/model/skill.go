@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// ErrorStat 连续变量的预报误差
+type ErrorStat struct {
+	MAE  float64 `json:"mae"`  // 平均绝对误差
+	Bias float64 `json:"bias"` // 平均偏差（预报减实况），正值表示预报偏高
+}
+
+// PrecipitationSkill 降水预报的列联表统计
+type PrecipitationSkill struct {
+	Accuracy         float64 `json:"accuracy"`          // 准确率：(hits + correct_negatives) / 样本数，即预报与实况一致（有或无降水）的比例
+	Hits             int     `json:"hits"`              // 预报有降水，实况有降水
+	Misses           int     `json:"misses"`            // 预报无降水，实况有降水
+	FalseAlarms      int     `json:"false_alarms"`      // 预报有降水，实况无降水
+	CorrectNegatives int     `json:"correct_negatives"` // 预报无降水，实况无降水
+}
+
+// SkillScore 某个数据提供商在某个预报提前量范围内的准确度
+type SkillScore struct {
+	Provider      string             `json:"provider"`      // 数据提供商
+	LeadTime      string             `json:"lead_time"`     // 预报提前量范围，如 12-24h
+	Samples       int                `json:"samples"`       // 参与计算的预报与实况配对数
+	Temperature   ErrorStat          `json:"temperature"`   // 温度误差
+	WindSpeed     ErrorStat          `json:"wind_speed"`    // 风速误差
+	Precipitation PrecipitationSkill `json:"precipitation"` // 降水预报统计
+}
+
+// ForecastSkillReport 预报准确度报告
+type ForecastSkillReport struct {
+	From   time.Time    `json:"from"`   // 预报有效时间的起始（含）
+	To     time.Time    `json:"to"`     // 预报有效时间的结束（不含）
+	Units  string       `json:"units"`  // 单位系统，影响温度和风速误差
+	Scores []SkillScore `json:"scores"` // 按提供商和提前量排序
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // WeatherRequest 天气查询请求结构体
 type WeatherRequest struct {
@@ -11,12 +14,60 @@ type WeatherRequest struct {
 	Lang      string  `json:"lang" form:"lang" binding:"omitempty"`                      // 语言
 }
 
+// Describe 返回位置的可读描述：城市名称，或保留 4 位小数的 纬度,经度，用于日志和错误信息
+func (r *WeatherRequest) Describe() string {
+	if r.City != "" {
+		return r.City
+	}
+	return fmt.Sprintf("%.4f,%.4f", r.Lat, r.Lon)
+}
+
+// Validate 验证请求参数：城市名称和坐标必须提供其中一个，坐标和单位系统必须合法
+func (r *WeatherRequest) Validate() error {
+	// 城市名称和坐标必须提供其中一个
+	if r.City == "" && (r.Lat == 0 && r.Lon == 0) {
+		return fmt.Errorf("必须提供城市名称或坐标信息")
+	}
+
+	// 如果提供了坐标，需要验证范围
+	if r.Lat != 0 || r.Lon != 0 {
+		if r.Lat < -90 || r.Lat > 90 {
+			return fmt.Errorf("纬度必须在 -90 到 90 之间")
+		}
+		if r.Lon < -180 || r.Lon > 180 {
+			return fmt.Errorf("经度必须在 -180 到 180 之间")
+		}
+	}
+
+	if r.Units != "" && !ValidUnits(r.Units) {
+		return fmt.Errorf("单位系统必须是 metric、imperial 或 standard")
+	}
+
+	return nil
+}
+
+// ValidUnits 判断单位系统是否受支持
+func ValidUnits(units string) bool {
+	switch units {
+	case "metric", "imperial", "standard":
+		return true
+	}
+	return false
+}
+
 // WeatherResponse 标准化的天气响应结构体
 type WeatherResponse struct {
 	Location    Location    `json:"location"`    // 位置信息
 	Current     Current     `json:"current"`     // 当前天气
 	Timestamp   int64       `json:"timestamp"`   // 响应时间戳
 	Provider    string      `json:"provider"`    // 数据提供商
+	Calendar    *CalendarInfo `json:"calendar,omitempty"` // 农历与节气（通过 include=calendar 获取）
+	Summary     string        `json:"summary,omitempty"`  // 自然语言摘要（通过 include=summary 获取）
+	Derived     *Derived      `json:"derived,omitempty"`  // 衍生指标（通过 include=derived 获取）
+	Astronomy   *Astronomy    `json:"astronomy,omitempty"` // 天文信息（通过 include=astronomy 获取）
+	Anomaly     *Anomaly      `json:"anomaly,omitempty"`   // 气候异常（通过 include=anomaly 获取）
+	Routing     *RoutingInfo  `json:"routing,omitempty"`   // 按地区路由选择的数据提供商（启用路由时返回）
+	Consensus   *ConsensusInfo `json:"consensus,omitempty"` // 多数据提供商融合的说明（使用融合服务时返回）
 }
 
 // Location 位置信息
@@ -37,7 +88,7 @@ type Current struct {
 	Pressure        int         `json:"pressure"`         // 大气压力（hPa）
 	Humidity        int         `json:"humidity"`         // 湿度（%）
 	Visibility      int         `json:"visibility"`       // 能见度（米）
-	UVIndex         float64     `json:"uv_index"`         // 紫外线指数
+	UVIndex         *float64    `json:"uv_index,omitempty"` // 紫外线指数，数据提供商不提供时为 nil
 	Weather         []Weather   `json:"weather"`          // 天气状况
 	Wind            Wind        `json:"wind"`             // 风力信息
 	Clouds          Clouds      `json:"clouds"`           // 云量信息
@@ -54,6 +105,8 @@ type Weather struct {
 	Main        string `json:"main"`        // 天气主要状况
 	Description string `json:"description"` // 天气详细描述
 	Icon        string `json:"icon"`        // 天气图标代码
+	Condition   *Condition `json:"condition,omitempty"` // 与数据提供商无关的天气状况
+	IconURL     string     `json:"icon_url,omitempty"`  // 内嵌图标的地址
 }
 
 // Wind 风力信息
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// WebhookEventThreshold 阈值规则触发事件
+const WebhookEventThreshold = "weather.threshold"
+
+// Webhook 告警规则：location 的 field 满足 operator threshold，或满足 condition 表达式时向 url 推送通知
+type Webhook struct {
+	ID              string         `json:"id"`                          // 规则 ID
+	URL             string         `json:"url,omitempty"`               // 接收通知的地址，与 channels 至少提供一个
+	Channels        []string       `json:"channels,omitempty"`          // 同时推送的通知渠道名称
+	Secret          string         `json:"secret,omitempty"`            // HMAC-SHA256 签名密钥，仅在创建时返回
+	Location        WeatherRequest `json:"location"`                    // 监控的位置
+	Field           string         `json:"field,omitempty"`             // Current 中的字段，如 wind.gust
+	Operator        string         `json:"operator,omitempty"`          // 比较运算符：>、>=、<、<=、==、!=
+	Threshold       float64        `json:"threshold"`                   // 阈值，单位与 location.units 一致
+	Condition       string         `json:"condition,omitempty"`         // 条件表达式，与 field/operator/threshold 二选一
+	Cooldown        int            `json:"cooldown"`                    // 两次触发之间的最小间隔（秒）
+	CreatedAt       time.Time      `json:"created_at"`                  // 创建时间
+	LastTriggeredAt *time.Time     `json:"last_triggered_at,omitempty"` // 最近一次触发时间
+}
+
+// WebhookPayload 推送给接收方的通知内容
+type WebhookPayload struct {
+	Event       string                 `json:"event"`               // 事件类型
+	WebhookID   string                 `json:"webhook_id"`          // 规则 ID
+	Field       string                 `json:"field,omitempty"`     // 触发字段
+	Operator    string                 `json:"operator,omitempty"`  // 比较运算符
+	Threshold   float64                `json:"threshold"`           // 阈值
+	Value       float64                `json:"value"`               // 触发时的实际值
+	Condition   string                 `json:"condition,omitempty"` // 触发的条件表达式
+	Values      map[string]interface{} `json:"values,omitempty"`    // 条件表达式引用的字段值
+	Weather     *WeatherResponse       `json:"weather"`             // 触发时的天气数据
+	TriggeredAt time.Time              `json:"triggered_at"`        // 触发时间
+}
+
+// WebhookDelivery 单次投递尝试的记录
+type WebhookDelivery struct {
+	ID          string    `json:"id"`                    // 投递 ID，同一次通知的多次重试共享
+	WebhookID   string    `json:"webhook_id"`            // 规则 ID
+	Channel     string    `json:"channel,omitempty"`     // 通知渠道名称，推送到 url 时为空
+	Attempt     int       `json:"attempt"`               // 第几次尝试，从 1 开始
+	Success     bool      `json:"success"`               // 接收方是否返回 2xx
+	StatusCode  int       `json:"status_code,omitempty"` // 接收方返回的状态码
+	Error       string    `json:"error,omitempty"`       // 失败原因
+	Duration    int64     `json:"duration_ms"`           // 请求耗时（毫秒）
+	DeliveredAt time.Time `json:"delivered_at"`          // 尝试时间
+}
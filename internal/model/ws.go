@@ -0,0 +1,27 @@
+package model
+
+// WebSocket 消息类型
+const (
+	WSTypeSubscribe    = "subscribe"    // 客户端订阅位置
+	WSTypeUnsubscribe  = "unsubscribe"  // 客户端取消订阅
+	WSTypeSubscribed   = "subscribed"   // 订阅成功
+	WSTypeUnsubscribed = "unsubscribed" // 取消订阅成功
+	WSTypeWeather      = "weather"      // 天气更新
+	WSTypeError        = "error"        // 错误
+)
+
+// WSClientMessage 客户端发送的 WebSocket 消息
+type WSClientMessage struct {
+	Type string `json:"type"`          // 消息类型：subscribe、unsubscribe
+	Key  string `json:"key,omitempty"` // 取消订阅时可直接使用订阅成功时返回的位置标识
+	WeatherRequest
+}
+
+// WSServerMessage 服务端推送的 WebSocket 消息
+type WSServerMessage struct {
+	Type    string           `json:"type"`              // 消息类型
+	Key     string           `json:"key,omitempty"`     // 位置标识
+	Data    *WeatherResponse `json:"data,omitempty"`    // 天气数据
+	Feature *Feature         `json:"feature,omitempty"` // 天气数据的 GeoJSON 点要素，连接以 format=geojson 建立时代替 data
+	Error   *ErrorResponse   `json:"error,omitempty"`   // 错误信息
+}
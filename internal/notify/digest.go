@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// digestData 简报模板可以使用的数据
+type digestData struct {
+	Title    string
+	Items    []model.DigestItem
+	TempUnit string
+	Time     string
+}
+
+// SendDigest 渲染多个位置的天气简报并通过指定渠道发送，loc 为发送时间显示使用的时区
+func (n *Notifier) SendDigest(ctx context.Context, name string, digest *model.Digest, loc *time.Location) (*Message, error) {
+	ch, ok := n.channels[name]
+	if !ok {
+		return nil, ErrUnknownChannel
+	}
+
+	msg, err := ch.renderDigest(digest, loc)
+	if err != nil {
+		return nil, err
+	}
+	return msg, n.deliver(ctx, ch, msg)
+}
+
+// renderDigest 使用渠道格式对应的简报模板渲染消息
+func (ch *channel) renderDigest(digest *model.Digest, loc *time.Location) (*Message, error) {
+	lang := "zh_cn"
+	if strings.HasPrefix(digest.Lang, "en") {
+		lang = "en"
+	}
+
+	tempUnit, _ := unitLabels(digest.Units)
+	data := digestData{
+		Title:    digest.Title,
+		Items:    digest.Items,
+		TempUnit: tempUnit,
+		Time:     digest.ScheduledAt.In(loc).Format("2006-01-02 15:04 MST"),
+	}
+
+	var body strings.Builder
+	if err := ch.templates.ExecuteTemplate(&body, "digest."+ch.format+"."+lang+".tmpl", data); err != nil {
+		return nil, fmt.Errorf("渲染简报模板失败: %w", err)
+	}
+	return &Message{Title: digest.Title, Body: strings.TrimSpace(body.String())}, nil
+}
@@ -0,0 +1,340 @@
+// Package notify 实现天气告警的通知渠道：SMTP 邮件、钉钉机器人、企业微信机器人、飞书机器人和 Slack Incoming Webhook。
+//
+// 渠道在 JSON 文件中配置，每个渠道有独立的限流器和消息模板。消息正文由 text/template 根据
+// model.WeatherResponse 渲染，默认模板按消息格式和语言放在 templates 目录下，也可以在渠道配置中覆盖。
+package notify
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/quota"
+	"gin-weather/internal/summary"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// 渠道类型
+const (
+	TypeSMTP     = "smtp"
+	TypeDingTalk = "dingtalk"
+	TypeWeCom    = "wecom"
+	TypeFeishu   = "feishu"
+	TypeSlack    = "slack"
+)
+
+// defaultRatePerMinute 渠道未配置限流时每分钟最多发送的消息数，与钉钉机器人的限制一致
+const defaultRatePerMinute = 20
+
+var (
+	// ErrUnknownChannel 渠道不存在
+	ErrUnknownChannel = errors.New("通知渠道不存在")
+	// ErrRateLimited 渠道发送过于频繁
+	ErrRateLimited = errors.New("通知渠道发送过于频繁，请稍后再试")
+)
+
+// ChannelConfig 渠道配置，机器人类渠道使用 url 和 secret，SMTP 渠道使用 host 等字段
+type ChannelConfig struct {
+	Name          string   `json:"name"`                      // 渠道名称，在 API 和告警规则中引用
+	Type          string   `json:"type"`                      // smtp、dingtalk、wecom、feishu、slack
+	URL           string   `json:"url,omitempty"`             // 机器人 Webhook 地址
+	Secret        string   `json:"secret,omitempty"`          // 钉钉、飞书机器人的签名密钥
+	RatePerMinute int      `json:"rate_per_minute,omitempty"` // 每分钟最多发送的消息数
+	Template      string   `json:"template,omitempty"`        // 自定义消息模板，为空时使用默认模板
+	Host          string   `json:"host,omitempty"`            // SMTP 服务器地址
+	Port          int      `json:"port,omitempty"`            // SMTP 端口，默认 587，tls 为 true 时默认 465
+	TLS           bool     `json:"tls,omitempty"`             // 是否使用隐式 TLS（SMTPS）
+	Username      string   `json:"username,omitempty"`        // SMTP 用户名
+	Password      string   `json:"password,omitempty"`        // SMTP 密码
+	From          string   `json:"from,omitempty"`            // 发件人
+	To            []string `json:"to,omitempty"`              // 收件人
+}
+
+// channelsFile 渠道配置文件格式
+type channelsFile struct {
+	Channels []ChannelConfig `json:"channels"`
+}
+
+// Event 需要发送的通知
+type Event struct {
+	Title   string                 // 标题
+	Reason  string                 // 触发原因，可以为空
+	Weather *model.WeatherResponse // 天气数据
+	Units   string                 // 天气数据使用的单位系统
+	Lang    string                 // 消息语言
+}
+
+// Message 渲染后的消息
+type Message struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// templateData 消息模板可以使用的数据
+type templateData struct {
+	Title     string
+	Reason    string
+	Weather   *model.WeatherResponse
+	Summary   string
+	TempUnit  string
+	SpeedUnit string
+	Time      string
+}
+
+// driver 渠道驱动
+type driver interface {
+	send(ctx context.Context, msg *Message) error
+}
+
+// channel 已初始化的渠道
+type channel struct {
+	config    ChannelConfig
+	driver    driver
+	format    string
+	templates *template.Template
+	limiter   *quota.Limiter
+}
+
+// Notifier 通知渠道集合，可以并发使用
+type Notifier struct {
+	channels map[string]*channel
+	timeout  time.Duration
+}
+
+// Load 从配置文件加载渠道，path 为空时返回没有渠道的 Notifier
+func Load(path string, timeout time.Duration) (*Notifier, error) {
+	if path == "" {
+		return New(nil, timeout)
+	}
+
+	var file channelsFile
+	if err := config.LoadFile(path, "通知渠道配置", &file); err != nil {
+		return nil, err
+	}
+	return New(file.Channels, timeout)
+}
+
+// New 根据渠道配置创建 Notifier
+func New(configs []ChannelConfig, timeout time.Duration) (*Notifier, error) {
+	n := &Notifier{
+		channels: make(map[string]*channel, len(configs)),
+		timeout:  timeout,
+	}
+	client := &http.Client{Timeout: timeout}
+
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("通知渠道缺少 name")
+		}
+		if _, ok := n.channels[cfg.Name]; ok {
+			return nil, fmt.Errorf("通知渠道 %s 重复定义", cfg.Name)
+		}
+
+		ch, err := newChannel(cfg, client, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("通知渠道 %s 配置无效: %w", cfg.Name, err)
+		}
+		n.channels[cfg.Name] = ch
+	}
+	return n, nil
+}
+
+// newChannel 创建渠道驱动、模板和限流器
+func newChannel(cfg ChannelConfig, client *http.Client, timeout time.Duration) (*channel, error) {
+	ch := &channel{config: cfg}
+
+	var err error
+	switch cfg.Type {
+	case TypeSMTP:
+		ch.driver, err = newSMTP(&cfg, timeout)
+		ch.format = "text"
+	case TypeDingTalk:
+		ch.driver, err = &dingTalk{url: cfg.URL, secret: cfg.Secret, client: client}, checkURL(cfg.URL)
+		ch.format = "markdown"
+	case TypeWeCom:
+		ch.driver, err = &weCom{url: cfg.URL, client: client}, checkURL(cfg.URL)
+		ch.format = "markdown"
+	case TypeFeishu:
+		ch.driver, err = &feishu{url: cfg.URL, secret: cfg.Secret, client: client}, checkURL(cfg.URL)
+		ch.format = "text"
+	case TypeSlack:
+		ch.driver, err = &slack{url: cfg.URL, client: client}, checkURL(cfg.URL)
+		ch.format = "slack"
+	default:
+		return nil, fmt.Errorf("不支持的渠道类型 %q", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ch.templates = template.New("").Funcs(templateFuncs)
+	if cfg.Template != "" {
+		if _, err := ch.templates.New("custom").Parse(cfg.Template); err != nil {
+			return nil, fmt.Errorf("解析消息模板失败: %w", err)
+		}
+	} else {
+		if _, err := ch.templates.ParseFS(templateFS, "templates/"+ch.format+".*.tmpl"); err != nil {
+			return nil, fmt.Errorf("加载默认消息模板失败: %w", err)
+		}
+	}
+	// 简报有多个位置，与告警模板的数据结构不同，总是使用默认模板
+	if _, err := ch.templates.ParseFS(templateFS, "templates/digest."+ch.format+".*.tmpl"); err != nil {
+		return nil, fmt.Errorf("加载简报消息模板失败: %w", err)
+	}
+
+	rate := cfg.RatePerMinute
+	if rate <= 0 {
+		rate = defaultRatePerMinute
+	}
+	// 只允许少量突发，避免短时间内触发机器人平台的限流
+	ch.limiter = quota.NewLimiter(rate, (rate+3)/4)
+	ch.config.RatePerMinute = rate
+
+	return ch, nil
+}
+
+// Has 判断渠道是否存在
+func (n *Notifier) Has(name string) bool {
+	_, ok := n.channels[name]
+	return ok
+}
+
+// Channels 按名称返回全部渠道的概要，不包含密钥
+func (n *Notifier) Channels() []model.NotifyChannel {
+	list := make([]model.NotifyChannel, 0, len(n.channels))
+	for _, ch := range n.channels {
+		list = append(list, model.NotifyChannel{
+			Name:          ch.config.Name,
+			Type:          ch.config.Type,
+			RatePerMinute: ch.config.RatePerMinute,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Send 渲染消息并通过指定渠道发送，超出渠道限流时返回 ErrRateLimited
+func (n *Notifier) Send(ctx context.Context, name string, event *Event) (*Message, error) {
+	ch, ok := n.channels[name]
+	if !ok {
+		return nil, ErrUnknownChannel
+	}
+
+	msg, err := ch.render(event)
+	if err != nil {
+		return nil, err
+	}
+	return msg, n.deliver(ctx, ch, msg)
+}
+
+// deliver 在渠道限流内发送已渲染的消息
+func (n *Notifier) deliver(ctx context.Context, ch *channel, msg *Message) error {
+	if !ch.limiter.Allow() {
+		return ErrRateLimited
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+	if err := ch.driver.send(ctx, msg); err != nil {
+		return fmt.Errorf("通过 %s 发送通知失败: %w", ch.config.Name, err)
+	}
+	return nil
+}
+
+// render 使用渠道模板渲染消息
+func (ch *channel) render(event *Event) (*Message, error) {
+	lang := event.Lang
+	if !strings.HasPrefix(lang, "en") {
+		lang = "zh_cn"
+	} else {
+		lang = "en"
+	}
+
+	name := "custom"
+	if ch.config.Template == "" {
+		name = ch.format + "." + lang + ".tmpl"
+	}
+
+	tempUnit, speedUnit := unitLabels(event.Units)
+	loc := time.FixedZone("", event.Weather.Location.Timezone)
+	data := templateData{
+		Title:     event.Title,
+		Reason:    event.Reason,
+		Weather:   event.Weather,
+		Summary:   summary.Generate(event.Weather, event.Units, event.Lang),
+		TempUnit:  tempUnit,
+		SpeedUnit: speedUnit,
+		Time:      time.Unix(event.Weather.Timestamp, 0).In(loc).Format("2006-01-02 15:04"),
+	}
+
+	var body strings.Builder
+	if err := ch.templates.ExecuteTemplate(&body, name, data); err != nil {
+		return nil, fmt.Errorf("渲染消息模板失败: %w", err)
+	}
+	return &Message{Title: event.Title, Body: strings.TrimSpace(body.String())}, nil
+}
+
+// templateFuncs 消息模板可以使用的函数
+var templateFuncs = template.FuncMap{
+	// num 格式化数值，去掉多余的小数位
+	"num": func(v float64) string {
+		return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+	},
+	// deref 取指针指向的数值
+	"deref": func(v *float64) float64 {
+		return *v
+	},
+	// risk 降水风险等级的中文名称
+	"risk": func(level string) string {
+		switch level {
+		case model.PrecipRiskLow:
+			return "低"
+		case model.PrecipRiskModerate:
+			return "中"
+		case model.PrecipRiskHigh:
+			return "高"
+		}
+		return "未知"
+	},
+	// conditions 拼接全部天气状况描述
+	"conditions": func(w *model.WeatherResponse) string {
+		descriptions := make([]string, 0, len(w.Current.Weather))
+		for _, weather := range w.Current.Weather {
+			descriptions = append(descriptions, weather.Description)
+		}
+		return strings.Join(descriptions, "、")
+	},
+}
+
+// unitLabels 返回单位系统对应的温度和风速单位
+func unitLabels(units string) (string, string) {
+	switch units {
+	case "imperial":
+		return "°F", "mph"
+	case "standard":
+		return "K", "m/s"
+	}
+	return "°C", "m/s"
+}
+
+// checkURL 校验机器人 Webhook 地址
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url 必须是 http 或 https 地址")
+	}
+	return nil
+}
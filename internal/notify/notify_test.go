@@ -0,0 +1,387 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+func testEvent() *Event {
+	return &Event{
+		Title:  "天气告警：Shanghai",
+		Reason: "wind.gust > 15（当前值 18）",
+		Weather: &model.WeatherResponse{
+			Location: model.Location{Name: "Shanghai", Timezone: 28800},
+			Current: model.Current{
+				Temperature: 8.25,
+				FeelsLike:   5,
+				Humidity:    80,
+				Weather:     []model.Weather{{Main: "Rain", Description: "小雨"}},
+				Wind:        model.Wind{Speed: 9, Direction: 90, Gust: 18},
+			},
+			Timestamp: 1640995200,
+		},
+		Units: "metric",
+		Lang:  "zh_cn",
+	}
+}
+
+// robotServer 记录收到的请求并返回固定响应
+type robotServer struct {
+	mu       sync.Mutex
+	response string
+	requests []*http.Request
+	bodies   []map[string]interface{}
+}
+
+func (s *robotServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	var body map[string]interface{}
+	json.Unmarshal(data, &body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	s.bodies = append(s.bodies, body)
+	s.mu.Unlock()
+
+	w.Write([]byte(s.response))
+}
+
+func newNotifier(t *testing.T, configs ...ChannelConfig) *Notifier {
+	t.Helper()
+	n, err := New(configs, 5*time.Second)
+	if err != nil {
+		t.Fatalf("创建 Notifier 失败: %v", err)
+	}
+	return n
+}
+
+func TestDingTalk(t *testing.T) {
+	robot := &robotServer{response: `{"errcode":0,"errmsg":"ok"}`}
+	server := httptest.NewServer(robot)
+	defer server.Close()
+
+	n := newNotifier(t, ChannelConfig{Name: "ops", Type: TypeDingTalk, URL: server.URL + "/robot/send?access_token=abc", Secret: "SECtest"})
+	msg, err := n.Send(context.Background(), "ops", testEvent())
+	if err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	query := robot.requests[0].URL.Query()
+	if query.Get("access_token") != "abc" {
+		t.Error("期望保留原有的 access_token 参数")
+	}
+	if want := dingTalkSign("SECtest", query.Get("timestamp")); query.Get("sign") != want {
+		t.Errorf("签名不匹配: %s != %s", query.Get("sign"), want)
+	}
+
+	markdown := robot.bodies[0]["markdown"].(map[string]interface{})
+	if robot.bodies[0]["msgtype"] != "markdown" || markdown["title"] != "天气告警：Shanghai" || markdown["text"] != msg.Body {
+		t.Errorf("消息内容不正确: %v", robot.bodies[0])
+	}
+	for _, want := range []string{"### 天气告警：Shanghai", "> wind.gust > 15", "温度：8.3°C（体感 5°C）", "阵风 18 m/s", "2022-01-01 08:00"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("期望消息包含 %q，实际为:\n%s", want, msg.Body)
+		}
+	}
+}
+
+func TestDingTalkError(t *testing.T) {
+	robot := &robotServer{response: `{"errcode":310000,"errmsg":"sign not match"}`}
+	server := httptest.NewServer(robot)
+	defer server.Close()
+
+	n := newNotifier(t, ChannelConfig{Name: "ops", Type: TypeDingTalk, URL: server.URL})
+	if _, err := n.Send(context.Background(), "ops", testEvent()); err == nil || !strings.Contains(err.Error(), "310000") {
+		t.Errorf("期望返回钉钉错误码，实际为 %v", err)
+	}
+}
+
+func TestSendErrorHidesURL(t *testing.T) {
+	// 取一个已经关闭的端口，连接会被拒绝
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	tests := []ChannelConfig{
+		{Name: "dingtalk", Type: TypeDingTalk, URL: "http://" + addr + "/robot/send?access_token=tok-secret", Secret: "SECtest"},
+		{Name: "wecom", Type: TypeWeCom, URL: "http://" + addr + "/cgi-bin/webhook/send?key=tok-secret"},
+		{Name: "feishu", Type: TypeFeishu, URL: "http://" + addr + "/open-apis/bot/v2/hook/tok-secret"},
+		{Name: "slack", Type: TypeSlack, URL: "http://" + addr + "/services/T000/B000/tok-secret"},
+	}
+	n := newNotifier(t, tests...)
+	for _, cfg := range tests {
+		_, err := n.Send(context.Background(), cfg.Name, testEvent())
+		if err == nil {
+			t.Errorf("%s: 期望连接失败", cfg.Name)
+			continue
+		}
+		if strings.Contains(err.Error(), "tok-secret") || strings.Contains(err.Error(), "sign=") {
+			t.Errorf("%s: 错误信息包含机器人地址中的密钥: %v", cfg.Name, err)
+		}
+	}
+}
+
+func TestWeCom(t *testing.T) {
+	robot := &robotServer{response: `{"errcode":0,"errmsg":"ok"}`}
+	server := httptest.NewServer(robot)
+	defer server.Close()
+
+	n := newNotifier(t, ChannelConfig{Name: "wecom", Type: TypeWeCom, URL: server.URL})
+	msg, err := n.Send(context.Background(), "wecom", testEvent())
+	if err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	markdown := robot.bodies[0]["markdown"].(map[string]interface{})
+	if markdown["content"] != msg.Body {
+		t.Errorf("消息内容不正确: %v", robot.bodies[0])
+	}
+}
+
+func TestSendDigest(t *testing.T) {
+	robot := &robotServer{response: `{"errcode":0,"errmsg":"ok"}`}
+	server := httptest.NewServer(robot)
+	defer server.Close()
+
+	probability := 35.0
+	event := testEvent()
+	digest := &model.Digest{
+		Title:       "早间天气",
+		ScheduledAt: time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC),
+		Units:       "metric",
+		Lang:        "zh_cn",
+		Items: []model.DigestItem{
+			{Name: "Shanghai", Weather: event.Weather, High: 12, Low: 6.5, PrecipProbability: &probability, PrecipRisk: model.PrecipRiskModerate, Forecast: true},
+			{Name: "Nowhere", Error: "city not found"},
+		},
+	}
+
+	// 自定义模板只用于告警，简报仍使用默认模板
+	n := newNotifier(t, ChannelConfig{Name: "wecom", Type: TypeWeCom, URL: server.URL, Template: "{{.Title}}"})
+	msg, err := n.SendDigest(context.Background(), "wecom", digest, time.FixedZone("CST", 8*3600))
+	if err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	for _, want := range []string{"### 早间天气", "**Shanghai** 小雨", "最高 12°C，最低 6.5°C", "降水风险：中（35%）", "**Nowhere** 获取天气失败：city not found", "2024-03-01 15:00 CST"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("期望消息包含 %q，实际为:\n%s", want, msg.Body)
+		}
+	}
+	markdown := robot.bodies[0]["markdown"].(map[string]interface{})
+	if markdown["content"] != msg.Body {
+		t.Errorf("消息内容不正确: %v", robot.bodies[0])
+	}
+
+	if _, err := n.SendDigest(context.Background(), "missing", digest, time.UTC); !errors.Is(err, ErrUnknownChannel) {
+		t.Errorf("期望返回 ErrUnknownChannel，实际为 %v", err)
+	}
+}
+
+func TestFeishu(t *testing.T) {
+	robot := &robotServer{response: `{"code":0,"msg":"success"}`}
+	server := httptest.NewServer(robot)
+	defer server.Close()
+
+	n := newNotifier(t, ChannelConfig{Name: "feishu", Type: TypeFeishu, URL: server.URL, Secret: "secret"})
+	event := testEvent()
+	event.Lang = "en"
+	msg, err := n.Send(context.Background(), "feishu", event)
+	if err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	body := robot.bodies[0]
+	if want := feishuSign("secret", body["timestamp"].(string)); body["sign"] != want {
+		t.Errorf("签名不匹配: %v != %s", body["sign"], want)
+	}
+	if body["msg_type"] != "text" || body["content"].(map[string]interface{})["text"] != msg.Body {
+		t.Errorf("消息内容不正确: %v", body)
+	}
+	if !strings.Contains(msg.Body, "Temperature: 8.3°C (feels like 5°C)") {
+		t.Errorf("期望使用英文模板，实际为:\n%s", msg.Body)
+	}
+}
+
+func TestSlackCustomTemplate(t *testing.T) {
+	robot := &robotServer{response: "ok"}
+	server := httptest.NewServer(robot)
+	defer server.Close()
+
+	n := newNotifier(t, ChannelConfig{
+		Name:     "slack",
+		Type:     TypeSlack,
+		URL:      server.URL,
+		Template: `{{.Title}}: {{.Weather.Location.Name}} {{num .Weather.Current.Temperature}}{{.TempUnit}}`,
+	})
+	if _, err := n.Send(context.Background(), "slack", testEvent()); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	if text := robot.bodies[0]["text"]; text != "天气告警：Shanghai: Shanghai 8.3°C" {
+		t.Errorf("期望使用自定义模板，实际为 %v", text)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	robot := &robotServer{response: "ok"}
+	server := httptest.NewServer(robot)
+	defer server.Close()
+
+	n := newNotifier(t, ChannelConfig{Name: "slack", Type: TypeSlack, URL: server.URL, RatePerMinute: 1})
+	if _, err := n.Send(context.Background(), "slack", testEvent()); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	if _, err := n.Send(context.Background(), "slack", testEvent()); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("期望返回 ErrRateLimited，实际为 %v", err)
+	}
+	if _, err := n.Send(context.Background(), "missing", testEvent()); !errors.Is(err, ErrUnknownChannel) {
+		t.Errorf("期望返回 ErrUnknownChannel，实际为 %v", err)
+	}
+}
+
+// fakeSMTP 启动只接收一封邮件的本地 SMTP 服务，返回监听端口和收到的邮件内容
+func fakeSMTP(t *testing.T) (int, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		var envelope []string
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch command {
+			case "EHLO":
+				tp.PrintfLine("250-localhost")
+				tp.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				envelope = append(envelope, line)
+				tp.PrintfLine("235 2.7.0 Authentication successful")
+			case "MAIL", "RCPT":
+				envelope = append(envelope, line)
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 Go ahead")
+				lines, _ := tp.ReadDotLines()
+				received <- strings.Join(envelope, "\n") + "\n\n" + strings.Join(lines, "\n")
+				tp.PrintfLine("250 OK")
+			case "QUIT":
+				tp.PrintfLine("221 Bye")
+				return
+			default:
+				tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestSMTP(t *testing.T) {
+	port, received := fakeSMTP(t)
+
+	n := newNotifier(t, ChannelConfig{
+		Name:     "mail",
+		Type:     TypeSMTP,
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "bot",
+		Password: "pass",
+		From:     "天气机器人 <bot@example.com>",
+		To:       []string{"ops@example.com", "oncall@example.com"},
+	})
+	msg, err := n.Send(context.Background(), "mail", testEvent())
+	if err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	mail := <-received
+	for _, want := range []string{"MAIL FROM:<bot@example.com>", "RCPT TO:<ops@example.com>", "RCPT TO:<oncall@example.com>", "AUTH PLAIN", "Subject: =?UTF-8?b?", "Content-Type: text/plain; charset=UTF-8"} {
+		if !strings.Contains(mail, want) {
+			t.Errorf("期望邮件包含 %q，实际为:\n%s", want, mail)
+		}
+	}
+
+	encoded := strings.Join(strings.Split(mail[strings.Index(mail, "base64\n\n")+len("base64\n\n"):], "\n"), "")
+	body, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("解码邮件正文失败: %v", err)
+	}
+	if string(body) != msg.Body {
+		t.Errorf("邮件正文不正确:\n%s", body)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	os.Setenv("TEST_DINGTALK_SECRET", "SECfromenv")
+	defer os.Unsetenv("TEST_DINGTALK_SECRET")
+
+	path := filepath.Join(t.TempDir(), "channels.json")
+	os.WriteFile(path, []byte(`{"channels":[
+		{"name":"ops","type":"dingtalk","url":"https://oapi.dingtalk.com/robot/send?access_token=x","secret":"${TEST_DINGTALK_SECRET}"},
+		{"name":"team","type":"slack","url":"https://hooks.slack.com/services/x","rate_per_minute":5}
+	]}`), 0o644)
+
+	n, err := Load(path, time.Second)
+	if err != nil {
+		t.Fatalf("加载渠道配置失败: %v", err)
+	}
+	if n.channels["ops"].config.Secret != "SECfromenv" {
+		t.Errorf("期望展开环境变量，实际为 %s", n.channels["ops"].config.Secret)
+	}
+	channels := n.Channels()
+	if len(channels) != 2 || channels[0].Name != "ops" || channels[0].RatePerMinute != defaultRatePerMinute || channels[1].RatePerMinute != 5 {
+		t.Errorf("渠道列表不正确: %+v", channels)
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ChannelConfig
+	}{
+		{"缺少名称", ChannelConfig{Type: TypeSlack, URL: "https://hooks.slack.com/x"}},
+		{"未知类型", ChannelConfig{Name: "x", Type: "telegram", URL: "https://example.com"}},
+		{"非法地址", ChannelConfig{Name: "x", Type: TypeWeCom, URL: "qyapi.weixin.qq.com"}},
+		{"缺少收件人", ChannelConfig{Name: "x", Type: TypeSMTP, Host: "smtp.example.com", From: "bot@example.com"}},
+		{"模板错误", ChannelConfig{Name: "x", Type: TypeSlack, URL: "https://hooks.slack.com/x", Template: "{{.Title"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]ChannelConfig{tt.config}, time.Second); err == nil {
+				t.Error("期望返回配置错误")
+			}
+		})
+	}
+}
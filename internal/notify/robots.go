@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxResponseSize 读取机器人响应的最大长度
+const maxResponseSize = 64 << 10
+
+// dingTalk 钉钉自定义机器人，配置了加签密钥时在地址上附加 timestamp 和 sign
+type dingTalk struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func (d *dingTalk) send(ctx context.Context, msg *Message) error {
+	target := d.url
+	if d.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		var err error
+		target, err = withQuery(target, map[string]string{
+			"timestamp": timestamp,
+			"sign":      dingTalkSign(d.secret, timestamp),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": msg.Title,
+			"text":  msg.Body,
+		},
+	}
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := postJSON(ctx, d.client, target, payload, &result); err != nil {
+		return err
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("钉钉返回错误 %d: %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// dingTalkSign 钉钉加签：对 "timestamp\nsecret" 以 secret 为密钥做 HMAC-SHA256 后 Base64 编码
+func dingTalkSign(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// weCom 企业微信群机器人
+type weCom struct {
+	url    string
+	client *http.Client
+}
+
+func (w *weCom) send(ctx context.Context, msg *Message) error {
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": msg.Body,
+		},
+	}
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := postJSON(ctx, w.client, w.url, payload, &result); err != nil {
+		return err
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("企业微信返回错误 %d: %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// feishu 飞书自定义机器人，配置了签名校验时在请求体中附加 timestamp 和 sign
+type feishu struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func (f *feishu) send(ctx context.Context, msg *Message) error {
+	payload := map[string]interface{}{
+		"msg_type": "text",
+		"content": map[string]string{
+			"text": msg.Body,
+		},
+	}
+	if f.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		payload["timestamp"] = timestamp
+		payload["sign"] = feishuSign(f.secret, timestamp)
+	}
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := postJSON(ctx, f.client, f.url, payload, &result); err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("飞书返回错误 %d: %s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// feishuSign 飞书签名：以 "timestamp\nsecret" 为密钥对空内容做 HMAC-SHA256 后 Base64 编码
+func feishuSign(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// slack Slack Incoming Webhook
+type slack struct {
+	url    string
+	client *http.Client
+}
+
+func (s *slack) send(ctx context.Context, msg *Message) error {
+	return postJSON(ctx, s.client, s.url, map[string]string{"text": msg.Body}, nil)
+}
+
+// postJSON 发送 JSON 请求，result 不为 nil 时解析响应
+func postJSON(ctx context.Context, client *http.Client, target string, payload, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return withoutURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return withoutURL(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("返回状态码 %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("解析响应失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// withoutURL 去掉 HTTP 客户端错误中的机器人地址。地址中包含 access_token、key 等密钥，
+// 错误信息会返回给测试接口的调用方并写入投递日志
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// withQuery 在地址上附加查询参数
+func withQuery(raw string, params map[string]string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", withoutURL(err)
+	}
+	query := u.Query()
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
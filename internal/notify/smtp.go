@@ -0,0 +1,146 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpDriver SMTP 邮件，tls 为 true 时使用隐式 TLS，否则在服务器支持时升级为 STARTTLS
+type smtpDriver struct {
+	host     string
+	port     int
+	useTLS   bool
+	username string
+	password string
+	from     string
+	to       []string
+	timeout  time.Duration
+}
+
+// newSMTP 校验 SMTP 配置并创建驱动
+func newSMTP(cfg *ChannelConfig, timeout time.Duration) (*smtpDriver, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("缺少 SMTP 服务器地址 host")
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("发件人 from 无效: %v", err)
+	}
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("缺少收件人 to")
+	}
+	for _, to := range cfg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return nil, fmt.Errorf("收件人 %s 无效: %v", to, err)
+		}
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+		if cfg.TLS {
+			port = 465
+		}
+	}
+
+	return &smtpDriver{
+		host:     cfg.Host,
+		port:     port,
+		useTLS:   cfg.TLS,
+		username: cfg.Username,
+		password: cfg.Password,
+		from:     cfg.From,
+		to:       cfg.To,
+		timeout:  timeout,
+	}, nil
+}
+
+func (s *smtpDriver) send(ctx context.Context, msg *Message) error {
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	dialer := &net.Dialer{Timeout: s.timeout}
+
+	var conn net.Conn
+	var err error
+	if s.useTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !s.useTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+				return err
+			}
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return err
+		}
+	}
+
+	from, _ := mail.ParseAddress(s.from)
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range s.to {
+		addr, _ := mail.ParseAddress(to)
+		if err := client.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(s.buildMessage(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMessage 生成 UTF-8 纯文本邮件，正文使用 Base64 编码
+func (s *smtpDriver) buildMessage(msg *Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + s.from + "\r\n")
+	b.WriteString("To: " + strings.Join(s.to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Title) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return []byte(b.String())
+}
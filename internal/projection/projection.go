@@ -0,0 +1,238 @@
+// Package projection 对任意可 JSON 序列化的响应结构体做字段投影
+//
+// 字段路径使用 JSON 字段名，以点号分隔，例如 current.weather.icon；
+// 路径经过数组时作用于数组中的每个元素。路径会先根据结构体类型校验，
+// 未知的路径会返回错误，即使该字段因为 omitempty 没有出现在本次响应中也能被识别。
+package projection
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// ParseList 解析逗号分隔的路径列表，支持多次传入同一参数
+func ParseList(values []string) []string {
+	var paths []string
+	for _, value := range values {
+		for _, path := range strings.Split(value, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// Apply 对数据做投影：fields 非空时只保留指定字段，exclude 中的字段会被移除
+func Apply(data interface{}, fields, exclude []string) (interface{}, error) {
+	if len(fields) == 0 && len(exclude) == 0 {
+		return data, nil
+	}
+
+	t := reflect.TypeOf(data)
+	for _, path := range append(append([]string{}, fields...), exclude...) {
+		if err := Validate(t, path); err != nil {
+			return nil, err
+		}
+	}
+
+	generic, err := toGeneric(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) > 0 {
+		tree := make(node)
+		for _, path := range fields {
+			tree.add(strings.Split(path, "."))
+		}
+		generic = tree.pick(generic)
+	}
+	for _, path := range exclude {
+		remove(generic, strings.Split(path, "."))
+	}
+
+	return generic, nil
+}
+
+// Validate 根据类型校验字段路径是否存在
+func Validate(t reflect.Type, path string) error {
+	current := t
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("字段路径 %q 格式不正确", path)
+		}
+
+		current = elemType(current)
+		if current == nil || current.Kind() == reflect.Interface {
+			// 动态类型无法静态校验
+			return nil
+		}
+		if isLeaf(current) {
+			return fmt.Errorf("未知字段 %q：%s 没有子字段", path, strings.Join(segments[:i], "."))
+		}
+
+		switch current.Kind() {
+		case reflect.Map:
+			current = current.Elem()
+		case reflect.Struct:
+			field, ok := lookupField(current, segment)
+			if !ok {
+				return fmt.Errorf("未知字段 %q", path)
+			}
+			current = field
+		default:
+			return fmt.Errorf("未知字段 %q：%s 没有子字段", path, strings.Join(segments[:i], "."))
+		}
+	}
+	return nil
+}
+
+// elemType 解开指针、切片和数组，返回元素类型
+func elemType(t reflect.Type) reflect.Type {
+	for t != nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+	return nil
+}
+
+// isLeaf 判断类型是否序列化为不可再分的 JSON 值
+func isLeaf(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return false
+	default:
+		return true
+	}
+}
+
+// lookupField 按 JSON 字段名查找结构体字段的类型，支持匿名嵌入字段
+func lookupField(t reflect.Type, name string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		jsonName := strings.Split(tag, ",")[0]
+
+		if jsonName == "" && field.Anonymous {
+			if embedded := elemType(field.Type); embedded != nil && embedded.Kind() == reflect.Struct {
+				if ft, ok := lookupField(embedded, name); ok {
+					return ft, true
+				}
+			}
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		if jsonName == name {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+// toGeneric 通过 JSON 往返将数据转换为通用的 map/slice 结构
+func toGeneric(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("序列化响应失败: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return generic, nil
+}
+
+// node 字段选择树，空节点表示保留整个字段
+type node map[string]node
+
+// add 向选择树中加入一条路径
+func (n node) add(segments []string) {
+	child, ok := n[segments[0]]
+	if len(segments) == 1 {
+		// 选择整个字段会覆盖更细的子字段选择
+		n[segments[0]] = make(node)
+		return
+	}
+	if ok && len(child) == 0 {
+		return
+	}
+	if !ok {
+		child = make(node)
+		n[segments[0]] = child
+	}
+	child.add(segments[1:])
+}
+
+// pick 按选择树保留字段
+func (n node) pick(value interface{}) interface{} {
+	if len(n) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(n))
+		for key, child := range n {
+			if fieldValue, ok := v[key]; ok {
+				result[key] = child.pick(fieldValue)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			result[i] = n.pick(elem)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// remove 按路径移除字段
+func remove(value interface{}, segments []string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(segments) == 1 {
+			delete(v, segments[0])
+			return
+		}
+		if child, ok := v[segments[0]]; ok {
+			remove(child, segments[1:])
+		}
+	case []interface{}:
+		for _, elem := range v {
+			remove(elem, segments)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package projection
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gin-weather/internal/model"
+)
+
+func newResponse() *model.WeatherResponse {
+	return &model.WeatherResponse{
+		Location: model.Location{Name: "Beijing", Country: "CN"},
+		Current: model.Current{
+			Temperature: 25.5,
+			Humidity:    60,
+			Weather: []model.Weather{
+				{ID: 800, Main: "Clear", Icon: "01d"},
+				{ID: 701, Main: "Mist", Icon: "50d"},
+			},
+		},
+		Provider: "openweathermap",
+	}
+}
+
+func toJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	return string(raw)
+}
+
+func TestApplyFields(t *testing.T) {
+	fields := ParseList([]string{"location.name,current.temperature", "current.weather.icon"})
+	got, err := Apply(newResponse(), fields, nil)
+	if err != nil {
+		t.Fatalf("投影失败: %v", err)
+	}
+
+	want := `{"current":{"temperature":25.5,"weather":[{"icon":"01d"},{"icon":"50d"}]},"location":{"name":"Beijing"}}`
+	if s := toJSON(t, got); s != want {
+		t.Errorf("期望 %s，实际为 %s", want, s)
+	}
+}
+
+func TestApplyExclude(t *testing.T) {
+	got, err := Apply(newResponse(), []string{"location.name", "location.country", "provider"}, []string{"location.country"})
+	if err != nil {
+		t.Fatalf("投影失败: %v", err)
+	}
+
+	want := `{"location":{"name":"Beijing"},"provider":"openweathermap"}`
+	if s := toJSON(t, got); s != want {
+		t.Errorf("期望 %s，实际为 %s", want, s)
+	}
+}
+
+func TestApplyUnknownField(t *testing.T) {
+	invalid := []string{"current.temp", "current.temperature.value", "current.updated_at.year", "location..name"}
+	for _, path := range invalid {
+		if _, err := Apply(newResponse(), []string{path}, nil); err == nil {
+			t.Errorf("%s: 期望返回错误", path)
+		}
+	}
+
+	// omitempty 字段即使没有出现在响应中也是合法路径
+	if _, err := Apply(newResponse(), []string{"current.rain.1h", "summary"}, nil); err != nil {
+		t.Errorf("期望合法路径不返回错误，实际为 %v", err)
+	}
+}
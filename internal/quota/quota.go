@@ -0,0 +1,80 @@
+// Package quota 提供令牌桶限流器，用于把后台任务对上游天气 API 的调用控制在配额之内
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter 令牌桶限流器
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration // 生成一个令牌所需的时间
+	burst    float64       // 令牌桶容量
+	tokens   float64       // 当前令牌数
+	last     time.Time     // 上次补充令牌的时间
+}
+
+// NewLimiter 创建每分钟最多 perMinute 次、允许突发 burst 次的限流器
+func NewLimiter(perMinute, burst int) *Limiter {
+	if perMinute <= 0 {
+		perMinute = 1
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &Limiter{
+		interval: time.Minute / time.Duration(perMinute),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Allow 尝试立即获取一个令牌
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	if l.tokens >= 1 {
+		l.tokens--
+		return true
+	}
+	return false
+}
+
+// Wait 阻塞直到获取一个令牌或 ctx 结束
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.refill(now)
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) * float64(l.interval))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// refill 按经过的时间补充令牌
+func (l *Limiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last)
+	l.last = now
+	l.tokens += float64(elapsed) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
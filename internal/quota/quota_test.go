@@ -0,0 +1,43 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := NewLimiter(60, 2)
+
+	if !limiter.Allow() || !limiter.Allow() {
+		t.Fatal("期望突发容量内的请求被允许")
+	}
+	if limiter.Allow() {
+		t.Error("期望超出突发容量的请求被拒绝")
+	}
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	// 每分钟 6000 次，即每 10ms 一个令牌
+	limiter := NewLimiter(6000, 1)
+	limiter.Allow()
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("等待令牌失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Errorf("期望等待约 10ms，实际为 %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewLimiter(1, 1).Wait(ctx); err != nil {
+		t.Errorf("期望有令牌时立即返回，实际为 %v", err)
+	}
+	limiter = NewLimiter(1, 1)
+	limiter.Allow()
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("期望 ctx 结束时返回错误")
+	}
+}
@@ -0,0 +1,40 @@
+// Package resource 提供 Webhook、简报订阅等通过 API 创建和管理的资源共用的校验错误和随机标识
+package resource
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// ValidationError 资源校验失败，API 层据此返回 400
+type ValidationError struct {
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+// Invalidf 构造校验错误
+func Invalidf(format string, args ...interface{}) error {
+	return &ValidationError{msg: fmt.Sprintf(format, args...)}
+}
+
+// NewID 生成随机 ID
+func NewID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成 ID 失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// NewSecret 生成随机签名密钥
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成密钥失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
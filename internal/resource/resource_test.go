@@ -0,0 +1,29 @@
+package resource
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInvalidf(t *testing.T) {
+	err := Invalidf("最多 %d 个位置", 3)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || err.Error() != "最多 3 个位置" {
+		t.Errorf("期望返回校验错误，实际为 %v", err)
+	}
+}
+
+func TestNewIDAndSecret(t *testing.T) {
+	id, err := NewID()
+	if err != nil || len(id) != 16 {
+		t.Errorf("期望 16 位十六进制 ID，实际为 %q, %v", id, err)
+	}
+	other, _ := NewID()
+	if other == id {
+		t.Error("期望两次生成的 ID 不同")
+	}
+	secret, err := NewSecret()
+	if err != nil || len(secret) != 64 {
+		t.Errorf("期望 64 位十六进制密钥，实际为 %q, %v", secret, err)
+	}
+}
@@ -0,0 +1,242 @@
+// Package routing 按请求的位置在多个天气数据提供商之间选择。
+//
+// 路由规则按顺序匹配国家代码、经纬度范围或多边形区域，第一条匹配的规则决定使用哪个数据提供商，
+// 都不匹配时使用默认提供商；城市名称先通过地理编码解析为坐标和国家代码再匹配
+package routing
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/service"
+)
+
+const (
+	// RuleDefault 没有规则匹配时报告的规则名称
+	RuleDefault = "default"
+	// RuleOverride 通过 provider 参数指定数据提供商时报告的规则名称
+	RuleOverride = "override"
+)
+
+// RuleConfig 一条路由规则，位置满足 countries、bbox、polygon 中任意一个条件即匹配
+type RuleConfig struct {
+	Name      string      `json:"name"`                // 规则名称，会在响应中报告
+	Provider  string      `json:"provider"`            // 匹配时使用的数据提供商名称
+	Countries []string    `json:"countries,omitempty"` // ISO 3166 国家代码，如 CN
+	BBox      []float64   `json:"bbox,omitempty"`      // 经纬度范围 [最小经度, 最小纬度, 最大经度, 最大纬度]
+	Polygon   [][]float64 `json:"polygon,omitempty"`   // 多边形顶点 [[经度, 纬度], ...]
+}
+
+// File 路由配置文件格式
+type File struct {
+	Providers []config.ProviderConfig `json:"providers"`         // 额外的数据提供商
+	Rules     []RuleConfig            `json:"rules"`             // 按顺序匹配的规则
+	Default   string                  `json:"default,omitempty"` // 没有规则匹配时使用的数据提供商，默认为 WEATHER_PROVIDER
+}
+
+// Load 从配置文件加载路由规则，primary 是按 base 创建的天气服务，以 base.Provider 为名称参与路由；
+// extra 是由其他组件创建、可以在规则中按名称引用的天气服务，如多数据提供商融合
+func Load(path string, base *config.WeatherConfig, primary service.WeatherService, extra map[string]service.WeatherService) (*Service, error) {
+	var file File
+	if err := config.LoadFile(path, "路由配置", &file); err != nil {
+		return nil, err
+	}
+	return New(&file, base, primary, extra)
+}
+
+// New 根据路由配置创建路由天气服务，参数含义同 Load
+func New(file *File, base *config.WeatherConfig, primary service.WeatherService, extra map[string]service.WeatherService) (*Service, error) {
+	backends := map[string]service.WeatherService{base.Provider: primary}
+	for name, backend := range extra {
+		if _, ok := backends[name]; ok {
+			return nil, fmt.Errorf("数据提供商 %s 重复定义", name)
+		}
+		backends[name] = backend
+	}
+	for _, pc := range file.Providers {
+		if pc.Name == "" {
+			return nil, fmt.Errorf("数据提供商缺少 name")
+		}
+		if _, ok := backends[pc.Name]; ok {
+			return nil, fmt.Errorf("数据提供商 %s 重复定义", pc.Name)
+		}
+
+		backend, err := service.New(pc.WeatherConfig(base))
+		if err != nil {
+			return nil, fmt.Errorf("数据提供商 %s 配置无效: %w", pc.Name, err)
+		}
+		backends[pc.Name] = backend
+	}
+
+	fallback := file.Default
+	if fallback == "" {
+		fallback = base.Provider
+	}
+	if _, ok := backends[fallback]; !ok {
+		return nil, fmt.Errorf("默认数据提供商 %s 不存在", fallback)
+	}
+
+	rules := make([]rule, 0, len(file.Rules))
+	names := make(map[string]bool, len(file.Rules))
+	for _, rc := range file.Rules {
+		if rc.Name == "" || rc.Name == RuleDefault || rc.Name == RuleOverride {
+			return nil, fmt.Errorf("路由规则的 name 不能为空，也不能是 %s 或 %s", RuleDefault, RuleOverride)
+		}
+		if names[rc.Name] {
+			return nil, fmt.Errorf("路由规则 %s 重复定义", rc.Name)
+		}
+		names[rc.Name] = true
+		if _, ok := backends[rc.Provider]; !ok {
+			return nil, fmt.Errorf("路由规则 %s 引用的数据提供商 %s 不存在", rc.Name, rc.Provider)
+		}
+
+		r, err := newRule(&rc)
+		if err != nil {
+			return nil, fmt.Errorf("路由规则 %s 配置无效: %w", rc.Name, err)
+		}
+		rules = append(rules, *r)
+	}
+
+	// 地理编码优先使用默认提供商，它不支持时再使用主服务
+	geocoder, ok := backends[fallback].(service.Geocoder)
+	if !ok {
+		geocoder, _ = primary.(service.Geocoder)
+	}
+
+	return &Service{
+		backends: backends,
+		rules:    rules,
+		fallback: fallback,
+		geocoder: geocoder,
+	}, nil
+}
+
+// Service 按路由规则选择数据提供商的天气服务
+type Service struct {
+	backends map[string]service.WeatherService
+	rules    []rule
+	fallback string
+	// geocoder 解析城市名称和坐标所在国家，为 nil 时城市名称的请求总是使用默认提供商
+	geocoder service.Geocoder
+}
+
+// choose 返回处理请求的数据提供商名称和匹配的规则名称。city 不为空时先解析出坐标和国家代码；
+// 按坐标请求时只有遇到按国家匹配的规则才进行反向地理编码
+func (s *Service) choose(city string, lat, lon float64) (string, string) {
+	var country string
+	located := false
+	if city != "" {
+		if s.geocoder == nil {
+			return s.fallback, RuleDefault
+		}
+		location, err := s.geocoder.Geocode(city)
+		if err != nil {
+			log.Printf("路由时解析城市 %s 失败，使用默认数据提供商: %v", city, err)
+			return s.fallback, RuleDefault
+		}
+		lat, lon, country = location.Latitude, location.Longitude, location.Country
+		located = true
+	}
+
+	for i := range s.rules {
+		r := &s.rules[i]
+		if len(r.countries) > 0 && !located {
+			located = true
+			if s.geocoder != nil {
+				// 海上等没有国家的位置会返回错误，此时只按坐标条件匹配
+				if location, err := s.geocoder.ReverseGeocode(lat, lon); err == nil {
+					country = location.Country
+				}
+			}
+		}
+		if r.matches(lat, lon, country) {
+			return r.provider, r.name
+		}
+	}
+	return s.fallback, RuleDefault
+}
+
+// rule 解析后的路由规则
+type rule struct {
+	name      string
+	provider  string
+	countries map[string]bool
+	bbox      []float64
+	polygon   [][]float64
+}
+
+// newRule 检查并解析规则的匹配条件
+func newRule(rc *RuleConfig) (*rule, error) {
+	r := &rule{name: rc.Name, provider: rc.Provider}
+
+	if len(rc.Countries) > 0 {
+		r.countries = make(map[string]bool, len(rc.Countries))
+		for _, code := range rc.Countries {
+			r.countries[strings.ToUpper(strings.TrimSpace(code))] = true
+		}
+	}
+
+	if rc.BBox != nil {
+		if len(rc.BBox) != 4 {
+			return nil, fmt.Errorf("bbox 必须包含 4 个数")
+		}
+		if rc.BBox[1] > rc.BBox[3] || rc.BBox[1] < -90 || rc.BBox[3] > 90 {
+			return nil, fmt.Errorf("bbox 的纬度范围无效")
+		}
+		r.bbox = rc.BBox
+	}
+
+	if rc.Polygon != nil {
+		if len(rc.Polygon) < 3 {
+			return nil, fmt.Errorf("polygon 至少需要 3 个顶点")
+		}
+		for _, p := range rc.Polygon {
+			if len(p) != 2 {
+				return nil, fmt.Errorf("polygon 的每个顶点必须是 [经度, 纬度]")
+			}
+		}
+		r.polygon = rc.Polygon
+	}
+
+	if r.countries == nil && r.bbox == nil && r.polygon == nil {
+		return nil, fmt.Errorf("至少需要 countries、bbox、polygon 中的一个条件")
+	}
+	return r, nil
+}
+
+// matches 判断位置是否满足规则的任意一个条件，country 为空表示国家未知
+func (r *rule) matches(lat, lon float64, country string) bool {
+	if country != "" && r.countries[strings.ToUpper(country)] {
+		return true
+	}
+	if r.bbox != nil && inBBox(r.bbox, lat, lon) {
+		return true
+	}
+	return r.polygon != nil && inPolygon(r.polygon, lat, lon)
+}
+
+// inBBox 判断坐标是否在经纬度范围内，最小经度大于最大经度时表示范围跨越 180° 经线
+func inBBox(bbox []float64, lat, lon float64) bool {
+	if lat < bbox[1] || lat > bbox[3] {
+		return false
+	}
+	if bbox[0] <= bbox[2] {
+		return lon >= bbox[0] && lon <= bbox[2]
+	}
+	return lon >= bbox[0] || lon <= bbox[2]
+}
+
+// inPolygon 使用射线法判断坐标是否在多边形内，多边形按平面处理，不应跨越 180° 经线
+func inPolygon(polygon [][]float64, lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		xi, yi := polygon[i][0], polygon[i][1]
+		xj, yj := polygon[j][0], polygon[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,178 @@
+package routing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// mockService 模拟天气服务，返回的 Provider 为 name，并按固定的表做地理编码
+type mockService struct {
+	name string
+}
+
+var places = map[string]*model.Location{
+	"beijing": {Name: "Beijing", Country: "CN", Latitude: 39.9, Longitude: 116.4},
+	"london":  {Name: "London", Country: "GB", Latitude: 51.5, Longitude: -0.1},
+}
+
+func (m *mockService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	return &model.WeatherResponse{Location: model.Location{Name: city}, Provider: m.name}, nil
+}
+
+func (m *mockService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return &model.WeatherResponse{Location: model.Location{Latitude: lat, Longitude: lon}, Provider: m.name}, nil
+}
+
+func (m *mockService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockService) Geocode(city string) (*model.Location, error) {
+	if place, ok := places[strings.ToLower(city)]; ok {
+		return place, nil
+	}
+	return nil, errors.New("未找到城市")
+}
+
+func (m *mockService) ReverseGeocode(lat, lon float64) (*model.Location, error) {
+	for _, place := range places {
+		if place.Latitude == lat && place.Longitude == lon {
+			return place, nil
+		}
+	}
+	return nil, errors.New("没有对应的位置")
+}
+
+// newTestService 创建包含 openweathermap、china、europe 三个提供商的路由，后两者替换为模拟服务
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	base := &config.WeatherConfig{Provider: "openweathermap", APIKey: "key"}
+	s, err := New(&File{
+		Providers: []config.ProviderConfig{
+			{Name: "china", Provider: "openweathermap"},
+			{Name: "europe", Provider: "openweathermap"},
+		},
+		Rules: []RuleConfig{
+			{Name: "mainland", Provider: "china", Countries: []string{"cn"}},
+			{Name: "europe-box", Provider: "europe", BBox: []float64{-10, 35, 30, 60}},
+			{Name: "pacific", Provider: "china", BBox: []float64{170, -20, -170, 20}},
+			{Name: "triangle", Provider: "europe", Polygon: [][]float64{{0, 0}, {10, 0}, {0, 10}}},
+		},
+	}, base, &mockService{name: "openweathermap"}, nil)
+	if err != nil {
+		t.Fatalf("创建路由失败: %v", err)
+	}
+	s.backends["china"] = &mockService{name: "china"}
+	s.backends["europe"] = &mockService{name: "europe"}
+	return s
+}
+
+func TestService_Routes(t *testing.T) {
+	s := newTestService(t)
+
+	tests := []struct {
+		name     string
+		city     string
+		lat, lon float64
+		provider string
+		rule     string
+	}{
+		{"按城市匹配国家", "Beijing", 0, 0, "china", "mainland"},
+		{"按城市匹配范围", "London", 0, 0, "europe", "europe-box"},
+		{"无法解析的城市", "Atlantis", 0, 0, "openweathermap", RuleDefault},
+		{"按坐标反向地理编码匹配国家", "", 39.9, 116.4, "china", "mainland"},
+		{"跨越 180° 经线的范围", "", 0, 179.5, "china", "pacific"},
+		{"多边形内", "", 2, 2, "europe", "triangle"},
+		{"多边形外", "", 8, 8, "openweathermap", RuleDefault},
+	}
+	for _, tt := range tests {
+		var weather *model.WeatherResponse
+		var err error
+		if tt.city != "" {
+			weather, err = s.GetWeatherByCity(tt.city, "metric", "zh_cn")
+		} else {
+			weather, err = s.GetWeatherByCoordinates(tt.lat, tt.lon, "metric", "zh_cn")
+		}
+		if err != nil {
+			t.Fatalf("%s: 获取天气失败: %v", tt.name, err)
+		}
+		if weather.Provider != tt.provider || weather.Routing == nil ||
+			weather.Routing.Provider != tt.provider || weather.Routing.Rule != tt.rule {
+			t.Errorf("%s: 期望使用 %s（规则 %s），实际为 %s %+v", tt.name, tt.provider, tt.rule, weather.Provider, weather.Routing)
+		}
+	}
+}
+
+func TestService_WithProvider(t *testing.T) {
+	s := newTestService(t)
+
+	pinned, err := service.SelectProvider(s, "europe")
+	if err != nil {
+		t.Fatalf("指定数据提供商失败: %v", err)
+	}
+	weather, _ := pinned.GetWeatherByCity("Beijing", "metric", "zh_cn")
+	if weather.Provider != "europe" || weather.Routing.Rule != RuleOverride {
+		t.Errorf("期望跳过路由规则使用 europe，实际为 %s %+v", weather.Provider, weather.Routing)
+	}
+
+	if _, err := service.SelectProvider(s, "unknown"); err == nil {
+		t.Error("期望未知的数据提供商返回错误")
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	base := &config.WeatherConfig{Provider: "openweathermap"}
+	tests := []struct {
+		name string
+		file File
+	}{
+		{"未知的服务类型", File{Providers: []config.ProviderConfig{{Name: "x", Provider: "unknown"}}}},
+		{"重复的提供商", File{Providers: []config.ProviderConfig{{Name: "openweathermap", Provider: "openweathermap"}}}},
+		{"默认提供商不存在", File{Default: "x"}},
+		{"规则引用不存在的提供商", File{Rules: []RuleConfig{{Name: "r", Provider: "x", Countries: []string{"CN"}}}}},
+		{"规则没有条件", File{Rules: []RuleConfig{{Name: "r", Provider: "openweathermap"}}}},
+		{"无效的 bbox", File{Rules: []RuleConfig{{Name: "r", Provider: "openweathermap", BBox: []float64{0, 10, 10}}}}},
+		{"无效的多边形", File{Rules: []RuleConfig{{Name: "r", Provider: "openweathermap", Polygon: [][]float64{{0, 0}, {1, 1}}}}}},
+		{"保留的规则名称", File{Rules: []RuleConfig{{Name: RuleDefault, Provider: "openweathermap", Countries: []string{"CN"}}}}},
+	}
+	for _, tt := range tests {
+		if _, err := New(&tt.file, base, &mockService{}, nil); err == nil {
+			t.Errorf("%s: 期望返回错误", tt.name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	os.Setenv("ROUTING_TEST_KEY", "secret")
+	defer os.Unsetenv("ROUTING_TEST_KEY")
+
+	path := filepath.Join(t.TempDir(), "routing.json")
+	os.WriteFile(path, []byte(`{
+		"providers": [{"name": "china", "provider": "openweathermap", "api_key": "${ROUTING_TEST_KEY}"}],
+		"rules": [{"name": "mainland", "provider": "china", "countries": ["CN"]}],
+		"default": "china"
+	}`), 0o644)
+
+	s, err := Load(path, &config.WeatherConfig{Provider: "openweathermap"}, &mockService{}, nil)
+	if err != nil {
+		t.Fatalf("加载路由配置失败: %v", err)
+	}
+	if s.fallback != "china" || len(s.rules) != 1 {
+		t.Errorf("路由配置不正确: %+v", s)
+	}
+	if owm, ok := s.backends["china"].(*service.OpenWeatherMapService); !ok || owm == nil {
+		t.Errorf("期望 china 为 OpenWeatherMap 服务")
+	}
+}
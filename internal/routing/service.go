@@ -0,0 +1,120 @@
+package routing
+
+import (
+	"fmt"
+	"time"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// GetWeatherByCity 根据城市名称选择数据提供商并获取天气信息
+func (s *Service) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	name, rule := s.choose(city, 0, 0)
+	weather, err := s.backends[name].GetWeatherByCity(city, units, lang)
+	return weatherWithRouting(weather, err, name, rule)
+}
+
+// GetWeatherByCoordinates 根据坐标选择数据提供商并获取天气信息
+func (s *Service) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	name, rule := s.choose("", lat, lon)
+	weather, err := s.backends[name].GetWeatherByCoordinates(lat, lon, units, lang)
+	return weatherWithRouting(weather, err, name, rule)
+}
+
+// GetForecastByCity 根据城市名称选择数据提供商并获取天气预报
+func (s *Service) GetForecastByCity(city, units, lang string) (*model.Forecast, error) {
+	name, rule := s.choose(city, 0, 0)
+	forecast, err := service.FetchForecast(s.backends[name], &model.WeatherRequest{City: city, Units: units, Lang: lang})
+	return forecastWithRouting(forecast, err, name, rule)
+}
+
+// GetForecastByCoordinates 根据坐标选择数据提供商并获取天气预报
+func (s *Service) GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error) {
+	name, rule := s.choose("", lat, lon)
+	forecast, err := service.FetchForecast(s.backends[name], &model.WeatherRequest{Lat: lat, Lon: lon, Units: units, Lang: lang})
+	return forecastWithRouting(forecast, err, name, rule)
+}
+
+// GetHistoricalByCity 根据城市名称选择数据提供商并获取历史天气
+func (s *Service) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	name, rule := s.choose(city, 0, 0)
+	weather, err := s.backends[name].GetHistoricalByCity(city, date, units, lang)
+	return weatherWithRouting(weather, err, name, rule)
+}
+
+// GetHistoricalByCoordinates 根据坐标选择数据提供商并获取历史天气
+func (s *Service) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	name, rule := s.choose("", lat, lon)
+	weather, err := s.backends[name].GetHistoricalByCoordinates(lat, lon, date, units, lang)
+	return weatherWithRouting(weather, err, name, rule)
+}
+
+// WithProvider 返回跳过路由规则、总是使用名为 name 的数据提供商的天气服务
+func (s *Service) WithProvider(name string) (service.WeatherService, error) {
+	backend, ok := s.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的数据提供商: %s", name)
+	}
+	return &pinnedService{name: name, inner: backend}, nil
+}
+
+// pinnedService 总是使用同一个数据提供商，响应中报告的规则为 override
+type pinnedService struct {
+	name  string
+	inner service.WeatherService
+}
+
+// GetWeatherByCity 根据城市名称获取天气信息
+func (s *pinnedService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	weather, err := s.inner.GetWeatherByCity(city, units, lang)
+	return weatherWithRouting(weather, err, s.name, RuleOverride)
+}
+
+// GetWeatherByCoordinates 根据坐标获取天气信息
+func (s *pinnedService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	weather, err := s.inner.GetWeatherByCoordinates(lat, lon, units, lang)
+	return weatherWithRouting(weather, err, s.name, RuleOverride)
+}
+
+// GetForecastByCity 根据城市名称获取天气预报
+func (s *pinnedService) GetForecastByCity(city, units, lang string) (*model.Forecast, error) {
+	forecast, err := service.FetchForecast(s.inner, &model.WeatherRequest{City: city, Units: units, Lang: lang})
+	return forecastWithRouting(forecast, err, s.name, RuleOverride)
+}
+
+// GetForecastByCoordinates 根据坐标获取天气预报
+func (s *pinnedService) GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error) {
+	forecast, err := service.FetchForecast(s.inner, &model.WeatherRequest{Lat: lat, Lon: lon, Units: units, Lang: lang})
+	return forecastWithRouting(forecast, err, s.name, RuleOverride)
+}
+
+// GetHistoricalByCity 根据城市名称获取历史天气
+func (s *pinnedService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	weather, err := s.inner.GetHistoricalByCity(city, date, units, lang)
+	return weatherWithRouting(weather, err, s.name, RuleOverride)
+}
+
+// GetHistoricalByCoordinates 根据坐标获取历史天气
+func (s *pinnedService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	weather, err := s.inner.GetHistoricalByCoordinates(lat, lon, date, units, lang)
+	return weatherWithRouting(weather, err, s.name, RuleOverride)
+}
+
+// weatherWithRouting 在成功的天气结果上记录路由信息
+func weatherWithRouting(weather *model.WeatherResponse, err error, name, rule string) (*model.WeatherResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	weather.Routing = &model.RoutingInfo{Provider: name, Rule: rule}
+	return weather, nil
+}
+
+// forecastWithRouting 在成功的预报结果上记录路由信息
+func forecastWithRouting(forecast *model.Forecast, err error, name, rule string) (*model.Forecast, error) {
+	if err != nil {
+		return nil, err
+	}
+	forecast.Routing = &model.RoutingInfo{Provider: name, Rule: rule}
+	return forecast, nil
+}
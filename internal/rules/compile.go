@@ -0,0 +1,261 @@
+package rules
+
+import (
+	"math"
+	"reflect"
+	"strings"
+)
+
+// evalFunc 编译后的求值函数
+type evalFunc func(ctx *evalContext) (interface{}, error)
+
+// evalContext 求值上下文，items 保存各层 any/all/count 当前遍历的元素
+type evalContext struct {
+	root  reflect.Value
+	items []reflect.Value
+}
+
+// compiler 类型检查并将语法树编译为求值函数
+type compiler struct {
+	root   *typ
+	scopes []*typ
+	paths  map[string]evalFunc
+}
+
+// compile 编译节点，返回静态类型和求值函数
+func (c *compiler) compile(n node) (*typ, evalFunc, error) {
+	switch n := n.(type) {
+	case *numberLit:
+		v := n.value
+		return numberType, func(*evalContext) (interface{}, error) { return v, nil }, nil
+	case *stringLit:
+		v := n.value
+		return stringType, func(*evalContext) (interface{}, error) { return v, nil }, nil
+	case *boolLit:
+		v := n.value
+		return boolType, func(*evalContext) (interface{}, error) { return v, nil }, nil
+	case *pathExpr:
+		return c.compilePath(n)
+	case *unaryExpr:
+		return c.compileUnary(n)
+	case *binaryExpr:
+		return c.compileBinary(n)
+	case *callExpr:
+		return c.compileCall(n)
+	}
+	return nil, nil, errorf(n.position(), "不支持的表达式")
+}
+
+// compilePath 解析字段路径，绝对路径从 Env 开始，相对路径从当前遍历的元素开始
+func (c *compiler) compilePath(n *pathExpr) (*typ, evalFunc, error) {
+	t := c.root
+	depth := -1
+	if n.relative {
+		if len(c.scopes) == 0 {
+			return nil, nil, errorf(n.pos, "以 \".\" 开头的路径只能在 any、all、count 的条件中使用")
+		}
+		depth = len(c.scopes) - 1
+		t = c.scopes[depth]
+	}
+
+	indexes := make([][]int, 0, len(n.segments))
+	for i, segment := range n.segments {
+		if t.kind != kindObject {
+			return nil, nil, errorf(n.pos, "%s 的类型为 %s，不能访问字段 %s", joinPath(n, i), t, segment)
+		}
+		field, ok := fieldByJSON(t.rt, segment)
+		var ft *typ
+		if ok {
+			ft, ok = typeOf(field.Type)
+		}
+		if !ok {
+			return nil, nil, errorf(n.pos, "未知字段 %s，可用字段: %s", joinPath(n, i+1), strings.Join(fieldNames(t.rt), "、"))
+		}
+		indexes = append(indexes, field.Index)
+		t = ft
+	}
+
+	result := t
+	eval := func(ctx *evalContext) (interface{}, error) {
+		v := ctx.root
+		if depth >= 0 {
+			v = ctx.items[depth]
+		}
+		for _, index := range indexes {
+			v = indirect(v).FieldByIndex(index)
+		}
+		return toValue(v, result), nil
+	}
+
+	if !n.relative && result.scalar() {
+		c.paths[joinPath(n, len(n.segments))] = eval
+	}
+	return result, eval, nil
+}
+
+// compileUnary 编译一元运算
+func (c *compiler) compileUnary(n *unaryExpr) (*typ, evalFunc, error) {
+	t, x, err := c.compile(n.x)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if n.op == "!" {
+		if t.kind != kindBool {
+			return nil, nil, errorf(n.pos, "\"!\" 需要 bool 类型，实际为 %s", t)
+		}
+		return boolType, func(ctx *evalContext) (interface{}, error) {
+			v, err := x(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return !v.(bool), nil
+		}, nil
+	}
+
+	if t.kind != kindNumber {
+		return nil, nil, errorf(n.pos, "\"-\" 需要 number 类型，实际为 %s", t)
+	}
+	return numberType, func(ctx *evalContext) (interface{}, error) {
+		v, err := x(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return -v.(float64), nil
+	}, nil
+}
+
+// compileBinary 编译二元运算，&& 和 || 短路求值
+func (c *compiler) compileBinary(n *binaryExpr) (*typ, evalFunc, error) {
+	lt, x, err := c.compile(n.x)
+	if err != nil {
+		return nil, nil, err
+	}
+	rt, y, err := c.compile(n.y)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mismatch := func() error {
+		return errorf(n.pos, "\"%s\" 不能用于 %s 和 %s", n.op, lt, rt)
+	}
+
+	switch n.op {
+	case "&&", "||":
+		if lt.kind != kindBool || rt.kind != kindBool {
+			return nil, nil, mismatch()
+		}
+		and := n.op == "&&"
+		return boolType, func(ctx *evalContext) (interface{}, error) {
+			l, err := x(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if l.(bool) != and {
+				return l, nil
+			}
+			return y(ctx)
+		}, nil
+
+	case "==", "!=":
+		if !lt.scalar() || lt.kind != rt.kind {
+			return nil, nil, mismatch()
+		}
+		equal := n.op == "=="
+		return boolType, binary(x, y, func(l, r interface{}) (interface{}, error) {
+			return (l == r) == equal, nil
+		}), nil
+
+	case "<", "<=", ">", ">=":
+		if lt.kind != rt.kind || (lt.kind != kindNumber && lt.kind != kindString) {
+			return nil, nil, mismatch()
+		}
+		op := n.op
+		return boolType, binary(x, y, func(l, r interface{}) (interface{}, error) {
+			cmp, ok := compare(l, r)
+			if !ok {
+				return false, nil
+			}
+			switch op {
+			case "<":
+				return cmp < 0, nil
+			case "<=":
+				return cmp <= 0, nil
+			case ">":
+				return cmp > 0, nil
+			}
+			return cmp >= 0, nil
+		}), nil
+
+	case "+":
+		if lt.kind == kindString && rt.kind == kindString {
+			return stringType, binary(x, y, func(l, r interface{}) (interface{}, error) {
+				return l.(string) + r.(string), nil
+			}), nil
+		}
+	}
+
+	if lt.kind != kindNumber || rt.kind != kindNumber {
+		return nil, nil, mismatch()
+	}
+	op, pos := n.op, n.pos
+	return numberType, binary(x, y, func(l, r interface{}) (interface{}, error) {
+		a, b := l.(float64), r.(float64)
+		switch op {
+		case "+":
+			return a + b, nil
+		case "-":
+			return a - b, nil
+		case "*":
+			return a * b, nil
+		}
+		if b == 0 {
+			return nil, errorf(pos, "除数为 0")
+		}
+		if op == "/" {
+			return a / b, nil
+		}
+		return math.Mod(a, b), nil
+	}), nil
+}
+
+// binary 先对两侧求值再调用 fn
+func binary(x, y evalFunc, fn func(l, r interface{}) (interface{}, error)) evalFunc {
+	return func(ctx *evalContext) (interface{}, error) {
+		l, err := x(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r, err := y(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return fn(l, r)
+	}
+}
+
+// compare 比较两个同类型的 number 或 string，任意一侧为 NaN 时无法比较
+func compare(l, r interface{}) (int, bool) {
+	if a, ok := l.(float64); ok {
+		b := r.(float64)
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		case a == b:
+			return 0, true
+		}
+		return 0, false
+	}
+	return strings.Compare(l.(string), r.(string)), true
+}
+
+// joinPath 返回路径前 n 段的文本
+func joinPath(p *pathExpr, n int) string {
+	path := strings.Join(p.segments[:n], ".")
+	if p.relative {
+		return "." + path
+	}
+	return path
+}
@@ -0,0 +1,190 @@
+package rules
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// funcNames 支持的函数，用于错误提示
+var funcNames = []string{"any", "all", "count", "len", "abs", "min", "max", "contains", "lower"}
+
+// compileCall 编译函数调用
+func (c *compiler) compileCall(n *callExpr) (*typ, evalFunc, error) {
+	switch n.name {
+	case "any", "all", "count":
+		return c.compileQuantifier(n)
+	}
+
+	types := make([]*typ, len(n.args))
+	args := make([]evalFunc, len(n.args))
+	for i, arg := range n.args {
+		t, f, err := c.compile(arg)
+		if err != nil {
+			return nil, nil, err
+		}
+		types[i], args[i] = t, f
+	}
+
+	switch n.name {
+	case "len":
+		if len(args) != 1 || (types[0].kind != kindList && types[0].kind != kindString) {
+			return nil, nil, errorf(n.pos, "len 需要 1 个 list 或 string 参数")
+		}
+		return numberType, call(args, func(v []interface{}) interface{} {
+			if s, ok := v[0].(string); ok {
+				return float64(utf8.RuneCountInString(s))
+			}
+			return float64(indirect(v[0].(reflect.Value)).Len())
+		}), nil
+
+	case "abs":
+		if err := checkArgs(n, types, 1, 1, kindNumber); err != nil {
+			return nil, nil, err
+		}
+		return numberType, call(args, func(v []interface{}) interface{} {
+			return math.Abs(v[0].(float64))
+		}), nil
+
+	case "min", "max":
+		if err := checkArgs(n, types, 2, -1, kindNumber); err != nil {
+			return nil, nil, err
+		}
+		pick := math.Min
+		if n.name == "max" {
+			pick = math.Max
+		}
+		return numberType, call(args, func(v []interface{}) interface{} {
+			result := v[0].(float64)
+			for _, x := range v[1:] {
+				result = pick(result, x.(float64))
+			}
+			return result
+		}), nil
+
+	case "contains":
+		if err := checkArgs(n, types, 2, 2, kindString); err != nil {
+			return nil, nil, err
+		}
+		return boolType, call(args, func(v []interface{}) interface{} {
+			return strings.Contains(v[0].(string), v[1].(string))
+		}), nil
+
+	case "lower":
+		if err := checkArgs(n, types, 1, 1, kindString); err != nil {
+			return nil, nil, err
+		}
+		return stringType, call(args, func(v []interface{}) interface{} {
+			return strings.ToLower(v[0].(string))
+		}), nil
+	}
+
+	names := append([]string(nil), funcNames...)
+	sort.Strings(names)
+	return nil, nil, errorf(n.pos, "未知函数 %s，可用函数: %s", n.name, strings.Join(names, "、"))
+}
+
+// compileQuantifier 编译 any/all/count：第二个参数对列表中的每个元素求值，
+// 其中以 "." 开头的路径指向当前元素
+func (c *compiler) compileQuantifier(n *callExpr) (*typ, evalFunc, error) {
+	if len(n.args) != 2 {
+		return nil, nil, errorf(n.pos, "%s 需要 2 个参数：列表和条件", n.name)
+	}
+
+	lt, list, err := c.compile(n.args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	if lt.kind != kindList {
+		return nil, nil, errorf(n.args[0].position(), "%s 的第一个参数必须是 list，实际为 %s", n.name, lt)
+	}
+
+	depth := len(c.scopes)
+	c.scopes = append(c.scopes, lt.elem)
+	pt, pred, err := c.compile(n.args[1])
+	c.scopes = c.scopes[:depth]
+	if err != nil {
+		return nil, nil, err
+	}
+	if pt.kind != kindBool {
+		return nil, nil, errorf(n.args[1].position(), "%s 的条件必须是 bool，实际为 %s", n.name, pt)
+	}
+
+	name := n.name
+	resultType := boolType
+	if name == "count" {
+		resultType = numberType
+	}
+
+	return resultType, func(ctx *evalContext) (interface{}, error) {
+		v, err := list(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items := indirect(v.(reflect.Value))
+
+		ctx.items = append(ctx.items[:depth], reflect.Value{})
+		defer func() { ctx.items = ctx.items[:depth] }()
+
+		count := 0
+		for i := 0; i < items.Len(); i++ {
+			ctx.items[depth] = items.Index(i)
+			matched, err := pred(ctx)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case matched.(bool) && name == "any":
+				return true, nil
+			case !matched.(bool) && name == "all":
+				return false, nil
+			case matched.(bool):
+				count++
+			}
+		}
+
+		switch name {
+		case "any":
+			return false, nil
+		case "all":
+			return true, nil
+		}
+		return float64(count), nil
+	}, nil
+}
+
+// checkArgs 检查参数数量和类型，max 为 -1 表示不限
+func checkArgs(n *callExpr, types []*typ, min, max int, k kind) error {
+	if len(types) < min || (max >= 0 && len(types) > max) {
+		switch {
+		case max < 0:
+			return errorf(n.pos, "%s 至少需要 %d 个参数", n.name, min)
+		case min == max:
+			return errorf(n.pos, "%s 需要 %d 个参数", n.name, min)
+		}
+		return errorf(n.pos, "%s 需要 %d 到 %d 个参数", n.name, min, max)
+	}
+	for i, t := range types {
+		if t.kind != k {
+			return errorf(n.args[i].position(), "%s 的第 %d 个参数必须是 %s，实际为 %s", n.name, i+1, (&typ{kind: k}), t)
+		}
+	}
+	return nil
+}
+
+// call 对参数求值后调用 fn
+func call(args []evalFunc, fn func(v []interface{}) interface{}) evalFunc {
+	return func(ctx *evalContext) (interface{}, error) {
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			v, err := arg(ctx)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return fn(values), nil
+	}
+}
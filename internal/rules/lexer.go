@@ -0,0 +1,141 @@
+package rules
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+// token 词法单元，pos 为以字符计的位置
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// twoCharOps 双字符运算符
+var twoCharOps = []string{"&&", "||", "==", "!=", "<=", ">="}
+
+// lex 将表达式切分为词法单元
+func lex(src string) ([]token, error) {
+	runes := []rune(src)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		// 路径中 "." 之后的字段名允许以数字开头，如 rain.1h
+		case isNameRune(r) && len(tokens) > 0 && tokens[len(tokens)-1].text == "." && tokens[len(tokens)-1].kind == tokOp && tokens[len(tokens)-1].pos == i-1:
+			start := i
+			for i < len(runes) && isNameRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), pos: start})
+
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			if i+1 < len(runes) && runes[i] == '.' && unicode.IsDigit(runes[i+1]) {
+				i++
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			text := string(runes[start:i])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, errorf(start, "无效的数字 %s", text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, num: num, pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && isNameRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), pos: start})
+
+		case r == '"' || r == '\'':
+			text, next, err := lexString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: text, pos: i})
+			i = next
+
+		default:
+			op := ""
+			if i+1 < len(runes) {
+				for _, candidate := range twoCharOps {
+					if string(runes[i:i+2]) == candidate {
+						op = candidate
+						break
+					}
+				}
+			}
+			if op == "" && strings.ContainsRune("<>!+-*/%(),.", r) {
+				op = string(r)
+			}
+			if op == "" {
+				return nil, errorf(i, "无法识别的字符 %q", r)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len([]rune(op))
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+// lexString 读取以单引号或双引号包围的字符串，返回内容和结束位置
+func lexString(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == quote:
+			return b.String(), i + 1, nil
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return "", 0, errorf(start, "字符串没有结束")
+			}
+			i++
+			switch runes[i] {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case '\\', '"', '\'':
+				b.WriteRune(runes[i])
+			default:
+				return "", 0, errorf(i-1, "无效的转义字符 \\%c", runes[i])
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", 0, errorf(start, "字符串没有结束")
+}
+
+// isNameRune 判断字符是否可以出现在标识符中
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
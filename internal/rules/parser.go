@@ -0,0 +1,259 @@
+package rules
+
+import "fmt"
+
+// maxDepth 表达式的最大嵌套深度
+const maxDepth = 64
+
+// node 语法树节点
+type node interface {
+	position() int
+}
+
+type numberLit struct {
+	pos   int
+	value float64
+}
+
+type stringLit struct {
+	pos   int
+	value string
+}
+
+type boolLit struct {
+	pos   int
+	value bool
+}
+
+// pathExpr 字段路径，relative 为 true 时相对于 any/all/count 当前遍历的元素
+type pathExpr struct {
+	pos      int
+	relative bool
+	segments []string
+}
+
+type unaryExpr struct {
+	pos int
+	op  string
+	x   node
+}
+
+type binaryExpr struct {
+	pos  int
+	op   string
+	x, y node
+}
+
+type callExpr struct {
+	pos  int
+	name string
+	args []node
+}
+
+func (n *numberLit) position() int  { return n.pos }
+func (n *stringLit) position() int  { return n.pos }
+func (n *boolLit) position() int    { return n.pos }
+func (n *pathExpr) position() int   { return n.pos }
+func (n *unaryExpr) position() int  { return n.pos }
+func (n *binaryExpr) position() int { return n.pos }
+func (n *callExpr) position() int   { return n.pos }
+
+// precedence 二元运算符优先级，数值越大结合越紧
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+// parser 递归下降语法分析器
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+// parse 将表达式解析为语法树
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, errorf(tok.pos, "多余的内容 %s", describeToken(tok))
+	}
+	return expr, nil
+}
+
+// parseExpr 按优先级爬升解析二元表达式
+func (p *parser) parseExpr(minPrec int) (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, errorf(p.peek().pos, "表达式嵌套过深")
+	}
+
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		prec, ok := precedence[tok.text]
+		if tok.kind != tokOp || !ok || prec <= minPrec {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseExpr(prec)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{pos: tok.pos, op: tok.text, x: left, y: right}
+	}
+}
+
+// parseUnary 解析一元运算
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok.kind == tokOp && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > maxDepth {
+			return nil, errorf(tok.pos, "表达式嵌套过深")
+		}
+
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{pos: tok.pos, op: tok.text, x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary 解析字面量、路径、函数调用和括号表达式
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return &numberLit{pos: tok.pos, value: tok.num}, nil
+
+	case tokString:
+		return &stringLit{pos: tok.pos, value: tok.text}, nil
+
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &boolLit{pos: tok.pos, value: tok.text == "true"}, nil
+		}
+		if p.peek().text == "(" && p.peek().kind == tokOp {
+			return p.parseCall(tok)
+		}
+		path := &pathExpr{pos: tok.pos, segments: []string{tok.text}}
+		return path, p.parseSegments(path)
+
+	case tokOp:
+		switch tok.text {
+		case "(":
+			expr, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return expr, nil
+		case ".":
+			path := &pathExpr{pos: tok.pos, relative: true}
+			if next := p.peek(); next.kind == tokIdent {
+				p.next()
+				path.segments = append(path.segments, next.text)
+			}
+			return path, p.parseSegments(path)
+		}
+	}
+
+	return nil, errorf(tok.pos, "意外的 %s", describeToken(tok))
+}
+
+// parseSegments 解析路径中后续的 ".字段"
+func (p *parser) parseSegments(path *pathExpr) error {
+	for p.peek().kind == tokOp && p.peek().text == "." {
+		p.next()
+		tok := p.next()
+		if tok.kind != tokIdent {
+			return errorf(tok.pos, "\".\" 之后需要字段名，实际为 %s", describeToken(tok))
+		}
+		path.segments = append(path.segments, tok.text)
+	}
+	return nil
+}
+
+// parseCall 解析函数调用的参数列表
+func (p *parser) parseCall(name token) (node, error) {
+	p.next() // (
+	call := &callExpr{pos: name.pos, name: name.text}
+	if p.peek().kind == tokOp && p.peek().text == ")" {
+		p.next()
+		return call, nil
+	}
+
+	for {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+
+		tok := p.next()
+		if tok.kind == tokOp && tok.text == ")" {
+			return call, nil
+		}
+		if tok.kind != tokOp || tok.text != "," {
+			return nil, errorf(tok.pos, "函数参数之间需要 \",\"，实际为 %s", describeToken(tok))
+		}
+	}
+}
+
+// expect 读取指定的运算符
+func (p *parser) expect(op string) error {
+	tok := p.next()
+	if tok.kind != tokOp || tok.text != op {
+		return errorf(tok.pos, "需要 %q，实际为 %s", op, describeToken(tok))
+	}
+	return nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// describeToken 返回词法单元的可读描述，用于错误信息
+func describeToken(tok token) string {
+	switch tok.kind {
+	case tokEOF:
+		return "表达式结尾"
+	case tokString:
+		return fmt.Sprintf("字符串 %q", tok.text)
+	}
+	return fmt.Sprintf("%q", tok.text)
+}
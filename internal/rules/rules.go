@@ -0,0 +1,129 @@
+// Package rules 实现描述复合天气条件的小型表达式语言，例如
+//
+//	current.temperature < 3 && any(current.weather, .main == "Rain") && current.wind.speed > 8
+//
+// 表达式在编译时完成类型检查，之后可以对任意天气数据反复求值。
+// 字段路径与 JSON 字段名一致，可以访问 location、current、timestamp、provider 以及 derived 衍生指标；
+// any、all、count 的第二个参数对列表中的每个元素求值，其中以 "." 开头的路径指向当前元素
+package rules
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+
+	"gin-weather/internal/derived"
+	"gin-weather/internal/model"
+)
+
+// MaxLength 表达式的最大长度（字符）
+const MaxLength = 2048
+
+// Error 表达式编译或求值错误，Pos 为从 0 开始的字符位置
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("第 %d 个字符处: %s", e.Pos+1, e.Msg)
+}
+
+// errorf 构造带位置的错误
+func errorf(pos int, format string, args ...interface{}) error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Env 表达式可以访问的数据
+type Env struct {
+	Location  model.Location `json:"location"`
+	Current   model.Current  `json:"current"`
+	Timestamp int64          `json:"timestamp"`
+	Provider  string         `json:"provider"`
+	Derived   model.Derived  `json:"derived"`
+}
+
+// NewEnv 根据天气数据构造求值环境，同时计算衍生指标
+func NewEnv(resp *model.WeatherResponse, units string) *Env {
+	return &Env{
+		Location:  resp.Location,
+		Current:   resp.Current,
+		Timestamp: resp.Timestamp,
+		Provider:  resp.Provider,
+		Derived:   *derived.Compute(&resp.Current, units),
+	}
+}
+
+// envType 求值环境的类型
+var envType = &typ{kind: kindObject, rt: reflect.TypeOf(Env{})}
+
+// Program 编译后的表达式，可以并发求值
+type Program struct {
+	source string
+	eval   evalFunc
+	paths  map[string]evalFunc
+}
+
+// Compile 解析表达式并完成类型检查，表达式的结果必须是 bool
+func Compile(source string) (*Program, error) {
+	if utf8.RuneCountInString(source) > MaxLength {
+		return nil, fmt.Errorf("表达式长度不能超过 %d 个字符", MaxLength)
+	}
+
+	tree, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compiler{root: envType, paths: make(map[string]evalFunc)}
+	t, eval, err := c.compile(tree)
+	if err != nil {
+		return nil, err
+	}
+	if t.kind != kindBool {
+		return nil, errorf(0, "表达式的结果必须是 bool，实际为 %s", t)
+	}
+
+	return &Program{source: source, eval: eval, paths: c.paths}, nil
+}
+
+// String 返回表达式原文
+func (p *Program) String() string {
+	return p.source
+}
+
+// Eval 对环境求值
+func (p *Program) Eval(env *Env) (bool, error) {
+	result, err := p.eval(&evalContext{root: reflect.ValueOf(env)})
+	if err != nil {
+		return false, err
+	}
+	return result.(bool), nil
+}
+
+// Paths 返回表达式引用的标量字段路径（不含 any/all/count 中的相对路径）
+func (p *Program) Paths() []string {
+	paths := make([]string, 0, len(p.paths))
+	for path := range p.paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Values 返回表达式引用的字段在环境中的值，便于排查表达式结果；
+// JSON 无法表示的 NaN（如湿度为 0 时的露点）返回为 nil
+func (p *Program) Values(env *Env) map[string]interface{} {
+	ctx := &evalContext{root: reflect.ValueOf(env)}
+	values := make(map[string]interface{}, len(p.paths))
+	for path, eval := range p.paths {
+		value, _ := eval(ctx)
+		if f, ok := value.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			value = nil
+		}
+		values[path] = value
+	}
+	return values
+}
@@ -0,0 +1,153 @@
+package rules
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gin-weather/internal/model"
+)
+
+func testEnv() *Env {
+	return NewEnv(&model.WeatherResponse{
+		Location: model.Location{Name: "Shanghai", Country: "CN"},
+		Current: model.Current{
+			Temperature: 2.5,
+			Humidity:    90,
+			Weather: []model.Weather{
+				{ID: 500, Main: "Rain", Description: "小雨"},
+				{ID: 701, Main: "Mist", Description: "薄雾"},
+			},
+			Wind: model.Wind{Speed: 9, Gust: 16},
+			Rain: &model.Rain{OneHour: 1.2},
+		},
+		Provider: "openweathermap",
+	}, "metric")
+}
+
+func TestCompileAndEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`current.temperature < 3 && any(current.weather, .main == "Rain") && current.wind.speed > 8`, true},
+		{`current.temperature < 3 && current.wind.speed > 10`, false},
+		{`all(current.weather, .id >= 500)`, true},
+		{`all(current.weather, .main == "Rain")`, false},
+		{`count(current.weather, .id < 800) == 2`, true},
+		{`len(current.weather) == 2 && len(location.name) == 8`, true},
+		{`current.rain.1h > 1 && current.snow.1h == 0`, true},
+		{`!(location.country != "CN") || false`, true},
+		{`derived.beaufort == 5 && derived.dew_point < current.temperature`, true},
+		{`abs(-current.temperature) == 2.5 && max(1, current.wind.gust, 3) == 16 && min(4, 2) == 2`, true},
+		{`contains(lower(provider), "weather") && location.name + "!" == "Shanghai!"`, true},
+		{`(1 + 2) * 3 == 9 && 7 % 4 == 3 && 10 / 4 == 2.5`, true},
+		{`any(current.weather, contains(.description, "雾"))`, true},
+	}
+
+	env := testEnv()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			program, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("编译失败: %v", err)
+			}
+			got, err := program.Eval(env)
+			if err != nil {
+				t.Fatalf("求值失败: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("期望结果为 %v，实际为 %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{`current.temperature`, 0, "必须是 bool"},
+		{`current.temp < 3`, 0, "未知字段 current.temp"},
+		{`current.temperature < "3"`, 20, "不能用于 number 和 string"},
+		{`current.weather == 1`, 16, "不能用于 list 和 number"},
+		{`.main == "Rain"`, 0, "只能在 any、all、count 的条件中使用"},
+		{`any(current.wind, .speed > 1)`, 4, "第一个参数必须是 list"},
+		{`any(current.weather, .main)`, 21, "条件必须是 bool"},
+		{`any(current.weather, .mian == "Rain")`, 21, "未知字段 .mian"},
+		{`avg(1, 2) > 1`, 0, "未知函数 avg"},
+		{`min(1) > 0`, 0, "至少需要 2 个参数"},
+		{`current.temperature < 3 &&`, 26, "意外的 表达式结尾"},
+		{`(current.temperature < 3`, 24, "需要 \")\""},
+		{`location.name == "北京`, 17, "字符串没有结束"},
+		{`current.temperature # 3`, 20, "无法识别的字符"},
+		{`current.temperature.value > 1`, 0, "不能访问字段 value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Compile(tt.expr)
+			var compileErr *Error
+			if !errors.As(err, &compileErr) {
+				t.Fatalf("期望返回 *Error，实际为 %v", err)
+			}
+			if compileErr.Pos != tt.pos || !strings.Contains(compileErr.Msg, tt.msg) {
+				t.Errorf("期望在位置 %d 报错 %q，实际为 %d: %s", tt.pos, tt.msg, compileErr.Pos, compileErr.Msg)
+			}
+		})
+	}
+}
+
+func TestEvalRuntimeErrors(t *testing.T) {
+	program, err := Compile(`current.temperature / current.clouds.all > 1`)
+	if err != nil {
+		t.Fatalf("编译失败: %v", err)
+	}
+	if _, err := program.Eval(testEnv()); err == nil || !strings.Contains(err.Error(), "除数为 0") {
+		t.Errorf("期望返回除数为 0 的错误，实际为 %v", err)
+	}
+}
+
+func TestNaNComparisons(t *testing.T) {
+	env := testEnv()
+	env.Derived.DewPoint = nil
+
+	for _, expr := range []string{`derived.dew_point > 0`, `derived.dew_point <= 0`, `derived.dew_point == derived.dew_point`} {
+		program, err := Compile(expr)
+		if err != nil {
+			t.Fatalf("编译失败: %v", err)
+		}
+		if got, _ := program.Eval(env); got {
+			t.Errorf("期望 %s 为 false", expr)
+		}
+		if v, ok := program.Values(env)["derived.dew_point"]; !ok || v != nil {
+			t.Errorf("期望 NaN 字段值为 nil，实际为 %v", v)
+		}
+	}
+}
+
+func TestProgramValues(t *testing.T) {
+	program, err := Compile(`current.temperature < 3 && any(current.weather, .main == "Rain") && location.name == "Shanghai"`)
+	if err != nil {
+		t.Fatalf("编译失败: %v", err)
+	}
+
+	if paths := strings.Join(program.Paths(), ","); paths != "current.temperature,location.name" {
+		t.Errorf("期望引用的字段为 current.temperature,location.name，实际为 %s", paths)
+	}
+	values := program.Values(testEnv())
+	if values["current.temperature"] != 2.5 || values["location.name"] != "Shanghai" {
+		t.Errorf("字段值不正确: %v", values)
+	}
+}
+
+func TestCompileLimits(t *testing.T) {
+	if _, err := Compile(strings.Repeat("!", MaxLength) + "true"); err == nil {
+		t.Error("期望超长表达式返回错误")
+	}
+	if _, err := Compile(strings.Repeat("(", 100) + "true" + strings.Repeat(")", 100)); err == nil {
+		t.Error("期望嵌套过深的表达式返回错误")
+	}
+}
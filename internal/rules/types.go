@@ -0,0 +1,168 @@
+package rules
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// kind 表达式的值类型
+type kind int
+
+const (
+	kindNumber kind = iota
+	kindString
+	kindBool
+	kindList
+	kindObject
+)
+
+// typ 表达式的静态类型，列表记录元素类型，对象记录对应的结构体类型
+type typ struct {
+	kind kind
+	elem *typ
+	rt   reflect.Type
+}
+
+var (
+	numberType = &typ{kind: kindNumber}
+	stringType = &typ{kind: kindString}
+	boolType   = &typ{kind: kindBool}
+)
+
+func (t *typ) String() string {
+	switch t.kind {
+	case kindNumber:
+		return "number"
+	case kindString:
+		return "string"
+	case kindBool:
+		return "bool"
+	case kindList:
+		return "list"
+	}
+	return "object"
+}
+
+// scalar 判断是否为可以比较的标量类型
+func (t *typ) scalar() bool {
+	return t.kind == kindNumber || t.kind == kindString || t.kind == kindBool
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// typeOf 将 Go 类型映射为表达式类型，time.Time 视为 Unix 秒数
+func typeOf(rt reflect.Type) (*typ, bool) {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == timeType {
+		return numberType, true
+	}
+
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return numberType, true
+	case reflect.String:
+		return stringType, true
+	case reflect.Bool:
+		return boolType, true
+	case reflect.Struct:
+		return &typ{kind: kindObject, rt: rt}, true
+	case reflect.Slice:
+		elem, ok := typeOf(rt.Elem())
+		if !ok {
+			return nil, false
+		}
+		return &typ{kind: kindList, elem: elem, rt: rt}, true
+	}
+	return nil, false
+}
+
+// fieldByJSON 按 JSON 字段名查找结构体字段
+func fieldByJSON(rt reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		if tag == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// fieldNames 返回结构体的全部 JSON 字段名，用于错误提示
+func fieldNames(rt reflect.Type) []string {
+	var names []string
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if _, ok := typeOf(field.Type); !ok {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		names = append(names, tag)
+	}
+	return names
+}
+
+// nanValue 缺失的数值，与任何值比较都为 false
+var nanValue = reflect.ValueOf(math.NaN())
+
+// indirect 解引用指针，nil 指针视为对应类型的零值，如未降雨时的 rain.1h 为 0；
+// 但指向浮点数的 nil 指针表示无法计算的数值（如湿度为 0 时的露点），视为 NaN
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if elem := v.Type().Elem(); elem.Kind() == reflect.Float64 {
+				return nanValue
+			}
+			return reflect.Zero(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// toValue 将反射值转换为运行时值：标量转换为 float64、string、bool，列表和对象保留反射值
+func toValue(v reflect.Value, t *typ) interface{} {
+	v = indirect(v)
+	switch t.kind {
+	case kindNumber:
+		if v.Type() == timeType {
+			return float64(v.Interface().(time.Time).Unix())
+		}
+		switch {
+		case v.CanInt():
+			return float64(v.Int())
+		case v.CanUint():
+			return float64(v.Uint())
+		}
+		return v.Float()
+	case kindString:
+		return v.String()
+	case kindBool:
+		return v.Bool()
+	}
+	return v
+}
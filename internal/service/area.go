@@ -0,0 +1,13 @@
+package service
+
+import "gin-weather/internal/model"
+
+// AreaSearcher 可选的接口，由支持一次查询一个区域内多个城市天气的天气服务实现
+type AreaSearcher interface {
+	// FindNearby 返回距离 (lat, lon) 最近的至多 limit 个城市的天气，按距离由近到远排列
+	FindNearby(lat, lon float64, limit int, units, lang string) ([]model.WeatherResponse, error)
+
+	// FindInBBox 返回经纬度范围 [最小经度, 最小纬度, 最大经度, 最大纬度] 内的城市天气，
+	// zoom 为地图缩放级别，越大包含的小城市越多
+	FindInBBox(bbox [4]float64, zoom int, units, lang string) ([]model.WeatherResponse, error)
+}
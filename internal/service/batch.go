@@ -0,0 +1,57 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// FetchResult 批量查询中单个位置的结果
+type FetchResult struct {
+	Weather *model.WeatherResponse
+	Err     error
+}
+
+// Fetch 根据请求类型调用相应的服务方法：提供城市名称时按城市查询，否则按坐标查询
+func Fetch(weatherService WeatherService, req *model.WeatherRequest) (*model.WeatherResponse, error) {
+	if req.City != "" {
+		return weatherService.GetWeatherByCity(req.City, req.Units, req.Lang)
+	}
+	return weatherService.GetWeatherByCoordinates(req.Lat, req.Lon, req.Units, req.Lang)
+}
+
+// FetchHistorical 与 Fetch 类似地按城市或坐标获取 date 当天的历史天气
+func FetchHistorical(weatherService WeatherService, req *model.WeatherRequest, date time.Time) (*model.WeatherResponse, error) {
+	if req.City != "" {
+		return weatherService.GetHistoricalByCity(req.City, date, req.Units, req.Lang)
+	}
+	return weatherService.GetHistoricalByCoordinates(req.Lat, req.Lon, date, req.Units, req.Lang)
+}
+
+// FetchAll 以不超过 concurrency 的并发度批量获取天气，结果顺序与请求顺序一致，
+// 单个位置失败只记录在对应结果中，不影响其他位置
+func FetchAll(weatherService WeatherService, reqs []model.WeatherRequest, concurrency int) []FetchResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]FetchResult, len(reqs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := range reqs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			weather, err := Fetch(weatherService, &reqs[i])
+			results[i] = FetchResult{Weather: weather, Err: err}
+		}(i)
+	}
+
+	wg.Wait()
+	return results
+}
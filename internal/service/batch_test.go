@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// countingService 记录最大并发数的模拟天气服务
+type countingService struct {
+	active  int32
+	maxSeen int32
+}
+
+func (s *countingService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	n := atomic.AddInt32(&s.active, 1)
+	defer atomic.AddInt32(&s.active, -1)
+	for {
+		seen := atomic.LoadInt32(&s.maxSeen)
+		if n <= seen || atomic.CompareAndSwapInt32(&s.maxSeen, seen, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if city == "bad" {
+		return nil, fmt.Errorf("unknown city")
+	}
+	return &model.WeatherResponse{Location: model.Location{Name: city}}, nil
+}
+
+func (s *countingService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return s.GetWeatherByCity(fmt.Sprintf("%.1f,%.1f", lat, lon), units, lang)
+}
+
+func (s *countingService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.GetWeatherByCity(city, units, lang)
+}
+
+func (s *countingService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.GetWeatherByCoordinates(lat, lon, units, lang)
+}
+
+func TestFetchAll(t *testing.T) {
+	svc := &countingService{}
+	reqs := make([]model.WeatherRequest, 20)
+	for i := range reqs {
+		reqs[i].City = fmt.Sprintf("city-%d", i)
+	}
+	reqs[7].City = "bad"
+
+	results := FetchAll(svc, reqs, 3)
+
+	if svc.maxSeen > 3 {
+		t.Errorf("期望并发数不超过 3，实际为 %d", svc.maxSeen)
+	}
+	for i, result := range results {
+		if i == 7 {
+			if result.Err == nil {
+				t.Error("期望第 7 个结果返回错误")
+			}
+			continue
+		}
+		if result.Err != nil || result.Weather.Location.Name != reqs[i].City {
+			t.Errorf("结果 %d 顺序或内容不正确: %+v", i, result)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+
+	"gin-weather/internal/model"
+)
+
+// ErrForecastUnsupported 天气服务不提供预报
+var ErrForecastUnsupported = errors.New("当前天气服务不支持天气预报")
+
+// ForecastService 可选的天气预报接口，由支持预报的天气服务实现
+type ForecastService interface {
+	// GetForecastByCity 根据城市名称获取天气预报
+	GetForecastByCity(city, units, lang string) (*model.Forecast, error)
+
+	// GetForecastByCoordinates 根据坐标获取天气预报
+	GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error)
+}
+
+// FetchForecast 与 Fetch 类似地按城市或坐标获取天气预报，服务未实现 ForecastService 时返回 ErrForecastUnsupported
+func FetchForecast(weatherService WeatherService, req *model.WeatherRequest) (*model.Forecast, error) {
+	forecaster, ok := weatherService.(ForecastService)
+	if !ok {
+		return nil, ErrForecastUnsupported
+	}
+	if req.City != "" {
+		return forecaster.GetForecastByCity(req.City, req.Units, req.Lang)
+	}
+	return forecaster.GetForecastByCoordinates(req.Lat, req.Lon, req.Units, req.Lang)
+}
@@ -0,0 +1,12 @@
+package service
+
+import "gin-weather/internal/model"
+
+// Geocoder 可选的地理编码接口，由支持地理编码的天气服务实现，返回的位置只包含名称、国家代码和坐标
+type Geocoder interface {
+	// Geocode 把城市名称解析为位置，没有匹配时返回错误
+	Geocode(city string) (*model.Location, error)
+
+	// ReverseGeocode 查询坐标所在的位置，没有匹配时返回错误
+	ReverseGeocode(lat, lon float64) (*model.Location, error)
+}
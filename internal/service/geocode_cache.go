@@ -0,0 +1,61 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+)
+
+// maxGeocodes 地理编码缓存的条目上限。缓存键来自客户端提供的城市名称和坐标，必须有上限
+const maxGeocodes = 4096
+
+// geocodeCache 按最近使用淘汰的地理编码结果缓存，可以并发使用
+type geocodeCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // 最近使用的在前
+	items map[string]*list.Element
+}
+
+// geocodeEntry 缓存中的一个条目
+type geocodeEntry struct {
+	key   string
+	place *OWMGeocode
+}
+
+// newGeocodeCache 创建最多保存 size 个条目的缓存
+func newGeocodeCache(size int) *geocodeCache {
+	return &geocodeCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get 返回缓存的结果并标记为最近使用
+func (c *geocodeCache) get(key string) (*OWMGeocode, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*geocodeEntry).place, true
+}
+
+// put 保存结果，超过上限时淘汰最久未使用的条目
+func (c *geocodeCache) put(key string, place *OWMGeocode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*geocodeEntry).place = place
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&geocodeEntry{key: key, place: place})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*geocodeEntry).key)
+	}
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestGeocodeCache_Evicts(t *testing.T) {
+	c := newGeocodeCache(2)
+	c.put("a", &OWMGeocode{Name: "A"})
+	c.put("b", &OWMGeocode{Name: "B"})
+
+	// 访问 a 之后，b 是最久未使用的条目
+	if place, ok := c.get("a"); !ok || place.Name != "A" {
+		t.Fatalf("期望命中 a，实际为 %v", place)
+	}
+	c.put("c", &OWMGeocode{Name: "C"})
+	if _, ok := c.get("b"); ok {
+		t.Error("期望淘汰最久未使用的 b")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("期望保留最近使用的 a")
+	}
+
+	for i := 0; i < 100; i++ {
+		c.put("coord:"+strconv.Itoa(i), &OWMGeocode{})
+	}
+	if len(c.items) != 2 || c.order.Len() != 2 {
+		t.Errorf("期望缓存不超过 2 个条目，实际为 %d", len(c.items))
+	}
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,22 +13,45 @@ import (
 
 	"gin-weather/internal/config"
 	"gin-weather/internal/model"
+	"gin-weather/internal/quota"
 )
 
 // OpenWeatherMapService OpenWeatherMap 天气服务实现
 type OpenWeatherMapService struct {
 	config *config.WeatherConfig
 	client *http.Client
+	// limiter 上游 API 的调用配额，为 nil 时不限制
+	limiter *quota.Limiter
+	// geocodes 地理编码结果缓存，城市与坐标的对应关系基本不会变化
+	geocodes *geocodeCache
 }
 
 // NewOpenWeatherMapService 创建 OpenWeatherMap 服务实例
 func NewOpenWeatherMapService(cfg *config.WeatherConfig) *OpenWeatherMapService {
-	return &OpenWeatherMapService{
+	s := &OpenWeatherMapService{
 		config: cfg,
 		client: &http.Client{
 			Timeout: time.Duration(cfg.Timeout) * time.Second,
 		},
+		geocodes: newGeocodeCache(maxGeocodes),
 	}
+	if cfg.RatePerMinute > 0 {
+		s.limiter = quota.NewLimiter(cfg.RatePerMinute, cfg.RatePerMinute)
+	}
+	return s
+}
+
+// acquire 获取一次上游 API 的调用配额，最多等待请求超时时间
+func (s *OpenWeatherMapService) acquire() error {
+	if s.limiter == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+	if err := s.limiter.Wait(ctx); err != nil {
+		return ErrQuotaExceeded
+	}
+	return nil
 }
 
 // GetWeatherByCity 根据城市名称获取天气信息
@@ -54,39 +79,65 @@ func (s *OpenWeatherMapService) GetWeatherByCoordinates(lat, lon float64, units,
 
 // fetchWeather 发起天气 API 请求
 func (s *OpenWeatherMapService) fetchWeather(params url.Values) (*model.WeatherResponse, error) {
+	var owmResp OpenWeatherMapResponse
+	if err := s.getJSON("weather", params, &owmResp); err != nil {
+		return nil, err
+	}
+
+	// 转换为标准格式
+	return s.convertToStandardFormat(&owmResp), nil
+}
+
+// withoutURL 去掉 HTTP 客户端错误中的请求地址。地址的查询参数中包含 API Key，错误信息可能返回给客户端或写入日志
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// getJSON 请求 OpenWeatherMap 2.5 接口并把响应解析到 v
+func (s *OpenWeatherMapService) getJSON(path string, params url.Values, v interface{}) error {
+	return s.getJSONFrom(s.config.BaseURL, path, params, v)
+}
+
+// getJSONFrom 请求 baseURL 下的 OpenWeatherMap 接口并把响应解析到 v
+func (s *OpenWeatherMapService) getJSONFrom(baseURL, path string, params url.Values, v interface{}) error {
+	if err := s.acquire(); err != nil {
+		return err
+	}
+
 	// 构建请求 URL
-	requestURL := fmt.Sprintf("%s/weather?%s", s.config.BaseURL, params.Encode())
+	requestURL := fmt.Sprintf("%s/%s?%s", baseURL, path, params.Encode())
 
 	// 发起 HTTP 请求
 	resp, err := s.client.Get(requestURL)
 	if err != nil {
-		return nil, fmt.Errorf("请求天气 API 失败: %w", err)
+		return fmt.Errorf("请求天气 API 失败: %w", withoutURL(err))
 	}
 	defer resp.Body.Close()
 
 	// 读取响应体
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应体失败: %w", err)
+		return fmt.Errorf("读取响应体失败: %w", err)
 	}
 
 	// 检查 HTTP 状态码
 	if resp.StatusCode != http.StatusOK {
 		var errorResp OpenWeatherMapError
 		if err := json.Unmarshal(body, &errorResp); err == nil {
-			return nil, fmt.Errorf("天气 API 错误 [%d]: %s", errorResp.Cod, errorResp.Message)
+			return fmt.Errorf("天气 API 错误 [%d]: %s", errorResp.Cod, errorResp.Message)
 		}
-		return nil, fmt.Errorf("天气 API 请求失败，状态码: %d", resp.StatusCode)
+		return fmt.Errorf("天气 API 请求失败，状态码: %d", resp.StatusCode)
 	}
 
 	// 解析响应数据
-	var owmResp OpenWeatherMapResponse
-	if err := json.Unmarshal(body, &owmResp); err != nil {
-		return nil, fmt.Errorf("解析天气数据失败: %w", err)
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("解析天气数据失败: %w", err)
 	}
-
-	// 转换为标准格式
-	return s.convertToStandardFormat(&owmResp), nil
+	return nil
 }
 
 // getUnits 获取单位系统，默认为 metric
@@ -107,16 +158,6 @@ func (s *OpenWeatherMapService) getLang(lang string) string {
 
 // convertToStandardFormat 将 OpenWeatherMap 响应转换为标准格式
 func (s *OpenWeatherMapService) convertToStandardFormat(owm *OpenWeatherMapResponse) *model.WeatherResponse {
-	weather := make([]model.Weather, len(owm.Weather))
-	for i, w := range owm.Weather {
-		weather[i] = model.Weather{
-			ID:          w.ID,
-			Main:        w.Main,
-			Description: w.Description,
-			Icon:        w.Icon,
-		}
-	}
-
 	var rain *model.Rain
 	if owm.Rain != nil {
 		rain = &model.Rain{
@@ -149,7 +190,7 @@ func (s *OpenWeatherMapService) convertToStandardFormat(owm *OpenWeatherMapRespo
 			Pressure:    owm.Main.Pressure,
 			Humidity:    owm.Main.Humidity,
 			Visibility:  owm.Visibility,
-			Weather:     weather,
+			Weather:     convertWeather(owm.Weather),
 			Wind: model.Wind{
 				Speed:     owm.Wind.Speed,
 				Direction: owm.Wind.Deg,
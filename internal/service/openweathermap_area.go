@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"gin-weather/internal/model"
+)
+
+// FindNearby 通过 find 接口查询附近城市的天气
+func (s *OpenWeatherMapService) FindNearby(lat, lon float64, limit int, units, lang string) ([]model.WeatherResponse, error) {
+	params := url.Values{}
+	params.Add("lat", strconv.FormatFloat(lat, 'f', 6, 64))
+	params.Add("lon", strconv.FormatFloat(lon, 'f', 6, 64))
+	params.Add("cnt", strconv.Itoa(limit))
+	params.Add("appid", s.config.APIKey)
+	params.Add("units", s.getUnits(units))
+	params.Add("lang", s.getLang(lang))
+
+	return s.fetchArea("find", params)
+}
+
+// FindInBBox 通过 box/city 接口查询经纬度范围内城市的天气
+func (s *OpenWeatherMapService) FindInBBox(bbox [4]float64, zoom int, units, lang string) ([]model.WeatherResponse, error) {
+	params := url.Values{}
+	params.Add("bbox", fmt.Sprintf("%s,%s,%s,%s,%d",
+		strconv.FormatFloat(bbox[0], 'f', -1, 64), strconv.FormatFloat(bbox[1], 'f', -1, 64),
+		strconv.FormatFloat(bbox[2], 'f', -1, 64), strconv.FormatFloat(bbox[3], 'f', -1, 64), zoom))
+	params.Add("appid", s.config.APIKey)
+	params.Add("units", s.getUnits(units))
+	params.Add("lang", s.getLang(lang))
+
+	return s.fetchArea("box/city", params)
+}
+
+// fetchArea 请求返回城市列表的接口，并把每个城市转换为标准格式
+func (s *OpenWeatherMapService) fetchArea(path string, params url.Values) ([]model.WeatherResponse, error) {
+	var owmResp OWMAreaResponse
+	if err := s.getJSON(path, params, &owmResp); err != nil {
+		return nil, err
+	}
+
+	items := make([]model.WeatherResponse, len(owmResp.List))
+	for i := range owmResp.List {
+		item := &owmResp.List[i]
+		item.OpenWeatherMapResponse.Clouds.All = item.Clouds.All
+		if item.Clouds.Today > 0 {
+			item.OpenWeatherMapResponse.Clouds.All = item.Clouds.Today
+		}
+		items[i] = *s.convertToStandardFormat(&item.OpenWeatherMapResponse)
+	}
+	return items, nil
+}
+
+// OWMAreaResponse find 和 box/city 接口的响应，列表中每项与当前天气接口的响应格式基本相同
+type OWMAreaResponse struct {
+	List []OWMAreaItem `json:"list"`
+}
+
+// OWMAreaItem 城市列表中的一项；box/city 接口的云量字段为 today
+type OWMAreaItem struct {
+	OpenWeatherMapResponse
+	Clouds OWMAreaClouds `json:"clouds"`
+}
+
+// OWMAreaClouds 城市列表中的云量信息
+type OWMAreaClouds struct {
+	All   int `json:"all"`
+	Today int `json:"today"`
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin-weather/internal/config"
+)
+
+func TestOpenWeatherMapService_Area(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/find", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("cnt") != "2" || q.Get("lat") != "39.900000" || q.Get("units") != "metric" {
+			t.Errorf("请求参数不正确: %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"message":"accurate","cod":"200","count":2,"list":[
+			{"id":1,"name":"Beijing","coord":{"lat":39.9,"lon":116.4},"main":{"temp":20.5,"humidity":40},"dt":1700000000,
+			 "wind":{"speed":3,"deg":90},"sys":{"country":"CN"},"clouds":{"all":75},"weather":[{"id":803,"main":"Clouds","icon":"04d"}]},
+			{"id":2,"name":"Tianjin","coord":{"lat":39.1,"lon":117.2},"main":{"temp":19},"dt":1700000000,"sys":{"country":"CN"},"clouds":{"all":0},"weather":[]}]}`)
+	})
+	mux.HandleFunc("/box/city", func(w http.ResponseWriter, r *http.Request) {
+		if bbox := r.URL.Query().Get("bbox"); bbox != "116,39,117.5,40.5,8" {
+			t.Errorf("bbox 参数不正确: %s", bbox)
+		}
+		fmt.Fprint(w, `{"cod":200,"calctime":0.3,"cnt":1,"list":[
+			{"id":1,"dt":1700000000,"name":"Beijing","coord":{"Lon":116.4,"Lat":39.9},"main":{"temp":20.5,"pressure":1012},
+			 "wind":{"speed":3,"deg":90},"clouds":{"today":40},"weather":[{"id":500,"main":"Rain","icon":"10n"}]}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	s := NewOpenWeatherMapService(&config.WeatherConfig{APIKey: "test", Timeout: 5, BaseURL: server.URL})
+
+	nearby, err := s.FindNearby(39.9, 116.4, 2, "metric", "zh_cn")
+	if err != nil {
+		t.Fatalf("查询附近城市失败: %v", err)
+	}
+	if len(nearby) != 2 || nearby[0].Location.Name != "Beijing" || nearby[0].Location.Country != "CN" ||
+		nearby[0].Current.Temperature != 20.5 || nearby[0].Current.Clouds.All != 75 || nearby[1].Location.Latitude != 39.1 {
+		t.Errorf("附近城市结果不正确: %+v", nearby)
+	}
+
+	boxed, err := s.FindInBBox([4]float64{116, 39, 117.5, 40.5}, 8, "metric", "zh_cn")
+	if err != nil {
+		t.Fatalf("查询经纬度范围失败: %v", err)
+	}
+	if len(boxed) != 1 || boxed[0].Location.Longitude != 116.4 || boxed[0].Current.Clouds.All != 40 ||
+		boxed[0].Current.Weather[0].Condition.Slug != "rain_light" || boxed[0].Provider != "openweathermap" {
+		t.Errorf("经纬度范围结果不正确: %+v", boxed)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"strings"
+
+	"gin-weather/internal/icons"
+	"gin-weather/internal/model"
+)
+
+// owmConditions OpenWeatherMap 天气状况 ID 到中立状况标识的映射，
+// 参见 https://openweathermap.org/weather-conditions
+var owmConditions = map[int]string{
+	200: model.ConditionThunderstorm,
+	201: model.ConditionThunderstorm,
+	202: model.ConditionThunderstormHeavy,
+	210: model.ConditionThunderstorm,
+	211: model.ConditionThunderstorm,
+	212: model.ConditionThunderstormHeavy,
+	221: model.ConditionThunderstorm,
+	230: model.ConditionThunderstorm,
+	231: model.ConditionThunderstorm,
+	232: model.ConditionThunderstormHeavy,
+
+	300: model.ConditionDrizzleLight,
+	301: model.ConditionDrizzle,
+	302: model.ConditionDrizzleHeavy,
+	310: model.ConditionDrizzleLight,
+	311: model.ConditionDrizzle,
+	312: model.ConditionDrizzleHeavy,
+	313: model.ConditionRainShowersLight,
+	314: model.ConditionRainShowers,
+	321: model.ConditionRainShowersLight,
+
+	500: model.ConditionRainLight,
+	501: model.ConditionRain,
+	502: model.ConditionRainHeavy,
+	503: model.ConditionRainHeavy,
+	504: model.ConditionRainHeavy,
+	511: model.ConditionFreezingRain,
+	520: model.ConditionRainShowersLight,
+	521: model.ConditionRainShowers,
+	522: model.ConditionRainShowersViolent,
+	531: model.ConditionRainShowers,
+
+	600: model.ConditionSnowLight,
+	601: model.ConditionSnow,
+	602: model.ConditionSnowHeavy,
+	611: model.ConditionSleet,
+	612: model.ConditionSleetLight,
+	613: model.ConditionSleet,
+	615: model.ConditionSleetLight,
+	616: model.ConditionSleet,
+	620: model.ConditionSnowShowersLight,
+	621: model.ConditionSnowShowers,
+	622: model.ConditionSnowShowers,
+
+	701: model.ConditionMist,
+	711: model.ConditionSmoke,
+	721: model.ConditionHaze,
+	731: model.ConditionSand,
+	741: model.ConditionFog,
+	751: model.ConditionSand,
+	761: model.ConditionDust,
+	762: model.ConditionSmoke,
+	771: model.ConditionSquall,
+	781: model.ConditionTornado,
+
+	800: model.ConditionClear,
+	801: model.ConditionMainlyClear,
+	802: model.ConditionPartlyCloudy,
+	803: model.ConditionOvercast,
+	804: model.ConditionOvercast,
+}
+
+// owmCondition 把 OpenWeatherMap 的状况 ID 转换为中立的天气状况，图标代码以 n 结尾时为夜间
+func owmCondition(id int, icon string) *model.Condition {
+	slug, ok := owmConditions[id]
+	if !ok {
+		slug = model.ConditionUnknown
+	}
+	return model.NewCondition(slug, strings.HasSuffix(icon, "n"))
+}
+
+// convertWeather 把 OpenWeatherMap 的天气状况转换为标准格式，保留原始 ID 和图标代码，icon_url 指向中立状况的内嵌图标
+func convertWeather(owm []OWMWeather) []model.Weather {
+	weather := make([]model.Weather, len(owm))
+	for i, w := range owm {
+		condition := owmCondition(w.ID, w.Icon)
+		weather[i] = model.Weather{
+			ID:          w.ID,
+			Main:        w.Main,
+			Description: w.Description,
+			Icon:        w.Icon,
+			Condition:   condition,
+			IconURL:     icons.URL(condition),
+		}
+	}
+	return weather
+}
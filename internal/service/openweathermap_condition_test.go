@@ -0,0 +1,41 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"gin-weather/internal/model"
+)
+
+func TestConvertWeather(t *testing.T) {
+	tests := []struct {
+		id      int
+		icon    string
+		code    int
+		slug    string
+		variant string
+	}{
+		{800, "01d", 0, model.ConditionClear, model.VariantDay},
+		{800, "01n", 0, model.ConditionClear, model.VariantNight},
+		{804, "04d", 3, model.ConditionOvercast, model.VariantDay},
+		{500, "10n", 61, model.ConditionRainLight, model.VariantNight},
+		{522, "09d", 82, model.ConditionRainShowersViolent, model.VariantDay},
+		{611, "13d", 69, model.ConditionSleet, model.VariantDay},
+		{741, "50n", 45, model.ConditionFog, model.VariantNight},
+		{212, "11d", 97, model.ConditionThunderstormHeavy, model.VariantDay},
+		{999, "", -1, model.ConditionUnknown, model.VariantDay},
+	}
+	for _, tt := range tests {
+		weather := convertWeather([]OWMWeather{{ID: tt.id, Main: "Main", Icon: tt.icon}})
+		c := weather[0].Condition
+		if want := "/api/v1/icons/" + tt.slug + "-" + tt.variant + ".svg?v="; !strings.HasPrefix(weather[0].IconURL, want) {
+			t.Errorf("%d %s: 期望图标地址为 %s，实际为 %s", tt.id, tt.icon, want, weather[0].IconURL)
+		}
+		if weather[0].ID != tt.id || weather[0].Icon != tt.icon {
+			t.Errorf("%d: 原始 ID 和图标代码应保留，实际为 %+v", tt.id, weather[0])
+		}
+		if c == nil || c.Code != tt.code || c.Slug != tt.slug || c.Variant != tt.variant {
+			t.Errorf("%d %s: 期望 %d %s %s，实际为 %+v", tt.id, tt.icon, tt.code, tt.slug, tt.variant, c)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// GetForecastByCity 根据城市名称获取未来 5 天、每 3 小时一个时段的天气预报
+func (s *OpenWeatherMapService) GetForecastByCity(city, units, lang string) (*model.Forecast, error) {
+	params := url.Values{}
+	params.Add("q", city)
+	params.Add("appid", s.config.APIKey)
+	params.Add("units", s.getUnits(units))
+	params.Add("lang", s.getLang(lang))
+
+	return s.fetchForecast(params)
+}
+
+// GetForecastByCoordinates 根据坐标获取未来 5 天、每 3 小时一个时段的天气预报
+func (s *OpenWeatherMapService) GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error) {
+	params := url.Values{}
+	params.Add("lat", strconv.FormatFloat(lat, 'f', 6, 64))
+	params.Add("lon", strconv.FormatFloat(lon, 'f', 6, 64))
+	params.Add("appid", s.config.APIKey)
+	params.Add("units", s.getUnits(units))
+	params.Add("lang", s.getLang(lang))
+
+	return s.fetchForecast(params)
+}
+
+// fetchForecast 请求预报接口并转换为标准格式
+func (s *OpenWeatherMapService) fetchForecast(params url.Values) (*model.Forecast, error) {
+	var owmResp OWMForecastResponse
+	if err := s.getJSON("forecast", params, &owmResp); err != nil {
+		return nil, err
+	}
+
+	items := make([]model.ForecastItem, len(owmResp.List))
+	for i, entry := range owmResp.List {
+		item := model.ForecastItem{
+			Time:              time.Unix(entry.Dt, 0),
+			Temperature:       entry.Main.Temp,
+			TempMin:           entry.Main.TempMin,
+			TempMax:           entry.Main.TempMax,
+			Humidity:          entry.Main.Humidity,
+			WindSpeed:         entry.Wind.Speed,
+			PrecipProbability: entry.Pop,
+			Weather:           convertWeather(entry.Weather),
+		}
+		if entry.Rain != nil {
+			item.Rain = entry.Rain.ThreeHour
+		}
+		if entry.Snow != nil {
+			item.Snow = entry.Snow.ThreeHour
+		}
+		items[i] = item
+	}
+
+	return &model.Forecast{
+		Location: model.Location{
+			Name:      owmResp.City.Name,
+			Country:   owmResp.City.Country,
+			Latitude:  owmResp.City.Coord.Lat,
+			Longitude: owmResp.City.Coord.Lon,
+			Timezone:  owmResp.City.Timezone,
+		},
+		Items:     items,
+		Timestamp: time.Now().Unix(),
+		Provider:  "openweathermap",
+	}, nil
+}
+
+// OWMForecastResponse OpenWeatherMap 5 天预报接口响应结构体
+type OWMForecastResponse struct {
+	List []OWMForecastEntry `json:"list"`
+	City OWMForecastCity    `json:"city"`
+}
+
+// OWMForecastEntry 单个 3 小时预报时段
+type OWMForecastEntry struct {
+	Dt      int64        `json:"dt"`
+	Main    OWMMain      `json:"main"`
+	Weather []OWMWeather `json:"weather"`
+	Wind    OWMWind      `json:"wind"`
+	Pop     float64      `json:"pop"`
+	Rain    *OWMRain     `json:"rain,omitempty"`
+	Snow    *OWMSnow     `json:"snow,omitempty"`
+}
+
+// OWMForecastCity 预报对应的城市信息
+type OWMForecastCity struct {
+	Name     string `json:"name"`
+	Country  string `json:"country"`
+	Coord    Coord  `json:"coord"`
+	Timezone int    `json:"timezone"`
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gin-weather/internal/model"
+)
+
+// Geocode 通过地理编码接口把城市名称解析为位置，结果会被缓存
+func (s *OpenWeatherMapService) Geocode(city string) (*model.Location, error) {
+	params := url.Values{}
+	params.Add("q", city)
+	params.Add("limit", "1")
+	params.Add("appid", s.config.APIKey)
+
+	place, err := s.geocode("direct", "city:"+strings.ToLower(strings.TrimSpace(city)), params)
+	if err != nil {
+		return nil, err
+	}
+	if place == nil {
+		return nil, fmt.Errorf("未找到城市: %s", city)
+	}
+	return place.location(), nil
+}
+
+// ReverseGeocode 通过反向地理编码接口查询坐标所在的位置，结果会被缓存
+func (s *OpenWeatherMapService) ReverseGeocode(lat, lon float64) (*model.Location, error) {
+	params := url.Values{}
+	params.Add("lat", strconv.FormatFloat(lat, 'f', 6, 64))
+	params.Add("lon", strconv.FormatFloat(lon, 'f', 6, 64))
+	params.Add("limit", "1")
+	params.Add("appid", s.config.APIKey)
+
+	place, err := s.geocode("reverse", fmt.Sprintf("coord:%.4f,%.4f", lat, lon), params)
+	if err != nil {
+		return nil, err
+	}
+	if place == nil {
+		return nil, fmt.Errorf("坐标 %.4f,%.4f 没有对应的位置", lat, lon)
+	}
+	return place.location(), nil
+}
+
+// location 转换为标准的位置信息
+func (g *OWMGeocode) location() *model.Location {
+	return &model.Location{
+		Name:      g.Name,
+		Country:   g.Country,
+		Latitude:  g.Lat,
+		Longitude: g.Lon,
+	}
+}
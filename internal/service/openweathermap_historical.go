@@ -0,0 +1,194 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// GetHistoricalByCity 通过地理编码把城市名称解析为坐标，再查询该坐标 date 当天的历史天气
+func (s *OpenWeatherMapService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	params := url.Values{}
+	params.Add("q", city)
+	params.Add("limit", "1")
+	params.Add("appid", s.config.APIKey)
+
+	place, err := s.geocode("direct", "city:"+strings.ToLower(strings.TrimSpace(city)), params)
+	if err != nil {
+		return nil, err
+	}
+	if place == nil {
+		return nil, fmt.Errorf("未找到城市: %s", city)
+	}
+	return s.fetchHistorical(place, place.Lat, place.Lon, date, units, lang)
+}
+
+// GetHistoricalByCoordinates 查询坐标 date 当天的历史天气，位置名称通过反向地理编码获取
+func (s *OpenWeatherMapService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	params := url.Values{}
+	params.Add("lat", strconv.FormatFloat(lat, 'f', 6, 64))
+	params.Add("lon", strconv.FormatFloat(lon, 'f', 6, 64))
+	params.Add("limit", "1")
+	params.Add("appid", s.config.APIKey)
+
+	// 反向地理编码只用于补充位置名称，失败时不影响查询
+	place, _ := s.geocode("reverse", fmt.Sprintf("coord:%.4f,%.4f", lat, lon), params)
+	return s.fetchHistorical(place, lat, lon, date, units, lang)
+}
+
+// fetchHistorical 请求 One Call timemachine 接口，取 date 当天按经度估算的当地正午时刻的观测
+func (s *OpenWeatherMapService) fetchHistorical(place *OWMGeocode, lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	dt := observationTime(date, lon, time.Now())
+
+	params := url.Values{}
+	params.Add("lat", strconv.FormatFloat(lat, 'f', 6, 64))
+	params.Add("lon", strconv.FormatFloat(lon, 'f', 6, 64))
+	params.Add("dt", strconv.FormatInt(dt.Unix(), 10))
+	params.Add("appid", s.config.APIKey)
+	params.Add("units", s.getUnits(units))
+	params.Add("lang", s.getLang(lang))
+
+	var owmResp OWMTimemachineResponse
+	if err := s.getJSONFrom(s.config.OneCallURL, "timemachine", params, &owmResp); err != nil {
+		return nil, err
+	}
+	if len(owmResp.Data) == 0 {
+		return nil, fmt.Errorf("天气 API 没有返回 %s 的历史数据", date.UTC().Format("2006-01-02"))
+	}
+
+	return s.convertHistorical(&owmResp, place, s.getLang(lang)), nil
+}
+
+// geocode 请求地理编码接口，返回第一个匹配的位置，没有匹配时返回 nil；成功的结果会被缓存
+func (s *OpenWeatherMapService) geocode(path, key string, params url.Values) (*OWMGeocode, error) {
+	if cached, ok := s.geocodes.get(key); ok {
+		return cached, nil
+	}
+
+	var places []OWMGeocode
+	if err := s.getJSONFrom(s.config.GeoURL, path, params, &places); err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, nil
+	}
+	s.geocodes.put(key, &places[0])
+	return &places[0], nil
+}
+
+// convertHistorical 把 timemachine 响应转换为标准格式，没有的字段（如最高、最低温度）取当时的温度
+func (s *OpenWeatherMapService) convertHistorical(owm *OWMTimemachineResponse, place *OWMGeocode, lang string) *model.WeatherResponse {
+	data := &owm.Data[0]
+
+	current := model.Current{
+		Temperature: data.Temp,
+		FeelsLike:   data.FeelsLike,
+		TempMin:     data.Temp,
+		TempMax:     data.Temp,
+		Pressure:    data.Pressure,
+		Humidity:    data.Humidity,
+		Visibility:  data.Visibility,
+		UVIndex:     &data.UVI,
+		Weather:     convertWeather(data.Weather),
+		Wind: model.Wind{
+			Speed:     data.WindSpeed,
+			Direction: data.WindDeg,
+			Gust:      data.WindGust,
+		},
+		Clouds:    model.Clouds{All: data.Clouds},
+		Sunrise:   data.Sunrise,
+		Sunset:    data.Sunset,
+		UpdatedAt: time.Unix(data.Dt, 0),
+	}
+	if data.Rain != nil {
+		current.Rain = &model.Rain{OneHour: data.Rain.OneHour}
+	}
+	if data.Snow != nil {
+		current.Snow = &model.Snow{OneHour: data.Snow.OneHour}
+	}
+
+	location := model.Location{
+		Latitude:  owm.Lat,
+		Longitude: owm.Lon,
+		Timezone:  owm.TimezoneOffset,
+	}
+	if place != nil {
+		location.Name = place.localName(lang)
+		location.Country = place.Country
+	}
+
+	return &model.WeatherResponse{
+		Location:  location,
+		Current:   current,
+		Timestamp: time.Now().Unix(),
+		Provider:  "openweathermap",
+	}
+}
+
+// solarNoon 返回 date 所在 UTC 日期在经度 lon 处的太阳正午时刻，用来近似当地正午
+func solarNoon(date time.Time, lon float64) time.Time {
+	y, m, d := date.UTC().Date()
+	noon := time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	return noon.Add(-time.Duration(lon / 15 * float64(time.Hour))).Truncate(time.Second)
+}
+
+// observationTime 返回查询 date 当天历史观测的时刻：当地正午，查询今天且还没到正午时取 now，
+// 避免向 timemachine 接口请求未来的时刻
+func observationTime(date time.Time, lon float64, now time.Time) time.Time {
+	dt := solarNoon(date, lon)
+	if dt.After(now) {
+		return now.Truncate(time.Second)
+	}
+	return dt
+}
+
+// OWMTimemachineResponse One Call 3.0 timemachine 接口响应结构体
+type OWMTimemachineResponse struct {
+	Lat            float64              `json:"lat"`
+	Lon            float64              `json:"lon"`
+	Timezone       string               `json:"timezone"`
+	TimezoneOffset int                  `json:"timezone_offset"`
+	Data           []OWMTimemachineData `json:"data"`
+}
+
+// OWMTimemachineData 某一时刻的历史观测
+type OWMTimemachineData struct {
+	Dt         int64        `json:"dt"`
+	Sunrise    int64        `json:"sunrise"`
+	Sunset     int64        `json:"sunset"`
+	Temp       float64      `json:"temp"`
+	FeelsLike  float64      `json:"feels_like"`
+	Pressure   int          `json:"pressure"`
+	Humidity   int          `json:"humidity"`
+	UVI        float64      `json:"uvi"`
+	Clouds     int          `json:"clouds"`
+	Visibility int          `json:"visibility"`
+	WindSpeed  float64      `json:"wind_speed"`
+	WindDeg    int          `json:"wind_deg"`
+	WindGust   float64      `json:"wind_gust,omitempty"`
+	Weather    []OWMWeather `json:"weather"`
+	Rain       *OWMRain     `json:"rain,omitempty"`
+	Snow       *OWMSnow     `json:"snow,omitempty"`
+}
+
+// OWMGeocode 地理编码接口返回的位置
+type OWMGeocode struct {
+	Name       string            `json:"name"`
+	LocalNames map[string]string `json:"local_names,omitempty"`
+	Lat        float64           `json:"lat"`
+	Lon        float64           `json:"lon"`
+	Country    string            `json:"country"`
+}
+
+// localName 返回 lang 对应语言的名称，如 zh_cn 对应 local_names 中的 zh，没有时返回默认名称
+func (g *OWMGeocode) localName(lang string) string {
+	code, _, _ := strings.Cut(lang, "_")
+	if name := g.LocalNames[code]; name != "" {
+		return name
+	}
+	return g.Name
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+)
+
+func TestOpenWeatherMapService_GetHistoricalByCity(t *testing.T) {
+	geocodeCalls := 0
+	var dt int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geo/direct", func(w http.ResponseWriter, r *http.Request) {
+		geocodeCalls++
+		if r.URL.Query().Get("q") == "Nowhere" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"name":"Beijing","local_names":{"zh":"北京市","en":"Beijing"},"lat":39.9,"lon":120,"country":"CN"}]`)
+	})
+	mux.HandleFunc("/onecall/timemachine", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("units") != "imperial" || r.URL.Query().Get("lat") != "39.900000" {
+			t.Errorf("请求参数不正确: %s", r.URL.RawQuery)
+		}
+		dt, _ = strconv.ParseInt(r.URL.Query().Get("dt"), 10, 64)
+		fmt.Fprintf(w, `{"lat":39.9,"lon":120,"timezone":"Asia/Shanghai","timezone_offset":28800,"data":[
+			{"dt":%d,"sunrise":1709247000,"sunset":1709288000,"temp":41.5,"feels_like":38.2,"pressure":1021,"humidity":40,
+			 "uvi":2.1,"clouds":20,"visibility":10000,"wind_speed":6.9,"wind_deg":320,
+			 "weather":[{"id":801,"main":"Clouds","description":"少云","icon":"02d"}],"rain":{"1h":0.3}}]}`, dt)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	s := NewOpenWeatherMapService(&config.WeatherConfig{
+		APIKey:     "test",
+		Timeout:    5,
+		OneCallURL: server.URL + "/onecall",
+		GeoURL:     server.URL + "/geo",
+	})
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	weather, err := s.GetHistoricalByCity("Beijing", date, "imperial", "zh_cn")
+	if err != nil {
+		t.Fatalf("获取历史天气失败: %v", err)
+	}
+
+	// 东经 120 度的太阳正午约为 UTC 04:00
+	if want := time.Date(2024, 3, 1, 4, 0, 0, 0, time.UTC); dt != want.Unix() {
+		t.Errorf("期望查询时刻为 %v，实际为 %v", want, time.Unix(dt, 0).UTC())
+	}
+	if weather.Location.Name != "北京市" || weather.Location.Country != "CN" || weather.Location.Timezone != 28800 {
+		t.Errorf("位置信息不正确: %+v", weather.Location)
+	}
+	current := weather.Current
+	if current.Temperature != 41.5 || current.TempMax != 41.5 || current.Wind.Direction != 320 || current.Rain == nil || current.Rain.OneHour != 0.3 {
+		t.Errorf("天气数据转换不正确: %+v", current)
+	}
+	if len(current.Weather) != 1 || current.Weather[0].Description != "少云" || !current.UpdatedAt.Equal(time.Unix(dt, 0)) {
+		t.Errorf("天气状况不正确: %+v", current)
+	}
+
+	// 地理编码结果会被缓存
+	s.GetHistoricalByCity("beijing", date.AddDate(0, 0, 1), "imperial", "en")
+	if geocodeCalls != 1 {
+		t.Errorf("期望只请求一次地理编码，实际为 %d 次", geocodeCalls)
+	}
+
+	if _, err := s.GetHistoricalByCity("Nowhere", date, "imperial", "zh_cn"); err == nil {
+		t.Error("期望未找到城市时返回错误")
+	}
+}
+
+func TestObservationTime(t *testing.T) {
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	noon := time.Date(2024, 3, 5, 4, 16, 0, 0, time.UTC) // 东经 116 度的太阳正午
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"过去的日期取正午", time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC), noon},
+		{"今天已过正午", time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC), noon},
+		{"今天还没到正午取当前时刻", time.Date(2024, 3, 5, 2, 0, 0, 500, time.UTC), time.Date(2024, 3, 5, 2, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := observationTime(date, 116, tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: 期望 %v，实际为 %v", tt.name, tt.want, got)
+		}
+	}
+}
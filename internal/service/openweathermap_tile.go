@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// owmTileLayers 图层名称对应的 OpenWeatherMap 图层
+var owmTileLayers = map[string]string{
+	"precipitation": "precipitation_new",
+	"clouds":        "clouds_new",
+	"temperature":   "temp_new",
+	"wind":          "wind_new",
+	"pressure":      "pressure_new",
+}
+
+// maxTileBytes 单个瓦片的大小上限，防止异常响应占用过多内存
+const maxTileBytes = 1 << 20
+
+// FetchTile 通过 OpenWeatherMap 瓦片接口获取天气图层瓦片，API Key 只在服务端使用
+func (s *OpenWeatherMapService) FetchTile(layer string, z, x, y int) ([]byte, error) {
+	owmLayer, ok := owmTileLayers[layer]
+	if !ok {
+		return nil, fmt.Errorf("不支持的图层: %s", layer)
+	}
+	if err := s.acquire(); err != nil {
+		return nil, err
+	}
+
+	requestURL := fmt.Sprintf("%s/%s/%d/%d/%d.png?appid=%s", s.config.TileURL, owmLayer, z, x, y, s.config.APIKey)
+	resp, err := s.client.Get(requestURL)
+	if err != nil {
+		return nil, fmt.Errorf("请求瓦片失败: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("瓦片请求失败，状态码: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取瓦片失败: %w", err)
+	}
+	if len(data) > maxTileBytes {
+		return nil, fmt.Errorf("瓦片超过 %d 字节", maxTileBytes)
+	}
+	return data, nil
+}
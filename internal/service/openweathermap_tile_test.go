@@ -0,0 +1,50 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin-weather/internal/config"
+)
+
+func TestOpenWeatherMapService_FetchTile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/precipitation_new/3/4/2.png" || r.URL.Query().Get("appid") != "secret" {
+			t.Errorf("请求地址不正确: %s", r.URL)
+		}
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	s := NewOpenWeatherMapService(&config.WeatherConfig{APIKey: "secret", Timeout: 1, TileURL: server.URL, RatePerMinute: 1})
+
+	data, err := s.FetchTile("precipitation", 3, 4, 2)
+	if err != nil || string(data) != "png" {
+		t.Fatalf("获取瓦片失败: %q, %v", data, err)
+	}
+
+	// 每分钟 1 次的配额已用完，等待超过请求超时时间后放弃
+	if _, err := s.FetchTile("precipitation", 3, 4, 2); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("期望返回 ErrQuotaExceeded，实际为 %v", err)
+	}
+
+	if _, err := s.FetchTile("humidity", 0, 0, 0); err == nil {
+		t.Error("期望不支持的图层返回错误")
+	}
+}
+
+func TestOpenWeatherMapService_FetchTileErrorHidesAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	s := NewOpenWeatherMapService(&config.WeatherConfig{APIKey: "secret-key", Timeout: 1, TileURL: server.URL, BaseURL: server.URL})
+	if _, err := s.FetchTile("clouds", 0, 0, 0); err == nil || strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("期望返回不含 API Key 的错误，实际为 %v", err)
+	}
+	if _, err := s.GetWeatherByCity("Beijing", "metric", "zh_cn"); err == nil || strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("期望返回不含 API Key 的错误，实际为 %v", err)
+	}
+}
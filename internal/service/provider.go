@@ -0,0 +1,22 @@
+package service
+
+import "errors"
+
+// ErrProviderSelectionUnsupported 天气服务不支持按名称指定数据提供商
+var ErrProviderSelectionUnsupported = errors.New("未启用按地区路由，不支持指定数据提供商")
+
+// ProviderSelector 可选的接口，由按规则在多个数据提供商之间选择的天气服务实现，
+// 包装天气服务的组件也应实现它并把选择转发给被包装的服务
+type ProviderSelector interface {
+	// WithProvider 返回总是使用名为 name 的数据提供商的天气服务，name 不存在时返回错误
+	WithProvider(name string) (WeatherService, error)
+}
+
+// SelectProvider 返回总是使用名为 name 的数据提供商的天气服务，服务未实现 ProviderSelector 时返回 ErrProviderSelectionUnsupported
+func SelectProvider(weatherService WeatherService, name string) (WeatherService, error) {
+	selector, ok := weatherService.(ProviderSelector)
+	if !ok {
+		return nil, ErrProviderSelectionUnsupported
+	}
+	return selector.WithProvider(name)
+}
@@ -0,0 +1,25 @@
+package service
+
+import "errors"
+
+// ErrQuotaExceeded 在超时时间内没有等到上游 API 的调用配额
+var ErrQuotaExceeded = errors.New("超过天气 API 的调用配额")
+
+// TileLayers 支持的天气图层：降水、云量、温度、风速、气压
+var TileLayers = []string{"precipitation", "clouds", "temperature", "wind", "pressure"}
+
+// ValidTileLayer 判断图层名称是否受支持
+func ValidTileLayer(layer string) bool {
+	for _, l := range TileLayers {
+		if l == layer {
+			return true
+		}
+	}
+	return false
+}
+
+// TileProvider 可选的接口，由能提供天气图层瓦片的天气服务实现
+type TileProvider interface {
+	// FetchTile 获取 layer 图层在缩放级别 z 下坐标为 (x, y) 的 256×256 PNG 瓦片，layer 为 TileLayers 中的名称
+	FetchTile(layer string, z, x, y int) ([]byte, error)
+}
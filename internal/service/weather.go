@@ -1,6 +1,10 @@
 package service
 
 import (
+	"fmt"
+	"time"
+
+	"gin-weather/internal/config"
 	"gin-weather/internal/model"
 )
 
@@ -11,4 +15,20 @@ type WeatherService interface {
 	
 	// GetWeatherByCoordinates 根据坐标获取天气信息
 	GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error)
+
+	// GetHistoricalByCity 根据城市名称获取过去某一天的天气，date 只使用其 UTC 日期，返回当天当地正午前后的观测
+	GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error)
+
+	// GetHistoricalByCoordinates 根据坐标获取过去某一天的天气，date 的含义同 GetHistoricalByCity
+	GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error)
+}
+
+// New 根据 cfg.Provider 创建天气服务实例
+func New(cfg *config.WeatherConfig) (WeatherService, error) {
+	switch cfg.Provider {
+	case "openweathermap":
+		return NewOpenWeatherMapService(cfg), nil
+	default:
+		return nil, fmt.Errorf("不支持的天气服务提供商: %s", cfg.Provider)
+	}
 }
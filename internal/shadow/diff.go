@@ -0,0 +1,119 @@
+package shadow
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gin-weather/internal/derived"
+	"gin-weather/internal/model"
+)
+
+// field 参与比较的字段，取值统一换算为摄氏度和 m/s，容差也按这些单位表示
+type field struct {
+	name      string
+	tolerance float64
+	// circular 为 true 时取值是角度，差值取 -180 到 180 之间
+	circular bool
+	value    func(w *model.WeatherResponse, units string) float64
+}
+
+// conditionField 天气状况字段，按天气状况大类（Main）比较，不使用容差
+const conditionField = "condition"
+
+// defaultFields 参与比较的数值字段及默认容差
+var defaultFields = []field{
+	{name: "temperature", tolerance: 1, value: func(w *model.WeatherResponse, units string) float64 {
+		return derived.ToCelsius(w.Current.Temperature, units)
+	}},
+	{name: "feels_like", tolerance: 1.5, value: func(w *model.WeatherResponse, units string) float64 {
+		return derived.ToCelsius(w.Current.FeelsLike, units)
+	}},
+	{name: "humidity", tolerance: 5, value: func(w *model.WeatherResponse, units string) float64 {
+		return float64(w.Current.Humidity)
+	}},
+	{name: "pressure", tolerance: 2, value: func(w *model.WeatherResponse, units string) float64 {
+		return float64(w.Current.Pressure)
+	}},
+	{name: "wind_speed", tolerance: 1, value: func(w *model.WeatherResponse, units string) float64 {
+		return derived.ToMetersPerSecond(w.Current.Wind.Speed, units)
+	}},
+	{name: "wind_direction", tolerance: 45, circular: true, value: func(w *model.WeatherResponse, units string) float64 {
+		return float64(w.Current.Wind.Direction)
+	}},
+	{name: "clouds", tolerance: 20, value: func(w *model.WeatherResponse, units string) float64 {
+		return float64(w.Current.Clouds.All)
+	}},
+	{name: "visibility", tolerance: 1000, value: func(w *model.WeatherResponse, units string) float64 {
+		return float64(w.Current.Visibility)
+	}},
+}
+
+// parseTolerances 在默认字段的基础上应用 "字段=容差" 形式、以逗号分隔的容差配置
+func parseTolerances(raw string) ([]field, error) {
+	fields := make([]field, len(defaultFields))
+	copy(fields, defaultFields)
+
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("无效的容差配置 %q，格式应为 字段=容差", item)
+		}
+		tolerance, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || tolerance < 0 {
+			return nil, fmt.Errorf("字段 %s 的容差必须是非负数", name)
+		}
+
+		name = strings.TrimSpace(name)
+		found := false
+		for i := range fields {
+			if fields[i].name == name {
+				fields[i].tolerance = tolerance
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("未知的比较字段: %s", name)
+		}
+	}
+	return fields, nil
+}
+
+// snapshot 主服务结果中参与比较的部分。调用方在拿到结果后可能继续修改它，
+// 所以在返回前同步取出这些值，后台比较只使用快照
+type snapshot struct {
+	location    string
+	values      []float64
+	conditionID int
+	condition   string
+}
+
+// takeSnapshot 按 fields 的顺序取出 weather 中参与比较的值
+func takeSnapshot(fields []field, weather *model.WeatherResponse, units string) *snapshot {
+	s := &snapshot{
+		location: weather.Location.Name,
+		values:   make([]float64, len(fields)),
+	}
+	for i := range fields {
+		s.values[i] = fields[i].value(weather, units)
+	}
+	if len(weather.Current.Weather) > 0 {
+		s.conditionID = weather.Current.Weather[0].ID
+		s.condition = weather.Current.Weather[0].Main
+	}
+	return s
+}
+
+// difference 返回 fields[i] 上候选值减主服务值的差
+func difference(f *field, primary, candidate float64) float64 {
+	d := candidate - primary
+	if f.circular {
+		d = math.Mod(math.Mod(d, 360)+540, 360) - 180
+	}
+	return d
+}
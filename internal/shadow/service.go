@@ -0,0 +1,68 @@
+package shadow
+
+import (
+	"time"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+// mirroredService 包装主天气服务，把抽中的当前天气请求同时发给候选服务；
+// 预报和历史天气只转发到主服务
+type mirroredService struct {
+	inner  service.WeatherService
+	shadow *Shadow
+}
+
+// GetWeatherByCity 根据城市名称获取天气信息
+func (s *mirroredService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	start := time.Now()
+	weather, err := s.inner.GetWeatherByCity(city, units, lang)
+	if err == nil {
+		s.shadow.mirror(weather, units, time.Since(start), func() (*model.WeatherResponse, error) {
+			return s.shadow.candidate.GetWeatherByCity(city, units, lang)
+		})
+	}
+	return weather, err
+}
+
+// GetWeatherByCoordinates 根据坐标获取天气信息
+func (s *mirroredService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	start := time.Now()
+	weather, err := s.inner.GetWeatherByCoordinates(lat, lon, units, lang)
+	if err == nil {
+		s.shadow.mirror(weather, units, time.Since(start), func() (*model.WeatherResponse, error) {
+			return s.shadow.candidate.GetWeatherByCoordinates(lat, lon, units, lang)
+		})
+	}
+	return weather, err
+}
+
+// GetForecastByCity 转发到主服务
+func (s *mirroredService) GetForecastByCity(city, units, lang string) (*model.Forecast, error) {
+	return service.FetchForecast(s.inner, &model.WeatherRequest{City: city, Units: units, Lang: lang})
+}
+
+// GetForecastByCoordinates 转发到主服务
+func (s *mirroredService) GetForecastByCoordinates(lat, lon float64, units, lang string) (*model.Forecast, error) {
+	return service.FetchForecast(s.inner, &model.WeatherRequest{Lat: lat, Lon: lon, Units: units, Lang: lang})
+}
+
+// GetHistoricalByCity 转发到主服务
+func (s *mirroredService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.inner.GetHistoricalByCity(city, date, units, lang)
+}
+
+// GetHistoricalByCoordinates 转发到主服务
+func (s *mirroredService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return s.inner.GetHistoricalByCoordinates(lat, lon, date, units, lang)
+}
+
+// WithProvider 把数据提供商的选择转发给主服务，候选服务不受影响
+func (s *mirroredService) WithProvider(name string) (service.WeatherService, error) {
+	inner, err := service.SelectProvider(s.inner, name)
+	if err != nil {
+		return nil, err
+	}
+	return &mirroredService{inner: inner, shadow: s.shadow}, nil
+}
@@ -0,0 +1,302 @@
+// Package shadow 实现影子模式：在切换天气服务提供商之前，把一部分真实请求在后台同时发给候选服务，
+// 逐字段比较两者的结果并统计差异和耗时。
+//
+// 用户始终只收到主服务的结果；候选请求在主服务返回之后异步发起，并且有并发上限，
+// 不会增加面向用户的请求耗时
+package shadow
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/service"
+)
+
+const (
+	// latencyWindow 耗时统计使用的最近请求数
+	latencyWindow = 1000
+	// recentSize 保留的最近超出容差的比较数
+	recentSize = 20
+)
+
+// Shadow 影子模式的候选服务和比较统计
+type Shadow struct {
+	config    *config.ShadowConfig
+	primary   string
+	candidate service.WeatherService
+	fields    []field
+	// inFlight 限制同时进行的候选请求数
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	stopped bool
+	stats   stats
+}
+
+// stats 自 since 以来的比较统计，由 mu 保护
+type stats struct {
+	since           time.Time
+	sampled         int
+	skipped         int
+	candidateErrors int
+	compared        int
+	diverged        int
+	lastError       string
+	// fields 与 Shadow.fields 一一对应，最后一项是天气状况
+	fields           []fieldStat
+	primaryLatency   latencies
+	candidateLatency latencies
+	recent           []model.ShadowDivergence
+}
+
+// fieldStat 单个字段的累计差异
+type fieldStat struct {
+	compared, diverged int
+	sumAbs, maxAbs     float64
+}
+
+// latencies 最近 latencyWindow 次请求的耗时，写满后循环覆盖
+type latencies struct {
+	values []time.Duration
+	next   int
+}
+
+// New 创建影子模式，primary 为主服务提供商的名称，candidate 为候选服务
+func New(cfg *config.ShadowConfig, primary string, candidate service.WeatherService) (*Shadow, error) {
+	fields, err := parseTolerances(cfg.Tolerances)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Shadow{
+		config:    cfg,
+		primary:   primary,
+		candidate: candidate,
+		fields:    fields,
+		inFlight:  make(chan struct{}, cfg.MaxInFlight),
+		stats: stats{
+			since:  time.Now().UTC(),
+			fields: make([]fieldStat, len(fields)+1),
+		},
+	}, nil
+}
+
+// Wrap 返回把抽中的请求同时发给候选服务的天气服务，返回给调用方的始终是 weatherService 的结果
+func (s *Shadow) Wrap(weatherService service.WeatherService) service.WeatherService {
+	return &mirroredService{inner: weatherService, shadow: s}
+}
+
+// Stop 不再发起新的候选请求，并等待进行中的候选请求结束
+func (s *Shadow) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Report 返回当前的比较统计
+func (s *Shadow) Report() *model.ShadowReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := &s.stats
+	report := &model.ShadowReport{
+		Primary:          s.primary,
+		Candidate:        s.config.Provider,
+		SamplePercent:    s.config.SamplePercent,
+		Since:            st.since,
+		Sampled:          st.sampled,
+		Skipped:          st.skipped,
+		CandidateErrors:  st.candidateErrors,
+		Compared:         st.compared,
+		Diverged:         st.diverged,
+		LastError:        st.lastError,
+		Fields:           make([]model.ShadowFieldStat, 0, len(st.fields)),
+		PrimaryLatency:   st.primaryLatency.stat(),
+		CandidateLatency: st.candidateLatency.stat(),
+		Recent:           make([]model.ShadowDivergence, 0, len(st.recent)),
+	}
+
+	for i, fs := range st.fields {
+		stat := model.ShadowFieldStat{
+			Field:      conditionField,
+			Compared:   fs.compared,
+			Diverged:   fs.diverged,
+			MaxAbsDiff: round2(fs.maxAbs),
+		}
+		if i < len(s.fields) {
+			stat.Field = s.fields[i].name
+			stat.Tolerance = s.fields[i].tolerance
+		}
+		if fs.compared > 0 {
+			stat.MeanAbsDiff = round2(fs.sumAbs / float64(fs.compared))
+		}
+		report.Fields = append(report.Fields, stat)
+	}
+
+	for i := len(st.recent) - 1; i >= 0; i-- {
+		report.Recent = append(report.Recent, st.recent[i])
+	}
+	return report
+}
+
+// mirror 按抽样比例决定是否把本次请求发给候选服务；primaryLatency 为主服务的耗时，
+// fetch 向候选服务发起同样的请求。候选请求在后台进行，mirror 立即返回
+func (s *Shadow) mirror(weather *model.WeatherResponse, units string, primaryLatency time.Duration, fetch func() (*model.WeatherResponse, error)) {
+	if s.config.SamplePercent <= 0 || rand.IntN(100) >= s.config.SamplePercent {
+		return
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		s.stats.skipped++
+		s.mu.Unlock()
+		return
+	}
+	s.stats.sampled++
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	primary := takeSnapshot(s.fields, weather, units)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.inFlight }()
+
+		start := time.Now()
+		candidate, err := fetch()
+		s.compare(primary, primaryLatency, candidate, time.Since(start), err, units)
+	}()
+}
+
+// compare 比较主服务快照与候选服务的结果并更新统计
+func (s *Shadow) compare(primary *snapshot, primaryLatency time.Duration, weather *model.WeatherResponse, candidateLatency time.Duration, err error, units string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := &s.stats
+	st.primaryLatency.add(primaryLatency)
+	st.candidateLatency.add(candidateLatency)
+	if err != nil {
+		st.candidateErrors++
+		st.lastError = err.Error()
+		return
+	}
+
+	candidate := takeSnapshot(s.fields, weather, units)
+	var diffs []model.FieldDiff
+	for i := range s.fields {
+		f := &s.fields[i]
+		d := difference(f, primary.values[i], candidate.values[i])
+		fs := &st.fields[i]
+		fs.compared++
+		fs.sumAbs += math.Abs(d)
+		fs.maxAbs = math.Max(fs.maxAbs, math.Abs(d))
+		if math.Abs(d) > f.tolerance {
+			fs.diverged++
+			diffs = append(diffs, model.FieldDiff{
+				Field:      f.name,
+				Primary:    round2(primary.values[i]),
+				Candidate:  round2(candidate.values[i]),
+				Difference: round2(d),
+			})
+		}
+	}
+
+	if primary.condition != "" && candidate.condition != "" {
+		fs := &st.fields[len(s.fields)]
+		fs.compared++
+		if primary.condition != candidate.condition {
+			fs.diverged++
+			fs.sumAbs++
+			fs.maxAbs = 1
+			diffs = append(diffs, model.FieldDiff{
+				Field:      conditionField,
+				Primary:    float64(primary.conditionID),
+				Candidate:  float64(candidate.conditionID),
+				Difference: 1,
+			})
+		}
+	}
+
+	st.compared++
+	if len(diffs) == 0 {
+		return
+	}
+	st.diverged++
+	st.recent = append(st.recent, model.ShadowDivergence{
+		Time:     time.Now().UTC(),
+		Location: primary.location,
+		Fields:   diffs,
+	})
+	if len(st.recent) > recentSize {
+		st.recent = st.recent[len(st.recent)-recentSize:]
+	}
+}
+
+// add 记录一次耗时
+func (l *latencies) add(d time.Duration) {
+	if len(l.values) < latencyWindow {
+		l.values = append(l.values, d)
+		return
+	}
+	l.values[l.next] = d
+	l.next = (l.next + 1) % latencyWindow
+}
+
+// stat 计算耗时的平均值和分位数
+func (l *latencies) stat() model.LatencyStat {
+	if len(l.values) == 0 {
+		return model.LatencyStat{}
+	}
+
+	sorted := make([]time.Duration, len(l.values))
+	copy(sorted, l.values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	ms := func(d time.Duration) float64 { return round2(float64(d) / float64(time.Millisecond)) }
+	percentile := func(p float64) time.Duration {
+		return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+	}
+
+	return model.LatencyStat{
+		Samples: len(sorted),
+		Mean:    ms(sum / time.Duration(len(sorted))),
+		P50:     ms(percentile(0.5)),
+		P95:     ms(percentile(0.95)),
+		Max:     ms(sorted[len(sorted)-1]),
+	}
+}
+
+// round2 保留两位小数
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
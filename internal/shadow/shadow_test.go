@@ -0,0 +1,138 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+)
+
+// mockService 模拟天气服务，返回固定温度，delay 模拟上游耗时
+type mockService struct {
+	temperature float64
+	condition   string
+	delay       time.Duration
+	err         error
+}
+
+func (m *mockService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	time.Sleep(m.delay)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &model.WeatherResponse{
+		Location: model.Location{Name: city},
+		Current: model.Current{
+			Temperature: m.temperature,
+			Humidity:    60,
+			Wind:        model.Wind{Speed: 3, Direction: 350},
+			Weather:     []model.Weather{{ID: 800, Main: m.condition}},
+		},
+	}, nil
+}
+
+func (m *mockService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity("coords", units, lang)
+}
+
+func (m *mockService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestShadow_ComparesInBackground(t *testing.T) {
+	cfg := &config.ShadowConfig{Provider: "candidate", SamplePercent: 100, MaxInFlight: 4}
+	s, err := New(cfg, "primary", &mockService{temperature: 22, condition: "Rain", delay: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("创建影子模式失败: %v", err)
+	}
+	weatherService := s.Wrap(&mockService{temperature: 20, condition: "Clear"})
+
+	start := time.Now()
+	weather, err := weatherService.GetWeatherByCity("Beijing", "metric", "zh_cn")
+	if err != nil || weather.Current.Temperature != 20 {
+		t.Fatalf("期望返回主服务的结果，实际为 %+v, %v", weather, err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("候选请求不应增加耗时，实际耗时 %v", elapsed)
+	}
+	// 调用方修改返回结果不影响比较
+	weather.Current.Temperature = 100
+
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("停止影子模式失败: %v", err)
+	}
+	report := s.Report()
+	if report.Sampled != 1 || report.Compared != 1 || report.Diverged != 1 {
+		t.Fatalf("统计不正确: %+v", report)
+	}
+	if report.CandidateLatency.Samples != 1 || report.CandidateLatency.Mean < 100 {
+		t.Errorf("候选服务耗时统计不正确: %+v", report.CandidateLatency)
+	}
+
+	diffs := map[string]float64{}
+	for _, d := range report.Recent[0].Fields {
+		diffs[d.Field] = d.Difference
+	}
+	if len(diffs) != 2 || diffs["temperature"] != 2 || diffs[conditionField] != 1 {
+		t.Errorf("期望温度和天气状况超出容差，实际为 %+v", report.Recent[0].Fields)
+	}
+
+	// 停止后不再发起候选请求
+	weatherService.GetWeatherByCity("Beijing", "metric", "zh_cn")
+	if report := s.Report(); report.Sampled != 1 {
+		t.Errorf("停止后不应再抽样，实际抽样 %d 次", report.Sampled)
+	}
+}
+
+func TestShadow_CandidateErrorsAndSkips(t *testing.T) {
+	cfg := &config.ShadowConfig{Provider: "candidate", SamplePercent: 100, MaxInFlight: 1}
+	s, _ := New(cfg, "primary", &mockService{err: errors.New("upstream error"), delay: 50 * time.Millisecond})
+	weatherService := s.Wrap(&mockService{temperature: 20})
+
+	weatherService.GetWeatherByCity("Beijing", "metric", "zh_cn")
+	weatherService.GetWeatherByCity("Beijing", "metric", "zh_cn")
+	s.Stop(context.Background())
+
+	report := s.Report()
+	if report.Sampled != 1 || report.Skipped != 1 || report.CandidateErrors != 1 || report.LastError != "upstream error" {
+		t.Errorf("统计不正确: %+v", report)
+	}
+}
+
+func TestParseTolerances(t *testing.T) {
+	fields, err := parseTolerances("temperature=0.5, humidity = 10")
+	if err != nil {
+		t.Fatalf("解析容差失败: %v", err)
+	}
+	for _, f := range fields {
+		if f.name == "temperature" && f.tolerance != 0.5 || f.name == "humidity" && f.tolerance != 10 {
+			t.Errorf("字段 %s 的容差为 %v", f.name, f.tolerance)
+		}
+	}
+	if defaultFields[0].tolerance != 1 {
+		t.Error("不应修改默认容差")
+	}
+
+	for _, raw := range []string{"temperature", "temperature=-1", "unknown=1"} {
+		if _, err := parseTolerances(raw); err == nil {
+			t.Errorf("期望 %q 返回错误", raw)
+		}
+	}
+}
+
+func TestDifference_WindDirection(t *testing.T) {
+	f := &field{circular: true}
+	if d := difference(f, 350, 10); d != 20 {
+		t.Errorf("期望 350° 到 10° 的差为 20，实际为 %v", d)
+	}
+	if d := difference(f, 10, 350); d != -20 {
+		t.Errorf("期望 10° 到 350° 的差为 -20，实际为 %v", d)
+	}
+}
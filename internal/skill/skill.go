@@ -0,0 +1,171 @@
+// Package skill 根据预报与实况的配对计算各数据提供商的预报准确度。
+//
+// 计算只依赖传入的配对数据，不涉及存储，可以直接用构造的数据测试
+package skill
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gin-weather/internal/model"
+)
+
+// PrecipThreshold 降水概率达到该值时视为预报有降水
+const PrecipThreshold = 0.5
+
+// LeadBuckets 预报提前量的分组边界，超过最后一个边界的配对不参与计算
+var LeadBuckets = []time.Duration{0, 12 * time.Hour, 24 * time.Hour, 48 * time.Hour, 72 * time.Hour, 120 * time.Hour}
+
+// Pair 一条预报与同一位置、同一时刻实况的配对，温度为摄氏度，风速为 m/s
+type Pair struct {
+	Provider string
+	// Lead 预报有效时间与预报获取时间之差
+	Lead time.Duration
+
+	ForecastTemperature       float64
+	ForecastWindSpeed         float64
+	ForecastPrecipProbability float64 // 0-1
+
+	ObservedTemperature   float64
+	ObservedWindSpeed     float64
+	ObservedPrecipitation bool
+}
+
+// accumulator 一个分组内的累计值
+type accumulator struct {
+	samples                  int
+	tempAbs, tempSum         float64
+	windAbs, windSum         float64
+	hits, misses, falseAlarm int
+	correctNegatives         int
+}
+
+// key 分组键
+type key struct {
+	provider string
+	bucket   int
+}
+
+// Score 按提供商和提前量分组计算温度和风速的 MAE、偏差以及降水预报准确率，数值单位与配对相同
+func Score(pairs []Pair) []model.SkillScore {
+	groups := make(map[key]*accumulator)
+	for i := range pairs {
+		p := &pairs[i]
+		bucket := leadBucket(p.Lead)
+		if bucket < 0 {
+			continue
+		}
+
+		k := key{p.Provider, bucket}
+		acc := groups[k]
+		if acc == nil {
+			acc = &accumulator{}
+			groups[k] = acc
+		}
+		acc.add(p)
+	}
+
+	keys := make([]key, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].provider != keys[j].provider {
+			return keys[i].provider < keys[j].provider
+		}
+		return keys[i].bucket < keys[j].bucket
+	})
+
+	scores := make([]model.SkillScore, len(keys))
+	for i, k := range keys {
+		scores[i] = groups[k].score(k)
+	}
+	return scores
+}
+
+// Convert 把摄氏度和 m/s 计算的误差换算为 units 单位系统，温度误差只按比例换算
+func Convert(scores []model.SkillScore, units string) {
+	if units != "imperial" {
+		return
+	}
+	for i := range scores {
+		s := &scores[i]
+		s.Temperature.MAE = round(s.Temperature.MAE*9/5, 2)
+		s.Temperature.Bias = round(s.Temperature.Bias*9/5, 2)
+		s.WindSpeed.MAE = round(s.WindSpeed.MAE/0.44704, 2)
+		s.WindSpeed.Bias = round(s.WindSpeed.Bias/0.44704, 2)
+	}
+}
+
+// add 累计一条配对
+func (a *accumulator) add(p *Pair) {
+	a.samples++
+
+	d := p.ForecastTemperature - p.ObservedTemperature
+	a.tempAbs += math.Abs(d)
+	a.tempSum += d
+
+	d = p.ForecastWindSpeed - p.ObservedWindSpeed
+	a.windAbs += math.Abs(d)
+	a.windSum += d
+
+	forecast := p.ForecastPrecipProbability >= PrecipThreshold
+	switch {
+	case forecast && p.ObservedPrecipitation:
+		a.hits++
+	case !forecast && p.ObservedPrecipitation:
+		a.misses++
+	case forecast && !p.ObservedPrecipitation:
+		a.falseAlarm++
+	default:
+		a.correctNegatives++
+	}
+}
+
+// score 汇总分组的结果
+func (a *accumulator) score(k key) model.SkillScore {
+	n := float64(a.samples)
+	return model.SkillScore{
+		Provider: k.provider,
+		LeadTime: leadLabel(k.bucket),
+		Samples:  a.samples,
+		Temperature: model.ErrorStat{
+			MAE:  round(a.tempAbs/n, 2),
+			Bias: round(a.tempSum/n, 2),
+		},
+		WindSpeed: model.ErrorStat{
+			MAE:  round(a.windAbs/n, 2),
+			Bias: round(a.windSum/n, 2),
+		},
+		Precipitation: model.PrecipitationSkill{
+			Accuracy:         round(float64(a.hits+a.correctNegatives)/n, 3),
+			Hits:             a.hits,
+			Misses:           a.misses,
+			FalseAlarms:      a.falseAlarm,
+			CorrectNegatives: a.correctNegatives,
+		},
+	}
+}
+
+// leadBucket 返回提前量所在分组的下标，不在任何分组内时返回 -1
+func leadBucket(lead time.Duration) int {
+	for i := 1; i < len(LeadBuckets); i++ {
+		if lead >= LeadBuckets[i-1] && lead < LeadBuckets[i] {
+			return i - 1
+		}
+	}
+	return -1
+}
+
+// leadLabel 返回分组的可读名称，如 12-24h
+func leadLabel(bucket int) string {
+	return fmt.Sprintf("%d-%dh", int(LeadBuckets[bucket].Hours()), int(LeadBuckets[bucket+1].Hours()))
+}
+
+// round 保留 digits 位小数
+func round(v float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(v*p) / p
+}
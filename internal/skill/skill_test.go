@@ -0,0 +1,60 @@
+package skill
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	pairs := []Pair{
+		// owm 0-12h：温度误差 +2、-1，风速误差 +1、+1；一次命中，一次正确的无降水
+		{Provider: "owm", Lead: 3 * time.Hour, ForecastTemperature: 22, ObservedTemperature: 20, ForecastWindSpeed: 4, ObservedWindSpeed: 3, ForecastPrecipProbability: 0.8, ObservedPrecipitation: true},
+		{Provider: "owm", Lead: 11 * time.Hour, ForecastTemperature: 9, ObservedTemperature: 10, ForecastWindSpeed: 2, ObservedWindSpeed: 1, ForecastPrecipProbability: 0.1},
+		// owm 24-48h：一次漏报，一次空报
+		{Provider: "owm", Lead: 30 * time.Hour, ForecastTemperature: 15, ObservedTemperature: 12, ForecastPrecipProbability: 0.2, ObservedPrecipitation: true},
+		{Provider: "owm", Lead: 47 * time.Hour, ForecastTemperature: 15, ObservedTemperature: 12, ForecastPrecipProbability: 0.5},
+		// 另一个提供商的 12-24h
+		{Provider: "alt", Lead: 12 * time.Hour, ForecastTemperature: 10, ObservedTemperature: 10},
+		// 超出分组范围的配对被忽略
+		{Provider: "owm", Lead: 200 * time.Hour, ForecastTemperature: 100},
+		{Provider: "owm", Lead: -time.Hour, ForecastTemperature: 100},
+	}
+
+	scores := Score(pairs)
+	if len(scores) != 3 {
+		t.Fatalf("期望 3 个分组，实际为 %d: %+v", len(scores), scores)
+	}
+
+	alt := scores[0]
+	if alt.Provider != "alt" || alt.LeadTime != "12-24h" || alt.Samples != 1 || alt.Temperature.MAE != 0 {
+		t.Errorf("alt 分组不正确: %+v", alt)
+	}
+
+	short := scores[1]
+	if short.Provider != "owm" || short.LeadTime != "0-12h" || short.Samples != 2 {
+		t.Fatalf("owm 0-12h 分组不正确: %+v", short)
+	}
+	if short.Temperature.MAE != 1.5 || short.Temperature.Bias != 0.5 {
+		t.Errorf("温度误差不正确: %+v", short.Temperature)
+	}
+	if short.WindSpeed.MAE != 1 || short.WindSpeed.Bias != 1 {
+		t.Errorf("风速误差不正确: %+v", short.WindSpeed)
+	}
+	if p := short.Precipitation; p.Accuracy != 1 || p.Hits != 1 || p.CorrectNegatives != 1 {
+		t.Errorf("降水统计不正确: %+v", p)
+	}
+
+	long := scores[2]
+	if long.LeadTime != "24-48h" || long.Temperature.Bias != 3 {
+		t.Errorf("owm 24-48h 分组不正确: %+v", long)
+	}
+	if p := long.Precipitation; p.Accuracy != 0 || p.Misses != 1 || p.FalseAlarms != 1 {
+		t.Errorf("降水统计不正确: %+v", p)
+	}
+
+	// 华氏度下温度误差按比例换算，风速换算为 mph
+	Convert(scores, "imperial")
+	if scores[1].Temperature.MAE != 2.7 || scores[1].WindSpeed.MAE != 2.24 {
+		t.Errorf("单位换算不正确: %+v", scores[1])
+	}
+}
@@ -0,0 +1,169 @@
+// Package summary 根据天气数据生成自然语言摘要，供聊天机器人和推送通知使用
+//
+// 每种语言对应 templates 目录下的一个 text/template 模板，
+// 风力等级、风向和降水强度等措辞由语言词表提供。
+package summary
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+
+	"gin-weather/internal/derived"
+	"gin-weather/internal/model"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates 按语言索引的摘要模板
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// defaultLang 缺少对应语言模板时使用的语言
+const defaultLang = "zh_cn"
+
+// vocabulary 语言词表
+type vocabulary struct {
+	directions    []string // 八方位风向，从北开始顺时针
+	beaufortNames []string // 蒲福风级名称
+	rain          []string // 小、中、大、暴雨
+	snow          []string // 小、中、大雪
+}
+
+var vocabularies = map[string]vocabulary{
+	"zh_cn": {
+		directions:    []string{"北", "东北", "东", "东南", "南", "西南", "西", "西北"},
+		beaufortNames: []string{"无风", "软风", "轻风", "微风", "和风", "清劲风", "强风", "疾风", "大风", "烈风", "狂风", "暴风", "飓风"},
+		rain:          []string{"小雨", "中雨", "大雨", "暴雨"},
+		snow:          []string{"小雪", "中雪", "大雪"},
+	},
+	"en": {
+		directions: []string{"north", "northeast", "east", "southeast", "south", "southwest", "west", "northwest"},
+		beaufortNames: []string{
+			"calm", "light air", "light breeze", "gentle breeze", "moderate breeze", "fresh breeze",
+			"strong breeze", "near gale", "gale", "strong gale", "storm", "violent storm", "hurricane",
+		},
+		rain: []string{"light rain", "moderate rain", "heavy rain", "torrential rain"},
+		snow: []string{"light snow", "moderate snow", "heavy snow"},
+	},
+}
+
+// templateData 模板数据
+type templateData struct {
+	City          string
+	Condition     string
+	Temperature   string
+	FeelsLike     string
+	Calm          bool
+	WindDirection string
+	Beaufort      int
+	BeaufortName  string
+	Precipitation *precipitationData
+}
+
+// precipitationData 降水描述
+type precipitationData struct {
+	Intensity string
+	Amount    string
+}
+
+// Generate 生成天气摘要，units 为响应数据使用的单位系统
+func Generate(resp *model.WeatherResponse, units, lang string) string {
+	lang = resolveLang(lang)
+	vocab := vocabularies[lang]
+	current := &resp.Current
+
+	data := templateData{
+		City:        resp.Location.Name,
+		Temperature: formatTemperature(current.Temperature, units),
+		FeelsLike:   formatTemperature(current.FeelsLike, units),
+	}
+	if len(current.Weather) > 0 {
+		data.Condition = current.Weather[0].Description
+	}
+
+	level := derived.Beaufort(derived.ToMetersPerSecond(current.Wind.Speed, units))
+	data.Calm = level == 0
+	data.Beaufort = level
+	data.BeaufortName = vocab.beaufortNames[min(level, len(vocab.beaufortNames)-1)]
+	data.WindDirection = vocab.directions[directionIndex(current.Wind.Direction)]
+	data.Precipitation = describePrecipitation(current, vocab)
+
+	var sb strings.Builder
+	if err := templates.ExecuteTemplate(&sb, lang+".tmpl", data); err != nil {
+		// 模板在编译时已内置，执行失败说明模板本身有误
+		return fmt.Sprintf("%s %s", data.City, data.Condition)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// resolveLang 匹配模板语言，依次尝试完整语言代码、语言前缀（如 en_us → en）和默认语言
+func resolveLang(lang string) string {
+	lang = strings.ToLower(lang)
+	if _, ok := vocabularies[lang]; ok {
+		return lang
+	}
+	if i := strings.IndexAny(lang, "_-"); i > 0 {
+		if _, ok := vocabularies[lang[:i]]; ok {
+			return lang[:i]
+		}
+	}
+	return defaultLang
+}
+
+// directionIndex 将风向角度换算为八方位下标
+func directionIndex(degrees int) int {
+	return int(math.Round(float64((degrees%360+360)%360)/45)) % 8
+}
+
+// formatTemperature 按单位系统格式化温度
+func formatTemperature(value float64, units string) string {
+	switch units {
+	case "imperial":
+		return fmt.Sprintf("%.0f°F", math.Round(value))
+	case "standard":
+		return fmt.Sprintf("%.0f K", math.Round(value))
+	default:
+		return fmt.Sprintf("%.0f°C", math.Round(value))
+	}
+}
+
+// describePrecipitation 根据最近一小时的降水量描述降水强度，无降水时返回 nil
+func describePrecipitation(current *model.Current, vocab vocabulary) *precipitationData {
+	if current.Snow != nil {
+		if amount := hourlyAmount(current.Snow.OneHour, current.Snow.ThreeHour); amount > 0 {
+			return &precipitationData{
+				Intensity: vocab.snow[intensity(amount, []float64{1, 3})],
+				Amount:    fmt.Sprintf("%.1f", amount),
+			}
+		}
+	}
+	if current.Rain != nil {
+		if amount := hourlyAmount(current.Rain.OneHour, current.Rain.ThreeHour); amount > 0 {
+			return &precipitationData{
+				Intensity: vocab.rain[intensity(amount, []float64{2.5, 8, 16})],
+				Amount:    fmt.Sprintf("%.1f", amount),
+			}
+		}
+	}
+	return nil
+}
+
+// hourlyAmount 返回一小时降水量，缺少一小时数据时按三小时数据折算
+func hourlyAmount(oneHour, threeHour float64) float64 {
+	if oneHour > 0 {
+		return oneHour
+	}
+	return threeHour / 3
+}
+
+// intensity 按阈值确定降水强度等级
+func intensity(amount float64, limits []float64) int {
+	level := 0
+	for level < len(limits) && amount >= limits[level] {
+		level++
+	}
+	return level
+}
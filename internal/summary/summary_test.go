@@ -0,0 +1,43 @@
+package summary
+
+import (
+	"testing"
+
+	"gin-weather/internal/model"
+)
+
+func newResponse() *model.WeatherResponse {
+	return &model.WeatherResponse{
+		Location: model.Location{Name: "北京"},
+		Current: model.Current{
+			Temperature: 25.2,
+			FeelsLike:   26.8,
+			Weather:     []model.Weather{{ID: 800, Main: "Clear", Description: "晴"}},
+			Wind:        model.Wind{Speed: 4.1, Direction: 185},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	resp := newResponse()
+	if got, want := Generate(resp, "metric", "zh_cn"), "北京 今天晴，当前 25°C，体感 27°C，南风 3 级"; got != want {
+		t.Errorf("期望 %q，实际为 %q", want, got)
+	}
+
+	resp.Location.Name = "Beijing"
+	resp.Current.Weather[0].Description = "clear sky"
+	if got, want := Generate(resp, "metric", "en_us"), "Beijing today: clear sky, currently 25°C, feels like 27°C, gentle breeze from the south (force 3)."; got != want {
+		t.Errorf("期望 %q，实际为 %q", want, got)
+	}
+}
+
+func TestGenerateWithPrecipitationAndCalm(t *testing.T) {
+	resp := newResponse()
+	resp.Current.Weather[0].Description = "中雨"
+	resp.Current.Wind = model.Wind{Speed: 0.1}
+	resp.Current.Rain = &model.Rain{OneHour: 3.46}
+
+	if got, want := Generate(resp, "metric", "zh_cn"), "北京 今天中雨，当前 25°C，体感 27°C，无风，中雨（3.5 毫米/小时）"; got != want {
+		t.Errorf("期望 %q，实际为 %q", want, got)
+	}
+}
@@ -0,0 +1,280 @@
+// Package tiles 代理天气图层瓦片，使浏览器不需要持有上游 API Key。
+//
+// 瓦片按 图层/z/x/y.png 缓存在磁盘上，过期后重新获取；缓存目录超过大小上限时淘汰最久未使用的瓦片。
+// 上游失败时如果有过期的缓存，返回过期的瓦片
+package tiles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/service"
+)
+
+// ErrInvalidTile 图层或瓦片坐标无效
+var ErrInvalidTile = errors.New("瓦片参数无效")
+
+// Tile 一个瓦片及其缓存信息
+type Tile struct {
+	Data    []byte
+	ModTime time.Time // 从上游获取的时间
+	Expires time.Time // 缓存过期时间，早于当前时间表示返回的是过期的瓦片
+	ETag    string    // 根据内容计算的实体标签，已包含引号
+}
+
+// entry 磁盘上一个缓存瓦片的索引
+type entry struct {
+	size     int64
+	modTime  time.Time
+	lastUsed time.Time
+}
+
+// call 一次进行中的上游请求，同一个瓦片的并发请求共享结果
+type call struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// Proxy 天气图层瓦片代理
+type Proxy struct {
+	provider service.TileProvider
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	maxZoom  int
+
+	mu       sync.Mutex
+	entries  map[string]*entry
+	size     int64
+	inflight map[string]*call
+}
+
+// New 创建瓦片代理。cfg.CacheDir 不为空时自动创建目录，并索引其中已有的瓦片
+func New(cfg *config.TilesConfig, provider service.TileProvider) (*Proxy, error) {
+	p := &Proxy{
+		provider: provider,
+		dir:      cfg.CacheDir,
+		ttl:      time.Duration(cfg.TTL) * time.Second,
+		maxBytes: int64(cfg.MaxCacheMB) << 20,
+		maxZoom:  cfg.MaxZoom,
+		entries:  make(map[string]*entry),
+		inflight: make(map[string]*call),
+	}
+	if p.dir == "" {
+		return p, nil
+	}
+
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建瓦片缓存目录失败: %w", err)
+	}
+	err := filepath.WalkDir(p.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".png") {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		key, err := filepath.Rel(p.dir, strings.TrimSuffix(path, ".png"))
+		if err != nil {
+			return err
+		}
+		p.entries[filepath.ToSlash(key)] = &entry{size: info.Size(), modTime: info.ModTime(), lastUsed: info.ModTime()}
+		p.size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取瓦片缓存目录失败: %w", err)
+	}
+	p.evict()
+	return p, nil
+}
+
+// Validate 检查图层名称和瓦片坐标：z 不超过最大缩放级别，x、y 在 0 到 2^z-1 之间
+func (p *Proxy) Validate(layer string, z, x, y int) error {
+	if !service.ValidTileLayer(layer) {
+		return fmt.Errorf("%w: 图层必须是 %s 之一", ErrInvalidTile, strings.Join(service.TileLayers, "、"))
+	}
+	if z < 0 || z > p.maxZoom {
+		return fmt.Errorf("%w: 缩放级别必须在 0-%d 之间", ErrInvalidTile, p.maxZoom)
+	}
+	n := 1 << z
+	if x < 0 || x >= n || y < 0 || y >= n {
+		return fmt.Errorf("%w: 缩放级别 %d 下 x、y 必须在 0-%d 之间", ErrInvalidTile, z, n-1)
+	}
+	return nil
+}
+
+// Get 返回瓦片，缓存未过期时不请求上游
+func (p *Proxy) Get(layer string, z, x, y int) (*Tile, error) {
+	if err := p.Validate(layer, z, x, y); err != nil {
+		return nil, err
+	}
+	key := layer + "/" + strconv.Itoa(z) + "/" + strconv.Itoa(x) + "/" + strconv.Itoa(y)
+
+	cached := p.read(key)
+	if cached != nil && time.Now().Before(cached.Expires) {
+		return cached, nil
+	}
+
+	data, err := p.fetch(key, layer, z, x, y)
+	if err != nil {
+		if cached != nil {
+			log.Printf("获取瓦片 %s 失败，返回过期的缓存: %v", key, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := p.write(key, data, now); err != nil {
+		log.Printf("缓存瓦片 %s 失败: %v", key, err)
+	}
+	return newTile(data, now, p.ttl), nil
+}
+
+// fetch 从上游获取瓦片，同一个瓦片同时只有一个上游请求
+func (p *Proxy) fetch(key, layer string, z, x, y int) ([]byte, error) {
+	p.mu.Lock()
+	if c, ok := p.inflight[key]; ok {
+		p.mu.Unlock()
+		<-c.done
+		return c.data, c.err
+	}
+	c := &call{done: make(chan struct{})}
+	p.inflight[key] = c
+	p.mu.Unlock()
+
+	c.data, c.err = p.provider.FetchTile(layer, z, x, y)
+
+	p.mu.Lock()
+	delete(p.inflight, key)
+	p.mu.Unlock()
+	close(c.done)
+	return c.data, c.err
+}
+
+// read 读取缓存的瓦片，没有缓存或文件不可读时返回 nil
+func (p *Proxy) read(key string) *Tile {
+	if p.dir == "" {
+		return nil
+	}
+	p.mu.Lock()
+	e, ok := p.entries[key]
+	if ok {
+		e.lastUsed = time.Now()
+	}
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	data, err := os.ReadFile(p.path(key))
+	if err != nil {
+		p.mu.Lock()
+		if p.entries[key] == e {
+			delete(p.entries, key)
+			p.size -= e.size
+		}
+		p.mu.Unlock()
+		return nil
+	}
+	return newTile(data, e.modTime, p.ttl)
+}
+
+// write 把瓦片写入缓存，先写入临时文件再重命名，避免并发读取到不完整的内容
+func (p *Proxy) write(key string, data []byte, modTime time.Time) error {
+	if p.dir == "" {
+		return nil
+	}
+	path := p.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if old, ok := p.entries[key]; ok {
+		p.size -= old.size
+	}
+	p.entries[key] = &entry{size: int64(len(data)), modTime: modTime, lastUsed: modTime}
+	p.size += int64(len(data))
+	p.evictLocked()
+	return nil
+}
+
+// evict 淘汰最久未使用的瓦片，直到缓存大小不超过上限
+func (p *Proxy) evict() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.evictLocked()
+}
+
+// evictLocked 同 evict，调用方需持有 p.mu
+func (p *Proxy) evictLocked() {
+	if p.size <= p.maxBytes {
+		return
+	}
+	keys := make([]string, 0, len(p.entries))
+	for key := range p.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return p.entries[keys[i]].lastUsed.Before(p.entries[keys[j]].lastUsed)
+	})
+	for _, key := range keys {
+		if p.size <= p.maxBytes {
+			break
+		}
+		if err := os.Remove(p.path(key)); err != nil && !os.IsNotExist(err) {
+			log.Printf("淘汰瓦片缓存 %s 失败: %v", key, err)
+			continue
+		}
+		p.size -= p.entries[key].size
+		delete(p.entries, key)
+	}
+}
+
+// path 返回缓存瓦片的文件路径
+func (p *Proxy) path(key string) string {
+	return filepath.Join(p.dir, filepath.FromSlash(key)+".png")
+}
+
+// newTile 创建瓦片并计算实体标签
+func newTile(data []byte, modTime time.Time, ttl time.Duration) *Tile {
+	sum := sha256.Sum256(data)
+	return &Tile{
+		Data:    data,
+		ModTime: modTime,
+		Expires: modTime.Add(ttl),
+		ETag:    `"` + hex.EncodeToString(sum[:8]) + `"`,
+	}
+}
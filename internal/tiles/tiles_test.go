@@ -0,0 +1,141 @@
+package tiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+)
+
+// mockProvider 返回内容为 "layer/z/x/y#次数" 的瓦片，fail 为 true 时返回错误
+type mockProvider struct {
+	calls atomic.Int32
+	fail  atomic.Bool
+	delay time.Duration
+}
+
+func (m *mockProvider) FetchTile(layer string, z, x, y int) ([]byte, error) {
+	n := m.calls.Add(1)
+	time.Sleep(m.delay)
+	if m.fail.Load() {
+		return nil, errors.New("upstream error")
+	}
+	return []byte(fmt.Sprintf("%s/%d/%d/%d#%d", layer, z, x, y, n)), nil
+}
+
+func newTestProxy(t *testing.T, provider *mockProvider) *Proxy {
+	t.Helper()
+	p, err := New(&config.TilesConfig{CacheDir: t.TempDir(), TTL: 60, MaxCacheMB: 1, MaxZoom: 10}, provider)
+	if err != nil {
+		t.Fatalf("创建瓦片代理失败: %v", err)
+	}
+	return p
+}
+
+func TestProxy_Validate(t *testing.T) {
+	p := newTestProxy(t, &mockProvider{})
+	tests := []struct {
+		layer   string
+		z, x, y int
+		valid   bool
+	}{
+		{"clouds", 0, 0, 0, true},
+		{"temperature", 3, 7, 7, true},
+		{"clouds_new", 0, 0, 0, false},
+		{"clouds", 11, 0, 0, false},
+		{"clouds", 3, 8, 0, false},
+		{"clouds", 3, 0, -1, false},
+	}
+	for _, tt := range tests {
+		err := p.Validate(tt.layer, tt.z, tt.x, tt.y)
+		if (err == nil) != tt.valid || (err != nil && !errors.Is(err, ErrInvalidTile)) {
+			t.Errorf("%s/%d/%d/%d: 期望有效为 %v，实际错误为 %v", tt.layer, tt.z, tt.x, tt.y, tt.valid, err)
+		}
+	}
+}
+
+func TestProxy_CacheAndExpiry(t *testing.T) {
+	provider := &mockProvider{}
+	p := newTestProxy(t, provider)
+
+	first, err := p.Get("clouds", 2, 1, 3)
+	if err != nil {
+		t.Fatalf("获取瓦片失败: %v", err)
+	}
+	second, _ := p.Get("clouds", 2, 1, 3)
+	if provider.calls.Load() != 1 || string(second.Data) != "clouds/2/1/3#1" || second.ETag != first.ETag {
+		t.Errorf("期望第二次请求命中缓存，上游调用 %d 次，内容 %s", provider.calls.Load(), second.Data)
+	}
+	if _, err := os.Stat(filepath.Join(p.dir, "clouds", "2", "1", "3.png")); err != nil {
+		t.Errorf("期望瓦片写入磁盘: %v", err)
+	}
+
+	// 重启后从磁盘恢复索引
+	restarted, _ := New(&config.TilesConfig{CacheDir: p.dir, TTL: 60, MaxCacheMB: 1, MaxZoom: 10}, provider)
+	if tile, _ := restarted.Get("clouds", 2, 1, 3); provider.calls.Load() != 1 || string(tile.Data) != "clouds/2/1/3#1" {
+		t.Errorf("期望重启后命中磁盘缓存，上游调用 %d 次", provider.calls.Load())
+	}
+
+	// 过期后重新获取；上游失败时返回过期的瓦片
+	p.entries["clouds/2/1/3"].modTime = time.Now().Add(-time.Hour)
+	provider.fail.Store(true)
+	stale, err := p.Get("clouds", 2, 1, 3)
+	if err != nil || string(stale.Data) != "clouds/2/1/3#1" || time.Now().Before(stale.Expires) {
+		t.Errorf("期望返回过期的瓦片，实际为 %v, %v", stale, err)
+	}
+	provider.fail.Store(false)
+	fresh, _ := p.Get("clouds", 2, 1, 3)
+	if string(fresh.Data) != "clouds/2/1/3#3" {
+		t.Errorf("期望过期后重新获取，实际内容为 %s", fresh.Data)
+	}
+
+	provider.fail.Store(true)
+	if _, err := p.Get("clouds", 2, 0, 0); err == nil {
+		t.Error("期望没有缓存且上游失败时返回错误")
+	}
+}
+
+func TestProxy_Coalesce(t *testing.T) {
+	provider := &mockProvider{delay: 50 * time.Millisecond}
+	p := newTestProxy(t, provider)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.Get("wind", 1, 1, 1); err != nil {
+				t.Errorf("获取瓦片失败: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if provider.calls.Load() != 1 {
+		t.Errorf("期望并发请求合并为一次上游调用，实际为 %d 次", provider.calls.Load())
+	}
+}
+
+func TestProxy_Evict(t *testing.T) {
+	p := newTestProxy(t, &mockProvider{})
+	p.maxBytes = 40
+
+	// 每个瓦片 14 字节，上限 40 字节最多保留 2 个
+	p.Get("clouds", 1, 0, 0)
+	p.Get("clouds", 1, 0, 1)
+	time.Sleep(time.Millisecond)
+	p.Get("clouds", 1, 0, 0) // 最近使用过，不会被淘汰
+	p.Get("clouds", 1, 1, 0)
+
+	if _, ok := p.entries["clouds/1/0/1"]; ok || len(p.entries) != 2 || p.size > p.maxBytes {
+		t.Errorf("期望淘汰最久未使用的瓦片，剩余 %d 个共 %d 字节", len(p.entries), p.size)
+	}
+	if _, err := os.Stat(p.path("clouds/1/0/1")); !os.IsNotExist(err) {
+		t.Errorf("期望淘汰的瓦片文件被删除: %v", err)
+	}
+}
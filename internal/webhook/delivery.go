@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-weather/internal/model"
+	"gin-weather/internal/notify"
+	"gin-weather/internal/resource"
+)
+
+// 投递请求携带的头部
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-ID"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign 计算签名：对 "时间戳.请求体" 做 HMAC-SHA256，结果为 "sha256=" 加十六进制摘要。
+// 接收方应使用相同方式计算并用常量时间比较，同时检查时间戳以防重放
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver 投递通知，失败时按指数退避重试，每次尝试都会记录到投递历史
+func (m *Manager) deliver(hook model.Webhook, payload *model.WebhookPayload) {
+	defer m.wg.Done()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		m.record(model.WebhookDelivery{WebhookID: hook.ID, Attempt: 1, Error: err.Error(), DeliveredAt: time.Now()})
+		return
+	}
+
+	deliveryID, err := resource.NewID()
+	if err != nil {
+		deliveryID = strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	backoff := m.backoff
+	for attempt := 1; attempt <= m.config.MaxRetries+1; attempt++ {
+		record := m.send(&hook, deliveryID, body)
+		record.Attempt = attempt
+		m.record(record)
+		if record.Success {
+			return
+		}
+
+		if attempt > m.config.MaxRetries {
+			return
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// notifyChannel 通过通知渠道发送告警，渠道自带限流，失败时不重试
+func (m *Manager) notifyChannel(hook model.Webhook, name string, payload *model.WebhookPayload) {
+	defer m.wg.Done()
+
+	id, err := resource.NewID()
+	if err != nil {
+		id = strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	record := model.WebhookDelivery{
+		ID:          id,
+		WebhookID:   hook.ID,
+		Channel:     name,
+		Attempt:     1,
+		DeliveredAt: time.Now(),
+	}
+
+	_, err = m.notifier.Send(m.ctx, name, &notify.Event{
+		Title:   alertTitle(&hook, payload),
+		Reason:  alertReason(&hook, payload),
+		Weather: payload.Weather,
+		Units:   hook.Location.Units,
+		Lang:    hook.Location.Lang,
+	})
+	record.Duration = time.Since(record.DeliveredAt).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Success = true
+	}
+	m.record(record)
+}
+
+// alertTitle 通知渠道消息的标题
+func alertTitle(hook *model.Webhook, payload *model.WebhookPayload) string {
+	if strings.HasPrefix(hook.Location.Lang, "en") {
+		return "Weather alert: " + payload.Weather.Location.Name
+	}
+	return "天气告警：" + payload.Weather.Location.Name
+}
+
+// alertReason 通知渠道消息中的触发原因
+func alertReason(hook *model.Webhook, payload *model.WebhookPayload) string {
+	en := strings.HasPrefix(hook.Location.Lang, "en")
+	switch {
+	case payload.Condition != "" && en:
+		return "Condition met: " + payload.Condition
+	case payload.Condition != "":
+		return "满足条件：" + payload.Condition
+	case en:
+		return fmt.Sprintf("%s %s %g (current %g)", payload.Field, payload.Operator, payload.Threshold, payload.Value)
+	}
+	return fmt.Sprintf("%s %s %g（当前值 %g）", payload.Field, payload.Operator, payload.Threshold, payload.Value)
+}
+
+// send 发送一次投递请求
+func (m *Manager) send(hook *model.Webhook, deliveryID string, body []byte) model.WebhookDelivery {
+	start := time.Now()
+	record := model.WebhookDelivery{
+		ID:          deliveryID,
+		WebhookID:   hook.ID,
+		DeliveredAt: start,
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, time.Duration(m.config.Timeout)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	timestamp := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gin-weather-webhook/1.0")
+	req.Header.Set(HeaderEvent, model.WebhookEventThreshold)
+	req.Header.Set(HeaderID, hook.ID)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, body))
+
+	resp, err := m.client.Do(req)
+	record.Duration = time.Since(start).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	record.StatusCode = resp.StatusCode
+	record.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !record.Success {
+		record.Error = fmt.Sprintf("接收方返回状态码 %d", resp.StatusCode)
+	}
+	return record
+}
+
+// record 保存投递记录，每条规则只保留最近 HistorySize 条
+func (m *Manager) record(delivery model.WebhookDelivery) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// 规则已被删除时不再保留记录
+	if _, ok := m.hooks[delivery.WebhookID]; !ok {
+		return
+	}
+
+	history := append(m.history[delivery.WebhookID], delivery)
+	if over := len(history) - m.config.HistorySize; over > 0 {
+		history = append([]model.WebhookDelivery(nil), history[over:]...)
+	}
+	m.history[delivery.WebhookID] = history
+}
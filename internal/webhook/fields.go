@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"math"
+	"sort"
+
+	"gin-weather/internal/model"
+)
+
+// fieldFuncs 规则可以引用的 Current 字段，键与 JSON 字段路径一致，没有降水数据时按 0 处理
+var fieldFuncs = map[string]func(c *model.Current) float64{
+	"temperature": func(c *model.Current) float64 { return c.Temperature },
+	"feels_like":  func(c *model.Current) float64 { return c.FeelsLike },
+	"temp_min":    func(c *model.Current) float64 { return c.TempMin },
+	"temp_max":    func(c *model.Current) float64 { return c.TempMax },
+	"pressure":    func(c *model.Current) float64 { return float64(c.Pressure) },
+	"humidity":    func(c *model.Current) float64 { return float64(c.Humidity) },
+	"visibility":  func(c *model.Current) float64 { return float64(c.Visibility) },
+	"uv_index": func(c *model.Current) float64 {
+		if c.UVIndex == nil {
+			return math.NaN()
+		}
+		return *c.UVIndex
+	},
+	"wind.speed": func(c *model.Current) float64 { return c.Wind.Speed },
+	"wind.gust":  func(c *model.Current) float64 { return c.Wind.Gust },
+	"clouds.all": func(c *model.Current) float64 { return float64(c.Clouds.All) },
+	"rain.1h": func(c *model.Current) float64 {
+		if c.Rain == nil {
+			return 0
+		}
+		return c.Rain.OneHour
+	},
+	"snow.1h": func(c *model.Current) float64 {
+		if c.Snow == nil {
+			return 0
+		}
+		return c.Snow.OneHour
+	},
+}
+
+// operators 支持的比较运算符
+var operators = map[string]func(value, threshold float64) bool{
+	">":  func(v, t float64) bool { return v > t },
+	">=": func(v, t float64) bool { return v >= t },
+	"<":  func(v, t float64) bool { return v < t },
+	"<=": func(v, t float64) bool { return v <= t },
+	"==": func(v, t float64) bool { return v == t },
+	"!=": func(v, t float64) bool { return v != t },
+}
+
+// Fields 返回规则可以引用的字段列表
+func Fields() []string {
+	names := make([]string, 0, len(fieldFuncs))
+	for name := range fieldFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Match 判断当前天气是否满足规则，同时返回字段的实际值；数据提供商没有该字段的数据时不满足
+func Match(rule *model.Webhook, current *model.Current) (float64, bool) {
+	value := fieldFuncs[rule.Field](current)
+	if math.IsNaN(value) {
+		return value, false
+	}
+	return value, operators[rule.Operator](value, rule.Threshold)
+}
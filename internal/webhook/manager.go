@@ -0,0 +1,389 @@
+// Package webhook 实现阈值告警：按固定间隔检查已注册规则对应位置的当前天气，
+// 满足条件时向目标地址推送带 HMAC-SHA256 签名的通知，失败时按指数退避重试；
+// 规则也可以指定通知渠道，把告警直接发到钉钉、企业微信等聊天工具
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/notify"
+	"gin-weather/internal/resource"
+	"gin-weather/internal/rules"
+	"gin-weather/internal/service"
+)
+
+// ErrNotFound 规则不存在
+var ErrNotFound = errors.New("Webhook 不存在")
+
+// fetchConcurrency 每轮检查时并发查询天气的位置数上限
+const fetchConcurrency = 4
+
+// Manager Webhook 规则管理与后台检查
+type Manager struct {
+	config         *config.WebhooksConfig
+	store          *Store
+	weatherService service.WeatherService
+	notifier       *notify.Notifier
+	client         *http.Client
+	backoff        time.Duration
+
+	mu       sync.Mutex
+	hooks    map[string]*model.Webhook
+	programs map[string]*rules.Program
+	history  map[string][]model.WebhookDelivery
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager 创建管理器并加载磁盘上已有的规则，notifier 为 nil 时规则不能使用通知渠道
+func NewManager(cfg *config.WebhooksConfig, weatherService service.WeatherService, notifier *notify.Notifier) (*Manager, error) {
+	store, err := NewStore(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		config:         cfg,
+		store:          store,
+		weatherService: weatherService,
+		notifier:       notifier,
+		client:         &http.Client{},
+		backoff:        time.Duration(cfg.RetryBackoff) * time.Second,
+		hooks:          make(map[string]*model.Webhook, len(hooks)),
+		programs:       make(map[string]*rules.Program),
+		history:        make(map[string][]model.WebhookDelivery),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	for _, hook := range hooks {
+		if hook.Condition != "" {
+			program, err := rules.Compile(hook.Condition)
+			if err != nil {
+				cancel()
+				return nil, fmt.Errorf("Webhook %s 的条件表达式无效: %w", hook.ID, err)
+			}
+			m.programs[hook.ID] = program
+		}
+		m.hooks[hook.ID] = hook
+	}
+
+	return m, nil
+}
+
+// Start 启动后台检查
+func (m *Manager) Start() {
+	m.wg.Add(1)
+	go m.run()
+}
+
+// Stop 停止后台检查并等待进行中的投递结束，等待中的重试会被放弃
+func (m *Manager) Stop(ctx context.Context) error {
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Create 校验并注册规则，未提供密钥时自动生成；返回值包含密钥
+func (m *Manager) Create(hook *model.Webhook) (*model.Webhook, error) {
+	program, err := m.validate(hook)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := resource.NewID()
+	if err != nil {
+		return nil, err
+	}
+	if hook.Secret == "" {
+		if hook.Secret, err = resource.NewSecret(); err != nil {
+			return nil, err
+		}
+	}
+	hook.ID = id
+	hook.CreatedAt = time.Now()
+	hook.LastTriggeredAt = nil
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks[id] = hook
+	if err := m.store.Save(m.hooks); err != nil {
+		delete(m.hooks, id)
+		return nil, err
+	}
+	if program != nil {
+		m.programs[id] = program
+	}
+
+	created := *hook
+	return &created, nil
+}
+
+// List 按创建时间返回全部规则，不包含密钥
+func (m *Manager) List() []model.Webhook {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]model.Webhook, 0, len(m.hooks))
+	for _, hook := range m.hooks {
+		list = append(list, redact(hook))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Get 返回单条规则，不包含密钥
+func (m *Manager) Get(id string) (*model.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hook, ok := m.hooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := redact(hook)
+	return &copied, nil
+}
+
+// Delete 删除规则及其投递记录
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hook, ok := m.hooks[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(m.hooks, id)
+	if err := m.store.Save(m.hooks); err != nil {
+		m.hooks[id] = hook
+		return err
+	}
+	delete(m.programs, id)
+	delete(m.history, id)
+	return nil
+}
+
+// Deliveries 返回规则最近的投递记录，最新的在前
+func (m *Manager) Deliveries(id string) ([]model.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.hooks[id]; !ok {
+		return nil, ErrNotFound
+	}
+	history := m.history[id]
+	list := make([]model.WebhookDelivery, len(history))
+	for i, delivery := range history {
+		list[len(history)-1-i] = delivery
+	}
+	return list, nil
+}
+
+// run 按固定间隔检查规则
+func (m *Manager) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(m.config.EvalInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.evaluate(time.Now())
+		}
+	}
+}
+
+// evaluate 对每个位置只查询一次天气，再检查该位置上的全部规则
+func (m *Manager) evaluate(now time.Time) {
+	m.mu.Lock()
+	var reqs []model.WeatherRequest
+	rulesByLocation := make(map[model.WeatherRequest][]string)
+	for id, hook := range m.hooks {
+		if _, ok := rulesByLocation[hook.Location]; !ok {
+			reqs = append(reqs, hook.Location)
+		}
+		rulesByLocation[hook.Location] = append(rulesByLocation[hook.Location], id)
+	}
+	m.mu.Unlock()
+
+	if len(reqs) == 0 {
+		return
+	}
+
+	results := service.FetchAll(m.weatherService, reqs, fetchConcurrency)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	triggered := false
+	for i, result := range results {
+		if result.Err != nil {
+			log.Printf("Webhook 检查获取天气失败 (%s): %v", reqs[i].Describe(), result.Err)
+			continue
+		}
+		for _, id := range rulesByLocation[reqs[i]] {
+			hook, ok := m.hooks[id]
+			if !ok || m.coolingDown(hook, now) {
+				continue
+			}
+			payload, matched := m.match(hook, result.Weather)
+			if !matched {
+				continue
+			}
+
+			triggeredAt := now
+			hook.LastTriggeredAt = &triggeredAt
+			triggered = true
+
+			payload.TriggeredAt = now
+			if hook.URL != "" {
+				m.wg.Add(1)
+				go m.deliver(*hook, payload)
+			}
+			for _, name := range hook.Channels {
+				m.wg.Add(1)
+				go m.notifyChannel(*hook, name, payload)
+			}
+		}
+	}
+
+	// 保存触发时间，重启后冷却时间仍然有效
+	if triggered {
+		if err := m.store.Save(m.hooks); err != nil {
+			log.Printf("保存 Webhook 触发时间失败: %v", err)
+		}
+	}
+}
+
+// match 检查规则是否满足，满足时返回通知内容
+func (m *Manager) match(hook *model.Webhook, weather *model.WeatherResponse) (*model.WebhookPayload, bool) {
+	payload := &model.WebhookPayload{
+		Event:     model.WebhookEventThreshold,
+		WebhookID: hook.ID,
+		Weather:   weather,
+	}
+
+	program, ok := m.programs[hook.ID]
+	if !ok {
+		value, matched := Match(hook, &weather.Current)
+		payload.Field = hook.Field
+		payload.Operator = hook.Operator
+		payload.Threshold = hook.Threshold
+		payload.Value = value
+		return payload, matched
+	}
+
+	env := rules.NewEnv(weather, hook.Location.Units)
+	matched, err := program.Eval(env)
+	if err != nil {
+		log.Printf("Webhook %s 条件求值失败: %v", hook.ID, err)
+		return nil, false
+	}
+	payload.Condition = hook.Condition
+	payload.Values = program.Values(env)
+	return payload, matched
+}
+
+// coolingDown 判断规则是否仍处于冷却期
+func (m *Manager) coolingDown(hook *model.Webhook, now time.Time) bool {
+	if hook.LastTriggeredAt == nil {
+		return false
+	}
+	return now.Sub(*hook.LastTriggeredAt) < time.Duration(hook.Cooldown)*time.Second
+}
+
+// validate 校验规则并填充默认值，使用条件表达式时返回编译结果
+func (m *Manager) validate(hook *model.Webhook) (*rules.Program, error) {
+	if hook.URL == "" && len(hook.Channels) == 0 {
+		return nil, resource.Invalidf("url 和 channels 至少需要提供一个")
+	}
+	if hook.URL != "" {
+		target, err := url.Parse(hook.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return nil, resource.Invalidf("url 必须是 http 或 https 地址")
+		}
+	}
+	for _, name := range hook.Channels {
+		if m.notifier == nil || !m.notifier.Has(name) {
+			return nil, resource.Invalidf("通知渠道 %s 不存在", name)
+		}
+	}
+
+	if hook.Location.Units == "" {
+		hook.Location.Units = "metric"
+	}
+	if hook.Location.Lang == "" {
+		hook.Location.Lang = "zh_cn"
+	}
+	if err := hook.Location.Validate(); err != nil {
+		return nil, resource.Invalidf("%v", err)
+	}
+
+	if hook.Cooldown < 0 {
+		return nil, resource.Invalidf("cooldown 不能为负数")
+	}
+	if hook.Cooldown == 0 {
+		hook.Cooldown = m.config.DefaultCooldown
+	}
+
+	if hook.Condition != "" {
+		if hook.Field != "" || hook.Operator != "" {
+			return nil, resource.Invalidf("condition 不能与 field、operator 同时使用")
+		}
+		program, err := rules.Compile(hook.Condition)
+		if err != nil {
+			return nil, resource.Invalidf("条件表达式无效: %v", err)
+		}
+		return program, nil
+	}
+
+	if _, ok := fieldFuncs[hook.Field]; !ok {
+		return nil, resource.Invalidf("不支持的字段: %s，可用字段: %v", hook.Field, Fields())
+	}
+	if _, ok := operators[hook.Operator]; !ok {
+		return nil, resource.Invalidf("不支持的运算符: %s，可用运算符: >、>=、<、<=、==、!=", hook.Operator)
+	}
+	return nil, nil
+}
+
+// redact 返回去掉密钥的副本
+func redact(hook *model.Webhook) model.Webhook {
+	copied := *hook
+	copied.Secret = ""
+	return copied
+}
@@ -0,0 +1,337 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gin-weather/internal/config"
+	"gin-weather/internal/model"
+	"gin-weather/internal/notify"
+)
+
+// mockService 模拟天气服务，阵风固定为 18 m/s，温度固定为 -2°C
+type mockService struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (m *mockService) GetWeatherByCity(city, units, lang string) (*model.WeatherResponse, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	return &model.WeatherResponse{
+		Location: model.Location{Name: city},
+		Current:  model.Current{Temperature: -2, Wind: model.Wind{Speed: 9, Gust: 18}},
+	}, nil
+}
+
+func (m *mockService) GetWeatherByCoordinates(lat, lon float64, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity(fmt.Sprintf("%.2f,%.2f", lat, lon), units, lang)
+}
+
+func (m *mockService) GetHistoricalByCity(city string, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCity(city, units, lang)
+}
+
+func (m *mockService) GetHistoricalByCoordinates(lat, lon float64, date time.Time, units, lang string) (*model.WeatherResponse, error) {
+	return m.GetWeatherByCoordinates(lat, lon, units, lang)
+}
+
+// receiver 记录收到的投递，前 failures 次返回 500
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	if len(r.requests) <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func newTestManager(t *testing.T, dir string, ws *mockService) *Manager {
+	t.Helper()
+	cfg := &config.WebhooksConfig{Dir: dir, EvalInterval: 60, Timeout: 5, MaxRetries: 2, DefaultCooldown: 3600, HistorySize: 10}
+	m, err := NewManager(cfg, ws, nil)
+	if err != nil {
+		t.Fatalf("创建 Webhook 管理器失败: %v", err)
+	}
+	m.backoff = 10 * time.Millisecond
+	return m
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("等待条件满足超时")
+}
+
+func TestManager_TriggerSignedDelivery(t *testing.T) {
+	rcv := &receiver{}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	ws := &mockService{}
+	m := newTestManager(t, t.TempDir(), ws)
+
+	gust, err := m.Create(&model.Webhook{
+		URL:       server.URL,
+		Location:  model.WeatherRequest{City: "Shanghai"},
+		Field:     "wind.gust",
+		Operator:  ">",
+		Threshold: 15,
+	})
+	if err != nil {
+		t.Fatalf("创建规则失败: %v", err)
+	}
+	if gust.Secret == "" || gust.Cooldown != 3600 {
+		t.Errorf("期望自动生成密钥并使用默认冷却时间，实际为 %+v", gust)
+	}
+	// 同一位置上不满足条件的规则
+	if _, err := m.Create(&model.Webhook{
+		URL:       server.URL,
+		Location:  model.WeatherRequest{City: "Shanghai"},
+		Field:     "temperature",
+		Operator:  ">",
+		Threshold: 30,
+	}); err != nil {
+		t.Fatalf("创建规则失败: %v", err)
+	}
+
+	now := time.Now()
+	m.evaluate(now)
+	waitFor(t, func() bool { return rcv.count() == 1 })
+
+	if ws.calls != 1 {
+		t.Errorf("期望同一位置只查询一次天气，实际为 %d 次", ws.calls)
+	}
+
+	req, body := rcv.requests[0], rcv.bodies[0]
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if got, want := req.Header.Get(HeaderSignature), Sign(gust.Secret, timestamp, body); got != want {
+		t.Errorf("签名不匹配: %s != %s", got, want)
+	}
+
+	var payload model.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("解析通知内容失败: %v", err)
+	}
+	if payload.WebhookID != gust.ID || payload.Value != 18 || payload.Weather.Location.Name != "Shanghai" {
+		t.Errorf("通知内容不正确: %+v", payload)
+	}
+
+	// 冷却期内不会再次触发
+	m.evaluate(now.Add(time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	if rcv.count() != 1 {
+		t.Errorf("期望冷却期内不再触发，实际收到 %d 次", rcv.count())
+	}
+
+	m.Stop(t.Context())
+}
+
+func TestManager_RetryAndHistory(t *testing.T) {
+	rcv := &receiver{failures: 2}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	m := newTestManager(t, t.TempDir(), &mockService{})
+	hook, err := m.Create(&model.Webhook{
+		URL:       server.URL,
+		Location:  model.WeatherRequest{City: "Harbin"},
+		Field:     "temperature",
+		Operator:  "<",
+		Threshold: 0,
+	})
+	if err != nil {
+		t.Fatalf("创建规则失败: %v", err)
+	}
+
+	m.evaluate(time.Now())
+	waitFor(t, func() bool { return rcv.count() == 3 })
+	waitFor(t, func() bool {
+		history, _ := m.Deliveries(hook.ID)
+		return len(history) == 3
+	})
+
+	history, _ := m.Deliveries(hook.ID)
+	if !history[0].Success || history[0].Attempt != 3 {
+		t.Errorf("期望第 3 次尝试成功，实际为 %+v", history[0])
+	}
+	if history[2].Success || history[2].StatusCode != http.StatusInternalServerError {
+		t.Errorf("期望第 1 次尝试失败，实际为 %+v", history[2])
+	}
+	if history[0].ID != history[2].ID {
+		t.Error("期望同一次通知的重试共享投递 ID")
+	}
+
+	m.Stop(t.Context())
+}
+
+func TestManager_ConditionRule(t *testing.T) {
+	rcv := &receiver{}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	dir := t.TempDir()
+	m := newTestManager(t, dir, &mockService{})
+	hook, err := m.Create(&model.Webhook{
+		URL:       server.URL,
+		Location:  model.WeatherRequest{City: "Harbin"},
+		Condition: `current.temperature < 0 && current.wind.gust > 15`,
+	})
+	if err != nil {
+		t.Fatalf("创建规则失败: %v", err)
+	}
+
+	// 重新加载后条件表达式仍然生效
+	m = newTestManager(t, dir, &mockService{})
+	m.evaluate(time.Now())
+	waitFor(t, func() bool { return rcv.count() == 1 })
+
+	var payload model.WebhookPayload
+	if err := json.Unmarshal(rcv.bodies[0], &payload); err != nil {
+		t.Fatalf("解析通知内容失败: %v", err)
+	}
+	if payload.WebhookID != hook.ID || payload.Condition != hook.Condition || payload.Values["current.wind.gust"] != 18.0 {
+		t.Errorf("通知内容不正确: %+v", payload)
+	}
+
+	m.Stop(t.Context())
+}
+
+func TestManager_NotifyChannel(t *testing.T) {
+	rcv := &receiver{}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	notifier, err := notify.New([]notify.ChannelConfig{{Name: "team", Type: notify.TypeSlack, URL: server.URL}}, time.Second)
+	if err != nil {
+		t.Fatalf("创建 Notifier 失败: %v", err)
+	}
+	cfg := &config.WebhooksConfig{Dir: t.TempDir(), EvalInterval: 60, Timeout: 5, DefaultCooldown: 3600, HistorySize: 10}
+	m, err := NewManager(cfg, &mockService{}, notifier)
+	if err != nil {
+		t.Fatalf("创建 Webhook 管理器失败: %v", err)
+	}
+
+	if _, err := m.Create(&model.Webhook{Location: model.WeatherRequest{City: "Harbin"}, Field: "temperature", Operator: "<", Channels: []string{"missing"}}); err == nil {
+		t.Error("期望未知渠道返回验证错误")
+	}
+	hook, err := m.Create(&model.Webhook{
+		Location:  model.WeatherRequest{City: "Harbin"},
+		Field:     "temperature",
+		Operator:  "<",
+		Threshold: 0,
+		Channels:  []string{"team"},
+	})
+	if err != nil {
+		t.Fatalf("创建规则失败: %v", err)
+	}
+
+	m.evaluate(time.Now())
+	waitFor(t, func() bool {
+		history, _ := m.Deliveries(hook.ID)
+		return len(history) == 1
+	})
+
+	var body map[string]string
+	json.Unmarshal(rcv.bodies[0], &body)
+	if !strings.Contains(body["text"], "天气告警：Harbin") || !strings.Contains(body["text"], "temperature < 0（当前值 -2）") {
+		t.Errorf("通知内容不正确: %s", body["text"])
+	}
+	history, _ := m.Deliveries(hook.ID)
+	if !history[0].Success || history[0].Channel != "team" {
+		t.Errorf("期望记录渠道投递成功，实际为 %+v", history[0])
+	}
+
+	m.Stop(t.Context())
+}
+
+func TestManager_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	m := newTestManager(t, dir, &mockService{})
+	hook, err := m.Create(&model.Webhook{
+		URL:       "https://example.com/hook",
+		Location:  model.WeatherRequest{Lat: 31.23, Lon: 121.47},
+		Field:     "humidity",
+		Operator:  ">=",
+		Threshold: 90,
+		Cooldown:  600,
+	})
+	if err != nil {
+		t.Fatalf("创建规则失败: %v", err)
+	}
+
+	reloaded := newTestManager(t, dir, &mockService{})
+	got, err := reloaded.Get(hook.ID)
+	if err != nil {
+		t.Fatalf("重启后查询规则失败: %v", err)
+	}
+	if got.Cooldown != 600 || got.Secret != "" {
+		t.Errorf("期望保留规则且不返回密钥，实际为 %+v", got)
+	}
+
+	if err := reloaded.Delete(hook.ID); err != nil {
+		t.Fatalf("删除规则失败: %v", err)
+	}
+	if _, err := newTestManager(t, dir, &mockService{}).Get(hook.ID); err != ErrNotFound {
+		t.Errorf("期望删除后返回 ErrNotFound，实际为 %v", err)
+	}
+}
+
+func TestManager_Validate(t *testing.T) {
+	m := newTestManager(t, t.TempDir(), &mockService{})
+
+	tests := []struct {
+		name string
+		hook model.Webhook
+	}{
+		{"非法地址", newHook(model.WeatherRequest{City: "Beijing"}, "ftp://example.com", "temperature", ">")},
+		{"缺少位置", newHook(model.WeatherRequest{}, "https://example.com", "temperature", ">")},
+		{"未知字段", newHook(model.WeatherRequest{City: "Beijing"}, "https://example.com", "current.temp", ">")},
+		{"未知运算符", newHook(model.WeatherRequest{City: "Beijing"}, "https://example.com", "temperature", "=>")},
+		{"条件类型错误", model.Webhook{URL: "https://example.com", Location: model.WeatherRequest{City: "Beijing"}, Condition: `current.temperature && true`}},
+		{"条件与字段同时使用", model.Webhook{URL: "https://example.com", Location: model.WeatherRequest{City: "Beijing"}, Field: "temperature", Operator: "<", Condition: `true`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.Create(&tt.hook); err == nil {
+				t.Error("期望返回验证错误")
+			}
+		})
+	}
+}
+
+func newHook(loc model.WeatherRequest, url, field, operator string) model.Webhook {
+	return model.Webhook{URL: url, Location: loc, Field: field, Operator: operator}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gin-weather/internal/model"
+)
+
+// rulesFile 规则文件名
+const rulesFile = "webhooks.json"
+
+// Store 将规则以 JSON 文件形式保存在本地目录
+type Store struct {
+	path string
+}
+
+// NewStore 创建存储并确保目录存在
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建 Webhook 目录失败: %w", err)
+	}
+	return &Store{path: filepath.Join(dir, rulesFile)}, nil
+}
+
+// Load 读取全部规则，文件不存在时返回空列表
+func (s *Store) Load() ([]*model.Webhook, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 Webhook 规则失败: %w", err)
+	}
+
+	var hooks []*model.Webhook
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("解析 Webhook 规则失败: %w", err)
+	}
+	return hooks, nil
+}
+
+// Save 按创建时间顺序覆盖写入全部规则
+func (s *Store) Save(hooks map[string]*model.Webhook) error {
+	list := make([]*model.Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		list = append(list, hook)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化 Webhook 规则失败: %w", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中途崩溃留下损坏的文件
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("写入 Webhook 规则失败: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("写入 Webhook 规则失败: %w", err)
+	}
+	return nil
+}